@@ -0,0 +1,905 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"oops/main/infrastructure"
+	"oops/main/internal"
+	"sort"
+	"time"
+)
+
+type studentView struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type courseView struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type teacherView struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type enrollmentView struct {
+	StudentID   int     `json:"student_id"`
+	StudentName string  `json:"student_name"`
+	CourseID    int     `json:"course_id"`
+	CourseName  string  `json:"course_name"`
+	TeacherID   string  `json:"teacher_id"`
+	Score       float64 `json:"score"`
+	Grade       string  `json:"grade,omitempty"`
+}
+
+// attendanceView is a day's attendance or, with a SessionID, attendance at
+// a session on the timetable.
+type attendanceView struct {
+	Date      time.Time                 `json:"date"`
+	Present   bool                      `json:"present"`
+	SessionID int                       `json:"session_id,omitempty"`
+	Period    int                       `json:"period,omitempty"`
+	Type      internal.SessionType      `json:"type,omitempty"`
+	Status    internal.AttendanceStatus `json:"status,omitempty"`
+}
+
+func newEnrollmentView(e internal.EnrollNew) enrollmentView {
+	v := enrollmentView{
+		StudentID:   e.Student.ID(),
+		StudentName: e.Student.Name(),
+		CourseID:    e.Course.Id,
+		CourseName:  e.Course.Name,
+		TeacherID:   e.Teacher.ID,
+		Score:       e.Score(),
+	}
+	if e.Grader != nil {
+		v.Grade, _ = e.Grader.Grade(e.Enrollment)
+	}
+	return v
+}
+
+func (s *Server) listStudents(w http.ResponseWriter, r *http.Request) {
+	students, err := s.service.Students(principal(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := []studentView{}
+	for _, st := range students {
+		out = append(out, studentView{ID: st.ID(), Name: st.Name()})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) getStudent(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "studentID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	st, err := s.service.Student(principal(r), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, studentView{ID: st.ID(), Name: st.Name()})
+}
+
+// getTranscript returns the academic record built from the student's
+// uploaded marks.
+func (s *Server) getTranscript(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "studentID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	record, err := s.service.Transcript(principal(r), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, record)
+}
+
+// getGradeHistory lists the marks replaced on re-evaluation for a student.
+func (s *Server) getGradeHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "studentID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	history, err := s.service.GradeHistory(principal(r), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if history == nil {
+		history = []internal.GradeChange{}
+	}
+	writeJSON(w, http.StatusOK, history)
+}
+
+func (s *Server) listStudentReEvaluations(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "studentID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	requests, err := s.service.StudentReEvaluations(principal(r), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeReEvaluations(w, requests)
+}
+
+func (s *Server) listTeacherReEvaluations(w http.ResponseWriter, r *http.Request) {
+	requests, err := s.service.TeacherReEvaluations(principal(r), r.PathValue("teacherID"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeReEvaluations(w, requests)
+}
+
+func writeReEvaluations(w http.ResponseWriter, requests []*internal.ReEvaluation) {
+	out := []internal.ReEvaluation{}
+	for _, re := range requests {
+		out = append(out, *re)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) fileReEvaluation(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "studentID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body struct {
+		CourseID int    `json:"course_id"`
+		Reason   string `json:"reason"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	re, err := s.service.FileReEvaluation(principal(r), id, body.CourseID, body.Reason)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusCreated, *re)
+}
+
+// reviewReEvaluation decides a re-evaluation request; without a revision
+// the mark is upheld.
+func (s *Server) reviewReEvaluation(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "reEvaluationID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body struct {
+		Revision *internal.Revision `json:"revision,omitempty"`
+		Note     string             `json:"note,omitempty"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	re, err := s.service.ReviewReEvaluation(principal(r), id, body.Revision, body.Note)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusOK, *re)
+}
+
+func (s *Server) listStudentLeaves(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "studentID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	leaves, err := s.service.StudentLeaves(principal(r), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeLeaves(w, leaves)
+}
+
+func (s *Server) listTeacherLeaves(w http.ResponseWriter, r *http.Request) {
+	leaves, err := s.service.TeacherLeaves(principal(r), r.PathValue("teacherID"), internal.LeaveStatus(r.URL.Query().Get("status")))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeLeaves(w, leaves)
+}
+
+// listLeaves lists every leave request, optionally with one status, for
+// heads of department.
+func (s *Server) listLeaves(w http.ResponseWriter, r *http.Request) {
+	leaves, err := s.service.Leaves(principal(r), internal.LeaveStatus(r.URL.Query().Get("status")))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeLeaves(w, leaves)
+}
+
+func writeLeaves(w http.ResponseWriter, leaves []*internal.LeaveRequest) {
+	out := []internal.LeaveRequest{}
+	for _, l := range leaves {
+		out = append(out, *l)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) fileLeave(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "studentID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body struct {
+		CourseID int                `json:"course_id"`
+		Kind     internal.LeaveKind `json:"kind"`
+		From     time.Time          `json:"from"`
+		To       time.Time          `json:"to"`
+		Reason   string             `json:"reason"`
+		Document struct {
+			Title    string `json:"title"`
+			Filename string `json:"filename"`
+			MimeType string `json:"mime_type"`
+			Content  []byte `json:"content"` // base64
+		} `json:"document"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	l, err := s.service.FileLeave(principal(r), internal.LeaveRequest{
+		StudentID: id, CourseID: body.CourseID, Kind: body.Kind, From: body.From, To: body.To, Reason: body.Reason,
+		Document: internal.Document{Title: body.Document.Title, Filename: body.Document.Filename, MimeType: body.Document.MimeType, Content: body.Document.Content},
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusCreated, *l)
+}
+
+func (s *Server) reviewLeave(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "leaveID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body struct {
+		Approve bool   `json:"approve"`
+		Note    string `json:"note,omitempty"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	l, err := s.service.ReviewLeave(principal(r), id, body.Approve, body.Note)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusOK, *l)
+}
+
+func (s *Server) createStudent(w http.ResponseWriter, r *http.Request) {
+	var body studentView
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if body.ID <= 0 {
+		writeError(w, badRequest("student id must be positive"))
+		return
+	}
+	if err := s.service.AddStudent(principal(r), internal.NewStudent(body.ID, body.Name)); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusCreated, body)
+}
+
+func (s *Server) listCourses(w http.ResponseWriter, r *http.Request) {
+	courses, err := s.service.Courses(principal(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := []courseView{}
+	for _, c := range courses {
+		out = append(out, courseView{ID: c.Id, Name: c.Name})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) createCourse(w http.ResponseWriter, r *http.Request) {
+	var body courseView
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.service.AddCourse(principal(r), internal.NewCourse(body.ID, body.Name)); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusCreated, body)
+}
+
+func (s *Server) getGradeScale(w http.ResponseWriter, r *http.Request) {
+	scale, err := s.service.GradeScale(principal(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, scale)
+}
+
+// putGradeScale replaces the grade scale; every applicant's CGPA is
+// recalculated on the new one.
+func (s *Server) putGradeScale(w http.ResponseWriter, r *http.Request) {
+	var body internal.GradeScale
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.service.SetGradeScale(principal(r), &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusOK, &body)
+}
+
+func (s *Server) getAssessment(w http.ResponseWriter, r *http.Request) {
+	courseID, err := pathInt(r, "courseID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	a, err := s.service.Assessment(principal(r), courseID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, a)
+}
+
+// putAssessment declares the components a course is marked on; the course
+// comes from the path.
+func (s *Server) putAssessment(w http.ResponseWriter, r *http.Request) {
+	courseID, err := pathInt(r, "courseID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body struct {
+		Components []internal.AssessmentComponent `json:"components"`
+		Missing    internal.MissingMarks          `json:"missing,omitempty"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	a := &internal.Assessment{CourseID: courseID, Components: body.Components, Missing: body.Missing}
+	if err := s.service.SetAssessment(principal(r), a); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusOK, a)
+}
+
+func (s *Server) listTeachers(w http.ResponseWriter, r *http.Request) {
+	teachers, err := s.service.Teachers(principal(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := []teacherView{}
+	for _, t := range teachers {
+		out = append(out, teacherView{ID: t.ID, Name: t.Name})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) createTeacher(w http.ResponseWriter, r *http.Request) {
+	var body teacherView
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if body.ID == "" {
+		writeError(w, badRequest("teacher id is required"))
+		return
+	}
+	if err := s.service.AddTeacher(principal(r), internal.NewTeacher(body.ID, body.Name)); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusCreated, body)
+}
+
+func (s *Server) assignCourse(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		CourseID int `json:"course_id"`
+		Credits  int `json:"credits"`
+		Semester int `json:"semester,omitempty"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.service.AssignCourse(principal(r), r.PathValue("teacherID"), body.CourseID, body.Credits, body.Semester); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusCreated, body)
+}
+
+func (s *Server) listEnrollments(w http.ResponseWriter, r *http.Request) {
+	studentID, byStudent, err := queryInt(r, "student_id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	courseID, byCourse, err := queryInt(r, "course_id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	enrollments, err := s.service.Enrollments(principal(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := []enrollmentView{}
+	for _, e := range enrollments {
+		if (byStudent && e.Student.ID() != studentID) || (byCourse && e.Course.Id != courseID) {
+			continue
+		}
+		out = append(out, newEnrollmentView(e))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) createEnrollment(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		StudentID int                   `json:"student_id"`
+		CourseID  int                   `json:"course_id"`
+		TeacherID string                `json:"teacher_id"`
+		Grader    internal.GraderRecord `json:"grader"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	grader, err := internal.DecodeGrader(body.Grader)
+	if err != nil {
+		writeError(w, badRequest(err.Error()))
+		return
+	}
+	enrollment, err := s.service.Enroll(principal(r), body.StudentID, body.CourseID, body.TeacherID, grader)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusCreated, newEnrollmentView(enrollment))
+}
+
+func (s *Server) markAttendance(w http.ResponseWriter, r *http.Request) {
+	courseID, err := pathInt(r, "courseID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body struct {
+		StudentID int       `json:"student_id"`
+		TeacherID string    `json:"teacher_id"`
+		Date      time.Time `json:"date"`
+		Present   bool      `json:"present"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if body.Date.IsZero() {
+		writeError(w, badRequest("date is required"))
+		return
+	}
+	if err := s.service.MarkAttendance(principal(r), courseID, body.StudentID, body.TeacherID, body.Present, body.Date); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusCreated, attendanceView{Date: body.Date, Present: body.Present})
+}
+
+func (s *Server) getAttendance(w http.ResponseWriter, r *http.Request) {
+	courseID, err := pathInt(r, "courseID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	studentID, ok, err := queryInt(r, "student_id")
+	if err == nil && !ok {
+		err = badRequest("student_id is required")
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	records, err := s.service.Attendance(principal(r), courseID, studentID, r.URL.Query().Get("teacher_id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sessions, err := s.service.StudentSessions(principal(r), courseID, studentID, r.URL.Query().Get("teacher_id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := []attendanceView{}
+	for date, present := range records {
+		out = append(out, attendanceView{Date: date, Present: present})
+	}
+	for _, a := range sessions {
+		out = append(out, attendanceView{
+			Date: a.Date, Present: a.Status.Attended(),
+			SessionID: a.ID, Period: a.Period, Type: a.Type, Status: a.Status,
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].Date.Equal(out[j].Date) {
+			return out[i].Date.Before(out[j].Date)
+		}
+		return out[i].Period < out[j].Period
+	})
+	writeJSON(w, http.StatusOK, out)
+}
+
+// attendanceSummary returns a student's attendance in a course and whether
+// they are debarred from its exam.
+func (s *Server) attendanceSummary(w http.ResponseWriter, r *http.Request) {
+	studentID, err := pathInt(r, "studentID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	courseID, err := pathInt(r, "courseID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	summary, err := s.service.AttendanceSummary(principal(r), studentID, courseID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+func (s *Server) courseAttendance(w http.ResponseWriter, r *http.Request) {
+	courseID, err := pathInt(r, "courseID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	summary, err := s.service.CourseAttendance(principal(r), r.URL.Query().Get("teacher_id"), courseID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+func (s *Server) attendanceShortages(w http.ResponseWriter, r *http.Request) {
+	shortages, err := s.service.AttendanceShortages(principal(r), r.PathValue("teacherID"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, shortages)
+}
+
+// putMinimumAttendance sets the attendance a course requires, or without a
+// course_id the default for every course.
+func (s *Server) putMinimumAttendance(w http.ResponseWriter, r *http.Request) {
+	var body internal.AttendanceMinimum
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.service.SetMinimumAttendance(principal(r), body); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusOK, body)
+}
+
+func (s *Server) condoneShortage(w http.ResponseWriter, r *http.Request) {
+	courseID, err := pathInt(r, "courseID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body struct {
+		StudentID int    `json:"student_id"`
+		Reason    string `json:"reason"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	c, err := s.service.CondoneShortage(principal(r), body.StudentID, courseID, body.Reason)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusCreated, c)
+}
+
+func (s *Server) listSessions(w http.ResponseWriter, r *http.Request) {
+	courseID, err := pathInt(r, "courseID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sessions, err := s.service.Timetable(principal(r), courseID, r.URL.Query().Get("teacher_id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sessions)
+}
+
+func (s *Server) scheduleSession(w http.ResponseWriter, r *http.Request) {
+	courseID, err := pathInt(r, "courseID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body struct {
+		TeacherID string               `json:"teacher_id"`
+		Date      time.Time            `json:"date"`
+		Period    int                  `json:"period"`
+		Type      internal.SessionType `json:"type"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	session, err := s.service.ScheduleSession(principal(r), internal.ClassSession{
+		CourseID: courseID, TeacherID: body.TeacherID, Date: body.Date, Period: body.Period, Type: body.Type,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusCreated, session)
+}
+
+func (s *Server) sessionRoll(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := pathInt(r, "sessionID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	roll, err := s.service.SessionRoll(principal(r), sessionID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, roll)
+}
+
+// takeAttendance records a session's attendance for the whole class;
+// students left out are marked absent.
+func (s *Server) takeAttendance(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := pathInt(r, "sessionID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body struct {
+		Students []struct {
+			StudentID int                       `json:"student_id"`
+			Status    internal.AttendanceStatus `json:"status"`
+		} `json:"students"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	roll := make(map[int]internal.AttendanceStatus, len(body.Students))
+	for _, st := range body.Students {
+		if _, ok := roll[st.StudentID]; ok {
+			writeError(w, badRequest(fmt.Sprintf("student %d is listed twice", st.StudentID)))
+			return
+		}
+		roll[st.StudentID] = st.Status
+	}
+	taken, err := s.service.TakeAttendance(principal(r), sessionID, roll)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusOK, taken)
+}
+
+// exportAttendance returns a teacher's attendance in a course as a CSV
+// grid of students and sessions.
+func (s *Server) exportAttendance(w http.ResponseWriter, r *http.Request) {
+	courseID, err := pathInt(r, "courseID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sheet, err := s.service.AttendanceSheet(principal(r), courseID, r.URL.Query().Get("teacher_id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var buf bytes.Buffer
+	if err := infrastructure.WriteAttendanceCSV(&buf, sheet); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// importAttendance records the attendance in a CSV grid like the one
+// exportAttendance returns, reporting the rows it skipped.
+func (s *Server) importAttendance(w http.ResponseWriter, r *http.Request) {
+	courseID, err := pathInt(r, "courseID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sheet, err := infrastructure.ReadAttendanceCSV(r.Body, courseID, r.URL.Query().Get("teacher_id"))
+	if err != nil {
+		writeError(w, badRequest("invalid attendance sheet: "+err.Error()))
+		return
+	}
+	res, err := s.service.ImportAttendance(principal(r), sheet)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusOK, res)
+}
+
+func (s *Server) uploadMark(w http.ResponseWriter, r *http.Request) {
+	courseID, err := pathInt(r, "courseID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body struct {
+		TeacherID string  `json:"teacher_id"`
+		StudentID int     `json:"student_id"`
+		Score     float64 `json:"score"`
+		// Component and Attempt make Score the marks in one component of
+		// the course's assessment.
+		Component string `json:"component,omitempty"`
+		Attempt   int    `json:"attempt,omitempty"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if body.Component != "" {
+		err = s.service.UploadComponentMark(principal(r), body.TeacherID, courseID, body.StudentID,
+			internal.ComponentMark{Component: body.Component, Attempt: body.Attempt, Marks: body.Score})
+	} else {
+		err = s.service.UploadStudentMark(principal(r), body.TeacherID, courseID, body.StudentID, body.Score)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusOK, body)
+}
+
+// finaliseMarks grades a student's component marks before every component
+// is marked.
+func (s *Server) finaliseMarks(w http.ResponseWriter, r *http.Request) {
+	courseID, err := pathInt(r, "courseID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body struct {
+		TeacherID string `json:"teacher_id"`
+		StudentID int    `json:"student_id"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.service.FinaliseMarks(principal(r), body.TeacherID, courseID, body.StudentID); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusOK, body)
+}
+
+// curveRequest names the teacher grading their class of a course on a
+// curve, or none to grade the whole course. Without bands the default curve
+// for the method is used.
+type curveRequest struct {
+	TeacherID string               `json:"teacher_id"`
+	Method    internal.CurveMethod `json:"method"`
+	Bands     []internal.CurveBand `json:"bands,omitempty"`
+}
+
+func decodeCurve(r *http.Request) (int, curveRequest, internal.Curve, error) {
+	courseID, err := pathInt(r, "courseID")
+	if err != nil {
+		return 0, curveRequest{}, internal.Curve{}, err
+	}
+	var body curveRequest
+	if err := decodeBody(r, &body); err != nil {
+		return 0, body, internal.Curve{}, err
+	}
+	curve := internal.Curve{Method: body.Method, Bands: body.Bands}
+	if len(curve.Bands) == 0 {
+		curve.Bands = internal.DefaultCurve(body.Method).Bands
+	}
+	return courseID, body, curve, nil
+}
+
+func (s *Server) previewCurve(w http.ResponseWriter, r *http.Request) {
+	courseID, body, curve, err := decodeCurve(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	preview, err := s.service.PreviewCurve(principal(r), body.TeacherID, courseID, curve)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, preview)
+}
+
+func (s *Server) applyCurve(w http.ResponseWriter, r *http.Request) {
+	courseID, body, curve, err := decodeCurve(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	preview, err := s.service.ApplyCurve(principal(r), body.TeacherID, courseID, curve)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusOK, preview)
+}
+
+func (s *Server) courseResults(w http.ResponseWriter, r *http.Request) {
+	courseID, err := pathInt(r, "courseID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	results, err := s.service.CourseResults(principal(r), r.URL.Query().Get("teacher_id"), courseID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, results)
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"net/http"
+	"oops/main/internal"
+	"strings"
+	"time"
+)
+
+// Authenticator identifies the principal making a request.
+type Authenticator interface {
+	Authenticate(r *http.Request) (internal.Principal, error)
+}
+
+// sessionAuthenticator accepts bearer tokens issued by POST /login and acts
+// as the account they were issued for.
+type sessionAuthenticator struct {
+	sessions *internal.SessionManager
+	accounts *internal.AccountRegistry
+}
+
+func (a sessionAuthenticator) Authenticate(r *http.Request) (internal.Principal, error) {
+	session, err := a.sessions.Verify(bearerToken(r))
+	if err != nil {
+		return internal.Principal{}, err
+	}
+	account, err := a.accounts.ByUsername(session.Username)
+	if err != nil {
+		return internal.Principal{}, unauthenticated("account no longer exists")
+	}
+	return account.Principal(), nil
+}
+
+// bearerToken returns the token from an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+type unauthenticatedError struct{ msg string }
+
+func (e unauthenticatedError) Error() string { return e.msg }
+func (e unauthenticatedError) Unwrap() error { return internal.ErrUnauthenticated }
+
+func unauthenticated(msg string) error { return unauthenticatedError{msg: msg} }
+
+type accountView struct {
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	StudentID int    `json:"student_id,omitempty"`
+	TeacherID string `json:"teacher_id,omitempty"`
+}
+
+func newAccountView(a *internal.Account) accountView {
+	return accountView{Username: a.Username, Role: a.Role.String(), StudentID: a.StudentID, TeacherID: a.TeacherID}
+}
+
+type sessionView struct {
+	Token     string      `json:"token"`
+	ExpiresAt time.Time   `json:"expires_at"`
+	Account   accountView `json:"account"`
+}
+
+// login checks the password without holding mu, as hashing it is slow.
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.mu.Lock()
+	credentials := s.service.Credentials(body.Username)
+	s.mu.Unlock()
+	account, err := credentials.Check(body.Password)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	token, session, err := s.sessions.Issue(account.Username)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sessionView{Token: token, ExpiresAt: session.ExpiresAt, Account: newAccountView(account)})
+}
+
+func (s *Server) logout(w http.ResponseWriter, r *http.Request) {
+	if err := s.sessions.Revoke(bearerToken(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) me(w http.ResponseWriter, r *http.Request) {
+	session, err := s.sessions.Verify(bearerToken(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	account, err := s.portal.Accounts.ByUsername(session.Username)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newAccountView(account))
+}
+
+func (s *Server) listAccounts(w http.ResponseWriter, r *http.Request) {
+	accounts, err := s.service.Accounts(principal(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := []accountView{}
+	for _, a := range accounts {
+		out = append(out, newAccountView(a))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// createAccount hashes the new password without holding mu, but only once
+// the caller is known to be allowed to add accounts.
+func (s *Server) createAccount(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	by, err := s.auth.Authenticate(r)
+	if err == nil {
+		err = s.service.Policy.Authorize(by, internal.ActionManageAccounts, internal.Resource{})
+	}
+	s.mu.Unlock()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body struct {
+		accountView
+		Password string `json:"password"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	role, err := internal.ParseRole(body.Role)
+	if err != nil {
+		writeError(w, badRequest(err.Error()))
+		return
+	}
+	account, err := internal.NewAccount(body.Username, body.Password, role, body.StudentID, body.TeacherID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.service.AddAccount(by, account); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusCreated, newAccountView(account))
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"net/http"
+	"oops/main/internal"
+	"strconv"
+)
+
+// Inbox pages hold defaultInboxLimit messages unless the request asks for
+// up to maxInboxLimit.
+const (
+	defaultInboxLimit = 20
+	maxInboxLimit     = 100
+)
+
+type inboxView struct {
+	Notifications []internal.Message `json:"notifications"`
+	Total         int                `json:"total"`
+	Unread        int                `json:"unread"`
+	Offset        int                `json:"offset"`
+	Limit         int                `json:"limit"`
+}
+
+// listNotifications pages through a student's inbox, newest first. The
+// drive_id, kind and unread query parameters filter it; offset and limit
+// page it.
+func (s *Server) listNotifications(w http.ResponseWriter, r *http.Request) {
+	studentID, err := pathInt(r, "studentID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	q, err := inboxQuery(r, studentID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	page, err := s.service.Notifications(principal(r), q)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := inboxView{Notifications: page.Messages, Total: page.Total, Unread: page.Unread, Offset: q.Offset, Limit: q.Limit}
+	if out.Notifications == nil {
+		out.Notifications = []internal.Message{}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func inboxQuery(r *http.Request, studentID int) (internal.InboxQuery, error) {
+	q := internal.InboxQuery{StudentID: studentID, Limit: defaultInboxLimit}
+	var err error
+	if q.DriveID, _, err = queryInt(r, "drive_id"); err != nil {
+		return q, err
+	}
+	if kind := r.URL.Query().Get("kind"); kind != "" {
+		if q.Kind, err = internal.ParseNotificationKind(kind); err != nil {
+			return q, err
+		}
+	}
+	if unread := r.URL.Query().Get("unread"); unread != "" {
+		if q.UnreadOnly, err = strconv.ParseBool(unread); err != nil {
+			return q, badRequest("invalid unread: " + unread)
+		}
+	}
+	if q.Offset, _, err = queryInt(r, "offset"); err != nil {
+		return q, err
+	}
+	if q.Offset < 0 {
+		return q, badRequest("offset must not be negative")
+	}
+	limit, ok, err := queryInt(r, "limit")
+	if err != nil {
+		return q, err
+	}
+	if ok {
+		if limit < 1 || limit > maxInboxLimit {
+			return q, badRequest("limit must be between 1 and " + strconv.Itoa(maxInboxLimit))
+		}
+		q.Limit = limit
+	}
+	return q, nil
+}
+
+type unreadView struct {
+	Unread int `json:"unread"`
+}
+
+// unreadCount returns how many messages the student has left unread.
+func (s *Server) unreadCount(r *http.Request, studentID int) (unreadView, error) {
+	page, err := s.service.Notifications(principal(r), internal.InboxQuery{StudentID: studentID, UnreadOnly: true, Limit: 1})
+	return unreadView{Unread: page.Total}, err
+}
+
+func (s *Server) markNotificationRead(w http.ResponseWriter, r *http.Request) {
+	studentID, err := pathInt(r, "studentID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	id, err := pathInt(r, "notificationID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.service.MarkNotificationsRead(principal(r), studentID, id); err != nil {
+		writeError(w, err)
+		return
+	}
+	out, err := s.unreadCount(r, studentID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusOK, out)
+}
+
+func (s *Server) markAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	studentID, err := pathInt(r, "studentID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if _, err := s.service.MarkAllNotificationsRead(principal(r), studentID); err != nil {
+		writeError(w, err)
+		return
+	}
+	out, err := s.unreadCount(r, studentID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusOK, out)
+}
+
+func (s *Server) getSubscription(w http.ResponseWriter, r *http.Request) {
+	studentID, err := pathInt(r, "studentID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sub, err := s.service.Subscription(principal(r), studentID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sub)
+}
+
+type subscriptionRequest struct {
+	Channels   []string                    `json:"channels"`
+	Kinds      []internal.NotificationKind `json:"kinds"`
+	Email      string                      `json:"email"`
+	WebhookURL string                      `json:"webhook_url"`
+}
+
+func (s *Server) putSubscription(w http.ResponseWriter, r *http.Request) {
+	studentID, err := pathInt(r, "studentID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var req subscriptionRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	if len(req.Channels) == 0 {
+		writeError(w, badRequest("channels is required"))
+		return
+	}
+	sub := internal.Subscription{
+		StudentID:  studentID,
+		Channels:   req.Channels,
+		Kinds:      req.Kinds,
+		Email:      req.Email,
+		WebhookURL: req.WebhookURL,
+	}
+	if err := s.service.Subscribe(principal(r), sub); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusOK, sub)
+}
@@ -0,0 +1,574 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"oops/main/internal"
+	"time"
+)
+
+type driveView struct {
+	ID           int               `json:"id"`
+	CompanyID    int               `json:"company_id"`
+	RoleName     string            `json:"role_name"`
+	StartDate    time.Time         `json:"start_date"`
+	EndDate      time.Time         `json:"end_date"`
+	MinimumGPA   float64           `json:"minimum_gpa"`
+	CTC          int               `json:"ctc"`
+	JobCategory  string            `json:"job_category"`
+	State        string            `json:"state"`
+	Criteria     []string          `json:"criteria"`
+	Rounds       []roundView       `json:"rounds"`
+	Applications []applicationView `json:"applications"`
+}
+
+type roundView struct {
+	Name      string  `json:"name"`
+	Kind      string  `json:"kind"`
+	PassScore float64 `json:"pass_score"`
+}
+
+type roundResultView struct {
+	Round      int       `json:"round"`
+	Score      float64   `json:"score"`
+	Passed     bool      `json:"passed"`
+	RecordedAt time.Time `json:"recorded_at"`
+	Actor      string    `json:"actor"`
+}
+
+type roundFunnelView struct {
+	Round   int    `json:"round"`
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	Reached int    `json:"reached"`
+	Passed  int    `json:"passed"`
+	Failed  int    `json:"failed"`
+	Pending int    `json:"pending"`
+}
+
+type companyView struct {
+	ID     int         `json:"id"`
+	Name   string      `json:"name"`
+	Drives []driveView `json:"drives"`
+}
+
+type applicationView struct {
+	ID          int    `json:"id"`
+	DriveID     int    `json:"drive_id"`
+	StudentID   int    `json:"student_id"`
+	StudentName string `json:"student_name"`
+	Status      string `json:"status"`
+}
+
+type statusChangeView struct {
+	From  string    `json:"from"`
+	To    string    `json:"to"`
+	At    time.Time `json:"at"`
+	Actor string    `json:"actor"`
+}
+
+type offerView struct {
+	ID            int       `json:"id"`
+	ApplicationID int       `json:"application_id"`
+	StudentID     int       `json:"student_id"`
+	DriveID       int       `json:"drive_id"`
+	Status        string    `json:"status"`
+	IssuedAt      time.Time `json:"issued_at"`
+	RespondBy     time.Time `json:"respond_by"`
+}
+
+type applicantView struct {
+	StudentID      int     `json:"student_id"`
+	Name           string  `json:"name"`
+	CGPA           float64 `json:"cgpa"`
+	Status         string  `json:"status"`
+	Department     string  `json:"department,omitempty"`
+	GraduationYear int     `json:"graduation_year,omitempty"`
+}
+
+func newApplicationView(app *internal.Application) applicationView {
+	return applicationView{
+		ID:          app.ID(),
+		DriveID:     app.DriveID(),
+		StudentID:   app.Applicant.ID(),
+		StudentName: app.Applicant.Name(),
+		Status:      app.Status().String(),
+	}
+}
+
+// newDriveView describes d as it stands at now.
+func newDriveView(companyID int, d *internal.Drive, now time.Time) driveView {
+	v := driveView{
+		ID:           d.ID(),
+		CompanyID:    companyID,
+		RoleName:     d.RoleName(),
+		StartDate:    d.StartDate(),
+		EndDate:      d.EndDate(),
+		MinimumGPA:   d.Eligibility().Requirement(),
+		CTC:          d.CTC(),
+		JobCategory:  d.JobCategory().String(),
+		State:        d.State(now).String(),
+		Criteria:     []string{},
+		Rounds:       []roundView{},
+		Applications: []applicationView{},
+	}
+	for _, c := range d.Eligibility().Criteria() {
+		v.Criteria = append(v.Criteria, c.String())
+	}
+	for _, round := range d.Rounds() {
+		v.Rounds = append(v.Rounds, roundView{Name: round.Name, Kind: round.Kind.String(), PassScore: round.PassScore})
+	}
+	for _, app := range d.Applications() {
+		v.Applications = append(v.Applications, newApplicationView(app))
+	}
+	return v
+}
+
+func newCompanyView(c *internal.Company, now time.Time) companyView {
+	v := companyView{ID: c.ID(), Name: c.Name(), Drives: []driveView{}}
+	for _, d := range c.Drives() {
+		v.Drives = append(v.Drives, newDriveView(c.ID(), d, now))
+	}
+	return v
+}
+
+// newOfferView describes o as it stands at now.
+func newOfferView(o *internal.Offer, now time.Time) offerView {
+	return offerView{
+		ID:            o.ID(),
+		ApplicationID: o.ApplicationID(),
+		StudentID:     o.StudentID(),
+		DriveID:       o.DriveID(),
+		Status:        o.StatusAt(now).String(),
+		IssuedAt:      o.IssuedAt(),
+		RespondBy:     o.RespondBy(),
+	}
+}
+
+func newApplicantView(a *internal.Applicant) applicantView {
+	return applicantView{
+		StudentID: a.ID(), Name: a.Name(), CGPA: a.CGPA, Status: a.AcademicRecord.Status,
+		Department: a.Department, GraduationYear: a.GraduationYear,
+	}
+}
+
+func (s *Server) listCompanies(w http.ResponseWriter, r *http.Request) {
+	companies, err := s.service.Companies(principal(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := []companyView{}
+	for _, c := range companies {
+		out = append(out, newCompanyView(c, s.now()))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) createCompany(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if body.Name == "" {
+		writeError(w, badRequest("company name is required"))
+		return
+	}
+	c, err := s.service.AddCompany(principal(r), body.Name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusCreated, newCompanyView(c, s.now()))
+}
+
+func (s *Server) listDrives(w http.ResponseWriter, r *http.Request) {
+	companies, err := s.service.Companies(principal(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out, now := []driveView{}, s.now()
+	for _, c := range companies {
+		for _, d := range c.Drives() {
+			out = append(out, newDriveView(c.ID(), d, now))
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) createDrive(w http.ResponseWriter, r *http.Request) {
+	companyID, err := pathInt(r, "companyID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body struct {
+		RoleName    string    `json:"role_name"`
+		StartDate   time.Time `json:"start_date"`
+		EndDate     time.Time `json:"end_date"`
+		MinimumGPA  float64   `json:"minimum_gpa"`
+		CTC         int       `json:"ctc"`
+		JobCategory string    `json:"job_category"`
+		// Optional criteria beyond the minimum CGPA.
+		MaxBacklogs     *int     `json:"max_backlogs"`
+		MinAttendance   float64  `json:"min_attendance"`
+		Semesters       []int    `json:"semesters"`
+		GraduationYears []int    `json:"graduation_years"`
+		Departments     []string `json:"departments"`
+		// Rule is an eligibility expression such as
+		// "cgpa >= 7.5 && backlogs == 0".
+		Rule string `json:"rule"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	category, err := internal.ParseJobCategory(body.JobCategory)
+	if err != nil {
+		writeError(w, badRequest(err.Error()))
+		return
+	}
+	if body.EndDate.Before(body.StartDate) {
+		writeError(w, badRequest("end_date must not be before start_date"))
+		return
+	}
+	var rule *internal.Rule
+	if body.Rule != "" {
+		if rule, err = internal.ParseRule(body.Rule); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+	d := internal.NewDrive(body.StartDate, body.EndDate, body.RoleName, body.MinimumGPA, body.CTC, category)
+	if body.MaxBacklogs != nil {
+		d.AddCriteria(internal.MaxBacklogs(*body.MaxBacklogs))
+	}
+	if body.MinAttendance > 0 {
+		d.AddCriteria(internal.MinAttendance(body.MinAttendance))
+	}
+	if len(body.Semesters) > 0 {
+		d.AddCriteria(internal.AllowedSemesters(body.Semesters))
+	}
+	if len(body.GraduationYears) > 0 {
+		d.AddCriteria(internal.GraduationYears(body.GraduationYears))
+	}
+	if len(body.Departments) > 0 {
+		d.AddCriteria(internal.Departments(body.Departments))
+	}
+	if rule != nil {
+		d.AddCriteria(rule)
+	}
+	if err := s.service.AddDrive(principal(r), companyID, d); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusCreated, newDriveView(companyID, d, s.now()))
+}
+
+// now is the current time on the portal's clock.
+func (s *Server) now() time.Time {
+	return s.portal.Placement.Now()
+}
+
+func (s *Server) pathDrive(r *http.Request) (int, *internal.Drive, error) {
+	companyID, err := pathInt(r, "companyID")
+	if err != nil {
+		return 0, nil, err
+	}
+	driveID, err := pathInt(r, "driveID")
+	if err != nil {
+		return 0, nil, err
+	}
+	d, err := s.service.Drive(principal(r), companyID, driveID)
+	return companyID, d, err
+}
+
+func (s *Server) getDrive(w http.ResponseWriter, r *http.Request) {
+	companyID, d, err := s.pathDrive(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newDriveView(companyID, d, s.now()))
+}
+
+func (s *Server) listApplicants(w http.ResponseWriter, r *http.Request) {
+	applicants, err := s.service.Applicants(principal(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := []applicantView{}
+	for _, a := range applicants {
+		out = append(out, newApplicantView(a))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// createApplicant registers a known student for placements, building their
+// academic record from the supplied course results.
+func (s *Server) createApplicant(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		StudentID      int                     `json:"student_id"`
+		CourseResults  []internal.CourseResult `json:"course_results"`
+		Department     string                  `json:"department"`
+		GraduationYear int                     `json:"graduation_year"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	for _, cr := range body.CourseResults {
+		if cr.StudentId != body.StudentID {
+			writeError(w, badRequest(fmt.Sprintf("course result for student %d does not belong to student %d", cr.StudentId, body.StudentID)))
+			return
+		}
+	}
+	applicant, err := s.service.AddApplicant(principal(r), body.StudentID, body.CourseResults, body.Department, body.GraduationYear)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusCreated, newApplicantView(applicant))
+}
+
+func (s *Server) getAcademicRecord(w http.ResponseWriter, r *http.Request) {
+	studentID, err := pathInt(r, "studentID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	record, err := s.service.AcademicRecord(principal(r), studentID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, record)
+}
+
+func (s *Server) applyForDrive(w http.ResponseWriter, r *http.Request) {
+	companyID, d, err := s.pathDrive(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body struct {
+		StudentID int `json:"student_id"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.service.ApplyForDrive(principal(r), body.StudentID, companyID, d.ID()); err != nil {
+		writeError(w, err)
+		return
+	}
+	apps := d.Applications()
+	s.commit(w, http.StatusCreated, newApplicationView(apps[len(apps)-1]))
+}
+
+func (s *Server) updateApplicationStatus(w http.ResponseWriter, r *http.Request) {
+	_, d, err := s.pathDrive(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	studentID, err := pathInt(r, "studentID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	status, err := internal.ParseApplicationStatus(body.Status)
+	if err != nil {
+		writeError(w, badRequest(err.Error()))
+		return
+	}
+	if err := s.service.UpdateApplicationStatus(principal(r), studentID, d.ID(), status); err != nil {
+		writeError(w, err)
+		return
+	}
+	for _, app := range d.Applications() {
+		if app.Applicant.ID() == studentID {
+			s.commit(w, http.StatusOK, newApplicationView(app))
+			return
+		}
+	}
+	s.commit(w, http.StatusOK, body)
+}
+
+func (s *Server) applicationHistory(w http.ResponseWriter, r *http.Request) {
+	_, d, err := s.pathDrive(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	studentID, err := pathInt(r, "studentID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	history, err := s.service.ApplicationHistory(principal(r), studentID, d.ID())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := []statusChangeView{}
+	for _, h := range history {
+		out = append(out, statusChangeView{From: h.From.String(), To: h.To.String(), At: h.At, Actor: h.Actor})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) setDriveRounds(w http.ResponseWriter, r *http.Request) {
+	companyID, d, err := s.pathDrive(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body []roundView
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	rounds := make([]internal.Round, 0, len(body))
+	for _, rv := range body {
+		kind, err := internal.ParseRoundKind(rv.Kind)
+		if err != nil {
+			writeError(w, badRequest(err.Error()))
+			return
+		}
+		rounds = append(rounds, internal.Round{Name: rv.Name, Kind: kind, PassScore: rv.PassScore})
+	}
+	if err := s.service.SetDriveRounds(principal(r), companyID, d.ID(), rounds); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusOK, newDriveView(companyID, d, s.now()))
+}
+
+func (s *Server) recordRoundResult(w http.ResponseWriter, r *http.Request) {
+	companyID, d, err := s.pathDrive(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	studentID, err := pathInt(r, "studentID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body struct {
+		Round int     `json:"round"`
+		Score float64 `json:"score"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	res, err := s.service.RecordRoundResult(principal(r), studentID, companyID, d.ID(), body.Round, body.Score)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusCreated, roundResultView{
+		Round: res.Round, Score: res.Score, Passed: res.Passed, RecordedAt: res.RecordedAt, Actor: res.Actor,
+	})
+}
+
+func (s *Server) roundFunnel(w http.ResponseWriter, r *http.Request) {
+	companyID, d, err := s.pathDrive(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	funnel, err := s.service.RoundFunnel(principal(r), companyID, d.ID())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := []roundFunnelView{}
+	for _, f := range funnel {
+		out = append(out, roundFunnelView{
+			Round: f.Number, Name: f.Round.Name, Kind: f.Round.Kind.String(),
+			Reached: f.Reached, Passed: f.Passed, Failed: f.Failed, Pending: f.Pending,
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) listOffers(w http.ResponseWriter, r *http.Request) {
+	studentID, err := pathInt(r, "studentID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	offers, err := s.service.Offers(principal(r), studentID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out, now := []offerView{}, s.now()
+	for _, o := range offers {
+		out = append(out, newOfferView(o, now))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) acceptOffer(w http.ResponseWriter, r *http.Request) {
+	s.respondToOffer(w, r, true)
+}
+
+func (s *Server) declineOffer(w http.ResponseWriter, r *http.Request) {
+	s.respondToOffer(w, r, false)
+}
+
+func (s *Server) respondToOffer(w http.ResponseWriter, r *http.Request, accept bool) {
+	offerID, err := pathInt(r, "offerID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	offer, err := s.service.RespondToOffer(principal(r), offerID, accept)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusOK, newOfferView(offer, s.now()))
+}
+
+func (s *Server) publishResults(w http.ResponseWriter, r *http.Request) {
+	companyID, d, err := s.pathDrive(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.service.PublishResults(principal(r), companyID, d.ID()); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusOK, newDriveView(companyID, d, s.now()))
+}
+
+func (s *Server) archiveDrive(w http.ResponseWriter, r *http.Request) {
+	companyID, d, err := s.pathDrive(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.service.ArchiveDrive(principal(r), companyID, d.ID()); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.commit(w, http.StatusOK, newDriveView(companyID, d, s.now()))
+}
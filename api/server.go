@@ -0,0 +1,297 @@
+// Package api exposes the student portal over a REST/JSON HTTP interface.
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"oops/main/internal"
+	"strconv"
+	"sync"
+)
+
+// maxBodyBytes is the largest request body the server reads.
+const maxBodyBytes = 1 << 20
+
+// Server serves the portal's registrars over HTTP. All handlers share one
+// portal, so their access to it is serialised through mu. Bodies are read
+// and passwords hashed before mu is taken, so slow clients and logins do not
+// hold up other requests.
+type Server struct {
+	mu       sync.Mutex
+	portal   *internal.Portal
+	service  *internal.PortalService
+	repo     internal.Repository
+	sessions *internal.SessionManager
+	auth     Authenticator
+	mux      *http.ServeMux
+}
+
+// NewServer returns a server for portal that authenticates requests with
+// session tokens issued by sessions and authorizes them with the default
+// policy. If repo is non-nil the portal is saved to it after every
+// successful change, and a change that cannot be saved is rolled back.
+func NewServer(portal *internal.Portal, repo internal.Repository, sessions *internal.SessionManager) *Server {
+	s := &Server{
+		portal:   portal,
+		service:  internal.NewPortalService(portal, internal.DefaultPolicy()),
+		repo:     repo,
+		sessions: sessions,
+		auth:     sessionAuthenticator{sessions: sessions, accounts: portal.Accounts},
+		mux:      http.NewServeMux(),
+	}
+	s.routes()
+	return s
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("POST /login", s.login)
+	s.handle("POST /logout", s.logout)
+	s.handle("GET /me", s.me)
+	s.handle("GET /accounts", s.listAccounts)
+	s.mux.HandleFunc("POST /accounts", s.createAccount)
+
+	s.handle("GET /students", s.listStudents)
+	s.handle("POST /students", s.createStudent)
+	s.handle("GET /students/{studentID}", s.getStudent)
+	s.handle("GET /students/{studentID}/transcript", s.getTranscript)
+	s.handle("GET /students/{studentID}/grade-history", s.getGradeHistory)
+	s.handle("GET /students/{studentID}/re-evaluations", s.listStudentReEvaluations)
+	s.handle("GET /students/{studentID}/courses/{courseID}/attendance", s.attendanceSummary)
+	s.handle("POST /students/{studentID}/re-evaluations", s.fileReEvaluation)
+	s.handle("GET /students/{studentID}/leave-requests", s.listStudentLeaves)
+	s.handle("POST /students/{studentID}/leave-requests", s.fileLeave)
+	s.handle("GET /students/{studentID}/notifications", s.listNotifications)
+	s.handle("POST /students/{studentID}/notifications/read", s.markAllNotificationsRead)
+	s.handle("POST /students/{studentID}/notifications/{notificationID}/read", s.markNotificationRead)
+	s.handle("GET /students/{studentID}/subscription", s.getSubscription)
+	s.handle("PUT /students/{studentID}/subscription", s.putSubscription)
+
+	s.handle("GET /courses", s.listCourses)
+	s.handle("POST /courses", s.createCourse)
+	s.handle("GET /courses/{courseID}/assessment", s.getAssessment)
+	s.handle("PUT /courses/{courseID}/assessment", s.putAssessment)
+	s.handle("GET /grade-scale", s.getGradeScale)
+	s.handle("PUT /grade-scale", s.putGradeScale)
+
+	s.handle("GET /teachers", s.listTeachers)
+	s.handle("POST /teachers", s.createTeacher)
+	s.handle("POST /teachers/{teacherID}/courses", s.assignCourse)
+	s.handle("GET /teachers/{teacherID}/re-evaluations", s.listTeacherReEvaluations)
+	s.handle("GET /teachers/{teacherID}/attendance-shortages", s.attendanceShortages)
+	s.handle("POST /re-evaluations/{reEvaluationID}/review", s.reviewReEvaluation)
+	s.handle("GET /teachers/{teacherID}/leave-requests", s.listTeacherLeaves)
+	s.handle("GET /leave-requests", s.listLeaves)
+	s.handle("POST /leave-requests/{leaveID}/review", s.reviewLeave)
+
+	s.handle("GET /enrollments", s.listEnrollments)
+	s.handle("POST /enrollments", s.createEnrollment)
+
+	s.handle("GET /courses/{courseID}/attendance", s.getAttendance)
+	s.handle("POST /courses/{courseID}/attendance", s.markAttendance)
+	s.handle("GET /courses/{courseID}/attendance/summary", s.courseAttendance)
+	s.handle("GET /courses/{courseID}/sessions", s.listSessions)
+	s.handle("POST /courses/{courseID}/sessions", s.scheduleSession)
+	s.handle("GET /courses/{courseID}/attendance/sheet", s.exportAttendance)
+	s.handle("POST /courses/{courseID}/attendance/sheet", s.importAttendance)
+	s.handle("GET /sessions/{sessionID}/attendance", s.sessionRoll)
+	s.handle("PUT /sessions/{sessionID}/attendance", s.takeAttendance)
+	s.handle("POST /courses/{courseID}/condonations", s.condoneShortage)
+	s.handle("PUT /attendance-minimum", s.putMinimumAttendance)
+	s.handle("POST /courses/{courseID}/marks", s.uploadMark)
+	s.handle("POST /courses/{courseID}/marks/finalise", s.finaliseMarks)
+	s.handle("GET /courses/{courseID}/results", s.courseResults)
+	s.handle("POST /courses/{courseID}/curve/preview", s.previewCurve)
+	s.handle("POST /courses/{courseID}/curve", s.applyCurve)
+
+	s.handle("GET /companies", s.listCompanies)
+	s.handle("POST /companies", s.createCompany)
+	s.handle("GET /drives", s.listDrives)
+	s.handle("POST /companies/{companyID}/drives", s.createDrive)
+	s.handle("GET /companies/{companyID}/drives/{driveID}", s.getDrive)
+	s.handle("PUT /companies/{companyID}/drives/{driveID}/rounds", s.setDriveRounds)
+	s.handle("GET /companies/{companyID}/drives/{driveID}/funnel", s.roundFunnel)
+	s.handle("POST /companies/{companyID}/drives/{driveID}/publish", s.publishResults)
+	s.handle("POST /companies/{companyID}/drives/{driveID}/archive", s.archiveDrive)
+
+	s.handle("GET /applicants", s.listApplicants)
+	s.handle("POST /applicants", s.createApplicant)
+	s.handle("GET /applicants/{studentID}/record", s.getAcademicRecord)
+	s.handle("GET /applicants/{studentID}/offers", s.listOffers)
+	s.handle("POST /offers/{offerID}/accept", s.acceptOffer)
+	s.handle("POST /offers/{offerID}/decline", s.declineOffer)
+	s.handle("POST /companies/{companyID}/drives/{driveID}/applications", s.applyForDrive)
+	s.handle("PATCH /companies/{companyID}/drives/{driveID}/applications/{studentID}", s.updateApplicationStatus)
+	s.handle("GET /companies/{companyID}/drives/{driveID}/applications/{studentID}/history", s.applicationHistory)
+	s.handle("POST /companies/{companyID}/drives/{driveID}/applications/{studentID}/rounds", s.recordRoundResult)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := readBody(w, r); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// readBody reads r's body in full, up to maxBodyBytes, so that handlers
+// decode it without waiting on the client.
+func readBody(w http.ResponseWriter, r *http.Request) error {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return fmt.Errorf("%w: the limit is %d bytes", errTooLarge, tooLarge.Limit)
+		}
+		return badRequest("reading request body: " + err.Error())
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return nil
+}
+
+// handle registers h for pattern behind authentication. h runs holding mu.
+func (s *Server) handle(pattern string, h http.HandlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		p, err := s.auth.Authenticate(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		h(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, p)))
+	})
+}
+
+type principalKey struct{}
+
+// principal returns the authenticated caller of r.
+func principal(r *http.Request) internal.Principal {
+	p, _ := r.Context().Value(principalKey{}).(internal.Principal)
+	return p
+}
+
+// errBadRequest marks errors caused by a malformed request.
+var errBadRequest = errors.New("bad request")
+
+type badRequestError struct{ msg string }
+
+func (e badRequestError) Error() string { return e.msg }
+func (e badRequestError) Unwrap() error { return errBadRequest }
+
+func badRequest(msg string) error { return badRequestError{msg: msg} }
+
+// errTooLarge marks request bodies over maxBodyBytes.
+var errTooLarge = errors.New("request body too large")
+
+// statusFor maps the error kinds returned by the registrars to HTTP statuses.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, errBadRequest), errors.Is(err, internal.ErrInvalid):
+		return http.StatusBadRequest
+	case errors.Is(err, errTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, internal.ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, internal.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, internal.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, internal.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, internal.ErrNotEligible):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("api: writing response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, statusFor(err), errorBody{Error: err.Error()})
+}
+
+func decodeBody(r *http.Request, v any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return badRequest("invalid request body: " + err.Error())
+	}
+	return nil
+}
+
+func pathInt(r *http.Request, name string) (int, error) {
+	v, err := strconv.Atoi(r.PathValue(name))
+	if err != nil {
+		return 0, badRequest("invalid " + name + ": " + r.PathValue(name))
+	}
+	return v, nil
+}
+
+func queryInt(r *http.Request, name string) (int, bool, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return 0, false, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, false, badRequest("invalid " + name + ": " + raw)
+	}
+	return v, true, nil
+}
+
+// commit persists the portal after a successful change and writes the
+// response, or the error if the change could not be saved.
+func (s *Server) commit(w http.ResponseWriter, status int, v any) {
+	if err := s.save(); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, status, v)
+}
+
+// save writes the portal to the repository, if there is one. When that
+// fails the portal is put back as it was last saved, so what is served
+// matches what is on disk.
+func (s *Server) save() error {
+	if s.repo == nil {
+		return nil
+	}
+	err := internal.SavePortal(s.repo, s.portal)
+	if err == nil {
+		return nil
+	}
+	if rerr := internal.RevertPortal(s.repo, s.portal); rerr != nil {
+		log.Printf("api: rolling back a change that could not be saved: %v", rerr)
+	}
+	return err
+}
+
+// SendReminders expires lapsed offers, runs the portal's reminder scheduler
+// between requests and saves the portal if either changed anything. Call it
+// periodically, for example from a time.Ticker.
+func (s *Server) SendReminders() ([]internal.Reminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expired := s.portal.Placement.ExpireOffers(s.now())
+	sent := s.portal.Reminders.Run()
+	if len(sent)+len(expired) == 0 {
+		return sent, nil
+	}
+	return sent, s.save()
+}
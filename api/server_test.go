@@ -0,0 +1,1011 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"oops/main/internal"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func do(t *testing.T, h http.Handler, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if rec.Code != want {
+		t.Fatalf("expected status %d, got %d: %s", want, rec.Code, rec.Body.String())
+	}
+}
+
+// memoryRepository keeps the last saved snapshot in memory. Saves fail with
+// err while it is set.
+type memoryRepository struct {
+	saved *internal.Snapshot
+	saves int
+	err   error
+}
+
+func (m *memoryRepository) Save(s *internal.Snapshot) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.saved, m.saves = s, m.saves+1
+	return nil
+}
+
+func (m *memoryRepository) Load() (*internal.Snapshot, error) {
+	if m.saved == nil {
+		return nil, internal.ErrNoSnapshot
+	}
+	return m.saved, nil
+}
+
+func newTestServer(portal *internal.Portal, repo internal.Repository) *Server {
+	return NewServer(portal, repo, internal.NewSessionManager([]byte("test key"), time.Hour))
+}
+
+// switchAuthenticator lets a test change who the next requests come from.
+type switchAuthenticator struct {
+	as internal.Principal
+}
+
+func (a *switchAuthenticator) Authenticate(*http.Request) (internal.Principal, error) {
+	if a.as.Role == 0 {
+		return internal.Principal{}, internal.ErrUnauthenticated
+	}
+	return a.as, nil
+}
+
+var (
+	admin   = internal.Principal{Role: internal.RoleAdmin}
+	officer = internal.Principal{Role: internal.RolePlacementOfficer}
+)
+
+func TestServer_AcademicFlow(t *testing.T) {
+	repo := &memoryRepository{}
+	auth := &switchAuthenticator{as: admin}
+	srv := newTestServer(internal.NewPortal(), repo)
+	srv.auth = auth
+
+	expectStatus(t, do(t, srv, "POST", "/students", map[string]any{"id": 1, "name": "Alice"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/students", map[string]any{"id": 1, "name": "Dup"}), http.StatusConflict)
+	expectStatus(t, do(t, srv, "POST", "/courses", map[string]any{"id": 101, "name": "Math"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/teachers", map[string]any{"id": "T1", "name": "Prof. Smith"}), http.StatusCreated)
+
+	enroll := map[string]any{"student_id": 1, "course_id": 101, "teacher_id": "T1", "grader": map[string]any{"kind": "letter"}}
+	expectStatus(t, do(t, srv, "POST", "/enrollments", enroll), http.StatusUnprocessableEntity)
+	expectStatus(t, do(t, srv, "POST", "/teachers/T1/courses", map[string]any{"course_id": 101, "credits": 4}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/enrollments", enroll), http.StatusCreated)
+
+	att := map[string]any{"student_id": 1, "teacher_id": "T1", "date": "2025-07-01T09:00:00Z", "present": true}
+	expectStatus(t, do(t, srv, "POST", "/courses/101/attendance", att), http.StatusCreated)
+	rec := do(t, srv, "GET", "/courses/101/attendance?student_id=1&teacher_id=T1", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var records []attendanceView
+	_ = json.Unmarshal(rec.Body.Bytes(), &records)
+	if len(records) != 1 || !records[0].Present {
+		t.Errorf("unexpected attendance: %v", records)
+	}
+	expectStatus(t, do(t, srv, "GET", "/courses/101/attendance?student_id=2&teacher_id=T1", nil), http.StatusNotFound)
+
+	expectStatus(t, do(t, srv, "POST", "/courses/101/marks", map[string]any{"teacher_id": "T1", "student_id": 1, "score": 9.2}), http.StatusOK)
+	expectStatus(t, do(t, srv, "POST", "/courses/101/marks", map[string]any{"teacher_id": "T1", "student_id": 2, "score": 9.2}), http.StatusNotFound)
+	rec = do(t, srv, "GET", "/courses/101/results?teacher_id=T1", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var results []internal.StudentResult
+	_ = json.Unmarshal(rec.Body.Bytes(), &results)
+	if len(results) != 1 || results[0].Grade != "A" {
+		t.Errorf("unexpected results: %v", results)
+	}
+
+	expectStatus(t, do(t, srv, "GET", "/students/abc", nil), http.StatusBadRequest)
+	expectStatus(t, do(t, srv, "GET", "/students/9", nil), http.StatusNotFound)
+
+	auth.as = internal.Principal{Role: internal.RoleTeacher, TeacherID: "T2"}
+	expectStatus(t, do(t, srv, "POST", "/courses/101/attendance", att), http.StatusForbidden)
+	expectStatus(t, do(t, srv, "GET", "/courses/101/results?teacher_id=T1", nil), http.StatusForbidden)
+	auth.as = internal.Principal{Role: internal.RoleStudent, StudentID: 1}
+	expectStatus(t, do(t, srv, "GET", "/courses/101/attendance?student_id=1&teacher_id=T1", nil), http.StatusOK)
+	expectStatus(t, do(t, srv, "POST", "/students", map[string]any{"id": 2, "name": "Bob"}), http.StatusForbidden)
+	auth.as = internal.Principal{}
+	expectStatus(t, do(t, srv, "GET", "/courses", nil), http.StatusUnauthorized)
+
+	if repo.saved == nil || len(repo.saved.EnrollNew) != 1 || len(repo.saved.EnrollNew[0].Attendance) != 1 {
+		t.Errorf("expected the portal to be saved after changes, got %+v", repo.saved)
+	}
+}
+
+func TestServer_FailedSave(t *testing.T) {
+	repo := &memoryRepository{}
+	srv := newTestServer(internal.NewPortal(), repo)
+	srv.auth = &switchAuthenticator{as: admin}
+
+	expectStatus(t, do(t, srv, "POST", "/students", map[string]any{"id": 1, "name": "Alice"}), http.StatusCreated)
+	repo.err = errors.New("disk full")
+	expectStatus(t, do(t, srv, "POST", "/students", map[string]any{"id": 2, "name": "Bob"}), http.StatusInternalServerError)
+	rec := do(t, srv, "GET", "/students", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var students []map[string]any
+	_ = json.Unmarshal(rec.Body.Bytes(), &students)
+	if len(students) != 1 {
+		t.Errorf("expected Bob, who could not be saved, to be rolled back, got %v", students)
+	}
+	repo.err = nil
+	expectStatus(t, do(t, srv, "POST", "/students", map[string]any{"id": 2, "name": "Bob"}), http.StatusCreated)
+
+	huge := strings.NewReader(`{"id": 3, "name": "` + strings.Repeat("x", maxBodyBytes) + `"}`)
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("POST", "/students", huge))
+	expectStatus(t, rec, http.StatusRequestEntityTooLarge)
+}
+
+func TestServer_PlacementFlow(t *testing.T) {
+	auth := &switchAuthenticator{as: admin}
+	portal := internal.NewPortal()
+	now := time.Date(2025, time.June, 20, 9, 0, 0, 0, time.UTC)
+	portal.Placement.SetClock(func() time.Time { return now })
+	srv := newTestServer(portal, nil)
+	srv.auth = auth
+
+	expectStatus(t, do(t, srv, "POST", "/students", map[string]any{"id": 1, "name": "Alice"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/students", map[string]any{"id": 2, "name": "Bob"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/companies", map[string]any{"name": "Acme"}), http.StatusForbidden)
+	auth.as = officer
+
+	rec := do(t, srv, "POST", "/companies", map[string]any{"name": "Acme"})
+	expectStatus(t, rec, http.StatusCreated)
+	var company companyView
+	_ = json.Unmarshal(rec.Body.Bytes(), &company)
+
+	drive := map[string]any{
+		"role_name": "Engineer", "start_date": "2025-07-01T00:00:00Z", "end_date": "2025-07-15T00:00:00Z",
+		"minimum_gpa": 6.0, "ctc": 1200000, "job_category": "Dream",
+	}
+	rec = do(t, srv, "POST", "/companies/"+strconv.Itoa(company.ID)+"/drives", drive)
+	expectStatus(t, rec, http.StatusCreated)
+	var dv driveView
+	_ = json.Unmarshal(rec.Body.Bytes(), &dv)
+	drivePath := "/companies/" + strconv.Itoa(company.ID) + "/drives/" + strconv.Itoa(dv.ID)
+	if dv.State != "draft" {
+		t.Errorf("expected a draft drive before its start date, got %q", dv.State)
+	}
+
+	good := []map[string]any{{"student_id": 1, "course_id": 1, "course_name": "Math", "grade": "O", "semester": 1, "credits": 4}}
+	weak := []map[string]any{{"student_id": 2, "course_id": 1, "course_name": "Math", "grade": "C", "semester": 1, "credits": 4}}
+	expectStatus(t, do(t, srv, "POST", "/applicants", map[string]any{"student_id": 1, "course_results": good}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/applicants", map[string]any{"student_id": 2, "course_results": weak}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/applicants", map[string]any{"student_id": 2}), http.StatusConflict)
+	if body := do(t, srv, "POST", drivePath+"/applications", map[string]any{"student_id": 1}).Body.String(); !strings.Contains(body, "opens for applications on 2025-07-01") {
+		t.Errorf("expected applications to a draft drive to be rejected, got %s", body)
+	}
+	now = time.Date(2025, time.July, 5, 9, 0, 0, 0, time.UTC)
+
+	alice := internal.Principal{Role: internal.RoleStudent, StudentID: 1}
+	auth.as = alice
+	expectStatus(t, do(t, srv, "POST", drivePath+"/applications", map[string]any{"student_id": 2}), http.StatusForbidden)
+	expectStatus(t, do(t, srv, "POST", drivePath+"/applications", map[string]any{"student_id": 1}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "GET", "/applicants/1/record", nil), http.StatusOK)
+	expectStatus(t, do(t, srv, "GET", "/applicants/2/record", nil), http.StatusForbidden)
+	expectStatus(t, do(t, srv, "PATCH", drivePath+"/applications/1", map[string]any{"status": "selected"}), http.StatusForbidden)
+	auth.as = officer
+	expectStatus(t, do(t, srv, "POST", drivePath+"/applications", map[string]any{"student_id": 1}), http.StatusConflict)
+	expectStatus(t, do(t, srv, "POST", drivePath+"/applications", map[string]any{"student_id": 2}), http.StatusUnprocessableEntity)
+	expectStatus(t, do(t, srv, "POST", drivePath+"/applications", map[string]any{"student_id": 3}), http.StatusNotFound)
+	expectStatus(t, do(t, srv, "POST", "/companies/"+strconv.Itoa(company.ID)+"/drives/9999/applications", map[string]any{"student_id": 1}), http.StatusNotFound)
+
+	rec = do(t, srv, "PATCH", drivePath+"/applications/1", map[string]any{"status": "shortlisted"})
+	expectStatus(t, rec, http.StatusOK)
+	var app applicationView
+	_ = json.Unmarshal(rec.Body.Bytes(), &app)
+	if app.Status != "shortlisted" {
+		t.Errorf("expected shortlisted, got %q", app.Status)
+	}
+	expectStatus(t, do(t, srv, "PATCH", drivePath+"/applications/1", map[string]any{"status": "bogus"}), http.StatusBadRequest)
+	expectStatus(t, do(t, srv, "PATCH", drivePath+"/applications/1", map[string]any{"status": "applied"}), http.StatusConflict)
+
+	rec = do(t, srv, "GET", drivePath+"/applications/1/history", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var history []statusChangeView
+	_ = json.Unmarshal(rec.Body.Bytes(), &history)
+	if len(history) != 1 || history[0].From != "applied" || history[0].To != "shortlisted" || history[0].Actor != "placement_officer" {
+		t.Errorf("unexpected history: %+v", history)
+	}
+
+	rounds := []map[string]any{
+		{"name": "Aptitude", "kind": "aptitude", "pass_score": 50},
+		{"name": "Interview", "kind": "technical", "pass_score": 6},
+	}
+	expectStatus(t, do(t, srv, "PUT", drivePath+"/rounds", []map[string]any{{"name": "X", "kind": "chess"}}), http.StatusBadRequest)
+	expectStatus(t, do(t, srv, "PUT", drivePath+"/rounds", rounds), http.StatusOK)
+	expectStatus(t, do(t, srv, "POST", drivePath+"/applications/1/rounds", map[string]any{"round": 2, "score": 9}), http.StatusConflict)
+	rec = do(t, srv, "POST", drivePath+"/applications/1/rounds", map[string]any{"round": 1, "score": 64})
+	expectStatus(t, rec, http.StatusCreated)
+	var result roundResultView
+	_ = json.Unmarshal(rec.Body.Bytes(), &result)
+	if !result.Passed || result.Actor != "placement_officer" {
+		t.Errorf("unexpected round result: %+v", result)
+	}
+	expectStatus(t, do(t, srv, "PUT", drivePath+"/rounds", rounds), http.StatusConflict)
+
+	rec = do(t, srv, "GET", drivePath+"/funnel", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var funnel []roundFunnelView
+	_ = json.Unmarshal(rec.Body.Bytes(), &funnel)
+	if len(funnel) != 2 || funnel[0].Passed != 1 || funnel[1].Pending != 1 || funnel[1].Kind != "technical" {
+		t.Errorf("unexpected funnel: %+v", funnel)
+	}
+	auth.as = alice
+	expectStatus(t, do(t, srv, "GET", drivePath+"/funnel", nil), http.StatusForbidden)
+	auth.as = officer
+
+	rec = do(t, srv, "GET", drivePath, nil)
+	expectStatus(t, rec, http.StatusOK)
+	_ = json.Unmarshal(rec.Body.Bytes(), &dv)
+	if len(dv.Applications) != 1 || dv.Applications[0].StudentName != "Alice" {
+		t.Errorf("unexpected drive applications: %+v", dv.Applications)
+	}
+	if len(dv.Rounds) != 2 || dv.Rounds[0].Name != "Aptitude" {
+		t.Errorf("unexpected drive rounds: %+v", dv.Rounds)
+	}
+
+	expectStatus(t, do(t, srv, "POST", drivePath+"/applications/1/rounds", map[string]any{"round": 2, "score": 8}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "PATCH", drivePath+"/applications/1", map[string]any{"status": "selected"}), http.StatusOK)
+	expectStatus(t, do(t, srv, "POST", "/offers/1/accept", nil), http.StatusForbidden)
+	auth.as = alice
+	expectStatus(t, do(t, srv, "GET", "/applicants/2/offers", nil), http.StatusForbidden)
+	rec = do(t, srv, "GET", "/applicants/1/offers", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var offers []offerView
+	_ = json.Unmarshal(rec.Body.Bytes(), &offers)
+	if len(offers) != 1 || offers[0].Status != "pending" || offers[0].DriveID != dv.ID {
+		t.Fatalf("unexpected offers: %+v", offers)
+	}
+	rec = do(t, srv, "POST", "/offers/"+strconv.Itoa(offers[0].ID)+"/accept", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var offer offerView
+	_ = json.Unmarshal(rec.Body.Bytes(), &offer)
+	if offer.Status != "accepted" {
+		t.Errorf("expected accepted, got %q", offer.Status)
+	}
+	expectStatus(t, do(t, srv, "POST", "/offers/"+strconv.Itoa(offers[0].ID)+"/decline", nil), http.StatusConflict)
+	expectStatus(t, do(t, srv, "POST", "/offers/999/accept", nil), http.StatusNotFound)
+
+	auth.as = officer
+	restricted := map[string]any{
+		"role_name": "Embedded", "start_date": "2025-07-01T00:00:00Z", "end_date": "2025-07-15T00:00:00Z",
+		"minimum_gpa": 6.0, "ctc": 2500000, "job_category": "Marquee", "max_backlogs": 0, "departments": []string{"ECE"},
+	}
+	rec = do(t, srv, "POST", "/companies/"+strconv.Itoa(company.ID)+"/drives", restricted)
+	expectStatus(t, rec, http.StatusCreated)
+	_ = json.Unmarshal(rec.Body.Bytes(), &dv)
+	if len(dv.Criteria) != 3 || dv.Criteria[2] != "department in ECE" {
+		t.Errorf("unexpected criteria: %q", dv.Criteria)
+	}
+	rec = do(t, srv, "POST", "/companies/"+strconv.Itoa(company.ID)+"/drives/"+strconv.Itoa(dv.ID)+"/applications", map[string]any{"student_id": 2})
+	expectStatus(t, rec, http.StatusUnprocessableEntity)
+	if body := rec.Body.String(); !strings.Contains(body, "CGPA") || !strings.Contains(body, "department is not recorded") {
+		t.Errorf("expected every failed criterion in the error, got %s", body)
+	}
+
+	restricted["rule"] = "cgpa >= 6 && (backlogs == 0 || semester = 8)"
+	rec = do(t, srv, "POST", "/companies/"+strconv.Itoa(company.ID)+"/drives", restricted)
+	expectStatus(t, rec, http.StatusBadRequest)
+	if body := rec.Body.String(); !strings.Contains(body, "column 41") {
+		t.Errorf("expected the position of the error, got %s", body)
+	}
+	restricted["rule"] = "cgpa >= 6 && backlogs == 0"
+	rec = do(t, srv, "POST", "/companies/"+strconv.Itoa(company.ID)+"/drives", restricted)
+	expectStatus(t, rec, http.StatusCreated)
+	_ = json.Unmarshal(rec.Body.Bytes(), &dv)
+	if len(dv.Criteria) != 4 || dv.Criteria[3] != "cgpa >= 6 && backlogs == 0" {
+		t.Errorf("unexpected criteria: %q", dv.Criteria)
+	}
+
+	now = time.Date(2025, time.July, 16, 0, 0, 0, 0, time.UTC)
+	rec = do(t, srv, "GET", drivePath, nil)
+	_ = json.Unmarshal(rec.Body.Bytes(), &dv)
+	if dv.State != "closed" {
+		t.Errorf("expected the drive to close after its last day, got %q", dv.State)
+	}
+	expectStatus(t, do(t, srv, "POST", drivePath+"/archive", nil), http.StatusConflict)
+	auth.as = alice
+	expectStatus(t, do(t, srv, "POST", drivePath+"/publish", nil), http.StatusForbidden)
+	auth.as = officer
+	rec = do(t, srv, "POST", drivePath+"/publish", nil)
+	expectStatus(t, rec, http.StatusOK)
+	_ = json.Unmarshal(rec.Body.Bytes(), &dv)
+	if dv.State != "results published" {
+		t.Errorf("expected published results, got %q", dv.State)
+	}
+	rec = do(t, srv, "PATCH", drivePath+"/applications/1", map[string]any{"status": "rejected"})
+	expectStatus(t, rec, http.StatusConflict)
+	if !strings.Contains(rec.Body.String(), "results published") {
+		t.Errorf("expected statuses to be frozen after publishing, got %s", rec.Body.String())
+	}
+	expectStatus(t, do(t, srv, "POST", drivePath+"/publish", nil), http.StatusConflict)
+	expectStatus(t, do(t, srv, "POST", drivePath+"/archive", nil), http.StatusOK)
+}
+
+func TestServer_Sessions(t *testing.T) {
+	portal := internal.NewPortal()
+	portal.Academic.AddStudent(internal.NewStudent(1, "Alice"))
+	root, _ := internal.NewAccount("root", "rootpass1", internal.RoleAdmin, 0, "")
+	if err := portal.AddAccount(root); err != nil {
+		t.Fatal(err)
+	}
+	srv := newTestServer(portal, nil)
+
+	login := func(username, password string) (string, int) {
+		rec := do(t, srv, "POST", "/login", map[string]any{"username": username, "password": password})
+		var sv sessionView
+		_ = json.Unmarshal(rec.Body.Bytes(), &sv)
+		return sv.Token, rec.Code
+	}
+	withToken := func(token, method, path string, body any) *httptest.ResponseRecorder {
+		t.Helper()
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+
+	expectStatus(t, do(t, srv, "GET", "/students", nil), http.StatusUnauthorized)
+	expectStatus(t, do(t, srv, "POST", "/accounts", map[string]any{"username": "eve", "password": "evepass12", "role": "admin"}), http.StatusUnauthorized)
+	if _, code := login("nobody", "rootpass1"); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for an unknown username, got %d", code)
+	}
+	if _, code := login("root", "wrongpass"); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for wrong password, got %d", code)
+	}
+	rootToken, code := login("root", "rootpass1")
+	if code != http.StatusOK || rootToken == "" {
+		t.Fatalf("login failed with %d", code)
+	}
+
+	alice := map[string]any{"username": "alice", "password": "alicepass", "role": "student", "student_id": 1}
+	expectStatus(t, withToken(rootToken, "POST", "/accounts", alice), http.StatusCreated)
+	expectStatus(t, withToken(rootToken, "POST", "/accounts", map[string]any{"username": "bob", "password": "bobpass12", "role": "student", "student_id": 2}), http.StatusNotFound)
+	expectStatus(t, withToken(rootToken, "POST", "/accounts", map[string]any{"username": "eve", "password": "short", "role": "admin"}), http.StatusBadRequest)
+
+	aliceToken, _ := login("alice", "alicepass")
+	rec := withToken(aliceToken, "GET", "/me", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var me accountView
+	_ = json.Unmarshal(rec.Body.Bytes(), &me)
+	if me.Username != "alice" || me.Role != "student" || me.StudentID != 1 {
+		t.Errorf("unexpected account: %+v", me)
+	}
+	expectStatus(t, withToken(aliceToken, "GET", "/students/1", nil), http.StatusOK)
+	expectStatus(t, withToken(aliceToken, "POST", "/accounts", alice), http.StatusForbidden)
+
+	expectStatus(t, withToken(aliceToken, "POST", "/logout", nil), http.StatusNoContent)
+	expectStatus(t, withToken(aliceToken, "GET", "/students/1", nil), http.StatusUnauthorized)
+	expectStatus(t, withToken(rootToken, "GET", "/accounts", nil), http.StatusOK)
+}
+
+func TestServer_Notifications(t *testing.T) {
+	auth := &switchAuthenticator{as: admin}
+	portal := internal.NewPortal()
+	now := time.Date(2025, time.July, 5, 9, 0, 0, 0, time.UTC)
+	portal.Placement.SetClock(func() time.Time { return now })
+	srv := newTestServer(portal, nil)
+	srv.auth = auth
+
+	expectStatus(t, do(t, srv, "POST", "/students", map[string]any{"id": 1, "name": "Alice"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/students", map[string]any{"id": 2, "name": "Bob"}), http.StatusCreated)
+	alice := internal.Principal{Role: internal.RoleStudent, StudentID: 1}
+	auth.as = alice
+
+	rec := do(t, srv, "GET", "/students/1/subscription", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var sub internal.Subscription
+	_ = json.Unmarshal(rec.Body.Bytes(), &sub)
+	if len(sub.Channels) != 1 || sub.Channels[0] != internal.ChannelInbox {
+		t.Errorf("expected the inbox-only default, got %+v", sub)
+	}
+	expectStatus(t, do(t, srv, "PUT", "/students/1/subscription", map[string]any{"channels": []string{"email"}}), http.StatusBadRequest)
+	expectStatus(t, do(t, srv, "PUT", "/students/1/subscription", map[string]any{"channels": []string{"inbox"}, "kinds": []string{"gossip"}}), http.StatusBadRequest)
+	expectStatus(t, do(t, srv, "PUT", "/students/2/subscription", map[string]any{"channels": []string{"inbox"}}), http.StatusForbidden)
+	expectStatus(t, do(t, srv, "GET", "/students/2/notifications", nil), http.StatusForbidden)
+	rec = do(t, srv, "PUT", "/students/1/subscription", map[string]any{"channels": []string{"inbox"}, "kinds": []string{"application_status", "shortlisted"}})
+	expectStatus(t, rec, http.StatusOK)
+
+	auth.as = officer
+	rec = do(t, srv, "POST", "/companies", map[string]any{"name": "Acme"})
+	var company companyView
+	_ = json.Unmarshal(rec.Body.Bytes(), &company)
+	drive := map[string]any{
+		"role_name": "Engineer", "start_date": "2025-07-01T00:00:00Z", "end_date": "2025-07-15T00:00:00Z",
+		"minimum_gpa": 6.0, "ctc": 1200000, "job_category": "Dream",
+	}
+	rec = do(t, srv, "POST", "/companies/"+strconv.Itoa(company.ID)+"/drives", drive)
+	var dv driveView
+	_ = json.Unmarshal(rec.Body.Bytes(), &dv)
+	drivePath := "/companies/" + strconv.Itoa(company.ID) + "/drives/" + strconv.Itoa(dv.ID)
+	good := []map[string]any{{"student_id": 1, "course_id": 1, "course_name": "Math", "grade": "O", "semester": 1, "credits": 4}}
+	expectStatus(t, do(t, srv, "POST", "/applicants", map[string]any{"student_id": 1, "course_results": good}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", drivePath+"/applications", map[string]any{"student_id": 1}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "PATCH", drivePath+"/applications/1", map[string]any{"status": "shortlisted"}), http.StatusOK)
+
+	expectStatus(t, do(t, srv, "PATCH", drivePath+"/applications/1", map[string]any{"status": "cleared"}), http.StatusOK)
+
+	auth.as = alice
+	rec = do(t, srv, "GET", "/students/1/notifications", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var inbox inboxView
+	_ = json.Unmarshal(rec.Body.Bytes(), &inbox)
+	if inbox.Total != 2 || inbox.Unread != 2 || inbox.Limit != 20 || len(inbox.Notifications) != 2 {
+		t.Fatalf("expected the two status changes, got %+v", inbox)
+	}
+	if m := inbox.Notifications[1]; m.Kind != internal.NotifyShortlisted || m.DriveID != dv.ID || !m.CreatedAt.Equal(now) || !m.Unread() {
+		t.Errorf("expected the shortlisting last, newest first, got %+v", m)
+	}
+	shortlisted := inbox.Notifications[1].ID
+
+	rec = do(t, srv, "GET", "/students/1/notifications?kind=shortlisted&drive_id="+strconv.Itoa(dv.ID), nil)
+	_ = json.Unmarshal(rec.Body.Bytes(), &inbox)
+	if inbox.Total != 1 || inbox.Notifications[0].ID != shortlisted {
+		t.Errorf("expected the kind filter to find the shortlisting, got %+v", inbox)
+	}
+	rec = do(t, srv, "GET", "/students/1/notifications?drive_id=999", nil)
+	_ = json.Unmarshal(rec.Body.Bytes(), &inbox)
+	if inbox.Total != 0 || inbox.Notifications == nil {
+		t.Errorf("expected an empty page for another drive, got %+v", inbox)
+	}
+	rec = do(t, srv, "GET", "/students/1/notifications?offset=1&limit=1", nil)
+	_ = json.Unmarshal(rec.Body.Bytes(), &inbox)
+	if inbox.Total != 2 || len(inbox.Notifications) != 1 || inbox.Notifications[0].ID != shortlisted {
+		t.Errorf("expected the second page to hold the shortlisting, got %+v", inbox)
+	}
+	expectStatus(t, do(t, srv, "GET", "/students/1/notifications?limit=0", nil), http.StatusBadRequest)
+	expectStatus(t, do(t, srv, "GET", "/students/1/notifications?kind=gossip", nil), http.StatusBadRequest)
+	expectStatus(t, do(t, srv, "GET", "/students/1/notifications?unread=maybe", nil), http.StatusBadRequest)
+
+	rec = do(t, srv, "POST", "/students/1/notifications/"+strconv.Itoa(shortlisted)+"/read", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var unread unreadView
+	_ = json.Unmarshal(rec.Body.Bytes(), &unread)
+	if unread.Unread != 1 {
+		t.Errorf("expected one unread message left, got %+v", unread)
+	}
+	rec = do(t, srv, "GET", "/students/1/notifications?unread=true", nil)
+	_ = json.Unmarshal(rec.Body.Bytes(), &inbox)
+	if inbox.Total != 1 || inbox.Notifications[0].ID == shortlisted {
+		t.Errorf("expected only the unread status change, got %+v", inbox)
+	}
+	expectStatus(t, do(t, srv, "POST", "/students/1/notifications/999/read", nil), http.StatusNotFound)
+	expectStatus(t, do(t, srv, "POST", "/students/2/notifications/read", nil), http.StatusForbidden)
+	rec = do(t, srv, "POST", "/students/1/notifications/read", nil)
+	expectStatus(t, rec, http.StatusOK)
+	_ = json.Unmarshal(rec.Body.Bytes(), &unread)
+	if unread.Unread != 0 {
+		t.Errorf("expected every message read, got %+v", unread)
+	}
+
+	expectStatus(t, do(t, srv, "GET", "/students/3/notifications", nil), http.StatusForbidden)
+	auth.as = admin
+	expectStatus(t, do(t, srv, "GET", "/students/3/notifications", nil), http.StatusNotFound)
+	expectStatus(t, do(t, srv, "POST", "/students/1/notifications/read", nil), http.StatusForbidden)
+}
+
+func TestServer_SendReminders(t *testing.T) {
+	portal := internal.NewPortal()
+	now := time.Date(2025, time.July, 14, 9, 0, 0, 0, time.UTC)
+	portal.Placement.SetClock(func() time.Time { return now })
+	alice := internal.NewStudent(1, "Alice")
+	portal.Academic.AddStudent(alice)
+	if err := portal.Placement.AddApplicant(internal.NewApplicant(alice, internal.AcademicRecord{StudentId: 1, CGPA: 9})); err != nil {
+		t.Fatal(err)
+	}
+	company := internal.NewCompany("Acme")
+	portal.Placement.AddCompany(company)
+	drive := internal.NewDrive(now.AddDate(0, 0, -7), time.Date(2025, time.July, 15, 0, 0, 0, 0, time.UTC), "Engineer", 6, 1200000, internal.Dream)
+	if err := portal.Placement.AddDriveToCompany(company.ID(), drive); err != nil {
+		t.Fatal(err)
+	}
+	repo := &memoryRepository{}
+	srv := newTestServer(portal, repo)
+
+	sent, err := srv.SendReminders()
+	if err != nil || len(sent) != 1 || sent[0].StudentID != 1 || repo.saves != 1 {
+		t.Fatalf("expected one reminder saved, got %+v, %v after %d saves", sent, err, repo.saves)
+	}
+	if len(repo.saved.Reminders) != 1 {
+		t.Errorf("the saved portal should record the reminder, got %+v", repo.saved.Reminders)
+	}
+	if sent, _ := srv.SendReminders(); len(sent) != 0 || repo.saves != 1 {
+		t.Errorf("nothing new should be sent or saved, got %+v after %d saves", sent, repo.saves)
+	}
+}
+
+func TestServer_GradeScale(t *testing.T) {
+	repo := &memoryRepository{}
+	auth := &switchAuthenticator{as: admin}
+	srv := newTestServer(internal.NewPortal(), repo)
+	srv.auth = auth
+
+	rec := do(t, srv, "GET", "/grade-scale", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var scale internal.GradeScale
+	_ = json.Unmarshal(rec.Body.Bytes(), &scale)
+	if len(scale.Bands) != 7 || scale.Bands[0].Letter != internal.O || scale.Bands[0].Points != 10 {
+		t.Errorf("expected the default 10-point scale, got %+v", scale)
+	}
+
+	expectStatus(t, do(t, srv, "POST", "/students", map[string]any{"id": 1, "name": "Alice"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/students", map[string]any{"id": 2, "name": "Bob"}), http.StatusCreated)
+	auth.as = officer
+	results := []map[string]any{{"student_id": 1, "course_id": 1, "course_name": "Math", "grade": "B", "semester": 1, "credits": 3}}
+	expectStatus(t, do(t, srv, "POST", "/applicants", map[string]any{"student_id": 1, "course_results": results}), http.StatusCreated)
+
+	fourPoint := map[string]any{"name": "4.0 GPA", "bands": []map[string]any{
+		{"letter": "A", "points": 4, "pass": true, "min_score": 90},
+		{"letter": "B", "points": 3, "pass": true, "min_score": 80},
+		{"letter": "C", "points": 2, "pass": true, "min_score": 70},
+		{"letter": "F", "points": 0, "pass": false, "min_score": 0},
+	}}
+	unordered := map[string]any{"name": "bad", "bands": []map[string]any{
+		{"letter": "F", "points": 0, "min_score": 0},
+		{"letter": "A", "points": 4, "pass": true, "min_score": 90},
+	}}
+	auth.as = admin
+	expectStatus(t, do(t, srv, "PUT", "/grade-scale", unordered), http.StatusBadRequest)
+	auth.as = internal.Principal{Role: internal.RoleStudent, StudentID: 1}
+	expectStatus(t, do(t, srv, "PUT", "/grade-scale", fourPoint), http.StatusForbidden)
+	auth.as = admin
+	expectStatus(t, do(t, srv, "PUT", "/grade-scale", fourPoint), http.StatusOK)
+
+	auth.as = officer
+	rec = do(t, srv, "GET", "/applicants/1/record", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var record internal.AcademicRecord
+	_ = json.Unmarshal(rec.Body.Bytes(), &record)
+	if record.CGPA != 9.0/4 {
+		t.Errorf("expected the B recalculated to 3 points, got CGPA %v", record.CGPA)
+	}
+	if repo.saved == nil || repo.saved.GradeScale == nil || repo.saved.GradeScale.Name != "4.0 GPA" {
+		t.Errorf("expected the new scale to be saved, got %+v", repo.saved)
+	}
+	// The default scale's O is not on the 4.0 scale.
+	other := []map[string]any{{"student_id": 2, "course_id": 2, "course_name": "Art", "grade": "O", "semester": 1, "credits": 3}}
+	expectStatus(t, do(t, srv, "POST", "/applicants", map[string]any{"student_id": 2, "course_results": other}), http.StatusBadRequest)
+}
+
+func TestServer_Transcript(t *testing.T) {
+	auth := &switchAuthenticator{as: admin}
+	srv := newTestServer(internal.NewPortal(), &memoryRepository{})
+	srv.auth = auth
+
+	expectStatus(t, do(t, srv, "POST", "/students", map[string]any{"id": 1, "name": "Alice"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/courses", map[string]any{"id": 101, "name": "Math"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/teachers", map[string]any{"id": "T1", "name": "Prof. Smith"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/teachers/T1/courses", map[string]any{"course_id": 101, "credits": 4, "semester": 2}), http.StatusCreated)
+	enroll := map[string]any{"student_id": 1, "course_id": 101, "teacher_id": "T1", "grader": map[string]any{"kind": "scale", "max_score": 50}}
+	expectStatus(t, do(t, srv, "POST", "/enrollments", enroll), http.StatusCreated)
+
+	expectStatus(t, do(t, srv, "POST", "/courses/101/marks", map[string]any{"teacher_id": "T1", "student_id": 1, "score": 60}), http.StatusBadRequest)
+	expectStatus(t, do(t, srv, "POST", "/courses/101/marks", map[string]any{"teacher_id": "T1", "student_id": 1, "score": 41}), http.StatusOK)
+	rec := do(t, srv, "GET", "/courses/101/results?teacher_id=T1", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var results []internal.StudentResult
+	_ = json.Unmarshal(rec.Body.Bytes(), &results)
+	if len(results) != 1 || results[0].Grade != "A+" {
+		t.Errorf("expected 41 of 50 to be an A+, got %v", results)
+	}
+
+	auth.as = internal.Principal{Role: internal.RoleStudent, StudentID: 1}
+	rec = do(t, srv, "GET", "/students/1/transcript", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var record internal.AcademicRecord
+	_ = json.Unmarshal(rec.Body.Bytes(), &record)
+	sem := record.Semesters[2]
+	if sem == nil || sem.Courses[101].Grade != internal.Aplus || sem.SGPA != 9 {
+		t.Errorf("expected an A+ in semester 2 on the transcript, got %+v", record)
+	}
+	expectStatus(t, do(t, srv, "GET", "/students/2/transcript", nil), http.StatusForbidden)
+}
+
+func TestServer_Curve(t *testing.T) {
+	repo := &memoryRepository{}
+	auth := &switchAuthenticator{as: admin}
+	srv := newTestServer(internal.NewPortal(), repo)
+	srv.auth = auth
+
+	expectStatus(t, do(t, srv, "POST", "/courses", map[string]any{"id": 101, "name": "Math"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/teachers", map[string]any{"id": "T1", "name": "Prof. Smith"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/teachers/T1/courses", map[string]any{"course_id": 101, "credits": 4, "semester": 1}), http.StatusCreated)
+	for id, score := range map[int]float64{1: 40, 2: 55, 3: 70} {
+		expectStatus(t, do(t, srv, "POST", "/students", map[string]any{"id": id, "name": fmt.Sprintf("Student%d", id)}), http.StatusCreated)
+		enroll := map[string]any{"student_id": id, "course_id": 101, "teacher_id": "T1", "grader": map[string]any{"kind": "curve"}}
+		expectStatus(t, do(t, srv, "POST", "/enrollments", enroll), http.StatusCreated)
+		expectStatus(t, do(t, srv, "POST", "/courses/101/marks", map[string]any{"teacher_id": "T1", "student_id": id, "score": score}), http.StatusOK)
+	}
+
+	curve := map[string]any{"teacher_id": "T1", "method": "percentile", "bands": []map[string]any{
+		{"letter": "O", "percentile": 60}, {"letter": "B", "percentile": 30}, {"letter": "F"},
+	}}
+	saves := repo.saves
+	rec := do(t, srv, "POST", "/courses/101/curve/preview", curve)
+	expectStatus(t, rec, http.StatusOK)
+	var preview internal.CurvePreview
+	_ = json.Unmarshal(rec.Body.Bytes(), &preview)
+	if preview.Committed || preview.Counts[internal.O] != 1 || preview.Counts[internal.B] != 1 || repo.saves != saves {
+		t.Errorf("expected an unsaved preview with one O and one B, got %+v after %d saves", preview, repo.saves-saves)
+	}
+
+	expectStatus(t, do(t, srv, "POST", "/courses/101/curve", curve), http.StatusOK)
+	if repo.saves != saves+1 {
+		t.Error("expected applying a curve to save the portal")
+	}
+	rec = do(t, srv, "GET", "/courses/101/results?teacher_id=T1", nil)
+	var results []internal.StudentResult
+	_ = json.Unmarshal(rec.Body.Bytes(), &results)
+	grades := map[int]string{}
+	for _, r := range results {
+		grades[r.StudentID] = r.Grade
+	}
+	if want := map[int]string{1: "F", 2: "B", 3: "O"}; !reflect.DeepEqual(grades, want) {
+		t.Errorf("expected grades %v on the curve, got %v", want, grades)
+	}
+
+	expectStatus(t, do(t, srv, "POST", "/courses/101/curve/preview", map[string]any{"teacher_id": "T1", "method": "bell"}), http.StatusBadRequest)
+	expectStatus(t, do(t, srv, "POST", "/courses/101/curve", map[string]any{"teacher_id": "T2", "method": "stddev"}), http.StatusNotFound)
+	// Only those who moderate grades may curve a course across its teachers.
+	expectStatus(t, do(t, srv, "POST", "/courses/101/curve/preview", map[string]any{"method": "stddev"}), http.StatusOK)
+	auth.as = internal.Principal{Role: internal.RoleTeacher, TeacherID: "T1"}
+	expectStatus(t, do(t, srv, "POST", "/courses/101/curve", map[string]any{"method": "stddev"}), http.StatusForbidden)
+}
+
+func TestServer_Assessment(t *testing.T) {
+	srv := newTestServer(internal.NewPortal(), &memoryRepository{})
+	srv.auth = &switchAuthenticator{as: admin}
+
+	expectStatus(t, do(t, srv, "POST", "/students", map[string]any{"id": 1, "name": "Alice"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/courses", map[string]any{"id": 101, "name": "Math"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/teachers", map[string]any{"id": "T1", "name": "Prof. Smith"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/teachers/T1/courses", map[string]any{"course_id": 101, "credits": 4, "semester": 1}), http.StatusCreated)
+	enroll := map[string]any{"student_id": 1, "course_id": 101, "teacher_id": "T1", "grader": map[string]any{"kind": "scale"}}
+	expectStatus(t, do(t, srv, "POST", "/enrollments", enroll), http.StatusCreated)
+
+	expectStatus(t, do(t, srv, "GET", "/courses/101/assessment", nil), http.StatusNotFound)
+	components := []map[string]any{
+		{"name": "lab", "weight": 25, "max_marks": 20},
+		{"name": "final", "weight": 75, "max_marks": 100},
+	}
+	expectStatus(t, do(t, srv, "PUT", "/courses/101/assessment", map[string]any{"components": components[:1]}), http.StatusBadRequest)
+	expectStatus(t, do(t, srv, "PUT", "/courses/101/assessment", map[string]any{"components": components, "missing": "prorate"}), http.StatusOK)
+	rec := do(t, srv, "GET", "/courses/101/assessment", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var a internal.Assessment
+	_ = json.Unmarshal(rec.Body.Bytes(), &a)
+	if a.CourseID != 101 || len(a.Components) != 2 || a.Missing != internal.MissingProrate {
+		t.Errorf("expected the course's two components, got %+v", a)
+	}
+
+	expectStatus(t, do(t, srv, "POST", "/courses/101/marks", map[string]any{"teacher_id": "T1", "student_id": 1, "score": 90}), http.StatusConflict)
+	expectStatus(t, do(t, srv, "POST", "/courses/101/marks", map[string]any{"teacher_id": "T1", "student_id": 1, "component": "lab", "score": 18}), http.StatusOK)
+	rec = do(t, srv, "GET", "/courses/101/results?teacher_id=T1", nil)
+	var results []internal.StudentResult
+	_ = json.Unmarshal(rec.Body.Bytes(), &results)
+	if len(results) != 1 || results[0].Grade != "" || len(results[0].Components) != 1 {
+		t.Errorf("expected the lab mark stored but ungraded while the final is missing, got %+v", results)
+	}
+	finalise := map[string]any{"teacher_id": "T1", "student_id": 1}
+	expectStatus(t, do(t, srv, "POST", "/courses/101/marks/finalise", finalise), http.StatusOK)
+	rec = do(t, srv, "GET", "/courses/101/results?teacher_id=T1", nil)
+	_ = json.Unmarshal(rec.Body.Bytes(), &results)
+	if len(results) != 1 || results[0].Score != 90 || results[0].Grade != "O" {
+		t.Errorf("expected the lab alone to count once finalised, got %+v", results)
+	}
+}
+
+func TestServer_ReEvaluation(t *testing.T) {
+	auth := &switchAuthenticator{as: admin}
+	srv := newTestServer(internal.NewPortal(), &memoryRepository{})
+	srv.auth = auth
+
+	expectStatus(t, do(t, srv, "POST", "/students", map[string]any{"id": 1, "name": "Alice"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/courses", map[string]any{"id": 101, "name": "Math"}), http.StatusCreated)
+	for _, id := range []string{"T1", "T2"} {
+		expectStatus(t, do(t, srv, "POST", "/teachers", map[string]any{"id": id, "name": "Prof. " + id}), http.StatusCreated)
+	}
+	expectStatus(t, do(t, srv, "POST", "/teachers/T1/courses", map[string]any{"course_id": 101, "credits": 4, "semester": 1}), http.StatusCreated)
+	enroll := map[string]any{"student_id": 1, "course_id": 101, "teacher_id": "T1", "grader": map[string]any{"kind": "scale"}}
+	expectStatus(t, do(t, srv, "POST", "/enrollments", enroll), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/courses/101/marks", map[string]any{"teacher_id": "T1", "student_id": 1, "score": 58}), http.StatusOK)
+
+	request := map[string]any{"course_id": 101, "reason": "totalling error"}
+	expectStatus(t, do(t, srv, "POST", "/students/1/re-evaluations", request), http.StatusForbidden)
+	auth.as = internal.Principal{Role: internal.RoleStudent, StudentID: 1}
+	expectStatus(t, do(t, srv, "POST", "/students/2/re-evaluations", request), http.StatusForbidden)
+	rec := do(t, srv, "POST", "/students/1/re-evaluations", request)
+	expectStatus(t, rec, http.StatusCreated)
+	var re internal.ReEvaluation
+	_ = json.Unmarshal(rec.Body.Bytes(), &re)
+	if re.Status != internal.ReEvaluationPending || re.Grade != "B" {
+		t.Fatalf("expected a pending request against the B, got %+v", re)
+	}
+	review := fmt.Sprintf("/re-evaluations/%d/review", re.ID)
+	expectStatus(t, do(t, srv, "POST", review, map[string]any{}), http.StatusForbidden)
+
+	auth.as = internal.Principal{Role: internal.RoleTeacher, TeacherID: "T2"}
+	expectStatus(t, do(t, srv, "POST", review, map[string]any{"revision": map[string]any{"score": 100}}), http.StatusForbidden)
+	auth.as = internal.Principal{Role: internal.RoleTeacher, TeacherID: "T1"}
+	rec = do(t, srv, "GET", "/teachers/T1/re-evaluations", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var pending []internal.ReEvaluation
+	_ = json.Unmarshal(rec.Body.Bytes(), &pending)
+	if len(pending) != 1 || pending[0].ID != re.ID {
+		t.Errorf("expected the request among the teacher's, got %+v", pending)
+	}
+	expectStatus(t, do(t, srv, "POST", review, map[string]any{"revision": map[string]any{"score": 62}, "note": "re-totalled"}), http.StatusOK)
+
+	auth.as = internal.Principal{Role: internal.RoleStudent, StudentID: 1}
+	rec = do(t, srv, "GET", "/students/1/grade-history", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var history []internal.GradeChange
+	_ = json.Unmarshal(rec.Body.Bytes(), &history)
+	if len(history) != 1 || history[0].OldGrade != "B" || history[0].NewGrade != "B+" || history[0].Actor != "teacher T1" {
+		t.Errorf("expected the B revised to a B+ by T1, got %+v", history)
+	}
+	rec = do(t, srv, "GET", "/students/1/transcript", nil)
+	var record internal.AcademicRecord
+	_ = json.Unmarshal(rec.Body.Bytes(), &record)
+	if record.Semesters[1].Courses[101].Grade != internal.Bplus {
+		t.Errorf("expected the transcript to show the B+, got %+v", record.Semesters[1])
+	}
+}
+
+func TestServer_AttendanceShortage(t *testing.T) {
+	auth := &switchAuthenticator{as: admin}
+	srv := newTestServer(internal.NewPortal(), &memoryRepository{})
+	srv.auth = auth
+
+	expectStatus(t, do(t, srv, "POST", "/students", map[string]any{"id": 1, "name": "Alice"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/courses", map[string]any{"id": 101, "name": "Math"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/teachers", map[string]any{"id": "T1", "name": "Prof. Smith"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/teachers/T1/courses", map[string]any{"course_id": 101, "credits": 4, "semester": 1}), http.StatusCreated)
+	enroll := map[string]any{"student_id": 1, "course_id": 101, "teacher_id": "T1", "grader": map[string]any{"kind": "scale"}}
+	expectStatus(t, do(t, srv, "POST", "/enrollments", enroll), http.StatusCreated)
+	for i, present := range []bool{true, true, false} {
+		mark := map[string]any{"student_id": 1, "teacher_id": "T1", "date": fmt.Sprintf("2025-07-0%dT00:00:00Z", i+1), "present": present}
+		expectStatus(t, do(t, srv, "POST", "/courses/101/attendance", mark), http.StatusCreated)
+	}
+
+	expectStatus(t, do(t, srv, "PUT", "/attendance-minimum", map[string]any{"percent": 101}), http.StatusBadRequest)
+	expectStatus(t, do(t, srv, "PUT", "/attendance-minimum", map[string]any{"course_id": 101, "percent": 70}), http.StatusOK)
+
+	auth.as = internal.Principal{Role: internal.RoleTeacher, TeacherID: "T1"}
+	rec := do(t, srv, "GET", "/teachers/T1/attendance-shortages", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var shortages []internal.EnrollmentAttendance
+	_ = json.Unmarshal(rec.Body.Bytes(), &shortages)
+	if len(shortages) != 1 || shortages[0].StudentID != 1 || shortages[0].Minimum != 70 {
+		t.Fatalf("expected Alice short of 70%%, got %+v", shortages)
+	}
+	expectStatus(t, do(t, srv, "GET", "/teachers/T2/attendance-shortages", nil), http.StatusForbidden)
+	rec = do(t, srv, "GET", "/courses/101/attendance/summary?teacher_id=T1", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var course internal.CourseAttendance
+	_ = json.Unmarshal(rec.Body.Bytes(), &course)
+	if course.Attended != 2 || course.Held != 3 || len(course.Students) != 1 {
+		t.Errorf("expected 2 of 3 classes attended, got %+v", course)
+	}
+	expectStatus(t, do(t, srv, "POST", "/courses/101/marks", map[string]any{"teacher_id": "T1", "student_id": 1, "score": 80}), http.StatusUnprocessableEntity)
+
+	auth.as = admin
+	expectStatus(t, do(t, srv, "POST", "/courses/101/condonations", map[string]any{"student_id": 1, "reason": "medical leave"}), http.StatusCreated)
+	auth.as = internal.Principal{Role: internal.RoleStudent, StudentID: 1}
+	rec = do(t, srv, "GET", "/students/1/courses/101/attendance", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var summary internal.EnrollmentAttendance
+	_ = json.Unmarshal(rec.Body.Bytes(), &summary)
+	if !summary.Condoned || summary.Debarred {
+		t.Errorf("expected the shortage to be condoned, got %+v", summary)
+	}
+	auth.as = internal.Principal{Role: internal.RoleTeacher, TeacherID: "T1"}
+	expectStatus(t, do(t, srv, "POST", "/courses/101/marks", map[string]any{"teacher_id": "T1", "student_id": 1, "score": 80}), http.StatusOK)
+}
+
+func TestServer_ClassSessions(t *testing.T) {
+	auth := &switchAuthenticator{as: admin}
+	srv := newTestServer(internal.NewPortal(), &memoryRepository{})
+	srv.auth = auth
+
+	expectStatus(t, do(t, srv, "POST", "/students", map[string]any{"id": 1, "name": "Alice"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/students", map[string]any{"id": 2, "name": "Bob"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/courses", map[string]any{"id": 101, "name": "Math"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/teachers", map[string]any{"id": "T1", "name": "Prof. Smith"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/teachers/T1/courses", map[string]any{"course_id": 101, "credits": 4, "semester": 1}), http.StatusCreated)
+	for _, id := range []int{1, 2} {
+		enroll := map[string]any{"student_id": id, "course_id": 101, "teacher_id": "T1", "grader": map[string]any{"kind": "scale"}}
+		expectStatus(t, do(t, srv, "POST", "/enrollments", enroll), http.StatusCreated)
+	}
+
+	auth.as = internal.Principal{Role: internal.RoleTeacher, TeacherID: "T1"}
+	for period, kind := range map[int]string{1: "lecture", 2: "tutorial"} {
+		session := map[string]any{"teacher_id": "T1", "date": "2025-07-01T00:00:00Z", "period": period, "type": kind}
+		expectStatus(t, do(t, srv, "POST", "/courses/101/sessions", session), http.StatusCreated)
+	}
+	clash := map[string]any{"teacher_id": "T1", "date": "2025-07-01T00:00:00Z", "period": 2}
+	expectStatus(t, do(t, srv, "POST", "/courses/101/sessions", clash), http.StatusConflict)
+	other := map[string]any{"teacher_id": "T2", "date": "2025-07-01T00:00:00Z", "period": 3}
+	expectStatus(t, do(t, srv, "POST", "/courses/101/sessions", other), http.StatusForbidden)
+	rec := do(t, srv, "GET", "/courses/101/sessions", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var sessions []internal.ClassSession
+	_ = json.Unmarshal(rec.Body.Bytes(), &sessions)
+	if len(sessions) != 2 || sessions[0].Period != 1 || sessions[1].Type != internal.SessionTutorial {
+		t.Fatalf("expected the lecture then the tutorial, got %+v", sessions)
+	}
+	lecture := sessions[0].ID
+
+	expectStatus(t, do(t, srv, "PUT", fmt.Sprintf("/sessions/%d/attendance", lecture), map[string]any{
+		"students": []map[string]any{{"student_id": 1, "status": "late"}, {"student_id": 1, "status": "present"}},
+	}), http.StatusBadRequest)
+	rec = do(t, srv, "PUT", fmt.Sprintf("/sessions/%d/attendance", lecture), map[string]any{
+		"students": []map[string]any{{"student_id": 1, "status": "late"}},
+	})
+	expectStatus(t, rec, http.StatusOK)
+	var roll internal.SessionRoll
+	_ = json.Unmarshal(rec.Body.Bytes(), &roll)
+	want := []internal.RollEntry{{StudentID: 1, StudentName: "Alice", Status: internal.StatusLate}, {StudentID: 2, StudentName: "Bob", Status: internal.StatusAbsent}}
+	if !reflect.DeepEqual(roll.Students, want) {
+		t.Errorf("expected Alice late and Bob absent, got %+v", roll.Students)
+	}
+	expectStatus(t, do(t, srv, "GET", "/sessions/99/attendance", nil), http.StatusNotFound)
+
+	auth.as = internal.Principal{Role: internal.RoleStudent, StudentID: 1}
+	expectStatus(t, do(t, srv, "GET", fmt.Sprintf("/sessions/%d/attendance", lecture), nil), http.StatusForbidden)
+	rec = do(t, srv, "GET", "/courses/101/attendance?student_id=1&teacher_id=T1", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var records []struct {
+		Present   bool   `json:"present"`
+		SessionID int    `json:"session_id"`
+		Status    string `json:"status"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &records)
+	if len(records) != 1 || records[0].SessionID != lecture || !records[0].Present || records[0].Status != "late" {
+		t.Errorf("expected Alice's late arrival to count as present, got %+v", records)
+	}
+}
+
+func TestServer_AttendanceSheet(t *testing.T) {
+	auth := &switchAuthenticator{as: admin}
+	srv := newTestServer(internal.NewPortal(), &memoryRepository{})
+	srv.auth = auth
+
+	expectStatus(t, do(t, srv, "POST", "/students", map[string]any{"id": 1, "name": "Alice"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/courses", map[string]any{"id": 101, "name": "Math"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/teachers", map[string]any{"id": "T1", "name": "Prof. Smith"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/teachers/T1/courses", map[string]any{"course_id": 101, "credits": 4, "semester": 1}), http.StatusCreated)
+	enroll := map[string]any{"student_id": 1, "course_id": 101, "teacher_id": "T1", "grader": map[string]any{"kind": "scale"}}
+	expectStatus(t, do(t, srv, "POST", "/enrollments", enroll), http.StatusCreated)
+
+	auth.as = internal.Principal{Role: internal.RoleTeacher, TeacherID: "T1"}
+	upload := func(path, sheet string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(sheet))
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+	expectStatus(t, upload("/courses/101/attendance/sheet?teacher_id=T1", "id,2025-07-01\n1,P\n"), http.StatusBadRequest)
+	expectStatus(t, upload("/courses/101/attendance/sheet?teacher_id=T2", "student_id,2025-07-01\n1,P\n"), http.StatusForbidden)
+	rec := upload("/courses/101/attendance/sheet?teacher_id=T1", "student_id,2025-07-01,2025-07-02\n1,P,L\n2,P,P\n")
+	expectStatus(t, rec, http.StatusOK)
+	var res internal.AttendanceImport
+	_ = json.Unmarshal(rec.Body.Bytes(), &res)
+	if res.Imported != 1 || len(res.Scheduled) != 2 || len(res.Errors) != 1 || res.Errors[0].Line != 3 {
+		t.Errorf("expected Alice imported and the unknown student on line 3 reported, got %+v", res)
+	}
+
+	rec = do(t, srv, "GET", "/courses/101/attendance/sheet?teacher_id=T1", nil)
+	expectStatus(t, rec, http.StatusOK)
+	want := "student_id,student_name,2025-07-01/1,2025-07-02/1\n1,Alice,present,late\n"
+	if rec.Header().Get("Content-Type") != "text/csv" || rec.Body.String() != want {
+		t.Errorf("expected the grid as CSV, got %q: %s", rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+}
+
+func TestServer_Leave(t *testing.T) {
+	auth := &switchAuthenticator{as: admin}
+	srv := newTestServer(internal.NewPortal(), &memoryRepository{})
+	srv.auth = auth
+
+	expectStatus(t, do(t, srv, "POST", "/students", map[string]any{"id": 1, "name": "Alice"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/courses", map[string]any{"id": 101, "name": "Math"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/teachers", map[string]any{"id": "T1", "name": "Prof. Smith"}), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/teachers/T1/courses", map[string]any{"course_id": 101, "credits": 4, "semester": 1}), http.StatusCreated)
+	enroll := map[string]any{"student_id": 1, "course_id": 101, "teacher_id": "T1", "grader": map[string]any{"kind": "scale"}}
+	expectStatus(t, do(t, srv, "POST", "/enrollments", enroll), http.StatusCreated)
+
+	auth.as = internal.Principal{Role: internal.RoleTeacher, TeacherID: "T1"}
+	for day, present := range map[string]bool{"2025-07-01T00:00:00Z": true, "2025-07-02T00:00:00Z": false} {
+		mark := map[string]any{"student_id": 1, "teacher_id": "T1", "present": present, "date": day}
+		expectStatus(t, do(t, srv, "POST", "/courses/101/attendance", mark), http.StatusCreated)
+	}
+
+	auth.as = internal.Principal{Role: internal.RoleStudent, StudentID: 1}
+	leave := func(courseID int, from, to string) map[string]any {
+		return map[string]any{
+			"course_id": courseID, "kind": "medical", "from": from, "to": to, "reason": "flu",
+			"document": map[string]any{"filename": "certificate.pdf", "mime_type": "application/pdf", "content": base64.StdEncoding.EncodeToString([]byte("%PDF"))},
+		}
+	}
+	expectStatus(t, do(t, srv, "POST", "/students/2/leave-requests", leave(101, "2025-07-02T00:00:00Z", "2025-07-02T00:00:00Z")), http.StatusForbidden)
+	noDocument := leave(101, "2025-07-02T00:00:00Z", "2025-07-02T00:00:00Z")
+	delete(noDocument, "document")
+	expectStatus(t, do(t, srv, "POST", "/students/1/leave-requests", noDocument), http.StatusBadRequest)
+	expectStatus(t, do(t, srv, "POST", "/students/1/leave-requests", leave(101, "2025-07-02T00:00:00Z", "2025-07-02T00:00:00Z")), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/students/1/leave-requests", leave(0, "2025-07-03T00:00:00Z", "2025-07-04T00:00:00Z")), http.StatusCreated)
+	expectStatus(t, do(t, srv, "POST", "/leave-requests/1/review", map[string]any{"approve": true}), http.StatusForbidden)
+
+	// The teacher decides leave from their course but not from every course.
+	auth.as = internal.Principal{Role: internal.RoleTeacher, TeacherID: "T1"}
+	rec := do(t, srv, "GET", "/teachers/T1/leave-requests?status=pending", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var leaves []internal.LeaveRequest
+	_ = json.Unmarshal(rec.Body.Bytes(), &leaves)
+	if len(leaves) != 1 || leaves[0].ID != 1 || string(leaves[0].Document.Content) != "%PDF" {
+		t.Fatalf("expected the math leave with its certificate, got %+v", leaves)
+	}
+	rec = do(t, srv, "POST", "/leave-requests/1/review", map[string]any{"approve": true, "note": "get well soon"})
+	expectStatus(t, rec, http.StatusOK)
+	var approved internal.LeaveRequest
+	_ = json.Unmarshal(rec.Body.Bytes(), &approved)
+	if approved.Status != internal.LeaveApproved || len(approved.Excused) != 1 || len(approved.History) != 2 {
+		t.Errorf("expected the absence on 2 July to be excused, got %+v", approved)
+	}
+	expectStatus(t, do(t, srv, "POST", "/leave-requests/2/review", map[string]any{"approve": true}), http.StatusForbidden)
+	expectStatus(t, do(t, srv, "GET", "/leave-requests", nil), http.StatusForbidden)
+
+	auth.as = internal.Principal{Role: internal.RoleHOD}
+	rec = do(t, srv, "GET", "/leave-requests?status=pending", nil)
+	expectStatus(t, rec, http.StatusOK)
+	_ = json.Unmarshal(rec.Body.Bytes(), &leaves)
+	if len(leaves) != 1 || leaves[0].ID != 2 {
+		t.Fatalf("expected only the leave from every course to be pending, got %+v", leaves)
+	}
+	expectStatus(t, do(t, srv, "POST", "/leave-requests/2/review", map[string]any{"approve": false, "note": "no certificate from a doctor"}), http.StatusOK)
+	expectStatus(t, do(t, srv, "POST", "/leave-requests/2/review", map[string]any{"approve": true}), http.StatusConflict)
+
+	auth.as = internal.Principal{Role: internal.RoleStudent, StudentID: 1}
+	rec = do(t, srv, "GET", "/students/1/courses/101/attendance", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var summary internal.EnrollmentAttendance
+	_ = json.Unmarshal(rec.Body.Bytes(), &summary)
+	if summary.Attended != 1 || summary.Held != 1 || summary.Debarred {
+		t.Errorf("expected the excused absence to be left out, got %+v", summary)
+	}
+	rec = do(t, srv, "GET", "/students/1/leave-requests", nil)
+	expectStatus(t, rec, http.StatusOK)
+	_ = json.Unmarshal(rec.Body.Bytes(), &leaves)
+	if len(leaves) != 2 || leaves[0].Status != internal.LeaveApproved || leaves[1].Status != internal.LeaveRejected {
+		t.Errorf("expected one approved and one rejected leave, got %+v", leaves)
+	}
+}
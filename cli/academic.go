@@ -0,0 +1,579 @@
+package cli
+
+import (
+	"fmt"
+	"mime"
+	"oops/main/infrastructure"
+	"oops/main/internal"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func studentsList(e *env, args []string) error {
+	fs := newFlagSet(e, "students list")
+	state := stateFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return withPortal(*state, false, func(p *internal.Portal) error {
+		for _, st := range p.Academic.Students() {
+			fmt.Fprintf(e.stdout, "#%d : %s\n", st.ID(), st.Name())
+		}
+		return nil
+	})
+}
+
+func studentsAdd(e *env, args []string) error {
+	fs := newFlagSet(e, "students add")
+	state := stateFlag(fs)
+	id := fs.Int("id", 0, "student id")
+	name := fs.String("name", "", "student name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "id", "name"); err != nil {
+		return err
+	}
+	if *id <= 0 {
+		return usageErr("--id must be positive")
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		if internal.FindStudentByID(p.Academic.Students(), *id) != nil {
+			return fmt.Errorf("student with id %d already exists", *id)
+		}
+		p.Academic.AddStudent(internal.NewStudent(*id, *name))
+		fmt.Fprintf(e.stdout, "added student #%d : %s\n", *id, *name)
+		return nil
+	})
+}
+
+func studentsImport(e *env, args []string) error {
+	fs := newFlagSet(e, "students import")
+	state := stateFlag(fs)
+	in := fs.String("in", "students.json", "students JSON file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		var loaded internal.Registrar
+		if err := loaded.LoadStudentsFrom(*in); err != nil {
+			return err
+		}
+		added := 0
+		for _, st := range loaded.Students() {
+			if internal.FindStudentByID(p.Academic.Students(), st.ID()) != nil {
+				continue
+			}
+			p.Academic.AddStudent(st)
+			added++
+		}
+		fmt.Fprintf(e.stdout, "imported %d students (%d already present)\n", added, len(loaded.Students())-added)
+		return nil
+	})
+}
+
+func coursesList(e *env, args []string) error {
+	fs := newFlagSet(e, "courses list")
+	state := stateFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return withPortal(*state, false, func(p *internal.Portal) error {
+		for _, c := range p.Academic.Courses() {
+			fmt.Fprintf(e.stdout, "#%d : %s\n", c.Id, c.Name)
+		}
+		return nil
+	})
+}
+
+func coursesImport(e *env, args []string) error {
+	fs := newFlagSet(e, "courses import")
+	state := stateFlag(fs)
+	in := fs.String("in", "courses.json", "courses JSON file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		var loaded internal.Registrar
+		if err := loaded.LoadCoursesFrom(*in); err != nil {
+			return err
+		}
+		existing := map[int]bool{}
+		for _, c := range p.Academic.Courses() {
+			existing[c.Id] = true
+		}
+		added := 0
+		for _, c := range loaded.Courses() {
+			if existing[c.Id] {
+				continue
+			}
+			p.Academic.AddCourse(c)
+			existing[c.Id] = true
+			added++
+		}
+		fmt.Fprintf(e.stdout, "imported %d courses (%d already present)\n", added, len(loaded.Courses())-added)
+		return nil
+	})
+}
+
+func coursesShowAssessment(e *env, args []string) error {
+	fs := newFlagSet(e, "courses show-assessment")
+	state := stateFlag(fs)
+	course := fs.Int("course", 0, "course id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "course"); err != nil {
+		return err
+	}
+	return withPortal(*state, false, func(p *internal.Portal) error {
+		a, ok := p.Academic.Assessment(*course)
+		if !ok {
+			return fmt.Errorf("course %d has no assessment components", *course)
+		}
+		missing := a.Missing
+		if missing == "" {
+			missing = internal.MissingZero
+		}
+		fmt.Fprintf(e.stdout, "course %d, missing marks count as %s\n", a.CourseID, missing)
+		for _, c := range a.Components {
+			fmt.Fprintf(e.stdout, "%-12s %5.1f%%  out of %g", c.Name, c.Weight, c.MaxMarks)
+			if c.Count > 1 {
+				best := c.BestOf
+				if best == 0 {
+					best = c.Count
+				}
+				fmt.Fprintf(e.stdout, "  best %d of %d", best, c.Count)
+			}
+			fmt.Fprintln(e.stdout)
+		}
+		return nil
+	})
+}
+
+func coursesSetAssessment(e *env, args []string) error {
+	fs := newFlagSet(e, "courses set-assessment")
+	state := stateFlag(fs)
+	in := fs.String("in", "", "assessment JSON file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "in"); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+	a, err := internal.ParseAssessment(data)
+	if err != nil {
+		return err
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		if err := p.Academic.SetAssessment(a); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "course %d is marked on %d components\n", a.CourseID, len(a.Components))
+		return nil
+	})
+}
+
+func attendanceSchedule(e *env, args []string) error {
+	fs := newFlagSet(e, "attendance schedule")
+	state := stateFlag(fs)
+	course := fs.Int("course", 0, "course id")
+	teacher := fs.String("teacher", "", "teacher holding the session")
+	date := fs.String("date", "", "date of the session (YYYY-MM-DD)")
+	period := fs.Int("period", 0, "period of the day, from 1")
+	kind := fs.String("type", string(internal.SessionLecture), "lecture, lab or tutorial")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "course", "teacher", "date", "period"); err != nil {
+		return err
+	}
+	day, err := parseDate(*date)
+	if err != nil {
+		return usageErr("--date: %v", err)
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		s, err := p.Academic.ScheduleSession(internal.ClassSession{
+			CourseID: *course, TeacherID: *teacher, Date: day, Period: *period, Type: internal.SessionType(*kind),
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "scheduled session %d: %s of course %d on %s, period %d\n", s.ID, s.Type, s.CourseID, s.Date.Format(time.DateOnly), s.Period)
+		return nil
+	})
+}
+
+func attendanceTimetable(e *env, args []string) error {
+	fs := newFlagSet(e, "attendance timetable")
+	state := stateFlag(fs)
+	course := fs.Int("course", 0, "course id")
+	teacher := fs.String("teacher", "", "only this teacher's sessions")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "course"); err != nil {
+		return err
+	}
+	return withPortal(*state, false, func(p *internal.Portal) error {
+		sessions := p.Academic.Sessions(*course, *teacher)
+		if len(sessions) == 0 {
+			fmt.Fprintf(e.stdout, "no sessions scheduled for course %d\n", *course)
+			return nil
+		}
+		for _, s := range sessions {
+			fmt.Fprintf(e.stdout, "%3d  %s  period %d  %-8s  %s\n", s.ID, s.Date.Format(time.DateOnly), s.Period, s.Type, s.TeacherID)
+		}
+		return nil
+	})
+}
+
+// attendanceTake takes a session's attendance from lists of students by
+// status; the rest of the class is marked absent.
+func attendanceTake(e *env, args []string) error {
+	fs := newFlagSet(e, "attendance take")
+	state := stateFlag(fs)
+	session := fs.Int("session", 0, "session id")
+	lists := map[internal.AttendanceStatus]*string{}
+	for _, status := range []internal.AttendanceStatus{internal.StatusPresent, internal.StatusLate, internal.StatusExcused} {
+		lists[status] = fs.String(string(status), "", "comma separated ids of students "+string(status))
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "session"); err != nil {
+		return err
+	}
+	roll := map[int]internal.AttendanceStatus{}
+	for status, list := range lists {
+		if *list == "" {
+			continue
+		}
+		ids, err := parseInts(*list)
+		if err != nil {
+			return usageErr("--%s: %v", status, err)
+		}
+		for _, id := range ids {
+			if _, ok := roll[id]; ok {
+				return usageErr("student %d is listed twice", id)
+			}
+			roll[id] = status
+		}
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		if err := p.Academic.TakeAttendance(*session, roll); err != nil {
+			return err
+		}
+		taken, err := p.Academic.SessionRoll(*session)
+		if err != nil {
+			return err
+		}
+		for _, st := range taken.Students {
+			fmt.Fprintf(e.stdout, "%4d %-20s %s\n", st.StudentID, st.StudentName, st.Status)
+		}
+		return nil
+	})
+}
+
+func attendanceExport(e *env, args []string) error {
+	fs := newFlagSet(e, "attendance export")
+	state := stateFlag(fs)
+	course := fs.Int("course", 0, "course id")
+	teacher := fs.String("teacher", "", "teacher id")
+	out := fs.String("out", "", "CSV file to write (default standard output)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "course", "teacher"); err != nil {
+		return err
+	}
+	return withPortal(*state, false, func(p *internal.Portal) error {
+		sheet, err := p.Academic.AttendanceSheet(*course, *teacher)
+		if err != nil {
+			return err
+		}
+		if *out == "" {
+			return infrastructure.WriteAttendanceCSV(e.stdout, sheet)
+		}
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		if err := infrastructure.WriteAttendanceCSV(f, sheet); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "wrote %d students by %d sessions to %s\n", len(sheet.Rows), len(sheet.Columns), *out)
+		return nil
+	})
+}
+
+func attendanceImport(e *env, args []string) error {
+	fs := newFlagSet(e, "attendance import")
+	state := stateFlag(fs)
+	course := fs.Int("course", 0, "course id")
+	teacher := fs.String("teacher", "", "teacher id")
+	in := fs.String("in", "", "CSV grid of student ids by session dates")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "course", "teacher", "in"); err != nil {
+		return err
+	}
+	f, err := os.Open(*in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sheet, err := infrastructure.ReadAttendanceCSV(f, *course, *teacher)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *in, err)
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		res, err := p.Academic.ImportAttendance(sheet)
+		if err != nil {
+			return err
+		}
+		for _, s := range res.Scheduled {
+			fmt.Fprintf(e.stdout, "scheduled session %d: %s on %s, period %d\n", s.ID, s.Type, s.Date.Format(time.DateOnly), s.Period)
+		}
+		for _, re := range res.Errors {
+			fmt.Fprintf(e.stdout, "%s line %d: %s\n", *in, re.Line, re.Error)
+		}
+		fmt.Fprintf(e.stdout, "imported attendance for %d students (%d rows skipped)\n", res.Imported, len(res.Errors))
+		return nil
+	})
+}
+
+func attendanceShortages(e *env, args []string) error {
+	fs := newFlagSet(e, "attendance shortages")
+	state := stateFlag(fs)
+	teacher := fs.String("teacher", "", "teacher id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "teacher"); err != nil {
+		return err
+	}
+	return withPortal(*state, false, func(p *internal.Portal) error {
+		shortages := p.Academic.AttendanceShortages(*teacher)
+		if len(shortages) == 0 {
+			fmt.Fprintln(e.stdout, "no attendance shortages")
+			return nil
+		}
+		for _, a := range shortages {
+			status := "debarred"
+			if a.Condoned {
+				status = "condoned"
+			}
+			fmt.Fprintf(e.stdout, "course %d %-12s student %d %-12s %d/%d  %5.1f%% < %g%%  %s\n",
+				a.CourseID, a.CourseName, a.StudentID, a.StudentName, a.Attended, a.Held, a.Percentage, a.Minimum, status)
+		}
+		return nil
+	})
+}
+
+func attendanceSetMinimum(e *env, args []string) error {
+	fs := newFlagSet(e, "attendance set-minimum")
+	state := stateFlag(fs)
+	course := fs.Int("course", 0, "course id (default every course without its own minimum)")
+	percent := fs.Float64("percent", internal.DefaultMinimumAttendance, "minimum share of classes to attend")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		if err := p.Academic.SetMinimumAttendance(internal.AttendanceMinimum{CourseID: *course, Percent: *percent}); err != nil {
+			return err
+		}
+		if *course == 0 {
+			fmt.Fprintf(e.stdout, "courses require %g%% attendance\n", *percent)
+		} else {
+			fmt.Fprintf(e.stdout, "course %d requires %g%% attendance\n", *course, *percent)
+		}
+		return nil
+	})
+}
+
+func attendanceCondone(e *env, args []string) error {
+	fs := newFlagSet(e, "attendance condone")
+	state := stateFlag(fs)
+	student := fs.Int("student", 0, "student id")
+	course := fs.Int("course", 0, "course id")
+	reason := fs.String("reason", "", "why the shortage is condoned")
+	actor := fs.String("actor", "cli", "who is condoning the shortage")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "student", "course", "reason"); err != nil {
+		return err
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		c := internal.Condonation{StudentID: *student, CourseID: *course, Reason: *reason, By: *actor, At: p.Placement.Now().UTC()}
+		if err := p.Academic.CondoneShortage(c); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "condoned the attendance shortage of student %d in course %d\n", *student, *course)
+		return nil
+	})
+}
+
+func leaveFile(e *env, args []string) error {
+	fs := newFlagSet(e, "leave file")
+	state := stateFlag(fs)
+	student := fs.Int("student", 0, "student id")
+	course := fs.Int("course", 0, "course id (default every course the student takes)")
+	kind := fs.String("kind", string(internal.LeaveMedical), "medical or event")
+	from := fs.String("from", "", "first day of leave (YYYY-MM-DD)")
+	to := fs.String("to", "", "last day of leave (YYYY-MM-DD)")
+	reason := fs.String("reason", "", "why the student was away")
+	document := fs.String("document", "", "supporting document, such as a medical certificate")
+	actor := fs.String("actor", "cli", "who is filing the request")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "student", "from", "to", "reason", "document"); err != nil {
+		return err
+	}
+	start, err := parseDate(*from)
+	if err != nil {
+		return usageErr("invalid --from: %v", err)
+	}
+	end, err := parseDate(*to)
+	if err != nil {
+		return usageErr("invalid --to: %v", err)
+	}
+	content, err := os.ReadFile(*document)
+	if err != nil {
+		return err
+	}
+	doc := internal.Document{
+		Title:    filepath.Base(*document),
+		Filename: filepath.Base(*document),
+		Content:  content,
+		MimeType: mime.TypeByExtension(filepath.Ext(*document)),
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		l, err := p.Academic.FileLeave(internal.LeaveRequest{
+			StudentID: *student, CourseID: *course, Kind: internal.LeaveKind(*kind),
+			From: start, To: end, Reason: *reason, Document: doc,
+		}, *actor, p.Placement.Now().UTC())
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "filed leave request %d for student %d from %s to %s\n", l.ID, l.StudentID, l.From.Format(time.DateOnly), l.To.Format(time.DateOnly))
+		return nil
+	})
+}
+
+func leaveList(e *env, args []string) error {
+	fs := newFlagSet(e, "leave list")
+	state := stateFlag(fs)
+	student := fs.Int("student", 0, "student id (default every student)")
+	status := fs.String("status", "", "pending, approved or rejected (default all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return withPortal(*state, false, func(p *internal.Portal) error {
+		leaves := p.Academic.Leaves(*student, internal.LeaveStatus(*status))
+		if len(leaves) == 0 {
+			fmt.Fprintln(e.stdout, "no leave requests")
+			return nil
+		}
+		for _, l := range leaves {
+			course := "all courses"
+			if l.CourseID != 0 {
+				course = fmt.Sprintf("course %d", l.CourseID)
+			}
+			fmt.Fprintf(e.stdout, "#%d student %d %-11s %s to %s  %-7s %-8s %d excused  %s\n",
+				l.ID, l.StudentID, course, l.From.Format(time.DateOnly), l.To.Format(time.DateOnly), l.Kind, l.Status, len(l.Excused), l.Reason)
+		}
+		return nil
+	})
+}
+
+func leaveReview(e *env, args []string) error {
+	fs := newFlagSet(e, "leave review")
+	state := stateFlag(fs)
+	id := fs.Int("leave", 0, "leave request id")
+	approve := fs.Bool("approve", false, "approve the request, excusing the absences it covers")
+	reject := fs.Bool("reject", false, "reject the request")
+	note := fs.String("note", "", "note for the student")
+	actor := fs.String("actor", "cli", "who is deciding the request")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "leave"); err != nil {
+		return err
+	}
+	if *approve == *reject {
+		return usageErr("give exactly one of --approve and --reject")
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		l, err := p.Academic.ReviewLeave(*id, *approve, *note, *actor, p.Placement.Now().UTC())
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "leave request %d %s; %d absences excused\n", l.ID, l.Status, len(l.Excused))
+		return nil
+	})
+}
+
+func gradesShowScale(e *env, args []string) error {
+	fs := newFlagSet(e, "grades show-scale")
+	state := stateFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return withPortal(*state, false, func(p *internal.Portal) error {
+		scale := p.GradeScale()
+		fmt.Fprintf(e.stdout, "%s grade scale\n", scale.Name)
+		for _, b := range scale.Bands {
+			result := "pass"
+			if !b.Pass {
+				result = "fail"
+			}
+			fmt.Fprintf(e.stdout, "%-3s %5.2f points  %s  from %g%%\n", b.Letter, b.Points, result, b.MinScore)
+		}
+		return nil
+	})
+}
+
+func gradesSetScale(e *env, args []string) error {
+	fs := newFlagSet(e, "grades set-scale")
+	state := stateFlag(fs)
+	in := fs.String("in", "", "grade scale JSON file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "in"); err != nil {
+		return err
+	}
+	scale, err := loadGradeScale(*in)
+	if err != nil {
+		return err
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		if err := p.SetGradeScale(scale); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "grade scale set to %s; recalculated %d applicants\n", scale, len(p.Placement.Applicants()))
+		return nil
+	})
+}
+
+// loadGradeScale reads a grade scale from a JSON file.
+func loadGradeScale(path string) (*internal.GradeScale, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return internal.ParseGradeScale(data)
+}
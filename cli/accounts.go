@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"oops/main/internal"
+)
+
+func accountsList(e *env, args []string) error {
+	fs := newFlagSet(e, "accounts list")
+	state := stateFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return withPortal(*state, false, func(p *internal.Portal) error {
+		for _, a := range p.Accounts.Accounts() {
+			switch a.Role {
+			case internal.RoleStudent:
+				fmt.Fprintf(e.stdout, "%s : %s (student #%d)\n", a.Username, a.Role, a.StudentID)
+			case internal.RoleTeacher:
+				fmt.Fprintf(e.stdout, "%s : %s (teacher %s)\n", a.Username, a.Role, a.TeacherID)
+			default:
+				fmt.Fprintf(e.stdout, "%s : %s\n", a.Username, a.Role)
+			}
+		}
+		return nil
+	})
+}
+
+func accountsAdd(e *env, args []string) error {
+	fs := newFlagSet(e, "accounts add")
+	state := stateFlag(fs)
+	username := fs.String("username", "", "login name")
+	password := fs.String("password", "", "initial password (at least 8 characters)")
+	role := fs.String("role", "", "role (student, teacher, placement_officer, admin or hod)")
+	studentID := fs.Int("student", 0, "linked student id, for student accounts")
+	teacherID := fs.String("teacher", "", "linked teacher id, for teacher accounts")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "username", "password", "role"); err != nil {
+		return err
+	}
+	r, err := internal.ParseRole(*role)
+	if err != nil {
+		return usageErr("--role: %v", err)
+	}
+	account, err := internal.NewAccount(*username, *password, r, *studentID, *teacherID)
+	if err != nil {
+		return usageErr("%v", err)
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		if err := p.AddAccount(account); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "added account %s : %s\n", account.Username, account.Role)
+		return nil
+	})
+}
+
+func accountsPasswd(e *env, args []string) error {
+	fs := newFlagSet(e, "accounts passwd")
+	state := stateFlag(fs)
+	username := fs.String("username", "", "login name")
+	password := fs.String("password", "", "new password (at least 8 characters)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "username", "password"); err != nil {
+		return err
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		account, err := p.Accounts.ByUsername(*username)
+		if err != nil {
+			return err
+		}
+		if err := account.SetPassword(*password); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "changed password for %s\n", account.Username)
+		return nil
+	})
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"fmt"
+	"oops/main/internal"
+)
+
+// gpaChartFlags holds the inputs shared by the GPA based charts.
+type gpaChartFlags struct {
+	in, students, out *string
+	scale             *internal.GradeScale
+}
+
+func parseGPAChartFlags(e *env, name, defaultOut string, args []string) (gpaChartFlags, error) {
+	fs := newFlagSet(e, name)
+	f := gpaChartFlags{
+		in:       fs.String("in", "courseResults.json", "course results JSON"),
+		students: fs.String("students", "students.json", "students JSON"),
+		out:      fs.String("out", defaultOut, "output PNG"),
+		scale:    internal.DefaultGradeScale(),
+	}
+	fs.Func("scale", "grade scale JSON the results are graded on (default 10-point)", func(path string) error {
+		scale, err := loadGradeScale(path)
+		if err != nil {
+			return err
+		}
+		f.scale = scale
+		return nil
+	})
+	return f, fs.Parse(args)
+}
+
+func chartGPAHistogram(e *env, args []string) error {
+	f, err := parseGPAChartFlags(e, "charts gpa-histogram", "gpa_histogram.png", args)
+	if err != nil {
+		return err
+	}
+	hist, err := internal.GenerateGPAHistogramWithScale(*f.in, *f.students, f.scale)
+	if err != nil {
+		return err
+	}
+	if err := internal.ExportGPAHistogramChart(hist, *f.out); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "GPA histogram written to %s\n", *f.out)
+	return nil
+}
+
+func chartDeanList(e *env, args []string) error {
+	f, err := parseGPAChartFlags(e, "charts dean-list", "dean_list.png", args)
+	if err != nil {
+		return err
+	}
+	if err := internal.ExportDeanListChartWithScale(*f.in, *f.students, *f.out, f.scale); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "Dean's list chart written to %s\n", *f.out)
+	return nil
+}
+
+func chartAtRisk(e *env, args []string) error {
+	f, err := parseGPAChartFlags(e, "charts at-risk", "at_risk_students.png", args)
+	if err != nil {
+		return err
+	}
+	if err := internal.ExportAtRiskChartWithScale(*f.in, *f.students, *f.out, f.scale); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "At-risk chart written to %s\n", *f.out)
+	return nil
+}
+
+func chartPlacement(e *env, args []string) error {
+	fs := newFlagSet(e, "charts placement")
+	in := fs.String("in", "placement_offers.json", "placement offers JSON")
+	categorized := fs.String("categorized", "placement_chart.json", "where to write the offers grouped by category")
+	out := fs.String("out", "placement_chart.png", "output PNG")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	offers, err := internal.LoadOffers(*in)
+	if err != nil {
+		return err
+	}
+	if err := internal.ExportCategorizedOffers(*categorized, internal.CategorizeOffers(offers)); err != nil {
+		return err
+	}
+	if err := internal.ExportPlacementBarChart(*categorized, *out); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "Placement chart written to %s\n", *out)
+	return nil
+}
+
+func chartCompanySelection(e *env, args []string) error {
+	fs := newFlagSet(e, "charts company-selection")
+	in := fs.String("in", "placement_offers.json", "placement offers JSON")
+	out := fs.String("out", "company_selection.png", "output PNG")
+	jsonOut := fs.String("json", "company_selection.json", "output JSON with the plotted figures")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := internal.ExportCompanySelectionChart(*in, *out, *jsonOut); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "Company selection chart written to %s\n", *out)
+	return nil
+}
@@ -0,0 +1,250 @@
+// Package cli implements the portal command line tool.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"oops/main/infrastructure"
+	"oops/main/internal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultStatePath is where portal state is kept when --state is not given.
+const DefaultStatePath = "portal.json"
+
+// usageError reports a malformed command line; Run exits with status 2 for it.
+type usageError struct {
+	msg string
+}
+
+func (u usageError) Error() string { return u.msg }
+
+type env struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+type command struct {
+	name    string
+	summary string
+	run     func(e *env, args []string) error
+	sub     []*command
+}
+
+func commands() []*command {
+	return []*command{
+		{name: "students", summary: "manage students", sub: []*command{
+			{name: "list", summary: "list registered students", run: studentsList},
+			{name: "add", summary: "register a student", run: studentsAdd},
+			{name: "import", summary: "import students from a JSON file", run: studentsImport},
+		}},
+		{name: "courses", summary: "manage courses", sub: []*command{
+			{name: "list", summary: "list courses", run: coursesList},
+			{name: "import", summary: "import courses from a JSON file", run: coursesImport},
+			{name: "show-assessment", summary: "show the components a course is marked on", run: coursesShowAssessment},
+			{name: "set-assessment", summary: "declare a course's assessment components from a JSON file", run: coursesSetAssessment},
+		}},
+		{name: "attendance", summary: "take attendance and track shortages", sub: []*command{
+			{name: "schedule", summary: "add a session to a course's timetable", run: attendanceSchedule},
+			{name: "timetable", summary: "list a course's sessions", run: attendanceTimetable},
+			{name: "take", summary: "take a session's attendance for the whole class", run: attendanceTake},
+			{name: "export", summary: "export a teacher's attendance in a course as a CSV grid", run: attendanceExport},
+			{name: "import", summary: "import attendance from a CSV grid of students by sessions", run: attendanceImport},
+			{name: "shortages", summary: "list a teacher's students short of the minimum attendance", run: attendanceShortages},
+			{name: "set-minimum", summary: "set the minimum attendance, for one course or all", run: attendanceSetMinimum},
+			{name: "condone", summary: "condone a student's attendance shortage in a course", run: attendanceCondone},
+		}},
+		{name: "leave", summary: "manage leave requests that excuse absences", sub: []*command{
+			{name: "file", summary: "file a student's leave request with a supporting document", run: leaveFile},
+			{name: "list", summary: "list leave requests", run: leaveList},
+			{name: "review", summary: "approve or reject a pending leave request", run: leaveReview},
+		}},
+		{name: "grades", summary: "manage the grade scale", sub: []*command{
+			{name: "show-scale", summary: "show the grade scale", run: gradesShowScale},
+			{name: "set-scale", summary: "replace the grade scale from a JSON file", run: gradesSetScale},
+		}},
+		{name: "companies", summary: "manage placement companies", sub: []*command{
+			{name: "list", summary: "list companies and their drives", run: companiesList},
+			{name: "add", summary: "register a company", run: companiesAdd},
+		}},
+		{name: "drive", summary: "manage placement drives", sub: []*command{
+			{name: "list", summary: "list drives", run: driveList},
+			{name: "create", summary: "create a drive for a company", run: driveCreate},
+			{name: "status", summary: "update an application's status", run: driveStatus},
+			{name: "history", summary: "show an application's status history", run: driveHistory},
+			{name: "rounds", summary: "define a drive's selection rounds", run: driveRounds},
+			{name: "score", summary: "record an applicant's score in a selection round", run: driveScore},
+			{name: "funnel", summary: "show how applicants fared in each round", run: driveFunnel},
+			{name: "publish", summary: "publish a drive's results, freezing its applications", run: drivePublish},
+			{name: "archive", summary: "archive a drive whose results are published", run: driveArchive},
+		}},
+		{name: "applicants", summary: "manage placement applicants", sub: []*command{
+			{name: "list", summary: "list applicants", run: applicantsList},
+			{name: "add", summary: "register a student for placements", run: applicantsAdd},
+		}},
+		{name: "apply", summary: "apply a student to a drive", run: apply},
+		{name: "offers", summary: "manage placement offers", sub: []*command{
+			{name: "list", summary: "list offers, optionally for one student", run: offersList},
+			{name: "accept", summary: "accept an offer, making it the student's final offer", run: offersAccept},
+			{name: "decline", summary: "decline an offer", run: offersDecline},
+			{name: "expire", summary: "expire offers left pending past their deadline", run: offersExpire},
+		}},
+		{name: "notifications", summary: "manage student notifications", sub: []*command{
+			{name: "list", summary: "list the messages in a student's inbox, newest first", run: notificationsList},
+			{name: "read", summary: "mark messages in a student's inbox read", run: notificationsRead},
+			{name: "subscribe", summary: "choose how a student is notified", run: notificationsSubscribe},
+		}},
+		{name: "reminders", summary: "remind students of approaching deadlines", sub: []*command{
+			{name: "send", summary: "announce opened drives and send the deadline reminders that are due", run: remindersSend},
+		}},
+		{name: "charts", summary: "render analytics charts", sub: []*command{
+			{name: "gpa-histogram", summary: "GPA distribution histogram", run: chartGPAHistogram},
+			{name: "dean-list", summary: "students on the dean's list", run: chartDeanList},
+			{name: "at-risk", summary: "students at academic risk", run: chartAtRisk},
+			{name: "placement", summary: "placements by company and category", run: chartPlacement},
+			{name: "company-selection", summary: "company-wise selection metrics", run: chartCompanySelection},
+		}},
+		{name: "accounts", summary: "manage portal logins", sub: []*command{
+			{name: "list", summary: "list accounts", run: accountsList},
+			{name: "add", summary: "create an account", run: accountsAdd},
+			{name: "passwd", summary: "change an account's password", run: accountsPasswd},
+		}},
+		{name: "serve", summary: "serve the portal over HTTP", run: serve},
+	}
+}
+
+// Run executes the command line args (without the program name) and returns
+// the process exit code.
+func Run(args []string, stdout, stderr io.Writer) int {
+	e := &env{stdout: stdout, stderr: stderr}
+	cmds := commands()
+	path := []string{"portal"}
+	for {
+		if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+			printUsage(stderr, path, cmds)
+			return 2
+		}
+		cmd := find(cmds, args[0])
+		if cmd == nil {
+			fmt.Fprintf(stderr, "%s: unknown command %q\n", strings.Join(path, " "), args[0])
+			printUsage(stderr, path, cmds)
+			return 2
+		}
+		path, args = append(path, cmd.name), args[1:]
+		if cmd.run == nil {
+			cmds = cmd.sub
+			continue
+		}
+		err := cmd.run(e, args)
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, flag.ErrHelp):
+			return 2
+		case errors.As(err, new(usageError)):
+			fmt.Fprintf(stderr, "%s: %v\n", strings.Join(path, " "), err)
+			return 2
+		}
+		fmt.Fprintf(stderr, "%s: %v\n", strings.Join(path, " "), err)
+		return 1
+	}
+}
+
+func find(cmds []*command, name string) *command {
+	for _, c := range cmds {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func printUsage(w io.Writer, path []string, cmds []*command) {
+	fmt.Fprintf(w, "usage: %s <command> [flags]\n\ncommands:\n", strings.Join(path, " "))
+	for _, c := range cmds {
+		fmt.Fprintf(w, "  %-18s %s\n", c.name, c.summary)
+	}
+}
+
+func usageErr(format string, args ...any) error {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+func newFlagSet(e *env, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	return fs
+}
+
+// stateFlag registers the --state flag shared by every command that reads
+// or changes the saved portal.
+func stateFlag(fs *flag.FlagSet) *string {
+	return fs.String("state", DefaultStatePath, "portal state file (.db or .sqlite for SQLite, JSON otherwise)")
+}
+
+// openRepository picks the repository implementation from the file extension.
+func openRepository(path string) (internal.Repository, func() error, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".db", ".sqlite", ".sqlite3":
+		repo, err := infrastructure.OpenSQLRepository(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repo, repo.Close, nil
+	}
+	return infrastructure.NewFileRepository(path), func() error { return nil }, nil
+}
+
+// withPortal loads the portal at statePath, runs fn and, if save is true and
+// fn succeeded, writes the portal back.
+func withPortal(statePath string, save bool, fn func(p *internal.Portal) error) (err error) {
+	repo, closeRepo, err := openRepository(statePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := closeRepo(); err == nil {
+			err = cerr
+		}
+	}()
+	p, err := internal.LoadPortal(repo)
+	if err != nil {
+		return err
+	}
+	if err := fn(p); err != nil {
+		return err
+	}
+	if !save {
+		return nil
+	}
+	return internal.SavePortal(repo, p)
+}
+
+// parseDate accepts either a plain date or a full RFC 3339 timestamp.
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func requireFlags(fs *flag.FlagSet, names ...string) error {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	var missing []string
+	for _, n := range names {
+		if !set[n] {
+			missing = append(missing, "--"+n)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return usageErr("missing required flags: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
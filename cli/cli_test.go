@@ -0,0 +1,419 @@
+package cli
+
+import (
+	"bytes"
+	"oops/main/internal"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func run(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := Run(args, &stdout, &stderr)
+	return stdout.String() + stderr.String(), code
+}
+
+func mustRun(t *testing.T, args ...string) string {
+	t.Helper()
+	out, code := run(t, args...)
+	if code != 0 {
+		t.Fatalf("portal %s exited %d: %s", strings.Join(args, " "), code, out)
+	}
+	return out
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun_Usage(t *testing.T) {
+	if out, code := run(t); code != 2 || !strings.Contains(out, "students") {
+		t.Errorf("expected usage with exit 2, got %d: %s", code, out)
+	}
+	if _, code := run(t, "bogus"); code != 2 {
+		t.Errorf("expected exit 2 for unknown command, got %d", code)
+	}
+	if out, code := run(t, "drive", "create", "--company", "1"); code != 2 || !strings.Contains(out, "--role") {
+		t.Errorf("expected missing flag error, got %d: %s", code, out)
+	}
+}
+
+func TestRun_PlacementWorkflow(t *testing.T) {
+	for _, stateName := range []string{"portal.json", "portal.db"} {
+		t.Run(stateName, func(t *testing.T) {
+			dir := t.TempDir()
+			state := filepath.Join(dir, stateName)
+			// Applications are only taken while a drive is open, so run
+			// the workflow inside its window.
+			start, end := time.Now().AddDate(0, 0, -1).Format(time.DateOnly), time.Now().AddDate(0, 0, 14).Format(time.DateOnly)
+			students := writeFile(t, dir, "students.json", `[{"id": 1, "name": "Alice"}, {"id": 2, "name": "Bob"}]`)
+			results := writeFile(t, dir, "results.json", `[
+				{"student_id": 1, "course_id": 1, "course_name": "Math", "grade": "O", "semester": 1, "credits": 4},
+				{"student_id": 2, "course_id": 1, "course_name": "Math", "grade": "C", "semester": 1, "credits": 4}
+			]`)
+
+			mustRun(t, "students", "import", "--state", state, "--in", students)
+			if out := mustRun(t, "students", "import", "--state", state, "--in", students); !strings.Contains(out, "imported 0 students") {
+				t.Errorf("re-import should skip existing students: %s", out)
+			}
+			if out := mustRun(t, "students", "list", "--state", state); !strings.Contains(out, "#2 : Bob") {
+				t.Errorf("unexpected student list: %s", out)
+			}
+
+			companyID := idAfter(t, mustRun(t, "companies", "add", "--state", state, "--name", "Acme"), "added company #")
+			driveID := idAfter(t, mustRun(t, "drive", "create", "--state", state, "--company", companyID, "--role", "Engineer",
+				"--start", start, "--end", end, "--min-gpa", "6", "--ctc", "1200000", "--category", "Dream",
+				"--max-backlogs", "0", "--departments", "CSE,ECE", "--rule", "cgpa >= 6 && backlogs == 0"), "created drive #")
+			if _, code := run(t, "drive", "create", "--state", state, "--company", companyID, "--role", "X",
+				"--start", start, "--end", end, "--semesters", "7,eight"); code != 2 {
+				t.Errorf("malformed --semesters should be a usage error, exit %d", code)
+			}
+			if out, code := run(t, "drive", "create", "--state", state, "--company", companyID, "--role", "X",
+				"--start", start, "--end", end, "--rule", "cgpa >= && backlogs == 0"); code != 2 || !strings.Contains(out, "column 9") {
+				t.Errorf("malformed --rule should be a usage error naming the column, exit %d: %s", code, out)
+			}
+
+			mustRun(t, "applicants", "add", "--state", state, "--student", "1", "--results", results, "--department", "cse", "--graduation-year", "2026")
+			mustRun(t, "applicants", "add", "--state", state, "--student", "2", "--results", results)
+			if _, code := run(t, "notifications", "subscribe", "--state", state, "--student", "1", "--channels", "inbox,email"); code != 1 {
+				t.Errorf("the email channel without an address should be rejected, exit %d", code)
+			}
+			mustRun(t, "notifications", "subscribe", "--state", state, "--student", "1", "--kinds", "application_status,shortlisted")
+			mustRun(t, "apply", "--state", state, "--student", "1", "--company", companyID, "--drive", driveID)
+			if out, code := run(t, "apply", "--state", state, "--student", "2", "--company", companyID, "--drive", driveID); code != 1 || !strings.Contains(out, "department is not recorded") {
+				t.Errorf("ineligible student should not be able to apply, exit %d: %s", code, out)
+			}
+			if _, code := run(t, "drive", "status", "--state", state, "--drive", driveID, "--student", "1", "--status", "selected"); code != 1 {
+				t.Errorf("applied application should not jump to selected, exit %d", code)
+			}
+			if _, code := run(t, "drive", "rounds", "--state", state, "--company", companyID, "--drive", driveID, "--round", "Aptitude:chess:50"); code == 0 {
+				t.Error("unknown round kind should be rejected")
+			}
+			mustRun(t, "drive", "rounds", "--state", state, "--company", companyID, "--drive", driveID,
+				"--round", "Aptitude:aptitude:50", "--round", "Interview:technical:6")
+			score := func(round, score string) string {
+				return mustRun(t, "drive", "score", "--state", state, "--company", companyID, "--drive", driveID,
+					"--student", "1", "--round", round, "--score", score, "--actor", "officer")
+			}
+			if out := score("1", "70"); !strings.Contains(out, "passed round 1") || !strings.Contains(out, "shortlisted") {
+				t.Errorf("unexpected score output: %s", out)
+			}
+			if out := mustRun(t, "drive", "funnel", "--state", state, "--company", companyID, "--drive", driveID); !strings.Contains(out, "round 2 Interview (technical, pass 6.0): 1 reached, 0 passed, 0 failed, 1 pending") {
+				t.Errorf("unexpected funnel: %s", out)
+			}
+			if out := score("2", "8"); !strings.Contains(out, "cleared") {
+				t.Errorf("unexpected score output: %s", out)
+			}
+			mustRun(t, "drive", "status", "--state", state, "--drive", driveID, "--student", "1", "--status", "selected", "--actor", "officer")
+			if out := mustRun(t, "offers", "list", "--state", state, "--student", "1"); !strings.Contains(out, "offer #1 : student 1") || !strings.Contains(out, "pending") {
+				t.Errorf("unexpected offers: %s", out)
+			}
+			if out := mustRun(t, "offers", "accept", "--state", state, "--offer", "1"); !strings.Contains(out, "accepted") {
+				t.Errorf("unexpected accept output: %s", out)
+			}
+			if _, code := run(t, "offers", "decline", "--state", state, "--offer", "1"); code != 1 {
+				t.Errorf("declining an accepted offer should fail, exit %d", code)
+			}
+			if out := mustRun(t, "drive", "history", "--state", state, "--drive", driveID, "--student", "1"); strings.Count(out, "by officer") != 3 {
+				t.Errorf("unexpected history: %s", out)
+			}
+			if out := mustRun(t, "notifications", "list", "--state", state, "--student", "1"); strings.Count(out, "[application_status]") != 2 || !strings.Contains(out, "[shortlisted] You were shortlisted for Engineer") || !strings.Contains(out, "Engineer application is now selected") {
+				t.Errorf("unexpected notifications: %s", out)
+			}
+			out := mustRun(t, "notifications", "list", "--state", state, "--student", "1", "--kind", "shortlisted")
+			if !strings.Contains(out, "1 of 1 notifications, 1 unread") {
+				t.Errorf("unexpected filtered notifications: %s", out)
+			}
+			shortlisted := idAfter(t, out, "#")
+			if out := mustRun(t, "notifications", "list", "--state", state, "--student", "1", "--limit", "1"); !strings.Contains(out, "now selected (unread)") || !strings.Contains(out, "1 of 3 notifications, 3 unread") {
+				t.Errorf("expected the newest notification first: %s", out)
+			}
+			if _, code := run(t, "notifications", "read", "--state", state, "--student", "1"); code != 2 {
+				t.Errorf("read without --ids or --all should be a usage error, exit %d", code)
+			}
+			if _, code := run(t, "notifications", "read", "--state", state, "--student", "2", "--ids", shortlisted); code != 1 {
+				t.Errorf("marking another student's notification should fail, exit %d", code)
+			}
+			mustRun(t, "notifications", "read", "--state", state, "--student", "1", "--ids", shortlisted)
+			if out := mustRun(t, "notifications", "list", "--state", state, "--student", "1", "--unread"); strings.Contains(out, "shortlisted") || !strings.Contains(out, "2 of 2 notifications, 2 unread") {
+				t.Errorf("the shortlisting should be read: %s", out)
+			}
+			if out := mustRun(t, "notifications", "read", "--state", state, "--student", "1", "--all"); !strings.Contains(out, "marked 2 notifications read") {
+				t.Errorf("unexpected read output: %s", out)
+			}
+
+			if out := mustRun(t, "drive", "list", "--state", state); !strings.Contains(out, "Dream, open") || !strings.Contains(out, "1 applications") || !strings.Contains(out, "eligibility: backlogs <= 0; department in CSE, ECE; cgpa >= 6 && backlogs == 0") {
+				t.Errorf("unexpected drive list: %s", out)
+			}
+
+			if _, code := run(t, "drive", "archive", "--state", state, "--company", companyID, "--drive", driveID); code != 1 {
+				t.Errorf("archiving before publishing results should fail, exit %d", code)
+			}
+			mustRun(t, "drive", "publish", "--state", state, "--company", companyID, "--drive", driveID)
+			if out, code := run(t, "drive", "status", "--state", state, "--drive", driveID, "--student", "1", "--status", "rejected"); code != 1 || !strings.Contains(out, "results published") {
+				t.Errorf("statuses should be frozen once results are published, exit %d: %s", code, out)
+			}
+			mustRun(t, "drive", "archive", "--state", state, "--company", companyID, "--drive", driveID)
+			if out := mustRun(t, "drive", "list", "--state", state); !strings.Contains(out, "Dream, archived") {
+				t.Errorf("expected the drive to be archived: %s", out)
+			}
+		})
+	}
+}
+
+// idAfter returns the id printed right after prefix in a command's output.
+func idAfter(t *testing.T, out, prefix string) string {
+	t.Helper()
+	_, rest, ok := strings.Cut(out, prefix)
+	if !ok {
+		t.Fatalf("no %q in output %q", prefix, out)
+	}
+	return strings.Fields(rest)[0]
+}
+
+func TestRun_Accounts(t *testing.T) {
+	dir := t.TempDir()
+	state := filepath.Join(dir, "portal.json")
+	mustRun(t, "students", "add", "--state", state, "--id", "1", "--name", "Alice")
+
+	mustRun(t, "accounts", "add", "--state", state, "--username", "root", "--password", "rootpass1", "--role", "admin")
+	mustRun(t, "accounts", "add", "--state", state, "--username", "alice", "--password", "alicepass", "--role", "student", "--student", "1")
+	if _, code := run(t, "accounts", "add", "--state", state, "--username", "bob", "--password", "bobpass12", "--role", "student", "--student", "2"); code != 1 {
+		t.Errorf("account for unknown student should fail, exit %d", code)
+	}
+	if _, code := run(t, "accounts", "add", "--state", state, "--username", "eve", "--password", "short", "--role", "admin"); code != 2 {
+		t.Errorf("short password should be a usage error, exit %d", code)
+	}
+	mustRun(t, "accounts", "passwd", "--state", state, "--username", "alice", "--password", "newpass12")
+	if out := mustRun(t, "accounts", "list", "--state", state); !strings.Contains(out, "alice : student (student #1)") {
+		t.Errorf("unexpected account list: %s", out)
+	}
+}
+
+func TestRun_Reminders(t *testing.T) {
+	dir := t.TempDir()
+	state := filepath.Join(dir, "portal.db")
+	start, end := time.Now().AddDate(0, 0, -1).Format(time.DateOnly), time.Now().AddDate(0, 0, 3).Format(time.DateOnly)
+	students := writeFile(t, dir, "students.json", `[{"id": 1, "name": "Alice"}]`)
+	results := writeFile(t, dir, "results.json", `[{"student_id": 1, "course_id": 1, "course_name": "Math", "grade": "O", "semester": 1, "credits": 4}]`)
+	mustRun(t, "students", "import", "--state", state, "--in", students)
+	mustRun(t, "applicants", "add", "--state", state, "--student", "1", "--results", results)
+	companyID := idAfter(t, mustRun(t, "companies", "add", "--state", state, "--name", "Acme"), "added company #")
+	driveID := idAfter(t, mustRun(t, "drive", "create", "--state", state, "--company", companyID, "--role", "Engineer",
+		"--start", start, "--end", end, "--min-gpa", "6", "--ctc", "1200000", "--category", "Dream"), "created drive #")
+
+	if _, code := run(t, "reminders", "send", "--state", state, "--offsets", "7d,soon"); code != 1 {
+		t.Errorf("malformed --offsets should be rejected, exit %d", code)
+	}
+	if out := mustRun(t, "reminders", "send", "--state", state); !strings.Contains(out, "reminded student 1 to apply to drive #"+driveID) {
+		t.Errorf("expected a reminder for Alice: %s", out)
+	}
+	if out := mustRun(t, "reminders", "send", "--state", state); !strings.Contains(out, "sent 0 reminders") {
+		t.Errorf("reminders should not repeat: %s", out)
+	}
+	if out := mustRun(t, "notifications", "list", "--state", state, "--student", "1"); !strings.Contains(out, "[deadline_reminder] Applications for Engineer close in") {
+		t.Errorf("expected the reminder in Alice's inbox: %s", out)
+	}
+}
+
+func TestRun_GradeScale(t *testing.T) {
+	dir := t.TempDir()
+	state := filepath.Join(dir, "portal.json")
+	students := writeFile(t, dir, "students.json", `[{"id": 1, "name": "Alice"}, {"id": 2, "name": "Bob"}]`)
+	scale := writeFile(t, dir, "scale.json", `{"name": "S to F", "bands": [
+		{"letter": "S", "points": 10, "pass": true, "min_score": 90},
+		{"letter": "A", "points": 9, "pass": true, "min_score": 75},
+		{"letter": "E", "points": 5, "pass": true, "min_score": 40},
+		{"letter": "F", "points": 0, "pass": false, "min_score": 0}
+	]}`)
+	results := writeFile(t, dir, "results.json", `[
+		{"student_id": 1, "course_id": 1, "course_name": "Math", "grade": "S", "semester": 1, "credits": 4},
+		{"student_id": 2, "course_id": 1, "course_name": "Math", "grade": "E", "semester": 1, "credits": 4}
+	]`)
+	mustRun(t, "students", "import", "--state", state, "--in", students)
+
+	if out := mustRun(t, "grades", "show-scale", "--state", state); !strings.Contains(out, "10-point grade scale") || !strings.Contains(out, "A+") {
+		t.Errorf("expected the default scale, got: %s", out)
+	}
+	if out, code := run(t, "grades", "set-scale", "--state", state, "--in", students); code != 1 || !strings.Contains(out, "invalid grade scale") {
+		t.Errorf("expected a malformed scale to be refused, got %d: %s", code, out)
+	}
+	mustRun(t, "grades", "set-scale", "--state", state, "--in", scale)
+	if out := mustRun(t, "grades", "show-scale", "--state", state); !strings.Contains(out, "S to F grade scale") || !strings.Contains(out, "F    0.00 points  fail") {
+		t.Errorf("expected the S to F scale, got: %s", out)
+	}
+	if out := mustRun(t, "applicants", "add", "--state", state, "--student", "1", "--results", results); !strings.Contains(out, "CGPA 8.00") {
+		t.Errorf("expected an S read as 10 points, got: %s", out)
+	}
+
+	out := filepath.Join(dir, "dean_list.png")
+	mustRun(t, "charts", "dean-list", "--in", results, "--students", students, "--out", out, "--scale", scale)
+	data, err := os.ReadFile(filepath.Join(dir, "dean_list.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "Alice") || strings.Contains(string(data), "Bob") {
+		t.Errorf("expected only Alice on the dean's list, got %s", data)
+	}
+}
+
+func TestRun_Assessment(t *testing.T) {
+	dir := t.TempDir()
+	state := filepath.Join(dir, "portal.json")
+	courses := writeFile(t, dir, "courses.json", `[{"id": 101, "name": "Math"}]`)
+	assessment := writeFile(t, dir, "assessment.json", `{"course_id": 101, "components": [
+		{"name": "quiz", "weight": 20, "max_marks": 10, "count": 4, "best_of": 3},
+		{"name": "final", "weight": 80, "max_marks": 100}
+	]}`)
+	mustRun(t, "courses", "import", "--state", state, "--in", courses)
+
+	if out, code := run(t, "courses", "set-assessment", "--state", state, "--in", courses); code != 1 || !strings.Contains(out, "invalid assessment") {
+		t.Errorf("expected a malformed assessment to be refused, got %d: %s", code, out)
+	}
+	mustRun(t, "courses", "set-assessment", "--state", state, "--in", assessment)
+	out := mustRun(t, "courses", "show-assessment", "--state", state, "--course", "101")
+	if !strings.Contains(out, "missing marks count as zero") || !strings.Contains(out, "best 3 of 4") {
+		t.Errorf("expected the course's components, got: %s", out)
+	}
+}
+
+func TestRun_AttendanceMinimum(t *testing.T) {
+	dir := t.TempDir()
+	state := filepath.Join(dir, "portal.json")
+	courses := writeFile(t, dir, "courses.json", `[{"id": 101, "name": "Math"}]`)
+	mustRun(t, "courses", "import", "--state", state, "--in", courses)
+
+	if out, code := run(t, "attendance", "set-minimum", "--state", state, "--course", "102", "--percent", "80"); code != 1 || !strings.Contains(out, "course with id 102 not found") {
+		t.Errorf("expected an unknown course to be refused, got %d: %s", code, out)
+	}
+	if out := mustRun(t, "attendance", "set-minimum", "--state", state, "--course", "101", "--percent", "80"); !strings.Contains(out, "course 101 requires 80% attendance") {
+		t.Errorf("expected the course minimum to be set, got: %s", out)
+	}
+	if out := mustRun(t, "attendance", "shortages", "--state", state, "--teacher", "T1"); !strings.Contains(out, "no attendance shortages") {
+		t.Errorf("expected no shortages, got: %s", out)
+	}
+	if out, code := run(t, "attendance", "condone", "--state", state, "--student", "1", "--course", "101", "--reason", "ill"); code != 1 || !strings.Contains(out, "not enrolled") {
+		t.Errorf("expected a student who is not enrolled to be refused, got %d: %s", code, out)
+	}
+}
+
+func TestRun_AttendanceSessions(t *testing.T) {
+	dir := t.TempDir()
+	state := filepath.Join(dir, "portal.json")
+	// There are no commands to assign teachers or enroll students yet.
+	err := withPortal(state, true, func(p *internal.Portal) error {
+		math := internal.NewCourse(101, "Math")
+		teacher := internal.NewTeacher("T1", "Prof. Smith")
+		p.Academic.AddCourse(math)
+		p.Academic.AddTeacher(teacher)
+		p.Academic.AddTeacherenrollment(internal.NewTeacherEnrollment(teacher, internal.NewCreditCourse(math, 4)))
+		for _, st := range []internal.Student{internal.NewStudent(1, "Alice"), internal.NewStudent(2, "Bob"), internal.NewStudent(3, "Carol")} {
+			p.Academic.AddStudent(st)
+			p.Academic.AddEnrollnew(internal.NewEnrollNew(st, math, internal.ScaleGrader{}, 0, internal.Attendance{}, teacher))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out := mustRun(t, "attendance", "timetable", "--state", state, "--course", "101"); !strings.Contains(out, "no sessions scheduled for course 101") {
+		t.Errorf("expected an empty timetable, got: %s", out)
+	}
+	if out := mustRun(t, "attendance", "schedule", "--state", state, "--course", "101", "--teacher", "T1", "--date", "2025-07-01", "--period", "2", "--type", "lab"); !strings.Contains(out, "scheduled session 1: lab of course 101 on 2025-07-01, period 2") {
+		t.Errorf("expected the lab to be scheduled, got: %s", out)
+	}
+	if _, code := run(t, "attendance", "schedule", "--state", state, "--course", "101", "--teacher", "T1", "--date", "2025-07-01", "--period", "2"); code != 1 {
+		t.Errorf("expected a second session in the same period to be refused, got %d", code)
+	}
+	out := mustRun(t, "attendance", "take", "--state", state, "--session", "1", "--present", "1", "--late", "3")
+	for _, want := range []string{"Alice                present", "Bob                  absent", "Carol                late"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the roll, got: %s", want, out)
+		}
+	}
+	if _, code := run(t, "attendance", "take", "--state", state, "--session", "1", "--present", "1", "--excused", "1"); code != 2 {
+		t.Errorf("expected a student listed twice to be a usage error, got %d", code)
+	}
+}
+
+func TestRun_AttendanceImportExport(t *testing.T) {
+	dir := t.TempDir()
+	state := filepath.Join(dir, "portal.json")
+	err := withPortal(state, true, func(p *internal.Portal) error {
+		math := internal.NewCourse(101, "Math")
+		teacher := internal.NewTeacher("T1", "Prof. Smith")
+		p.Academic.AddCourse(math)
+		p.Academic.AddTeacher(teacher)
+		p.Academic.AddTeacherenrollment(internal.NewTeacherEnrollment(teacher, internal.NewCreditCourse(math, 4)))
+		st := internal.NewStudent(1, "Alice")
+		p.Academic.AddStudent(st)
+		p.Academic.AddEnrollnew(internal.NewEnrollNew(st, math, internal.ScaleGrader{}, 0, internal.Attendance{}, teacher))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sheet := writeFile(t, dir, "attendance.csv", "student_id,2025-07-01/2\n1,late\n7,P\n")
+	out := mustRun(t, "attendance", "import", "--state", state, "--course", "101", "--teacher", "T1", "--in", sheet)
+	for _, want := range []string{"scheduled session 1: lecture on 2025-07-01, period 2", "line 3: student 7 is not in teacher T1's class", "imported attendance for 1 students (1 rows skipped)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q, got: %s", want, out)
+		}
+	}
+	if out := mustRun(t, "attendance", "export", "--state", state, "--course", "101", "--teacher", "T1"); out != "student_id,student_name,2025-07-01/2\n1,Alice,late\n" {
+		t.Errorf("expected the imported grid, got: %q", out)
+	}
+	bad := writeFile(t, dir, "bad.csv", "student_id,tomorrow\n")
+	if out, code := run(t, "attendance", "import", "--state", state, "--course", "101", "--teacher", "T1", "--in", bad); code != 1 || !strings.Contains(out, "not a date") {
+		t.Errorf("expected a bad heading to be refused, got %d: %s", code, out)
+	}
+}
+
+func TestRun_Leave(t *testing.T) {
+	dir := t.TempDir()
+	state := filepath.Join(dir, "portal.json")
+	err := withPortal(state, true, func(p *internal.Portal) error {
+		math := internal.NewCourse(101, "Math")
+		teacher := internal.NewTeacher("T1", "Prof. Smith")
+		alice := internal.NewStudent(1, "Alice")
+		p.Academic.AddCourse(math)
+		p.Academic.AddTeacher(teacher)
+		p.Academic.AddStudent(alice)
+		p.Academic.AddTeacherenrollment(internal.NewTeacherEnrollment(teacher, internal.NewCreditCourse(math, 4)))
+		p.Academic.AddEnrollnew(internal.NewEnrollNew(alice, math, internal.ScaleGrader{}, 0, internal.Attendance{}, teacher))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustRun(t, "attendance", "schedule", "--state", state, "--course", "101", "--teacher", "T1", "--date", "2025-07-02", "--period", "1")
+	mustRun(t, "attendance", "take", "--state", state, "--session", "1")
+
+	cert := writeFile(t, dir, "certificate.pdf", "%PDF")
+	if out := mustRun(t, "leave", "file", "--state", state, "--student", "1", "--course", "101", "--from", "2025-07-01", "--to", "2025-07-03", "--reason", "flu", "--document", cert); !strings.Contains(out, "filed leave request 1 for student 1 from 2025-07-01 to 2025-07-03") {
+		t.Errorf("expected the leave to be filed, got: %s", out)
+	}
+	if out, code := run(t, "leave", "review", "--state", state, "--leave", "1"); code != 2 || !strings.Contains(out, "--approve") {
+		t.Errorf("expected a review without a decision to be refused, got %d: %s", code, out)
+	}
+	if out := mustRun(t, "leave", "review", "--state", state, "--leave", "1", "--approve", "--actor", "T1"); !strings.Contains(out, "leave request 1 approved; 1 absences excused") {
+		t.Errorf("expected the absence to be excused, got: %s", out)
+	}
+	if out := mustRun(t, "leave", "list", "--state", state, "--status", "approved"); !strings.Contains(out, "#1 student 1 course 101") || !strings.Contains(out, "1 excused") {
+		t.Errorf("expected the approved leave to be listed, got: %s", out)
+	}
+	if out := mustRun(t, "leave", "list", "--state", state, "--status", "pending"); !strings.Contains(out, "no leave requests") {
+		t.Errorf("expected no pending leave, got: %s", out)
+	}
+}
@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"oops/main/internal"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func notificationsList(e *env, args []string) error {
+	fs := newFlagSet(e, "notifications list")
+	state := stateFlag(fs)
+	q := internal.InboxQuery{}
+	fs.IntVar(&q.StudentID, "student", 0, "student id")
+	fs.IntVar(&q.DriveID, "drive", 0, "only list notifications about this drive")
+	fs.Func("kind", "only list notifications of this kind", func(s string) error {
+		var err error
+		q.Kind, err = internal.ParseNotificationKind(s)
+		return err
+	})
+	fs.BoolVar(&q.UnreadOnly, "unread", false, "only list unread notifications")
+	fs.IntVar(&q.Offset, "offset", 0, "skip this many of the newest notifications")
+	fs.IntVar(&q.Limit, "limit", 0, "list at most this many notifications (0 lists all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "student"); err != nil {
+		return err
+	}
+	if q.Offset < 0 || q.Limit < 0 {
+		return usageErr("--offset and --limit must not be negative")
+	}
+	return withPortal(*state, false, func(p *internal.Portal) error {
+		page := p.Notifications.Inbox().Query(q)
+		for _, m := range page.Messages {
+			read := ""
+			if m.Unread() {
+				read = " (unread)"
+			}
+			fmt.Fprintf(e.stdout, "#%d %s [%s] %s%s\n", m.ID, m.CreatedAt.Format(time.RFC3339), m.Kind, m.Subject, read)
+		}
+		fmt.Fprintf(e.stdout, "%d of %d notifications, %d unread\n", len(page.Messages), page.Total, page.Unread)
+		return nil
+	})
+}
+
+func notificationsRead(e *env, args []string) error {
+	fs := newFlagSet(e, "notifications read")
+	state := stateFlag(fs)
+	studentID := fs.Int("student", 0, "student id")
+	var ids []int
+	fs.Func("ids", "comma-separated ids of the notifications to mark read", func(s string) error {
+		for _, part := range strings.Split(s, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return fmt.Errorf("invalid notification id %q", part)
+			}
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	all := fs.Bool("all", false, "mark every notification of the student read")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "student"); err != nil {
+		return err
+	}
+	if (len(ids) == 0) == !*all {
+		return usageErr("exactly one of --ids and --all is required")
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		if *all {
+			fmt.Fprintf(e.stdout, "marked %d notifications read\n", p.Notifications.MarkAllRead(*studentID))
+			return nil
+		}
+		if err := p.Notifications.MarkRead(*studentID, ids...); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "marked %d notifications read\n", len(ids))
+		return nil
+	})
+}
+
+func notificationsSubscribe(e *env, args []string) error {
+	fs := newFlagSet(e, "notifications subscribe")
+	state := stateFlag(fs)
+	studentID := fs.Int("student", 0, "student id")
+	channels := fs.String("channels", internal.ChannelInbox, "comma-separated channels: inbox, email, webhook")
+	kinds := fs.String("kinds", "", "comma-separated kinds to receive: drive_opened, application_status, shortlisted, marks_uploaded, deadline_reminder, re_evaluation, leave (default all)")
+	email := fs.String("email", "", "address for the email channel")
+	webhook := fs.String("webhook", "", "URL for the webhook channel")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "student"); err != nil {
+		return err
+	}
+	sub := internal.Subscription{StudentID: *studentID, Email: *email, WebhookURL: *webhook}
+	for _, ch := range strings.Split(*channels, ",") {
+		if ch = strings.TrimSpace(ch); ch != "" {
+			sub.Channels = append(sub.Channels, ch)
+		}
+	}
+	for _, k := range strings.Split(*kinds, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			sub.Kinds = append(sub.Kinds, internal.NotificationKind(k))
+		}
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		if internal.FindStudentByID(p.Academic.Students(), *studentID) == nil {
+			return fmt.Errorf("student with id %d not found", *studentID)
+		}
+		if err := p.Notifications.Subscribe(sub); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "student %d is notified by %s\n", sub.StudentID, strings.Join(sub.Channels, ", "))
+		return nil
+	})
+}
+
+// offsetsFlag registers --offsets, the comma-separated durations before a
+// deadline at which reminders are sent. Besides Go durations such as 2h it
+// accepts whole days such as 7d. The result is nil when the flag is unset.
+func offsetsFlag(fs *flag.FlagSet) *[]time.Duration {
+	var offsets []time.Duration
+	fs.Func("offsets", "comma-separated times before a deadline to send reminders, e.g. 7d,1d,2h (default 7d,1d,2h)", func(s string) error {
+		offsets = nil
+		for _, part := range strings.Split(s, ",") {
+			part = strings.TrimSpace(part)
+			var d time.Duration
+			if days, ok := strings.CutSuffix(part, "d"); ok {
+				n, err := strconv.Atoi(days)
+				if err != nil {
+					return fmt.Errorf("invalid offset %q", part)
+				}
+				d = time.Duration(n) * 24 * time.Hour
+			} else {
+				var err error
+				if d, err = time.ParseDuration(part); err != nil {
+					return fmt.Errorf("invalid offset %q", part)
+				}
+			}
+			if d <= 0 {
+				return fmt.Errorf("offset %q must be positive", part)
+			}
+			offsets = append(offsets, d)
+		}
+		return nil
+	})
+	return &offsets
+}
+
+func remindersSend(e *env, args []string) error {
+	fs := newFlagSet(e, "reminders send")
+	state := stateFlag(fs)
+	offsets := offsetsFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		p.Reminders.Offsets = *offsets
+		sent := p.Reminders.Run()
+		for _, r := range sent {
+			if r.OfferID != 0 {
+				fmt.Fprintf(e.stdout, "reminded student %d to answer offer #%d by %s\n", r.StudentID, r.OfferID, r.Deadline.Format(time.RFC3339))
+			} else {
+				fmt.Fprintf(e.stdout, "reminded student %d to apply to drive #%d by %s\n", r.StudentID, r.DriveID, r.Deadline.Format(time.RFC3339))
+			}
+		}
+		fmt.Fprintf(e.stdout, "sent %d reminders\n", len(sent))
+		return nil
+	})
+}
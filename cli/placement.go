@@ -0,0 +1,503 @@
+package cli
+
+import (
+	"fmt"
+	"oops/main/infrastructure"
+	"oops/main/internal"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func printDrive(e *env, p *internal.Portal, companyID int, d *internal.Drive) {
+	fmt.Fprintf(e.stdout, "  drive #%d (company #%d): %s, %s, %s, CTC %d, min GPA %.2f, %s to %s, %d applications\n",
+		d.ID(), companyID, d.RoleName(), d.JobCategory(), p.Placement.DriveState(d), d.CTC(), d.Eligibility().Requirement(),
+		d.StartDate().Format("2006-01-02"), d.EndDate().Format("2006-01-02"), len(d.Applications()))
+	if criteria := d.Eligibility().Criteria(); len(criteria) > 1 {
+		names := make([]string, 0, len(criteria)-1)
+		for _, c := range criteria[1:] {
+			names = append(names, c.String())
+		}
+		fmt.Fprintf(e.stdout, "    eligibility: %s\n", strings.Join(names, "; "))
+	}
+}
+
+// parseInts parses a comma separated list of integers, as given to --semesters.
+func parseInts(s string) ([]int, error) {
+	var out []int
+	for _, part := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", part)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func companiesList(e *env, args []string) error {
+	fs := newFlagSet(e, "companies list")
+	state := stateFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return withPortal(*state, false, func(p *internal.Portal) error {
+		for _, c := range p.Placement.Companies() {
+			fmt.Fprintf(e.stdout, "#%d : %s\n", c.ID(), c.Name())
+			for _, d := range c.Drives() {
+				printDrive(e, p, c.ID(), d)
+			}
+		}
+		return nil
+	})
+}
+
+func companiesAdd(e *env, args []string) error {
+	fs := newFlagSet(e, "companies add")
+	state := stateFlag(fs)
+	name := fs.String("name", "", "company name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "name"); err != nil {
+		return err
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		c := internal.NewCompany(*name)
+		p.Placement.AddCompany(c)
+		fmt.Fprintf(e.stdout, "added company #%d : %s\n", c.ID(), c.Name())
+		return nil
+	})
+}
+
+func driveList(e *env, args []string) error {
+	fs := newFlagSet(e, "drive list")
+	state := stateFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return withPortal(*state, false, func(p *internal.Portal) error {
+		for _, c := range p.Placement.Companies() {
+			for _, d := range c.Drives() {
+				printDrive(e, p, c.ID(), d)
+			}
+		}
+		return nil
+	})
+}
+
+func driveCreate(e *env, args []string) error {
+	fs := newFlagSet(e, "drive create")
+	state := stateFlag(fs)
+	companyID := fs.Int("company", 0, "id of the company running the drive")
+	role := fs.String("role", "", "role name")
+	start := fs.String("start", "", "first day of applications (YYYY-MM-DD or RFC 3339)")
+	end := fs.String("end", "", "last day of applications (YYYY-MM-DD or RFC 3339)")
+	minGPA := fs.Float64("min-gpa", 0, "minimum CGPA to apply")
+	ctc := fs.Int("ctc", 0, "cost to company")
+	category := fs.String("category", internal.Day.String(), `job category ("Day Company", "Dream", "Super Dream" or "Marquee")`)
+	maxBacklogs := fs.Int("max-backlogs", -1, "most active backlogs allowed (-1 for no limit)")
+	minAttendance := fs.Float64("min-attendance", 0, "minimum attendance percentage")
+	semesters := fs.String("semesters", "", "comma separated semesters students must currently be in")
+	years := fs.String("graduation-years", "", "comma separated graduating batches allowed to apply")
+	departments := fs.String("departments", "", "comma separated departments or branches allowed to apply")
+	rule := fs.String("rule", "", `eligibility rule, e.g. "cgpa >= 7.5 && backlogs == 0"`)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "company", "role", "start", "end"); err != nil {
+		return err
+	}
+	startDate, err := parseDate(*start)
+	if err != nil {
+		return usageErr("--start: %v", err)
+	}
+	endDate, err := parseDate(*end)
+	if err != nil {
+		return usageErr("--end: %v", err)
+	}
+	jc, err := internal.ParseJobCategory(*category)
+	if err != nil {
+		return usageErr("--category: %v", err)
+	}
+	var criteria []internal.Criterion
+	if *maxBacklogs >= 0 {
+		criteria = append(criteria, internal.MaxBacklogs(*maxBacklogs))
+	}
+	if *minAttendance > 0 {
+		criteria = append(criteria, internal.MinAttendance(*minAttendance))
+	}
+	if *semesters != "" {
+		sems, err := parseInts(*semesters)
+		if err != nil {
+			return usageErr("--semesters: %v", err)
+		}
+		criteria = append(criteria, internal.AllowedSemesters(sems))
+	}
+	if *years != "" {
+		ys, err := parseInts(*years)
+		if err != nil {
+			return usageErr("--graduation-years: %v", err)
+		}
+		criteria = append(criteria, internal.GraduationYears(ys))
+	}
+	if *departments != "" {
+		criteria = append(criteria, internal.Departments(strings.Split(*departments, ",")))
+	}
+	if *rule != "" {
+		r, err := internal.ParseRule(*rule)
+		if err != nil {
+			return usageErr("--rule: %v", err)
+		}
+		criteria = append(criteria, r)
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		d := internal.NewDrive(startDate, endDate, *role, *minGPA, *ctc, jc)
+		d.AddCriteria(criteria...)
+		if err := p.Placement.AddDriveToCompany(*companyID, d); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "created drive #%d\n", d.ID())
+		return nil
+	})
+}
+
+func driveStatus(e *env, args []string) error {
+	fs := newFlagSet(e, "drive status")
+	state := stateFlag(fs)
+	driveID := fs.Int("drive", 0, "drive id")
+	studentID := fs.Int("student", 0, "student id")
+	status := fs.String("status", "", "new status (shortlisted, cleared, selected, rejected)")
+	actor := fs.String("actor", "cli", "who is making the change, for the application's history")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "drive", "student", "status"); err != nil {
+		return err
+	}
+	st, err := internal.ParseApplicationStatus(*status)
+	if err != nil {
+		return usageErr("--status: %v", err)
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		if err := p.Placement.TransitionApplication(*studentID, *driveID, st, *actor); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "student %d is now %s for drive #%d\n", *studentID, st, *driveID)
+		return nil
+	})
+}
+
+func driveHistory(e *env, args []string) error {
+	fs := newFlagSet(e, "drive history")
+	state := stateFlag(fs)
+	driveID := fs.Int("drive", 0, "drive id")
+	studentID := fs.Int("student", 0, "student id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "drive", "student"); err != nil {
+		return err
+	}
+	return withPortal(*state, false, func(p *internal.Portal) error {
+		app, err := p.Placement.ApplicationFor(*studentID, *driveID)
+		if err != nil {
+			return err
+		}
+		for _, h := range app.History() {
+			fmt.Fprintf(e.stdout, "%s  %s -> %s  by %s\n", h.At.Format(time.RFC3339), h.From, h.To, h.Actor)
+		}
+		return nil
+	})
+}
+
+// parseRound parses a --round value of the form name:kind:pass-score.
+func parseRound(s string) (internal.Round, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return internal.Round{}, fmt.Errorf("%q is not name:kind:pass-score", s)
+	}
+	kind, err := internal.ParseRoundKind(parts[1])
+	if err != nil {
+		return internal.Round{}, err
+	}
+	pass, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return internal.Round{}, fmt.Errorf("invalid pass score %q", parts[2])
+	}
+	return internal.Round{Name: parts[0], Kind: kind, PassScore: pass}, nil
+}
+
+func driveRounds(e *env, args []string) error {
+	fs := newFlagSet(e, "drive rounds")
+	state := stateFlag(fs)
+	companyID := fs.Int("company", 0, "company id")
+	driveID := fs.Int("drive", 0, "drive id")
+	var rounds []internal.Round
+	fs.Func("round", "a selection round as name:kind:pass-score, in order (repeatable; kinds: aptitude, group_discussion, technical, hr)", func(s string) error {
+		r, err := parseRound(s)
+		if err != nil {
+			return err
+		}
+		rounds = append(rounds, r)
+		return nil
+	})
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "company", "drive", "round"); err != nil {
+		return err
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		if err := p.Placement.SetDriveRounds(*companyID, *driveID, rounds); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "drive #%d now has %d selection rounds\n", *driveID, len(rounds))
+		return nil
+	})
+}
+
+func driveScore(e *env, args []string) error {
+	fs := newFlagSet(e, "drive score")
+	state := stateFlag(fs)
+	companyID := fs.Int("company", 0, "company id")
+	driveID := fs.Int("drive", 0, "drive id")
+	studentID := fs.Int("student", 0, "student id")
+	round := fs.Int("round", 0, "round number, starting at 1")
+	score := fs.Float64("score", 0, "the student's score in the round")
+	actor := fs.String("actor", "cli", "who is recording the score, for the application's history")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "company", "drive", "student", "round", "score"); err != nil {
+		return err
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		res, err := p.Placement.RecordRoundResult(*studentID, *companyID, *driveID, *round, *score, *actor)
+		if err != nil {
+			return err
+		}
+		app, err := p.Placement.ApplicationFor(*studentID, *driveID)
+		if err != nil {
+			return err
+		}
+		outcome := "failed"
+		if res.Passed {
+			outcome = "passed"
+		}
+		fmt.Fprintf(e.stdout, "student %d %s round %d of drive #%d and is now %s\n", *studentID, outcome, res.Round, *driveID, app.Status())
+		return nil
+	})
+}
+
+func driveFunnel(e *env, args []string) error {
+	fs := newFlagSet(e, "drive funnel")
+	state := stateFlag(fs)
+	companyID := fs.Int("company", 0, "company id")
+	driveID := fs.Int("drive", 0, "drive id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "company", "drive"); err != nil {
+		return err
+	}
+	return withPortal(*state, false, func(p *internal.Portal) error {
+		funnel, err := p.Placement.RoundFunnel(*companyID, *driveID)
+		if err != nil {
+			return err
+		}
+		for _, f := range funnel {
+			fmt.Fprintf(e.stdout, "round %d %s (%s, pass %.1f): %d reached, %d passed, %d failed, %d pending\n",
+				f.Number, f.Round.Name, f.Round.Kind, f.Round.PassScore, f.Reached, f.Passed, f.Failed, f.Pending)
+		}
+		return nil
+	})
+}
+
+func drivePublish(e *env, args []string) error {
+	fs := newFlagSet(e, "drive publish")
+	state := stateFlag(fs)
+	companyID := fs.Int("company", 0, "company id")
+	driveID := fs.Int("drive", 0, "drive id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "company", "drive"); err != nil {
+		return err
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		if err := p.Placement.PublishResults(*companyID, *driveID); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "published the results of drive #%d\n", *driveID)
+		return nil
+	})
+}
+
+func driveArchive(e *env, args []string) error {
+	fs := newFlagSet(e, "drive archive")
+	state := stateFlag(fs)
+	companyID := fs.Int("company", 0, "company id")
+	driveID := fs.Int("drive", 0, "drive id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "company", "drive"); err != nil {
+		return err
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		if err := p.Placement.ArchiveDrive(*companyID, *driveID); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "archived drive #%d\n", *driveID)
+		return nil
+	})
+}
+
+func applicantsList(e *env, args []string) error {
+	fs := newFlagSet(e, "applicants list")
+	state := stateFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return withPortal(*state, false, func(p *internal.Portal) error {
+		for _, a := range p.Placement.Applicants() {
+			fmt.Fprintf(e.stdout, "#%d : %s, CGPA %.2f, %d drives applied\n", a.ID(), a.Name(), a.CGPA, len(a.DrivesAppliedFor()))
+		}
+		return nil
+	})
+}
+
+func applicantsAdd(e *env, args []string) error {
+	fs := newFlagSet(e, "applicants add")
+	state := stateFlag(fs)
+	studentID := fs.Int("student", 0, "id of a registered student")
+	results := fs.String("results", "courseResults.json", "course results JSON used to build the academic record")
+	department := fs.String("department", "", "the student's department or branch")
+	graduationYear := fs.Int("graduation-year", 0, "the year the student graduates")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "student"); err != nil {
+		return err
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		st := internal.FindStudentByID(p.Academic.Students(), *studentID)
+		if st == nil {
+			return fmt.Errorf("student with id %d not found", *studentID)
+		}
+		scale := p.GradeScale()
+		courseResults, err := infrastructure.LoadCourseResultsWithScale(*results, scale)
+		if err != nil {
+			return err
+		}
+		record := internal.NewAcademicRecord(st.ID())
+		record.SetGradeScale(scale)
+		for _, cr := range courseResults {
+			if cr.StudentId == st.ID() {
+				record.AddResult(cr, cr.Semester)
+			}
+		}
+		record.Status = internal.NewGPACalculator().DetermineStatus(scale.OnTenPointScale(record.CGPA))
+		applicant := internal.NewApplicant(*st, *record)
+		applicant.Department, applicant.GraduationYear = *department, *graduationYear
+		if err := p.Placement.AddApplicant(applicant); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "registered applicant #%d : %s, CGPA %.2f\n", st.ID(), st.Name(), record.CGPA)
+		return nil
+	})
+}
+
+func apply(e *env, args []string) error {
+	fs := newFlagSet(e, "apply")
+	state := stateFlag(fs)
+	studentID := fs.Int("student", 0, "student id")
+	companyID := fs.Int("company", 0, "company id")
+	driveID := fs.Int("drive", 0, "drive id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "student", "company", "drive"); err != nil {
+		return err
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		if err := p.Placement.ApplyForDrive(*studentID, *companyID, *driveID); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "student %d applied to drive #%d\n", *studentID, *driveID)
+		return nil
+	})
+}
+
+func printOffer(e *env, o *internal.Offer, now time.Time) {
+	fmt.Fprintf(e.stdout, "offer #%d : student %d, drive #%d, %s, respond by %s\n",
+		o.ID(), o.StudentID(), o.DriveID(), o.StatusAt(now), o.RespondBy().Format(time.RFC3339))
+}
+
+func offersList(e *env, args []string) error {
+	fs := newFlagSet(e, "offers list")
+	state := stateFlag(fs)
+	studentID := fs.Int("student", 0, "only list offers made to this student")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return withPortal(*state, false, func(p *internal.Portal) error {
+		offers := p.Placement.AllOffers()
+		if *studentID != 0 {
+			offers = p.Placement.OffersFor(*studentID)
+		}
+		for _, o := range offers {
+			printOffer(e, o, p.Placement.Now())
+		}
+		return nil
+	})
+}
+
+func offersAccept(e *env, args []string) error {
+	return offersRespond(e, "offers accept", args, true)
+}
+
+func offersDecline(e *env, args []string) error {
+	return offersRespond(e, "offers decline", args, false)
+}
+
+func offersRespond(e *env, name string, args []string, accept bool) error {
+	fs := newFlagSet(e, name)
+	state := stateFlag(fs)
+	offerID := fs.Int("offer", 0, "offer id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "offer"); err != nil {
+		return err
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		respond := p.Placement.DeclineOffer
+		if accept {
+			respond = p.Placement.AcceptOffer
+		}
+		if err := respond(*offerID); err != nil {
+			return err
+		}
+		o, err := p.Placement.OfferByID(*offerID)
+		if err != nil {
+			return err
+		}
+		printOffer(e, o, p.Placement.Now())
+		return nil
+	})
+}
+
+func offersExpire(e *env, args []string) error {
+	fs := newFlagSet(e, "offers expire")
+	state := stateFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return withPortal(*state, true, func(p *internal.Portal) error {
+		expired := p.Placement.ExpireOffers(p.Placement.Now())
+		fmt.Fprintf(e.stdout, "expired %d offers\n", len(expired))
+		return nil
+	})
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"oops/main/api"
+	"oops/main/infrastructure"
+	"oops/main/internal"
+	"time"
+)
+
+func serve(e *env, args []string) error {
+	fs := newFlagSet(e, "serve")
+	state := stateFlag(fs)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	ttl := fs.Duration("session-ttl", 12*time.Hour, "how long a login stays valid")
+	smtpAddr := fs.String("smtp-addr", "", "host:port of the SMTP server that sends email notifications (email is disabled when empty)")
+	smtpFrom := fs.String("smtp-from", "placements@localhost", "sender address of email notifications")
+	notifyRetries := fs.Int("notify-retries", 3, "how many times to retry a failed email or webhook notification")
+	remindEvery := fs.Duration("remind-every", 15*time.Minute, "how often to check for deadline reminders to send (0 disables them)")
+	offsets := offsetsFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *ttl <= 0 {
+		return usageErr("--session-ttl must be positive")
+	}
+	if *remindEvery < 0 {
+		return usageErr("--remind-every must not be negative")
+	}
+	if *notifyRetries < 0 {
+		return usageErr("--notify-retries must not be negative")
+	}
+	repo, closeRepo, err := openRepository(*state)
+	if err != nil {
+		return err
+	}
+	defer closeRepo()
+	p, err := internal.LoadPortal(repo)
+	if err != nil {
+		return err
+	}
+	if len(p.Accounts.Accounts()) == 0 {
+		fmt.Fprintln(e.stderr, "warning: no accounts exist yet; create one with \"portal accounts add\"")
+	}
+	// Sessions are signed with a key that lives only as long as the process,
+	// so restarting the server logs everybody out.
+	key, err := internal.NewSessionKey()
+	if err != nil {
+		return err
+	}
+	p.Notifications.AddChannel(&infrastructure.WebhookChannel{})
+	if *smtpAddr != "" {
+		p.Notifications.AddChannel(&infrastructure.SMTPChannel{Addr: *smtpAddr, From: *smtpFrom})
+	}
+	// Email and webhooks are sent in the background so that a slow mail
+	// server or webhook never holds up a request.
+	p.Notifications.StartQueue(4, *notifyRetries, 5*time.Second)
+	defer p.Notifications.StopQueue()
+	sessions := internal.NewSessionManager(key, *ttl)
+	p.Reminders.Offsets = *offsets
+	srv := api.NewServer(p, repo, sessions)
+	if *remindEvery > 0 {
+		go func() {
+			for range time.Tick(*remindEvery) {
+				if _, err := srv.SendReminders(); err != nil {
+					fmt.Fprintf(e.stderr, "sending reminders: %v\n", err)
+				}
+			}
+		}()
+	}
+	fmt.Fprintf(e.stdout, "serving portal from %s on %s\n", *state, *addr)
+	return http.ListenAndServe(*addr, srv)
+}
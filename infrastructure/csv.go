@@ -5,9 +5,12 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"oops/main/internal"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 var deanListStudents []internal.AcademicRecord
@@ -108,3 +111,113 @@ func ExportResultsAsCSV(results []internal.StudentResult) ([]byte, error) {
 	writer.Flush()
 	return buf.Bytes(), writer.Error()
 }
+
+// WriteAttendanceCSV writes an attendance sheet as a grid: student_id and
+// student_name, then a column per session headed by its date and period.
+func WriteAttendanceCSV(out io.Writer, sheet internal.AttendanceSheet) error {
+	w := csv.NewWriter(out)
+	header := []string{"student_id", "student_name"}
+	for _, c := range sheet.Columns {
+		header = append(header, c.String())
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, row := range sheet.Rows {
+		record := []string{strconv.Itoa(row.StudentID), row.StudentName}
+		for _, status := range row.Statuses {
+			record = append(record, string(status))
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// ReadAttendanceCSV reads a grid written by WriteAttendanceCSV, or kept by
+// hand: a student_id column, an optional student_name column and a column
+// per session headed by its date, with its period as in 2025-07-01/2 when
+// there is more than one session that day. Cells hold a status or its first
+// letter, or are left empty. Rows that cannot be read are returned in the
+// sheet's Errors.
+func ReadAttendanceCSV(in io.Reader, courseID int, teacherID string) (internal.AttendanceSheet, error) {
+	sheet := internal.AttendanceSheet{CourseID: courseID, TeacherID: teacherID}
+	r := csv.NewReader(in)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	header, err := r.Read()
+	if err == io.EOF {
+		return sheet, fmt.Errorf("attendance sheet is empty")
+	}
+	if err != nil {
+		return sheet, err
+	}
+	// Excel starts the CSV files it saves with a byte order mark.
+	if len(header) == 0 || strings.TrimSpace(strings.TrimPrefix(header[0], "\ufeff")) != "student_id" {
+		return sheet, fmt.Errorf("attendance sheet must start with a student_id column")
+	}
+	first := 1
+	if len(header) > 1 && strings.TrimSpace(header[1]) == "student_name" {
+		first = 2
+	}
+	for _, h := range header[first:] {
+		c, err := parseSheetColumn(h)
+		if err != nil {
+			return sheet, err
+		}
+		sheet.Columns = append(sheet.Columns, c)
+	}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return sheet, nil
+		}
+		if err != nil {
+			return sheet, err
+		}
+		line, _ := r.FieldPos(0)
+		if len(record) != len(header) {
+			sheet.Errors = append(sheet.Errors, internal.RowError{Line: line, Error: fmt.Sprintf("expected %d fields, got %d", len(header), len(record))})
+			continue
+		}
+		row := internal.SheetRow{Line: line}
+		if row.StudentID, err = strconv.Atoi(strings.TrimSpace(record[0])); err != nil {
+			sheet.Errors = append(sheet.Errors, internal.RowError{Line: line, Error: fmt.Sprintf("invalid student id %q", record[0])})
+			continue
+		}
+		if first == 2 {
+			row.StudentName = record[1]
+		}
+		for i, cell := range record[first:] {
+			status, err := internal.ParseAttendanceStatus(cell)
+			if err != nil {
+				sheet.Errors = append(sheet.Errors, internal.RowError{Line: line, StudentID: row.StudentID, Error: fmt.Sprintf("%s: %v", sheet.Columns[i], err)})
+				row.Statuses = nil
+				break
+			}
+			row.Statuses = append(row.Statuses, status)
+		}
+		if len(row.Statuses) == len(sheet.Columns) {
+			sheet.Rows = append(sheet.Rows, row)
+		}
+	}
+}
+
+// parseSheetColumn reads a session column heading: a date, optionally
+// followed by a slash and the period.
+func parseSheetColumn(h string) (internal.SheetColumn, error) {
+	date, period, hasPeriod := strings.Cut(strings.TrimSpace(h), "/")
+	var c internal.SheetColumn
+	var err error
+	if c.Date, err = time.Parse(time.DateOnly, date); err != nil {
+		return c, fmt.Errorf("column %q is not a date (YYYY-MM-DD)", h)
+	}
+	if hasPeriod {
+		if c.Period, err = strconv.Atoi(period); err != nil || c.Period < 1 {
+			return c, fmt.Errorf("column %q has an invalid period", h)
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,62 @@
+package infrastructure
+
+import (
+	"bytes"
+	"oops/main/internal"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAttendanceCSV(t *testing.T) {
+	in := "student_id,student_name,2025-07-01/3,2025-07-02\n" +
+		"1,Alice,P,late\n" +
+		"x,Bob,A,\n" +
+		"2,Bob,A,\n" +
+		"3,Carol,present\n" +
+		"4,Dan,Q,P\n"
+	sheet, err := ReadAttendanceCSV(strings.NewReader(in), 101, "T1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	wantColumns := []internal.SheetColumn{{Date: day, Period: 3}, {Date: day.AddDate(0, 0, 1)}}
+	if !reflect.DeepEqual(sheet.Columns, wantColumns) {
+		t.Errorf("expected the lab on the 1st and a session on the 2nd, got %+v", sheet.Columns)
+	}
+	wantRows := []internal.SheetRow{
+		{Line: 2, StudentID: 1, StudentName: "Alice", Statuses: []internal.AttendanceStatus{internal.StatusPresent, internal.StatusLate}},
+		{Line: 4, StudentID: 2, StudentName: "Bob", Statuses: []internal.AttendanceStatus{internal.StatusAbsent, ""}},
+	}
+	if !reflect.DeepEqual(sheet.Rows, wantRows) {
+		t.Errorf("expected Alice and Bob, got %+v", sheet.Rows)
+	}
+	var lines []int
+	for _, e := range sheet.Errors {
+		lines = append(lines, e.Line)
+	}
+	if !reflect.DeepEqual(lines, []int{3, 5, 6}) {
+		t.Errorf("expected lines 3, 5 and 6 to be reported, got %+v", sheet.Errors)
+	}
+
+	var out bytes.Buffer
+	if err := WriteAttendanceCSV(&out, sheet); err != nil {
+		t.Fatal(err)
+	}
+	want := "student_id,student_name,2025-07-01/3,2025-07-02\n1,Alice,present,late\n2,Bob,absent,\n"
+	if out.String() != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, out.String())
+	}
+
+	bom, err := ReadAttendanceCSV(strings.NewReader("\ufeff"+in), 101, "T1")
+	if err != nil || !reflect.DeepEqual(bom.Rows, wantRows) {
+		t.Errorf("expected a sheet saved with a byte order mark to read the same, got %+v, %v", bom.Rows, err)
+	}
+
+	for _, bad := range []string{"", "name,2025-07-01\n", "student_id,July 1\n", "student_id,2025-07-01/0\n"} {
+		if _, err := ReadAttendanceCSV(strings.NewReader(bad), 101, "T1"); err == nil {
+			t.Errorf("expected %q to be refused", bad)
+		}
+	}
+}
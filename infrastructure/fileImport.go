@@ -2,7 +2,6 @@ package infrastructure
 
 import (
 	"encoding/json"
-	"fmt"
 	"log"
 	"oops/main/internal"
 	"os"
@@ -17,38 +16,35 @@ type courseResultData struct {
 	Credits    float64 `json:"credits"`
 }
 
-func parseGrade(gradeStr string) (internal.AlphabeticGrade, error) {
-	gradeMap := map[string]internal.AlphabeticGrade{
-		"O":  internal.O,
-		"A+": internal.Aplus,
-		"A":  internal.A,
-		"B+": internal.Bplus,
-		"B":  internal.B,
-		"C":  internal.C,
-		"F":  internal.F,
+func LoadCourseResults() []internal.CourseResult {
+	results, err := LoadCourseResultsFrom("courseResults.json")
+	if err != nil {
+		log.Fatal("Failed to load courseResults.json:", err)
 	}
+	return results
+}
 
-	if grade, exists := gradeMap[gradeStr]; exists {
-		return grade, nil
-	}
-	return internal.F, fmt.Errorf("invalid grade: %s", gradeStr)
+// LoadCourseResultsFrom reads course results from the given JSON file,
+// skipping (and logging) entries with an unknown grade.
+func LoadCourseResultsFrom(path string) ([]internal.CourseResult, error) {
+	return LoadCourseResultsWithScale(path, internal.DefaultGradeScale())
 }
 
-func LoadCourseResults() []internal.CourseResult {
+// LoadCourseResultsWithScale is LoadCourseResultsFrom for grades on scale.
+func LoadCourseResultsWithScale(path string, scale *internal.GradeScale) ([]internal.CourseResult, error) {
 	var resultsData []courseResultData
-	data, err := os.ReadFile("courseResults.json")
+	data, err := os.ReadFile(path)
 	if err != nil {
-		log.Fatal("Failed to read courseResults.json:", err)
+		return nil, err
 	}
 
-	err = json.Unmarshal(data, &resultsData)
-	if err != nil {
-		log.Fatal("Failed to unmarshal course results:", err)
+	if err := json.Unmarshal(data, &resultsData); err != nil {
+		return nil, err
 	}
 
 	var results []internal.CourseResult
 	for _, rd := range resultsData {
-		grade, err := parseGrade(rd.Grade)
+		grade, err := scale.ParseGrade(rd.Grade)
 		if err != nil {
 			log.Printf("Warning: %v for student ID %d", err, rd.StudentID)
 			continue
@@ -56,7 +52,7 @@ func LoadCourseResults() []internal.CourseResult {
 		courseResult := internal.NewCourseResult(rd.StudentID, rd.CourseID, rd.CourseName, grade, rd.Semester, rd.Credits)
 		results = append(results, courseResult)
 	}
-	return results
+	return results, nil
 }
 
 /*
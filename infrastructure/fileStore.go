@@ -0,0 +1,59 @@
+package infrastructure
+
+import (
+	"errors"
+	"io/fs"
+	"oops/main/internal"
+	"os"
+	"path/filepath"
+)
+
+// FileRepository keeps a portal snapshot in a single JSON file.
+type FileRepository struct {
+	Path string
+}
+
+func NewFileRepository(path string) *FileRepository {
+	return &FileRepository{Path: path}
+}
+
+// Save writes the snapshot to a temporary file next to Path and renames it
+// into place, so a crash mid-write never leaves a truncated snapshot behind.
+func (fr *FileRepository) Save(s *internal.Snapshot) error {
+	data, err := internal.EncodeSnapshot(s)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(fr.Path), filepath.Base(fr.Path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), fr.Path)
+}
+
+// Load reads the snapshot at Path, returning internal.ErrNoSnapshot when the
+// file does not exist yet.
+func (fr *FileRepository) Load() (*internal.Snapshot, error) {
+	data, err := os.ReadFile(fr.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, internal.ErrNoSnapshot
+	}
+	if err != nil {
+		return nil, err
+	}
+	return internal.DecodeSnapshot(data)
+}
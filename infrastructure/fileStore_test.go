@@ -0,0 +1,48 @@
+package infrastructure
+
+import (
+	"errors"
+	"oops/main/internal"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileRepository_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "portal.json")
+	repo := NewFileRepository(path)
+
+	if _, err := repo.Load(); !errors.Is(err, internal.ErrNoSnapshot) {
+		t.Fatalf("expected ErrNoSnapshot before first save, got %v", err)
+	}
+
+	p := internal.NewPortal()
+	p.Academic.AddStudent(internal.NewStudent(1, "Alice"))
+	if err := internal.SavePortal(repo, p); err != nil {
+		t.Fatalf("SavePortal failed: %v", err)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected only the snapshot file after save, found %d entries", len(entries))
+	}
+
+	restored, err := internal.LoadPortal(repo)
+	if err != nil {
+		t.Fatalf("LoadPortal failed: %v", err)
+	}
+	snap, _ := restored.Snapshot()
+	if len(snap.Students) != 1 || snap.Students[0].Name != "Alice" {
+		t.Errorf("unexpected students after load: %v", snap.Students)
+	}
+}
+
+func TestFileRepository_LoadCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "portal.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileRepository(path).Load(); err == nil {
+		t.Error("expected error for corrupt snapshot")
+	}
+}
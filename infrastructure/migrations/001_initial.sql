@@ -0,0 +1,134 @@
+CREATE TABLE meta (
+    key   TEXT PRIMARY KEY,
+    value TEXT NOT NULL
+);
+
+CREATE TABLE students (
+    id   INTEGER PRIMARY KEY,
+    name TEXT NOT NULL
+);
+CREATE INDEX idx_students_name ON students (name);
+
+CREATE TABLE courses (
+    id   INTEGER PRIMARY KEY,
+    name TEXT NOT NULL
+);
+
+CREATE TABLE credit_courses (
+    id      INTEGER PRIMARY KEY,
+    name    TEXT NOT NULL,
+    credits INTEGER NOT NULL
+);
+
+CREATE TABLE teachers (
+    id   TEXT PRIMARY KEY,
+    name TEXT NOT NULL
+);
+
+CREATE TABLE teacher_enrollments (
+    position     INTEGER PRIMARY KEY,
+    teacher_id   TEXT NOT NULL,
+    teacher_name TEXT NOT NULL,
+    course_id    INTEGER NOT NULL REFERENCES credit_courses (id)
+);
+CREATE INDEX idx_teacher_enrollments_teacher ON teacher_enrollments (teacher_id);
+
+-- Plain Registrar enrollments without a teacher.
+CREATE TABLE enrollments (
+    position     INTEGER PRIMARY KEY,
+    student_id   INTEGER NOT NULL,
+    student_name TEXT NOT NULL,
+    course_id    INTEGER NOT NULL,
+    course_name  TEXT NOT NULL,
+    grader_kind  TEXT NOT NULL,
+    pass_mark    REAL NOT NULL,
+    score        REAL NOT NULL
+);
+CREATE INDEX idx_enrollments_student ON enrollments (student_id);
+
+-- NewRegistrarS enrollments; kind is 'enroll' for NewRegistrarS.enroll and
+-- 'documents' for the enrollments that carry uploaded documents.
+CREATE TABLE enroll_new (
+    id           INTEGER PRIMARY KEY,
+    kind         TEXT NOT NULL,
+    student_id   INTEGER NOT NULL,
+    student_name TEXT NOT NULL,
+    course_id    INTEGER NOT NULL,
+    course_name  TEXT NOT NULL,
+    teacher_id   TEXT NOT NULL,
+    teacher_name TEXT NOT NULL,
+    grader_kind  TEXT NOT NULL,
+    pass_mark    REAL NOT NULL,
+    score        REAL NOT NULL
+);
+CREATE INDEX idx_enroll_new_student ON enroll_new (student_id);
+CREATE INDEX idx_enroll_new_course_teacher ON enroll_new (course_id, teacher_id);
+
+CREATE TABLE attendance (
+    enroll_id INTEGER NOT NULL REFERENCES enroll_new (id) ON DELETE CASCADE,
+    date      TEXT NOT NULL,
+    present   INTEGER NOT NULL,
+    PRIMARY KEY (enroll_id, date)
+);
+
+CREATE TABLE documents (
+    id          INTEGER PRIMARY KEY,
+    enroll_id   INTEGER NOT NULL REFERENCES enroll_new (id) ON DELETE CASCADE,
+    title       TEXT NOT NULL,
+    filename    TEXT NOT NULL,
+    content     BLOB,
+    mime_type   TEXT NOT NULL,
+    uploaded_at TEXT NOT NULL
+);
+
+CREATE TABLE companies (
+    id   INTEGER PRIMARY KEY,
+    name TEXT NOT NULL
+);
+
+CREATE TABLE drives (
+    id           INTEGER PRIMARY KEY,
+    company_id   INTEGER NOT NULL REFERENCES companies (id) ON DELETE CASCADE,
+    start_date   TEXT NOT NULL,
+    end_date     TEXT NOT NULL,
+    role_name    TEXT NOT NULL,
+    minimum_gpa  REAL NOT NULL,
+    ctc          INTEGER NOT NULL,
+    job_category INTEGER NOT NULL
+);
+CREATE INDEX idx_drives_company ON drives (company_id);
+
+CREATE TABLE applicants (
+    student_id INTEGER PRIMARY KEY,
+    name       TEXT NOT NULL,
+    cgpa       REAL NOT NULL,
+    status     TEXT NOT NULL
+);
+
+CREATE TABLE course_results (
+    student_id  INTEGER NOT NULL REFERENCES applicants (student_id) ON DELETE CASCADE,
+    semester    INTEGER NOT NULL,
+    course_id   INTEGER NOT NULL,
+    course_name TEXT NOT NULL,
+    grade       TEXT NOT NULL,
+    credits     REAL NOT NULL,
+    PRIMARY KEY (student_id, semester, course_id)
+);
+
+-- kind is 'applied' for Applicant.drivesAppliedFor and 'offer' for offersReceived.
+CREATE TABLE applicant_drives (
+    student_id INTEGER NOT NULL REFERENCES applicants (student_id) ON DELETE CASCADE,
+    kind       TEXT NOT NULL,
+    position   INTEGER NOT NULL,
+    drive_id   INTEGER NOT NULL,
+    PRIMARY KEY (student_id, kind, position)
+);
+
+CREATE TABLE applications (
+    id         INTEGER PRIMARY KEY,
+    drive_id   INTEGER NOT NULL,
+    student_id INTEGER NOT NULL,
+    status     INTEGER NOT NULL
+);
+CREATE INDEX idx_applications_drive ON applications (drive_id);
+CREATE INDEX idx_applications_student ON applications (student_id);
@@ -0,0 +1,7 @@
+CREATE TABLE accounts (
+    username      TEXT PRIMARY KEY,
+    role          TEXT NOT NULL,
+    student_id    INTEGER,
+    teacher_id    TEXT,
+    password_hash TEXT NOT NULL
+);
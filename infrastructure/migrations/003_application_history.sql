@@ -0,0 +1,9 @@
+CREATE TABLE application_history (
+    application_id INTEGER NOT NULL REFERENCES applications (id) ON DELETE CASCADE,
+    position       INTEGER NOT NULL,
+    from_status    INTEGER NOT NULL,
+    to_status      INTEGER NOT NULL,
+    at             TEXT NOT NULL,
+    actor          TEXT NOT NULL,
+    PRIMARY KEY (application_id, position)
+);
@@ -0,0 +1,18 @@
+CREATE TABLE drive_rounds (
+    drive_id   INTEGER NOT NULL REFERENCES drives (id) ON DELETE CASCADE,
+    position   INTEGER NOT NULL,
+    name       TEXT NOT NULL,
+    kind       INTEGER NOT NULL,
+    pass_score REAL NOT NULL,
+    PRIMARY KEY (drive_id, position)
+);
+
+CREATE TABLE application_rounds (
+    application_id INTEGER NOT NULL REFERENCES applications (id) ON DELETE CASCADE,
+    round          INTEGER NOT NULL,
+    score          REAL NOT NULL,
+    passed         INTEGER NOT NULL,
+    recorded_at    TEXT NOT NULL,
+    actor          TEXT NOT NULL,
+    PRIMARY KEY (application_id, round)
+);
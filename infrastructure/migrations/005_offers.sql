@@ -0,0 +1,18 @@
+CREATE TABLE offers (
+    id             INTEGER PRIMARY KEY,
+    application_id INTEGER NOT NULL REFERENCES applications (id) ON DELETE CASCADE,
+    student_id     INTEGER NOT NULL,
+    drive_id       INTEGER NOT NULL,
+    status         INTEGER NOT NULL,
+    issued_at      TEXT NOT NULL,
+    respond_by     TEXT NOT NULL,
+    responded_at   TEXT NOT NULL
+);
+
+CREATE INDEX offers_student ON offers (student_id);
+
+-- Selections made before offers existed count as accepted offers.
+INSERT INTO offers (id, application_id, student_id, drive_id, status, issued_at, respond_by, responded_at)
+SELECT row_number() OVER (ORDER BY id), id, student_id, drive_id, 1,
+       '0001-01-01T00:00:00Z', '0001-01-01T00:00:00Z', '0001-01-01T00:00:00Z'
+FROM applications WHERE status = 3;
@@ -0,0 +1,12 @@
+ALTER TABLE applicants ADD COLUMN department TEXT NOT NULL DEFAULT '';
+ALTER TABLE applicants ADD COLUMN graduation_year INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE drive_criteria (
+    drive_id INTEGER NOT NULL REFERENCES drives (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    kind     TEXT NOT NULL,
+    value    REAL NOT NULL,
+    numbers  TEXT NOT NULL, -- JSON array
+    names    TEXT NOT NULL, -- JSON array
+    PRIMARY KEY (drive_id, position)
+);
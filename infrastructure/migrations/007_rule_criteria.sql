@@ -0,0 +1 @@
+ALTER TABLE drive_criteria ADD COLUMN expression TEXT NOT NULL DEFAULT '';
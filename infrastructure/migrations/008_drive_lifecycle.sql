@@ -0,0 +1,2 @@
+ALTER TABLE drives ADD COLUMN results_published_at TEXT NOT NULL DEFAULT '0001-01-01T00:00:00Z';
+ALTER TABLE drives ADD COLUMN archived_at TEXT NOT NULL DEFAULT '0001-01-01T00:00:00Z';
@@ -0,0 +1,26 @@
+ALTER TABLE drives ADD COLUMN announced_at TEXT NOT NULL DEFAULT '0001-01-01T00:00:00Z';
+
+-- Drives saved before notifications existed count as announced when they were
+-- saved, so upgrading does not notify students of old drives.
+UPDATE drives SET announced_at = (SELECT value FROM meta WHERE key = 'saved_at')
+WHERE EXISTS (SELECT 1 FROM meta WHERE key = 'saved_at');
+
+CREATE TABLE notification_subscriptions (
+    student_id  INTEGER PRIMARY KEY,
+    channels    TEXT NOT NULL, -- JSON array
+    kinds       TEXT NOT NULL, -- JSON array
+    email       TEXT NOT NULL,
+    webhook_url TEXT NOT NULL
+);
+
+CREATE TABLE notifications (
+    id         INTEGER PRIMARY KEY,
+    student_id INTEGER NOT NULL,
+    kind       TEXT NOT NULL,
+    drive_id   INTEGER NOT NULL,
+    subject    TEXT NOT NULL,
+    body       TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX notifications_student ON notifications (student_id);
@@ -0,0 +1,10 @@
+-- Deadline reminders already sent, keyed by what they were about and how long
+-- before the deadline, so restarting does not send them again.
+CREATE TABLE reminders (
+    key        TEXT PRIMARY KEY,
+    student_id INTEGER NOT NULL,
+    drive_id   INTEGER NOT NULL,
+    offer_id   INTEGER NOT NULL,
+    deadline   TEXT NOT NULL,
+    sent_at    TEXT NOT NULL
+);
@@ -0,0 +1,2 @@
+-- When the student read each notification; unread ones keep the zero time.
+ALTER TABLE notifications ADD COLUMN read_at TEXT NOT NULL DEFAULT '0001-01-01T00:00:00Z';
@@ -0,0 +1,9 @@
+-- The portal's grade scale, best grade first; its name is kept in meta under
+-- 'grade_scale_name'. No rows means the default 10-point scale.
+CREATE TABLE grade_scale (
+    position  INTEGER PRIMARY KEY,
+    letter    TEXT NOT NULL UNIQUE,
+    points    REAL NOT NULL,
+    pass      INTEGER NOT NULL,
+    min_score REAL NOT NULL
+);
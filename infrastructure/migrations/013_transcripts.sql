@@ -0,0 +1,16 @@
+-- The semester a course is taught in, the scale grader's maximum score, and
+-- the transcript results recorded from uploaded marks.
+ALTER TABLE credit_courses ADD COLUMN semester INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE enrollments ADD COLUMN max_score REAL NOT NULL DEFAULT 0;
+ALTER TABLE enroll_new ADD COLUMN max_score REAL NOT NULL DEFAULT 0;
+
+CREATE TABLE transcripts (
+    student_id  INTEGER NOT NULL,
+    course_id   INTEGER NOT NULL,
+    course_name TEXT NOT NULL,
+    grade       TEXT NOT NULL,
+    semester    INTEGER NOT NULL,
+    credits     REAL NOT NULL,
+    position    INTEGER NOT NULL,
+    PRIMARY KEY (student_id, course_id)
+);
@@ -0,0 +1,3 @@
+-- Cut-offs of curve graders, as a JSON list of {letter, min_score}.
+ALTER TABLE enrollments ADD COLUMN grader_cutoffs TEXT NOT NULL DEFAULT 'null';
+ALTER TABLE enroll_new ADD COLUMN grader_cutoffs TEXT NOT NULL DEFAULT 'null';
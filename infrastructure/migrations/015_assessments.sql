@@ -0,0 +1,21 @@
+-- Course assessments, their weighted components, and the component marks
+-- each enrollment's score was worked out from, as a JSON list of
+-- {component, attempt, marks}.
+CREATE TABLE assessments (
+    course_id INTEGER PRIMARY KEY,
+    missing   TEXT NOT NULL
+);
+
+CREATE TABLE assessment_components (
+    course_id INTEGER NOT NULL REFERENCES assessments(course_id),
+    position  INTEGER NOT NULL,
+    name      TEXT NOT NULL,
+    weight    REAL NOT NULL,
+    max_marks REAL NOT NULL,
+    count     INTEGER NOT NULL,
+    best_of   INTEGER NOT NULL,
+    PRIMARY KEY (course_id, position)
+);
+
+ALTER TABLE enrollments ADD COLUMN component_marks TEXT NOT NULL DEFAULT 'null';
+ALTER TABLE enroll_new ADD COLUMN component_marks TEXT NOT NULL DEFAULT 'null';
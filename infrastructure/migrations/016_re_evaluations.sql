@@ -0,0 +1,28 @@
+-- Re-evaluation requests against course marks, and the marks they replaced.
+CREATE TABLE re_evaluations (
+    id          INTEGER PRIMARY KEY,
+    student_id  INTEGER NOT NULL,
+    course_id   INTEGER NOT NULL,
+    teacher_id  TEXT NOT NULL,
+    reason      TEXT NOT NULL,
+    status      TEXT NOT NULL,
+    score       REAL NOT NULL,
+    grade       TEXT NOT NULL,
+    filed_at    TEXT NOT NULL,
+    reviewed_by TEXT NOT NULL,
+    reviewed_at TEXT NOT NULL,
+    note        TEXT NOT NULL
+);
+
+CREATE TABLE grade_history (
+    position         INTEGER PRIMARY KEY,
+    student_id       INTEGER NOT NULL,
+    course_id        INTEGER NOT NULL,
+    re_evaluation_id INTEGER NOT NULL REFERENCES re_evaluations(id),
+    old_score        REAL NOT NULL,
+    old_grade        TEXT NOT NULL,
+    new_score        REAL NOT NULL,
+    new_grade        TEXT NOT NULL,
+    at               TEXT NOT NULL,
+    actor            TEXT NOT NULL
+);
@@ -0,0 +1,16 @@
+-- The attendance each course requires (course 0 is the default) and the
+-- shortages condoned.
+CREATE TABLE attendance_minimums (
+    course_id INTEGER PRIMARY KEY,
+    percent   REAL NOT NULL
+);
+
+CREATE TABLE condonations (
+    student_id  INTEGER NOT NULL,
+    course_id   INTEGER NOT NULL,
+    reason      TEXT NOT NULL,
+    condoned_by TEXT NOT NULL,
+    condoned_at TEXT NOT NULL,
+    position    INTEGER NOT NULL,
+    PRIMARY KEY (student_id, course_id)
+);
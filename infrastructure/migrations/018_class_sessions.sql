@@ -0,0 +1,17 @@
+-- Course timetables and the attendance taken at each session.
+CREATE TABLE class_sessions (
+    id         INTEGER PRIMARY KEY,
+    course_id  INTEGER NOT NULL,
+    teacher_id TEXT NOT NULL,
+    date       TEXT NOT NULL,
+    period     INTEGER NOT NULL,
+    type       TEXT NOT NULL,
+    UNIQUE (teacher_id, date, period)
+);
+
+CREATE TABLE session_attendance (
+    enroll_id  INTEGER NOT NULL REFERENCES enroll_new (id) ON DELETE CASCADE,
+    session_id INTEGER NOT NULL,
+    status     TEXT NOT NULL,
+    PRIMARY KEY (enroll_id, session_id)
+);
@@ -0,0 +1,21 @@
+-- Leave requests with their supporting document and audit trail, and
+-- absences marked by date that were excused on leave.
+ALTER TABLE attendance ADD COLUMN excused INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE leave_requests (
+    id              INTEGER PRIMARY KEY,
+    student_id      INTEGER NOT NULL,
+    course_id       INTEGER NOT NULL,
+    kind            TEXT NOT NULL,
+    from_date       TEXT NOT NULL,
+    to_date         TEXT NOT NULL,
+    reason          TEXT NOT NULL,
+    status          TEXT NOT NULL,
+    doc_title       TEXT NOT NULL,
+    doc_filename    TEXT NOT NULL,
+    doc_content     BLOB NOT NULL,
+    doc_mime_type   TEXT NOT NULL,
+    doc_uploaded_at TEXT NOT NULL,
+    history         TEXT NOT NULL, -- JSON list of status changes
+    excused         TEXT NOT NULL  -- JSON list of excused absences
+);
@@ -0,0 +1,6 @@
+-- Whether each enrollment has a final mark. Existing enrollments count as
+-- marked when they have a score or component marks.
+ALTER TABLE enrollments ADD COLUMN marked INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE enroll_new ADD COLUMN marked INTEGER NOT NULL DEFAULT 0;
+UPDATE enrollments SET marked = 1 WHERE score <> 0 OR component_marks NOT IN ('null', '[]');
+UPDATE enroll_new SET marked = 1 WHERE score <> 0 OR component_marks NOT IN ('null', '[]');
@@ -0,0 +1,95 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/smtp"
+	"oops/main/internal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// SMTPChannel emails notifications through an SMTP server.
+type SMTPChannel struct {
+	Addr string // host:port of the server
+	From string
+	Auth smtp.Auth // nil for servers that need no login
+}
+
+func (c *SMTPChannel) Name() string { return internal.ChannelEmail }
+
+func (c *SMTPChannel) Deliver(sub internal.Subscription, m internal.Message) error {
+	if sub.Email == "" {
+		return fmt.Errorf("student %d has no email address", sub.StudentID)
+	}
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", c.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", sub.Email)
+	// The subject carries text such as a drive's role name that others
+	// chose, so encode it rather than let a line break start a new header.
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", m.CreatedAt.Format(time.RFC1123Z))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(m.Body)
+	msg.WriteString("\r\n")
+	return smtp.SendMail(c.Addr, c.Auth, c.From, []string{sub.Email}, []byte(msg.String()))
+}
+
+// WebhookChannel posts notifications as JSON to the URL each student
+// subscribed with.
+type WebhookChannel struct {
+	// Client posts the webhooks. Nil uses a client with a ten second
+	// timeout that only connects to public addresses, so a student cannot
+	// aim the server at its own network.
+	Client *http.Client
+}
+
+// publicClient is the webhook client used when none is configured.
+var publicClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{Timeout: 10 * time.Second, Control: dialPublic}).DialContext,
+	},
+}
+
+// dialPublic refuses connections to addresses that are not public, checking
+// the address a name resolved to rather than the name itself.
+func dialPublic(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !internal.PublicAddr(addr) {
+		return fmt.Errorf("webhook may not connect to %s, which is not a public address", host)
+	}
+	return nil
+}
+
+func (c *WebhookChannel) Name() string { return internal.ChannelWebhook }
+
+func (c *WebhookChannel) Deliver(sub internal.Subscription, m internal.Message) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	client := c.Client
+	if client == nil {
+		client = publicClient
+	}
+	resp, err := client.Post(sub.WebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s answered %s", sub.WebhookURL, resp.Status)
+	}
+	return nil
+}
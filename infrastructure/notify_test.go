@@ -0,0 +1,159 @@
+package infrastructure
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"oops/main/internal"
+	"strings"
+	"testing"
+)
+
+// fakeSMTP accepts one SMTP conversation on a local port and sends the
+// envelope recipient and message data it received.
+func fakeSMTP(t *testing.T) (addr string, mail <-chan [2]string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	out := make(chan [2]string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ready")
+		var rcpt string
+		var data strings.Builder
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "MAIL"):
+				reply("250 ok")
+			case strings.HasPrefix(cmd, "RCPT"):
+				rcpt = strings.TrimSpace(line[len("RCPT TO:"):])
+				reply("250 ok")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				reply("250 queued")
+				out <- [2]string{rcpt, data.String()}
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("502 unsupported")
+			}
+		}
+	}()
+	return ln.Addr().String(), out
+}
+
+// hookClient returns a client that sends every request to hook, so tests can
+// subscribe with a public-looking URL while the webhook listens locally.
+func hookClient(hook *httptest.Server) *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, hook.Listener.Addr().String())
+		},
+	}}
+}
+
+func TestDispatcher_EmailAndWebhook(t *testing.T) {
+	addr, mail := fakeSMTP(t)
+	var posted internal.Message
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&posted); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}))
+	defer hook.Close()
+
+	d := internal.NewDispatcher()
+	d.AddChannel(&SMTPChannel{Addr: addr, From: "placements@college.test"})
+	d.AddChannel(&WebhookChannel{Client: hookClient(hook)})
+	sub := internal.Subscription{
+		StudentID: 1, Channels: []string{internal.ChannelInbox, internal.ChannelEmail, internal.ChannelWebhook},
+		Email: "alice@college.test", WebhookURL: "http://hooks.college.test/notify",
+	}
+	if err := d.Subscribe(sub); err != nil {
+		t.Fatal(err)
+	}
+	n := &internal.StatusNotification{DriveID: 7, RoleName: "Engineer", From: internal.Applied, To: internal.ShortListed}
+	if err := d.Notify(1, n); err != nil {
+		t.Fatal(err)
+	}
+
+	got := <-mail
+	if got[0] != "<alice@college.test>" || !strings.Contains(got[1], "Subject: Your Engineer application is now shortlisted") {
+		t.Errorf("unexpected email to %s:\n%s", got[0], got[1])
+	}
+	if posted.StudentID != 1 || posted.DriveID != 7 || posted.Kind != internal.NotifyApplicationStatus {
+		t.Errorf("unexpected webhook payload: %+v", posted)
+	}
+	if msgs := d.Inbox().Messages(1); len(msgs) != 1 || msgs[0].ID != posted.ID {
+		t.Errorf("expected the message in the inbox too, got %+v", msgs)
+	}
+}
+
+func TestWebhookChannel_ReportsFailures(t *testing.T) {
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer hook.Close()
+	c := &WebhookChannel{Client: hook.Client()}
+	err := c.Deliver(internal.Subscription{StudentID: 1, WebhookURL: hook.URL}, internal.Message{StudentID: 1})
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("expected the webhook's status in the error, got %v", err)
+	}
+}
+
+func TestSMTPChannel_EncodesSubject(t *testing.T) {
+	addr, mail := fakeSMTP(t)
+	c := &SMTPChannel{Addr: addr, From: "placements@college.test"}
+	sub := internal.Subscription{StudentID: 1, Email: "alice@college.test"}
+	m := internal.Message{StudentID: 1, Subject: "You were shortlisted for Engineer\r\nBcc: eve@evil.test", Body: "hello"}
+	if err := c.Deliver(sub, m); err != nil {
+		t.Fatal(err)
+	}
+	got := <-mail
+	if strings.Contains(got[1], "\r\nBcc:") {
+		t.Errorf("a line break in the subject started a new header:\n%s", got[1])
+	}
+	if !strings.Contains(got[1], "Subject: =?utf-8?q?") {
+		t.Errorf("expected an encoded subject:\n%s", got[1])
+	}
+}
+
+func TestWebhookChannel_RefusesPrivateAddresses(t *testing.T) {
+	called := false
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer hook.Close()
+	c := &WebhookChannel{}
+	err := c.Deliver(internal.Subscription{StudentID: 1, WebhookURL: hook.URL}, internal.Message{StudentID: 1})
+	if err == nil || !strings.Contains(err.Error(), "not a public address") || called {
+		t.Errorf("expected the default client to refuse a loopback webhook, got %v", err)
+	}
+}
@@ -0,0 +1,1136 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"oops/main/internal"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// SQLRepository stores portal snapshots in an embedded SQLite database and
+// answers indexed lookups without restoring the whole portal.
+type SQLRepository struct {
+	db *sql.DB
+}
+
+// OpenSQLRepository opens (or creates) the database at path and applies any
+// pending migrations.
+func OpenSQLRepository(path string) (*SQLRepository, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	repo := &SQLRepository{db: db}
+	if err := repo.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return repo, nil
+}
+
+func (r *SQLRepository) Close() error {
+	return r.db.Close()
+}
+
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+func loadMigrations() ([]migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+	var out []migration
+	for _, e := range entries {
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s has no version prefix", e.Name())
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %v", e.Name(), err)
+		}
+		data, err := migrationFiles.ReadFile("migrations/" + e.Name())
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, migration{version: version, name: e.Name(), sql: string(data)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].version < out[j].version })
+	return out, nil
+}
+
+// SchemaVersion returns the highest migration applied to the database.
+func (r *SQLRepository) SchemaVersion() (int, error) {
+	var v sql.NullInt64
+	err := r.db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&v)
+	return int(v.Int64), err
+}
+
+func (r *SQLRepository) migrate() error {
+	if _, err := r.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	)`); err != nil {
+		return err
+	}
+	current, err := r.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		tx, err := r.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(m.sql); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("applying migration %s: %w", m.name, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+			m.version, m.name, time.Now().UTC().Format(time.RFC3339)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dataTables lists every table holding portal data, children before parents.
+var dataTables = []string{
+	"attendance", "session_attendance", "documents", "enroll_new", "enrollments",
+	"teacher_enrollments", "credit_courses", "teachers", "courses", "students",
+	"course_results", "transcripts", "applicant_drives", "offers", "application_rounds", "application_history", "applications", "applicants",
+	"drive_criteria", "drive_rounds", "drives", "companies", "accounts",
+	"notifications", "notification_subscriptions", "reminders", "grade_scale",
+	"assessment_components", "assessments", "grade_history", "re_evaluations",
+	"attendance_minimums", "condonations", "class_sessions", "leave_requests", "meta",
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+// Save replaces the stored portal with s in a single transaction.
+func (r *SQLRepository) Save(s *internal.Snapshot) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for _, table := range dataTables {
+		if _, err = tx.Exec("DELETE FROM " + table); err != nil {
+			return err
+		}
+	}
+
+	exec := func(query string, args ...any) {
+		if err == nil {
+			_, err = tx.Exec(query, args...)
+		}
+	}
+
+	exec(`INSERT INTO meta (key, value) VALUES ('saved_at', ?)`, formatTime(s.SavedAt))
+	for _, st := range s.Students {
+		exec(`INSERT INTO students (id, name) VALUES (?, ?)`, st.ID, st.Name)
+	}
+	for _, c := range s.Courses {
+		exec(`INSERT INTO courses (id, name) VALUES (?, ?)`, c.ID, c.Name)
+	}
+	for _, t := range s.Teachers {
+		exec(`INSERT OR REPLACE INTO teachers (id, name) VALUES (?, ?)`, t.ID, t.Name)
+	}
+	for i, te := range s.TeacherEnrollments {
+		exec(`INSERT OR REPLACE INTO credit_courses (id, name, credits, semester) VALUES (?, ?, ?, ?)`,
+			te.Course.ID, te.Course.Name, te.Course.Credits, te.Course.Semester)
+		exec(`INSERT INTO teacher_enrollments (position, teacher_id, teacher_name, course_id) VALUES (?, ?, ?, ?)`,
+			i, te.Teacher.ID, te.Teacher.Name, te.Course.ID)
+	}
+	for i, e := range s.Enrollments {
+		exec(`INSERT INTO enrollments (position, student_id, student_name, course_id, course_name, grader_kind, pass_mark, max_score, grader_cutoffs, score, component_marks, marked)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			i, e.Student.ID, e.Student.Name, e.Course.ID, e.Course.Name, e.Grader.Kind, e.Grader.PassMark, e.Grader.MaxScore, jsonList(e.Grader.Cutoffs), e.Score, jsonList(e.Marks), e.Marked)
+	}
+	if err != nil {
+		return err
+	}
+
+	insertEnrollNew := func(kind string, e internal.EnrollNewRecord) (int64, error) {
+		g := e.Enrollment.Grader
+		res, err := tx.Exec(`INSERT INTO enroll_new (kind, student_id, student_name, course_id, course_name, teacher_id, teacher_name, grader_kind, pass_mark, max_score, grader_cutoffs, score, component_marks, marked)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			kind, e.Enrollment.Student.ID, e.Enrollment.Student.Name, e.Enrollment.Course.ID, e.Enrollment.Course.Name,
+			e.Teacher.ID, e.Teacher.Name, g.Kind, g.PassMark, g.MaxScore, jsonList(g.Cutoffs), e.Enrollment.Score, jsonList(e.Enrollment.Marks), e.Enrollment.Marked)
+		if err != nil {
+			return 0, err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return 0, err
+		}
+		for _, a := range e.Attendance {
+			if _, err := tx.Exec(`INSERT INTO attendance (enroll_id, date, present, excused) VALUES (?, ?, ?, ?)`,
+				id, formatTime(a.Date), a.Present, a.Excused); err != nil {
+				return 0, err
+			}
+		}
+		for _, m := range e.Sessions {
+			if _, err := tx.Exec(`INSERT INTO session_attendance (enroll_id, session_id, status) VALUES (?, ?, ?)`,
+				id, m.SessionID, string(m.Status)); err != nil {
+				return 0, err
+			}
+		}
+		return id, nil
+	}
+	for _, e := range s.EnrollNew {
+		if _, err = insertEnrollNew("enroll", e); err != nil {
+			return err
+		}
+	}
+	for _, d := range s.Documents {
+		var id int64
+		if id, err = insertEnrollNew("documents", d.Enrollment); err != nil {
+			return err
+		}
+		for _, doc := range d.Documents {
+			exec(`INSERT INTO documents (enroll_id, title, filename, content, mime_type, uploaded_at) VALUES (?, ?, ?, ?, ?, ?)`,
+				id, doc.Title, doc.Filename, doc.Content, doc.MimeType, formatTime(doc.UploadedAt))
+		}
+	}
+
+	for _, c := range s.Companies {
+		exec(`INSERT INTO companies (id, name) VALUES (?, ?)`, c.ID, c.Name)
+		for _, d := range c.Drives {
+			exec(`INSERT INTO drives (id, company_id, start_date, end_date, role_name, minimum_gpa, ctc, job_category, announced_at, results_published_at, archived_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				d.ID, c.ID, formatTime(d.StartDate), formatTime(d.EndDate), d.RoleName, d.MinimumGPA, d.CTC, int(d.JobCategory),
+				formatTime(d.AnnouncedAt), formatTime(d.ResultsPublishedAt), formatTime(d.ArchivedAt))
+			for i, round := range d.Rounds {
+				exec(`INSERT INTO drive_rounds (drive_id, position, name, kind, pass_score) VALUES (?, ?, ?, ?, ?)`,
+					d.ID, i, round.Name, int(round.Kind), round.PassScore)
+			}
+			for i, c := range d.Criteria {
+				exec(`INSERT INTO drive_criteria (drive_id, position, kind, value, numbers, names, expression) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+					d.ID, i, c.Kind, c.Value, jsonList(c.Numbers), jsonList(c.Names), c.Expression)
+			}
+		}
+	}
+	for _, a := range s.Applicants {
+		ar := a.AcademicRecord
+		exec(`INSERT INTO applicants (student_id, name, cgpa, status, department, graduation_year) VALUES (?, ?, ?, ?, ?, ?)`,
+			a.Student.ID, a.Student.Name, ar.CGPA, ar.Status, a.Department, a.GraduationYear)
+		for sem, result := range ar.Semesters {
+			for _, cr := range result.Courses {
+				exec(`INSERT INTO course_results (student_id, semester, course_id, course_name, grade, credits) VALUES (?, ?, ?, ?, ?, ?)`,
+					a.Student.ID, sem, cr.CourseId, cr.CourseName, cr.Grade.String(), cr.Credits)
+			}
+		}
+		for i, id := range a.DrivesAppliedFor {
+			exec(`INSERT INTO applicant_drives (student_id, kind, position, drive_id) VALUES (?, 'applied', ?, ?)`, a.Student.ID, i, id)
+		}
+		for i, id := range a.OffersReceived {
+			exec(`INSERT INTO applicant_drives (student_id, kind, position, drive_id) VALUES (?, 'offer', ?, ?)`, a.Student.ID, i, id)
+		}
+	}
+	for _, app := range s.Applications {
+		exec(`INSERT INTO applications (id, drive_id, student_id, status) VALUES (?, ?, ?, ?)`,
+			app.ID, app.DriveID, app.StudentID, int(app.Status))
+		for i, h := range app.History {
+			exec(`INSERT INTO application_history (application_id, position, from_status, to_status, at, actor) VALUES (?, ?, ?, ?, ?, ?)`,
+				app.ID, i, int(h.From), int(h.To), formatTime(h.At), h.Actor)
+		}
+		for _, res := range app.RoundResults {
+			exec(`INSERT INTO application_rounds (application_id, round, score, passed, recorded_at, actor) VALUES (?, ?, ?, ?, ?, ?)`,
+				app.ID, res.Round, res.Score, res.Passed, formatTime(res.RecordedAt), res.Actor)
+		}
+	}
+	for _, o := range s.Offers {
+		exec(`INSERT INTO offers (id, application_id, student_id, drive_id, status, issued_at, respond_by, responded_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			o.ID, o.ApplicationID, o.StudentID, o.DriveID, int(o.Status), formatTime(o.IssuedAt), formatTime(o.RespondBy), formatTime(o.RespondedAt))
+	}
+	for _, a := range s.Accounts {
+		exec(`INSERT INTO accounts (username, role, student_id, teacher_id, password_hash) VALUES (?, ?, ?, ?, ?)`,
+			a.Username, a.Role, a.StudentID, a.TeacherID, a.PasswordHash)
+	}
+	for _, sub := range s.Subscriptions {
+		exec(`INSERT INTO notification_subscriptions (student_id, channels, kinds, email, webhook_url) VALUES (?, ?, ?, ?, ?)`,
+			sub.StudentID, jsonList(sub.Channels), jsonList(sub.Kinds), sub.Email, sub.WebhookURL)
+	}
+	for _, m := range s.Notifications {
+		exec(`INSERT INTO notifications (id, student_id, kind, drive_id, subject, body, created_at, read_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			m.ID, m.StudentID, string(m.Kind), m.DriveID, m.Subject, m.Body, formatTime(m.CreatedAt), formatTime(m.ReadAt))
+	}
+	for _, rm := range s.Reminders {
+		exec(`INSERT INTO reminders (key, student_id, drive_id, offer_id, deadline, sent_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			rm.Key, rm.StudentID, rm.DriveID, rm.OfferID, formatTime(rm.Deadline), formatTime(rm.SentAt))
+	}
+	for i, cr := range s.Transcripts {
+		exec(`INSERT INTO transcripts (student_id, course_id, course_name, grade, semester, credits, position) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			cr.StudentId, cr.CourseId, cr.CourseName, string(cr.Grade), cr.Semester, cr.Credits, i)
+	}
+	for _, a := range s.Assessments {
+		exec(`INSERT INTO assessments (course_id, missing) VALUES (?, ?)`, a.CourseID, string(a.Missing))
+		for i, c := range a.Components {
+			exec(`INSERT INTO assessment_components (course_id, position, name, weight, max_marks, count, best_of) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				a.CourseID, i, c.Name, c.Weight, c.MaxMarks, c.Count, c.BestOf)
+		}
+	}
+	for _, re := range s.ReEvaluations {
+		exec(`INSERT INTO re_evaluations (id, student_id, course_id, teacher_id, reason, status, score, grade, filed_at, reviewed_by, reviewed_at, note)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			re.ID, re.StudentID, re.CourseID, re.TeacherID, re.Reason, string(re.Status), re.Score, re.Grade,
+			formatTime(re.FiledAt), re.ReviewedBy, formatTime(re.ReviewedAt), re.Note)
+	}
+	for i, c := range s.GradeHistory {
+		exec(`INSERT INTO grade_history (position, student_id, course_id, re_evaluation_id, old_score, old_grade, new_score, new_grade, at, actor)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			i, c.StudentID, c.CourseID, c.ReEvaluationID, c.OldScore, c.OldGrade, c.NewScore, c.NewGrade, formatTime(c.At), c.Actor)
+	}
+	for _, m := range s.AttendanceMinimums {
+		exec(`INSERT INTO attendance_minimums (course_id, percent) VALUES (?, ?)`, m.CourseID, m.Percent)
+	}
+	for i, c := range s.Condonations {
+		exec(`INSERT INTO condonations (student_id, course_id, reason, condoned_by, condoned_at, position) VALUES (?, ?, ?, ?, ?, ?)`,
+			c.StudentID, c.CourseID, c.Reason, c.By, formatTime(c.At), i)
+	}
+	for _, l := range s.Leaves {
+		d := l.Document
+		exec(`INSERT INTO leave_requests (id, student_id, course_id, kind, from_date, to_date, reason, status,
+			doc_title, doc_filename, doc_content, doc_mime_type, doc_uploaded_at, history, excused)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			l.ID, l.StudentID, l.CourseID, string(l.Kind), formatTime(l.From), formatTime(l.To), l.Reason, string(l.Status),
+			d.Title, d.Filename, d.Content, d.MimeType, formatTime(d.UploadedAt), jsonList(l.History), jsonList(l.Excused))
+	}
+	for _, cs := range s.Sessions {
+		exec(`INSERT INTO class_sessions (id, course_id, teacher_id, date, period, type) VALUES (?, ?, ?, ?, ?, ?)`,
+			cs.ID, cs.CourseID, cs.TeacherID, formatTime(cs.Date), cs.Period, string(cs.Type))
+	}
+	if gs := s.GradeScale; gs != nil {
+		exec(`INSERT INTO meta (key, value) VALUES ('grade_scale_name', ?)`, gs.Name)
+		for i, b := range gs.Bands {
+			exec(`INSERT INTO grade_scale (position, letter, points, pass, min_score) VALUES (?, ?, ?, ?, ?)`,
+				i, string(b.Letter), b.Points, b.Pass, b.MinScore)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Load reads the stored portal, returning internal.ErrNoSnapshot when
+// nothing has been saved yet.
+func (r *SQLRepository) Load() (*internal.Snapshot, error) {
+	// The tables are kept at the current shape by the SQL migrations, so what
+	// comes out is always a snapshot of the current schema version.
+	s := &internal.Snapshot{SchemaVersion: internal.SnapshotSchemaVersion}
+	var savedAt string
+	err := r.db.QueryRow(`SELECT value FROM meta WHERE key = 'saved_at'`).Scan(&savedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, internal.ErrNoSnapshot
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.SavedAt, err = parseTime(savedAt); err != nil {
+		return nil, err
+	}
+
+	steps := []func(*internal.Snapshot) error{
+		r.loadAcademic, r.loadEnrollNew, r.loadCompanies, r.loadApplicants, r.loadApplications, r.loadOffers, r.loadAccounts,
+		r.loadNotifications, r.loadReminders, r.loadGradeScale, r.loadTranscripts, r.loadAssessments, r.loadReEvaluations, r.loadAttendanceRules,
+		r.loadSessions, r.loadLeaves,
+	}
+	for _, step := range steps {
+		if err := step(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// query runs q and calls scan for every row.
+func (r *SQLRepository) query(q string, scan func(*sql.Rows) error, args ...any) error {
+	rows, err := r.db.Query(q, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *SQLRepository) loadAcademic(s *internal.Snapshot) error {
+	err := r.query(`SELECT id, name FROM students ORDER BY rowid`, func(rows *sql.Rows) error {
+		var st internal.StudentData
+		if err := rows.Scan(&st.ID, &st.Name); err != nil {
+			return err
+		}
+		s.Students = append(s.Students, st)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = r.query(`SELECT id, name FROM courses ORDER BY rowid`, func(rows *sql.Rows) error {
+		var c internal.CourseRecord
+		if err := rows.Scan(&c.ID, &c.Name); err != nil {
+			return err
+		}
+		s.Courses = append(s.Courses, c)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = r.query(`SELECT id, name FROM teachers ORDER BY rowid`, func(rows *sql.Rows) error {
+		var t internal.TeacherRecord
+		if err := rows.Scan(&t.ID, &t.Name); err != nil {
+			return err
+		}
+		s.Teachers = append(s.Teachers, t)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = r.query(`SELECT te.teacher_id, te.teacher_name, c.id, c.name, c.credits, c.semester
+		FROM teacher_enrollments te JOIN credit_courses c ON c.id = te.course_id ORDER BY te.position`, func(rows *sql.Rows) error {
+		var te internal.TeacherEnrollmentRecord
+		if err := rows.Scan(&te.Teacher.ID, &te.Teacher.Name, &te.Course.ID, &te.Course.Name, &te.Course.Credits, &te.Course.Semester); err != nil {
+			return err
+		}
+		s.TeacherEnrollments = append(s.TeacherEnrollments, te)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return r.query(`SELECT student_id, student_name, course_id, course_name, grader_kind, pass_mark, max_score, grader_cutoffs, score, component_marks, marked
+		FROM enrollments ORDER BY position`, func(rows *sql.Rows) error {
+		var e internal.EnrollmentRecord
+		var cutoffs, marks string
+		if err := rows.Scan(&e.Student.ID, &e.Student.Name, &e.Course.ID, &e.Course.Name, &e.Grader.Kind, &e.Grader.PassMark, &e.Grader.MaxScore, &cutoffs, &e.Score, &marks, &e.Marked); err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(cutoffs), &e.Grader.Cutoffs); err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(marks), &e.Marks); err != nil {
+			return err
+		}
+		s.Enrollments = append(s.Enrollments, e)
+		return nil
+	})
+}
+
+func (r *SQLRepository) loadEnrollNew(s *internal.Snapshot) error {
+	type row struct {
+		id   int64
+		kind string
+		rec  internal.EnrollNewRecord
+	}
+	var rows []*row
+	byID := map[int64]*row{}
+	err := r.query(`SELECT id, kind, student_id, student_name, course_id, course_name, teacher_id, teacher_name, grader_kind, pass_mark, max_score, grader_cutoffs, score, component_marks, marked
+		FROM enroll_new ORDER BY id`, func(rs *sql.Rows) error {
+		w := &row{}
+		e := &w.rec.Enrollment
+		var cutoffs, marks string
+		if err := rs.Scan(&w.id, &w.kind, &e.Student.ID, &e.Student.Name, &e.Course.ID, &e.Course.Name,
+			&w.rec.Teacher.ID, &w.rec.Teacher.Name, &e.Grader.Kind, &e.Grader.PassMark, &e.Grader.MaxScore, &cutoffs, &e.Score, &marks, &e.Marked); err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(cutoffs), &e.Grader.Cutoffs); err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(marks), &e.Marks); err != nil {
+			return err
+		}
+		rows = append(rows, w)
+		byID[w.id] = w
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = r.query(`SELECT enroll_id, date, present, excused FROM attendance ORDER BY enroll_id, date`, func(rs *sql.Rows) error {
+		var id int64
+		var date string
+		var a internal.AttendanceRecord
+		if err := rs.Scan(&id, &date, &a.Present, &a.Excused); err != nil {
+			return err
+		}
+		var err error
+		if a.Date, err = parseTime(date); err != nil {
+			return err
+		}
+		byID[id].rec.Attendance = append(byID[id].rec.Attendance, a)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = r.query(`SELECT enroll_id, session_id, status FROM session_attendance ORDER BY enroll_id, session_id`, func(rs *sql.Rows) error {
+		var id int64
+		var m internal.SessionMark
+		var status string
+		if err := rs.Scan(&id, &m.SessionID, &status); err != nil {
+			return err
+		}
+		m.Status = internal.AttendanceStatus(status)
+		byID[id].rec.Sessions = append(byID[id].rec.Sessions, m)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	docs := map[int64][]internal.DocumentRecord{}
+	err = r.query(`SELECT enroll_id, title, filename, content, mime_type, uploaded_at FROM documents ORDER BY id`, func(rs *sql.Rows) error {
+		var id int64
+		var uploaded string
+		var d internal.DocumentRecord
+		if err := rs.Scan(&id, &d.Title, &d.Filename, &d.Content, &d.MimeType, &uploaded); err != nil {
+			return err
+		}
+		var err error
+		if d.UploadedAt, err = parseTime(uploaded); err != nil {
+			return err
+		}
+		docs[id] = append(docs[id], d)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, w := range rows {
+		if w.kind == "documents" {
+			s.Documents = append(s.Documents, internal.DocumentsRecord{Enrollment: w.rec, Documents: docs[w.id]})
+		} else {
+			s.EnrollNew = append(s.EnrollNew, w.rec)
+		}
+	}
+	return nil
+}
+
+func scanDrive(rows interface{ Scan(...any) error }) (internal.DriveRecord, int, error) {
+	var d internal.DriveRecord
+	var companyID, category int
+	var start, end, announced, published, archived string
+	if err := rows.Scan(&d.ID, &companyID, &start, &end, &d.RoleName, &d.MinimumGPA, &d.CTC, &category, &announced, &published, &archived); err != nil {
+		return d, 0, err
+	}
+	d.JobCategory = internal.JobCategory(category)
+	var err error
+	if d.StartDate, err = parseTime(start); err != nil {
+		return d, 0, err
+	}
+	if d.EndDate, err = parseTime(end); err != nil {
+		return d, 0, err
+	}
+	if d.AnnouncedAt, err = parseTime(announced); err != nil {
+		return d, 0, err
+	}
+	if d.ResultsPublishedAt, err = parseTime(published); err != nil {
+		return d, 0, err
+	}
+	if d.ArchivedAt, err = parseTime(archived); err != nil {
+		return d, 0, err
+	}
+	return d, companyID, nil
+}
+
+const driveColumns = `id, company_id, start_date, end_date, role_name, minimum_gpa, ctc, job_category, announced_at, results_published_at, archived_at`
+
+func (r *SQLRepository) loadCompanies(s *internal.Snapshot) error {
+	index := map[int]int{}
+	err := r.query(`SELECT id, name FROM companies ORDER BY rowid`, func(rows *sql.Rows) error {
+		var c internal.CompanyRecord
+		if err := rows.Scan(&c.ID, &c.Name); err != nil {
+			return err
+		}
+		index[c.ID] = len(s.Companies)
+		s.Companies = append(s.Companies, c)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	driveApps := map[int][]int{}
+	err = r.query(`SELECT id, drive_id FROM applications ORDER BY id`, func(rows *sql.Rows) error {
+		var id, driveID int
+		if err := rows.Scan(&id, &driveID); err != nil {
+			return err
+		}
+		driveApps[driveID] = append(driveApps[driveID], id)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	driveRounds, err := r.driveRounds(`SELECT drive_id, name, kind, pass_score FROM drive_rounds ORDER BY drive_id, position`)
+	if err != nil {
+		return err
+	}
+	driveCriteria, err := r.driveCriteria(`SELECT drive_id, kind, value, numbers, names, expression FROM drive_criteria ORDER BY drive_id, position`)
+	if err != nil {
+		return err
+	}
+	return r.query(`SELECT `+driveColumns+` FROM drives ORDER BY rowid`, func(rows *sql.Rows) error {
+		d, companyID, err := scanDrive(rows)
+		if err != nil {
+			return err
+		}
+		d.Applications = driveApps[d.ID]
+		d.Rounds = driveRounds[d.ID]
+		d.Criteria = driveCriteria[d.ID]
+		c := &s.Companies[index[companyID]]
+		c.Drives = append(c.Drives, d)
+		return nil
+	})
+}
+
+// driveRounds runs q, which selects drive_id, name, kind and pass_score, and
+// groups the rounds by drive.
+func (r *SQLRepository) driveRounds(q string, args ...any) (map[int][]internal.Round, error) {
+	out := map[int][]internal.Round{}
+	err := r.query(q, func(rows *sql.Rows) error {
+		var driveID, kind int
+		var round internal.Round
+		if err := rows.Scan(&driveID, &round.Name, &kind, &round.PassScore); err != nil {
+			return err
+		}
+		round.Kind = internal.RoundKind(kind)
+		out[driveID] = append(out[driveID], round)
+		return nil
+	}, args...)
+	return out, err
+}
+
+// driveCriteria runs q, which selects drive_id, kind, value, numbers, names
+// and expression, and groups the criteria by drive.
+func (r *SQLRepository) driveCriteria(q string, args ...any) (map[int][]internal.CriterionRecord, error) {
+	out := map[int][]internal.CriterionRecord{}
+	err := r.query(q, func(rows *sql.Rows) error {
+		var driveID int
+		var c internal.CriterionRecord
+		var numbers, names string
+		if err := rows.Scan(&driveID, &c.Kind, &c.Value, &numbers, &names, &c.Expression); err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(numbers), &c.Numbers); err != nil {
+			return fmt.Errorf("criterion of drive %d: %w", driveID, err)
+		}
+		if err := json.Unmarshal([]byte(names), &c.Names); err != nil {
+			return fmt.Errorf("criterion of drive %d: %w", driveID, err)
+		}
+		out[driveID] = append(out[driveID], c)
+		return nil
+	}, args...)
+	return out, err
+}
+
+// jsonList encodes a list column as JSON. Empty lists are stored as null so
+// they load back as nil, matching the snapshot.
+func jsonList[T any](values []T) string {
+	if len(values) == 0 {
+		return "null"
+	}
+	data, _ := json.Marshal(values)
+	return string(data)
+}
+
+func (r *SQLRepository) loadApplicants(s *internal.Snapshot) error {
+	index := map[int]int{}
+	err := r.query(`SELECT student_id, name, cgpa, status, department, graduation_year FROM applicants ORDER BY rowid`, func(rows *sql.Rows) error {
+		var a internal.ApplicantRecord
+		var cgpa float64
+		var status string
+		if err := rows.Scan(&a.Student.ID, &a.Student.Name, &cgpa, &status, &a.Department, &a.GraduationYear); err != nil {
+			return err
+		}
+		a.AcademicRecord = *internal.NewAcademicRecord(a.Student.ID)
+		a.AcademicRecord.CGPA, a.AcademicRecord.Status = cgpa, status
+		index[a.Student.ID] = len(s.Applicants)
+		s.Applicants = append(s.Applicants, a)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = r.query(`SELECT student_id, semester, course_id, course_name, grade, credits FROM course_results`, func(rows *sql.Rows) error {
+		var studentID, semester, courseID int
+		var name, gradeStr string
+		var credits float64
+		if err := rows.Scan(&studentID, &semester, &courseID, &name, &gradeStr, &credits); err != nil {
+			return err
+		}
+		ar := &s.Applicants[index[studentID]].AcademicRecord
+		cgpa := ar.CGPA
+		// Grades are checked against the snapshot's grade scale on restore.
+		ar.AddResult(internal.NewCourseResult(studentID, courseID, name, internal.AlphabeticGrade(gradeStr), semester, credits), semester)
+		ar.CGPA = cgpa // keep the stored value rather than the recomputed one
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return r.query(`SELECT student_id, kind, drive_id FROM applicant_drives ORDER BY student_id, kind, position`, func(rows *sql.Rows) error {
+		var studentID, driveID int
+		var kind string
+		if err := rows.Scan(&studentID, &kind, &driveID); err != nil {
+			return err
+		}
+		a := &s.Applicants[index[studentID]]
+		if kind == "offer" {
+			a.OffersReceived = append(a.OffersReceived, driveID)
+		} else {
+			a.DrivesAppliedFor = append(a.DrivesAppliedFor, driveID)
+		}
+		return nil
+	})
+}
+
+func (r *SQLRepository) loadApplications(s *internal.Snapshot) error {
+	err := r.query(`SELECT id, drive_id, student_id, status FROM applications ORDER BY id`, func(rows *sql.Rows) error {
+		var app internal.ApplicationRecord
+		var status int
+		if err := rows.Scan(&app.ID, &app.DriveID, &app.StudentID, &status); err != nil {
+			return err
+		}
+		app.Status = internal.ApplicationStatus(status)
+		s.Applications = append(s.Applications, app)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	index := make(map[int]int, len(s.Applications))
+	for i, app := range s.Applications {
+		index[app.ID] = i
+	}
+	err = r.query(`SELECT application_id, from_status, to_status, at, actor FROM application_history ORDER BY application_id, position`, func(rows *sql.Rows) error {
+		var appID, from, to int
+		var at string
+		var h internal.StatusChange
+		if err := rows.Scan(&appID, &from, &to, &at, &h.Actor); err != nil {
+			return err
+		}
+		i, ok := index[appID]
+		if !ok {
+			return fmt.Errorf("history references unknown application %d", appID)
+		}
+		var err error
+		if h.At, err = parseTime(at); err != nil {
+			return err
+		}
+		h.From, h.To = internal.ApplicationStatus(from), internal.ApplicationStatus(to)
+		s.Applications[i].History = append(s.Applications[i].History, h)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return r.query(`SELECT application_id, round, score, passed, recorded_at, actor FROM application_rounds ORDER BY application_id, round`, func(rows *sql.Rows) error {
+		var appID int
+		var recordedAt string
+		var res internal.RoundResult
+		if err := rows.Scan(&appID, &res.Round, &res.Score, &res.Passed, &recordedAt, &res.Actor); err != nil {
+			return err
+		}
+		i, ok := index[appID]
+		if !ok {
+			return fmt.Errorf("round result references unknown application %d", appID)
+		}
+		var err error
+		if res.RecordedAt, err = parseTime(recordedAt); err != nil {
+			return err
+		}
+		s.Applications[i].RoundResults = append(s.Applications[i].RoundResults, res)
+		return nil
+	})
+}
+
+func (r *SQLRepository) loadOffers(s *internal.Snapshot) error {
+	return r.query(`SELECT id, application_id, student_id, drive_id, status, issued_at, respond_by, responded_at FROM offers ORDER BY id`, func(rows *sql.Rows) error {
+		var o internal.OfferRecord
+		var status int
+		var issuedAt, respondBy, respondedAt string
+		if err := rows.Scan(&o.ID, &o.ApplicationID, &o.StudentID, &o.DriveID, &status, &issuedAt, &respondBy, &respondedAt); err != nil {
+			return err
+		}
+		o.Status = internal.OfferStatus(status)
+		var err error
+		if o.IssuedAt, err = parseTime(issuedAt); err != nil {
+			return err
+		}
+		if o.RespondBy, err = parseTime(respondBy); err != nil {
+			return err
+		}
+		if o.RespondedAt, err = parseTime(respondedAt); err != nil {
+			return err
+		}
+		s.Offers = append(s.Offers, o)
+		return nil
+	})
+}
+
+func (r *SQLRepository) loadAccounts(s *internal.Snapshot) error {
+	return r.query(`SELECT username, role, student_id, teacher_id, password_hash FROM accounts ORDER BY username`, func(rows *sql.Rows) error {
+		var a internal.AccountRecord
+		if err := rows.Scan(&a.Username, &a.Role, &a.StudentID, &a.TeacherID, &a.PasswordHash); err != nil {
+			return err
+		}
+		s.Accounts = append(s.Accounts, a)
+		return nil
+	})
+}
+
+func (r *SQLRepository) loadNotifications(s *internal.Snapshot) error {
+	err := r.query(`SELECT student_id, channels, kinds, email, webhook_url FROM notification_subscriptions ORDER BY student_id`, func(rows *sql.Rows) error {
+		var sub internal.Subscription
+		var channels, kinds string
+		if err := rows.Scan(&sub.StudentID, &channels, &kinds, &sub.Email, &sub.WebhookURL); err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(channels), &sub.Channels); err != nil {
+			return fmt.Errorf("subscription of student %d: %w", sub.StudentID, err)
+		}
+		if err := json.Unmarshal([]byte(kinds), &sub.Kinds); err != nil {
+			return fmt.Errorf("subscription of student %d: %w", sub.StudentID, err)
+		}
+		s.Subscriptions = append(s.Subscriptions, sub)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return r.query(`SELECT id, student_id, kind, drive_id, subject, body, created_at, read_at FROM notifications ORDER BY id`, func(rows *sql.Rows) error {
+		var m internal.Message
+		var createdAt, readAt string
+		if err := rows.Scan(&m.ID, &m.StudentID, &m.Kind, &m.DriveID, &m.Subject, &m.Body, &createdAt, &readAt); err != nil {
+			return err
+		}
+		var err error
+		if m.CreatedAt, err = parseTime(createdAt); err != nil {
+			return err
+		}
+		if m.ReadAt, err = parseTime(readAt); err != nil {
+			return err
+		}
+		s.Notifications = append(s.Notifications, m)
+		return nil
+	})
+}
+
+func (r *SQLRepository) loadReminders(s *internal.Snapshot) error {
+	return r.query(`SELECT key, student_id, drive_id, offer_id, deadline, sent_at FROM reminders ORDER BY key`, func(rows *sql.Rows) error {
+		var rm internal.Reminder
+		var deadline, sentAt string
+		if err := rows.Scan(&rm.Key, &rm.StudentID, &rm.DriveID, &rm.OfferID, &deadline, &sentAt); err != nil {
+			return err
+		}
+		var err error
+		if rm.Deadline, err = parseTime(deadline); err != nil {
+			return err
+		}
+		if rm.SentAt, err = parseTime(sentAt); err != nil {
+			return err
+		}
+		s.Reminders = append(s.Reminders, rm)
+		return nil
+	})
+}
+
+// loadGradeScale leaves s.GradeScale nil when the default scale is in use.
+func (r *SQLRepository) loadGradeScale(s *internal.Snapshot) error {
+	var bands []internal.GradeBand
+	err := r.query(`SELECT letter, points, pass, min_score FROM grade_scale ORDER BY position`, func(rows *sql.Rows) error {
+		var b internal.GradeBand
+		var letter string
+		if err := rows.Scan(&letter, &b.Points, &b.Pass, &b.MinScore); err != nil {
+			return err
+		}
+		b.Letter = internal.AlphabeticGrade(letter)
+		bands = append(bands, b)
+		return nil
+	})
+	if err != nil || len(bands) == 0 {
+		return err
+	}
+	s.GradeScale = &internal.GradeScale{Bands: bands}
+	err = r.db.QueryRow(`SELECT value FROM meta WHERE key = 'grade_scale_name'`).Scan(&s.GradeScale.Name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return err
+}
+
+func (r *SQLRepository) loadTranscripts(s *internal.Snapshot) error {
+	return r.query(`SELECT student_id, course_id, course_name, grade, semester, credits FROM transcripts ORDER BY position`, func(rows *sql.Rows) error {
+		var cr internal.CourseResult
+		var grade string
+		if err := rows.Scan(&cr.StudentId, &cr.CourseId, &cr.CourseName, &grade, &cr.Semester, &cr.Credits); err != nil {
+			return err
+		}
+		cr.Grade = internal.AlphabeticGrade(grade)
+		s.Transcripts = append(s.Transcripts, cr)
+		return nil
+	})
+}
+
+func (r *SQLRepository) loadAssessments(s *internal.Snapshot) error {
+	byCourse := map[int]*internal.Assessment{}
+	err := r.query(`SELECT course_id, missing FROM assessments ORDER BY course_id`, func(rows *sql.Rows) error {
+		a := &internal.Assessment{}
+		var missing string
+		if err := rows.Scan(&a.CourseID, &missing); err != nil {
+			return err
+		}
+		a.Missing = internal.MissingMarks(missing)
+		byCourse[a.CourseID] = a
+		s.Assessments = append(s.Assessments, a)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return r.query(`SELECT course_id, name, weight, max_marks, count, best_of FROM assessment_components ORDER BY course_id, position`, func(rows *sql.Rows) error {
+		var courseID int
+		var c internal.AssessmentComponent
+		if err := rows.Scan(&courseID, &c.Name, &c.Weight, &c.MaxMarks, &c.Count, &c.BestOf); err != nil {
+			return err
+		}
+		a, ok := byCourse[courseID]
+		if !ok {
+			return fmt.Errorf("assessment component %q of unknown course %d", c.Name, courseID)
+		}
+		a.Components = append(a.Components, c)
+		return nil
+	})
+}
+
+func (r *SQLRepository) loadReEvaluations(s *internal.Snapshot) error {
+	err := r.query(`SELECT id, student_id, course_id, teacher_id, reason, status, score, grade, filed_at, reviewed_by, reviewed_at, note
+		FROM re_evaluations ORDER BY id`, func(rows *sql.Rows) error {
+		var re internal.ReEvaluation
+		var status, filed, reviewed string
+		if err := rows.Scan(&re.ID, &re.StudentID, &re.CourseID, &re.TeacherID, &re.Reason, &status, &re.Score, &re.Grade,
+			&filed, &re.ReviewedBy, &reviewed, &re.Note); err != nil {
+			return err
+		}
+		re.Status = internal.ReEvaluationStatus(status)
+		var err error
+		if re.FiledAt, err = parseTime(filed); err != nil {
+			return err
+		}
+		if re.ReviewedAt, err = parseTime(reviewed); err != nil {
+			return err
+		}
+		s.ReEvaluations = append(s.ReEvaluations, re)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return r.query(`SELECT student_id, course_id, re_evaluation_id, old_score, old_grade, new_score, new_grade, at, actor
+		FROM grade_history ORDER BY position`, func(rows *sql.Rows) error {
+		var c internal.GradeChange
+		var at string
+		if err := rows.Scan(&c.StudentID, &c.CourseID, &c.ReEvaluationID, &c.OldScore, &c.OldGrade, &c.NewScore, &c.NewGrade, &at, &c.Actor); err != nil {
+			return err
+		}
+		var err error
+		c.At, err = parseTime(at)
+		s.GradeHistory = append(s.GradeHistory, c)
+		return err
+	})
+}
+
+func (r *SQLRepository) loadAttendanceRules(s *internal.Snapshot) error {
+	err := r.query(`SELECT course_id, percent FROM attendance_minimums ORDER BY course_id`, func(rows *sql.Rows) error {
+		var m internal.AttendanceMinimum
+		if err := rows.Scan(&m.CourseID, &m.Percent); err != nil {
+			return err
+		}
+		s.AttendanceMinimums = append(s.AttendanceMinimums, m)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return r.query(`SELECT student_id, course_id, reason, condoned_by, condoned_at FROM condonations ORDER BY position`, func(rows *sql.Rows) error {
+		var c internal.Condonation
+		var at string
+		if err := rows.Scan(&c.StudentID, &c.CourseID, &c.Reason, &c.By, &at); err != nil {
+			return err
+		}
+		var err error
+		c.At, err = parseTime(at)
+		s.Condonations = append(s.Condonations, c)
+		return err
+	})
+}
+
+func (r *SQLRepository) loadSessions(s *internal.Snapshot) error {
+	return r.query(`SELECT id, course_id, teacher_id, date, period, type FROM class_sessions ORDER BY id`, func(rows *sql.Rows) error {
+		var cs internal.ClassSession
+		var date, kind string
+		if err := rows.Scan(&cs.ID, &cs.CourseID, &cs.TeacherID, &date, &cs.Period, &kind); err != nil {
+			return err
+		}
+		cs.Type = internal.SessionType(kind)
+		var err error
+		cs.Date, err = parseTime(date)
+		s.Sessions = append(s.Sessions, cs)
+		return err
+	})
+}
+
+func (r *SQLRepository) loadLeaves(s *internal.Snapshot) error {
+	return r.query(`SELECT id, student_id, course_id, kind, from_date, to_date, reason, status,
+		doc_title, doc_filename, doc_content, doc_mime_type, doc_uploaded_at, history, excused
+		FROM leave_requests ORDER BY id`, func(rows *sql.Rows) error {
+		var l internal.LeaveRequest
+		d := &l.Document
+		var kind, from, to, status, uploaded, history, excused string
+		if err := rows.Scan(&l.ID, &l.StudentID, &l.CourseID, &kind, &from, &to, &l.Reason, &status,
+			&d.Title, &d.Filename, &d.Content, &d.MimeType, &uploaded, &history, &excused); err != nil {
+			return err
+		}
+		l.Kind, l.Status = internal.LeaveKind(kind), internal.LeaveStatus(status)
+		var err error
+		if l.From, err = parseTime(from); err != nil {
+			return err
+		}
+		if l.To, err = parseTime(to); err != nil {
+			return err
+		}
+		if d.UploadedAt, err = parseTime(uploaded); err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(history), &l.History); err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(excused), &l.Excused); err != nil {
+			return err
+		}
+		s.Leaves = append(s.Leaves, l)
+		return nil
+	})
+}
+
+// StudentByID looks a student up through the primary key index.
+func (r *SQLRepository) StudentByID(id int) (internal.Student, error) {
+	var name string
+	err := r.db.QueryRow(`SELECT name FROM students WHERE id = ?`, id).Scan(&name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return internal.Student{}, fmt.Errorf("student with id %d not found", id)
+	}
+	if err != nil {
+		return internal.Student{}, err
+	}
+	return internal.NewStudent(id, name), nil
+}
+
+// StudentsByName returns every student with exactly the given name.
+func (r *SQLRepository) StudentsByName(name string) ([]internal.Student, error) {
+	var students []internal.Student
+	err := r.query(`SELECT id, name FROM students WHERE name = ? ORDER BY id`, func(rows *sql.Rows) error {
+		var id int
+		var n string
+		if err := rows.Scan(&id, &n); err != nil {
+			return err
+		}
+		students = append(students, internal.NewStudent(id, n))
+		return nil
+	}, name)
+	return students, err
+}
+
+// DriveByID returns the stored drive belonging to the given company.
+func (r *SQLRepository) DriveByID(companyID, driveID int) (internal.DriveRecord, error) {
+	row := r.db.QueryRow(`SELECT `+driveColumns+` FROM drives WHERE id = ? AND company_id = ?`, driveID, companyID)
+	d, _, err := scanDrive(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return d, fmt.Errorf("drive by id  %d and company with id %d not found", driveID, companyID)
+	}
+	if err != nil {
+		return d, err
+	}
+	err = r.query(`SELECT id FROM applications WHERE drive_id = ? ORDER BY id`, func(rows *sql.Rows) error {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		d.Applications = append(d.Applications, id)
+		return nil
+	}, driveID)
+	if err != nil {
+		return d, err
+	}
+	rounds, err := r.driveRounds(`SELECT drive_id, name, kind, pass_score FROM drive_rounds WHERE drive_id = ? ORDER BY position`, driveID)
+	if err != nil {
+		return d, err
+	}
+	d.Rounds = rounds[driveID]
+	criteria, err := r.driveCriteria(`SELECT drive_id, kind, value, numbers, names, expression FROM drive_criteria WHERE drive_id = ? ORDER BY position`, driveID)
+	d.Criteria = criteria[driveID]
+	return d, err
+}
+
+// ApplicationsByStudent returns every application filed by the student.
+func (r *SQLRepository) ApplicationsByStudent(studentID int) ([]internal.ApplicationRecord, error) {
+	var apps []internal.ApplicationRecord
+	err := r.query(`SELECT id, drive_id, student_id, status FROM applications WHERE student_id = ? ORDER BY id`, func(rows *sql.Rows) error {
+		var app internal.ApplicationRecord
+		var status int
+		if err := rows.Scan(&app.ID, &app.DriveID, &app.StudentID, &status); err != nil {
+			return err
+		}
+		app.Status = internal.ApplicationStatus(status)
+		apps = append(apps, app)
+		return nil
+	}, studentID)
+	return apps, err
+}
@@ -0,0 +1,222 @@
+package infrastructure
+
+import (
+	"errors"
+	"oops/main/internal"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func sampleSnapshot() *internal.Snapshot {
+	day := time.Date(2025, time.July, 1, 9, 0, 0, 0, time.UTC)
+	record := internal.NewAcademicRecord(1)
+	record.AddResult(internal.NewCourseResult(1, 101, "Math", internal.Aplus, 1, 4), 1)
+	record.Status = "Normal"
+
+	enrollment := internal.EnrollmentRecord{
+		Student: internal.StudentData{ID: 1, Name: "Alice"},
+		Course:  internal.CourseRecord{ID: 101, Name: "Math"},
+		Grader:  internal.GraderRecord{Kind: "pass_fail", PassMark: 5},
+		Score:   7,
+		Marked:  true,
+	}
+	withTeacher := internal.EnrollNewRecord{
+		Enrollment: enrollment,
+		Teacher:    internal.TeacherRecord{ID: "T1", Name: "Prof. Smith"},
+		Attendance: []internal.AttendanceRecord{{Date: day, Present: true}, {Date: day.AddDate(0, 0, 1), Excused: true}},
+		Sessions:   []internal.SessionMark{{SessionID: 1, Status: internal.StatusLate}, {SessionID: 2, Status: internal.StatusExcused}},
+	}
+	withTeacher.Enrollment.Grader = internal.GraderRecord{Kind: "scale", MaxScore: 10}
+	withTeacher.Enrollment.Marks = []internal.ComponentMark{{Component: "quiz", Attempt: 2, Marks: 8}, {Component: "final", Attempt: 1, Marks: 6.5}}
+	curved := withTeacher
+	curved.Enrollment.Grader = internal.GraderRecord{Kind: "curve", Cutoffs: []internal.CurveCutoff{
+		{Letter: internal.O, MinScore: 8.5}, {Letter: internal.B, MinScore: 4}, {Letter: internal.F},
+	}}
+
+	return &internal.Snapshot{
+		SchemaVersion: internal.SnapshotSchemaVersion,
+		SavedAt:       day,
+		Students:      []internal.StudentData{{ID: 1, Name: "Alice"}, {ID: 2, Name: "Bob"}},
+		Courses:       []internal.CourseRecord{{ID: 101, Name: "Math"}},
+		Enrollments:   []internal.EnrollmentRecord{enrollment},
+		Teachers:      []internal.TeacherRecord{{ID: "T1", Name: "Prof. Smith"}},
+		TeacherEnrollments: []internal.TeacherEnrollmentRecord{
+			{Teacher: internal.TeacherRecord{ID: "T1", Name: "Prof. Smith"}, Course: internal.CourseRecord{ID: 101, Name: "Math", Credits: 4, Semester: 1}},
+		},
+		EnrollNew: []internal.EnrollNewRecord{withTeacher},
+		Documents: []internal.DocumentsRecord{{
+			Enrollment: curved,
+			Documents:  []internal.DocumentRecord{{Title: "A1", Filename: "a1.pdf", Content: []byte("pdf"), MimeType: "application/pdf", UploadedAt: day}},
+		}},
+		Companies: []internal.CompanyRecord{{ID: 10, Name: "Acme", Drives: []internal.DriveRecord{{
+			ID: 20, StartDate: day, EndDate: day.AddDate(0, 0, 14), RoleName: "Engineer",
+			MinimumGPA: 6, CTC: 1200000, JobCategory: internal.Dream, Applications: []int{1},
+			Rounds: []internal.Round{{Name: "Aptitude", Kind: internal.AptitudeTest, PassScore: 60}, {Name: "HR", Kind: internal.HRInterview, PassScore: 5}},
+			Criteria: []internal.CriterionRecord{
+				{Kind: "max_backlogs", Value: 0},
+				{Kind: "graduation_years", Numbers: []int{2026}},
+				{Kind: "departments", Names: []string{"CSE", "ECE"}},
+				{Kind: "rule", Expression: "cgpa >= 7.5 && attendance >= 75"},
+			},
+			ResultsPublishedAt: day.AddDate(0, 0, 20),
+			AnnouncedAt:        day,
+		}}}},
+		Applicants: []internal.ApplicantRecord{{
+			Student:          internal.StudentData{ID: 1, Name: "Alice"},
+			AcademicRecord:   *record,
+			DrivesAppliedFor: []int{20},
+			Department:       "CSE",
+			GraduationYear:   2026,
+		}},
+		Applications: []internal.ApplicationRecord{{ID: 1, DriveID: 20, StudentID: 1, Status: internal.ShortListed, History: []internal.StatusChange{
+			{From: internal.Applied, To: internal.ShortListed, At: day.AddDate(0, 0, 2), Actor: "officer"},
+		}, RoundResults: []internal.RoundResult{
+			{Round: 1, Score: 72, Passed: true, RecordedAt: day.AddDate(0, 0, 2), Actor: "officer"},
+		}}},
+		Offers: []internal.OfferRecord{{
+			ID: 1, ApplicationID: 1, StudentID: 1, DriveID: 20, Status: internal.OfferAccepted,
+			IssuedAt: day.AddDate(0, 0, 5), RespondBy: day.AddDate(0, 0, 12), RespondedAt: day.AddDate(0, 0, 6),
+		}},
+		Accounts: []internal.AccountRecord{
+			{Username: "alice", Role: "student", StudentID: 1, PasswordHash: "pbkdf2-sha256$1$c2FsdA$a2V5"},
+			{Username: "smith", Role: "teacher", TeacherID: "T1", PasswordHash: "pbkdf2-sha256$1$c2FsdA$a2V5"},
+		},
+		Subscriptions: []internal.Subscription{
+			{StudentID: 1, Channels: []string{internal.ChannelInbox, internal.ChannelEmail}, Kinds: []internal.NotificationKind{internal.NotifyApplicationStatus}, Email: "alice@college.test"},
+			{StudentID: 2, Channels: []string{internal.ChannelWebhook}, WebhookURL: "https://example.test/hook"},
+		},
+		Notifications: []internal.Message{
+			{ID: 1, StudentID: 1, Kind: internal.NotifyDriveOpened, DriveID: 20, Subject: "New drive open: Engineer (Dream)", Body: "Acme is hiring.", CreatedAt: day, ReadAt: day.Add(time.Hour)},
+			{ID: 2, StudentID: 1, Kind: internal.NotifyMarksUploaded, Subject: "Marks uploaded for Math", Body: "You scored 91.", CreatedAt: day.AddDate(0, 0, 1)},
+		},
+		Reminders: []internal.Reminder{
+			{Key: "drive:20:student:2:168h0m0s", StudentID: 2, DriveID: 20, Deadline: day.AddDate(0, 0, 15), SentAt: day.AddDate(0, 0, 8)},
+			{Key: "offer:1:24h0m0s", StudentID: 1, DriveID: 20, OfferID: 1, Deadline: day.AddDate(0, 0, 12), SentAt: day.AddDate(0, 0, 11)},
+		},
+		GradeScale: &internal.GradeScale{Name: "autonomous", Bands: []internal.GradeBand{
+			{Letter: internal.O, Points: 10, Pass: true, MinScore: 85},
+			{Letter: internal.Aplus, Points: 9, Pass: true, MinScore: 70},
+			{Letter: internal.B, Points: 6, Pass: true, MinScore: 45},
+			{Letter: internal.F, Points: 0, MinScore: 0},
+		}},
+		Transcripts: []internal.CourseResult{internal.NewCourseResult(1, 101, "Math", internal.Aplus, 1, 4)},
+		Assessments: []*internal.Assessment{{CourseID: 101, Missing: internal.MissingProrate, Components: []internal.AssessmentComponent{
+			{Name: "quiz", Weight: 30, MaxMarks: 10, Count: 3, BestOf: 2},
+			{Name: "final", Weight: 70, MaxMarks: 10},
+		}}},
+		ReEvaluations: []internal.ReEvaluation{
+			{ID: 1, StudentID: 1, CourseID: 101, TeacherID: "T1", Reason: "question 3 was not marked", Status: internal.ReEvaluationRevised,
+				Score: 6, Grade: "B", FiledAt: day.AddDate(0, 0, 2), ReviewedBy: "T1", ReviewedAt: day.AddDate(0, 0, 3), Note: "added question 3"},
+			{ID: 2, StudentID: 2, CourseID: 101, TeacherID: "T1", Reason: "totalling", Status: internal.ReEvaluationPending, Score: 4, Grade: "F", FiledAt: day.AddDate(0, 0, 4)},
+		},
+		GradeHistory:       []internal.GradeChange{{StudentID: 1, CourseID: 101, ReEvaluationID: 1, OldScore: 6, OldGrade: "B", NewScore: 7, NewGrade: "A+", At: day.AddDate(0, 0, 3), Actor: "T1"}},
+		AttendanceMinimums: []internal.AttendanceMinimum{{Percent: 70}, {CourseID: 101, Percent: 80}},
+		Condonations:       []internal.Condonation{{StudentID: 1, CourseID: 101, Reason: "hospitalised", By: "admin", At: day.AddDate(0, 0, 5)}},
+		Sessions: []internal.ClassSession{
+			{ID: 1, CourseID: 101, TeacherID: "T1", Date: day, Period: 1, Type: internal.SessionLecture},
+			{ID: 2, CourseID: 101, TeacherID: "T1", Date: day, Period: 3, Type: internal.SessionLab},
+		},
+		Leaves: []internal.LeaveRequest{
+			{ID: 1, StudentID: 1, Kind: internal.LeaveMedical, From: day.AddDate(0, 0, 1), To: day.AddDate(0, 0, 2), Reason: "fever",
+				Document: internal.Document{Title: "Certificate", Filename: "cert.pdf", Content: []byte("pdf"), MimeType: "application/pdf", UploadedAt: day},
+				Status:   internal.LeaveApproved,
+				History: []internal.LeaveChange{
+					{Status: internal.LeavePending, At: day, Actor: "alice"},
+					{Status: internal.LeaveApproved, At: day.AddDate(0, 0, 3), Actor: "hod", Note: "get well soon"},
+				},
+				Excused: []internal.ExcusedAbsence{{CourseID: 101, Date: day.AddDate(0, 0, 1)}},
+			},
+			{ID: 2, StudentID: 2, CourseID: 101, Kind: internal.LeaveEvent, From: day, To: day, Reason: "hackathon",
+				Document: internal.Document{Title: "Invite", Filename: "invite.png", Content: []byte("png"), MimeType: "image/png", UploadedAt: day},
+				Status:   internal.LeavePending, History: []internal.LeaveChange{{Status: internal.LeavePending, At: day, Actor: "bob"}},
+			},
+		},
+	}
+}
+
+func openTestSQLRepository(t *testing.T) *SQLRepository {
+	t.Helper()
+	repo, err := OpenSQLRepository(filepath.Join(t.TempDir(), "portal.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLRepository failed: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func TestSQLRepository_SaveAndLoad(t *testing.T) {
+	repo := openTestSQLRepository(t)
+	if _, err := repo.Load(); !errors.Is(err, internal.ErrNoSnapshot) {
+		t.Fatalf("expected ErrNoSnapshot on empty database, got %v", err)
+	}
+
+	want := sampleSnapshot()
+	if err := repo.Save(want); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	// Saving twice must replace rather than duplicate rows.
+	if err := repo.Save(want); err != nil {
+		t.Fatalf("second Save failed: %v", err)
+	}
+	got, err := repo.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded snapshot differs\n got: %+v\nwant: %+v", got, want)
+	}
+	if _, err := internal.RestorePortal(got); err != nil {
+		t.Errorf("RestorePortal of loaded snapshot failed: %v", err)
+	}
+}
+
+func TestSQLRepository_IndexedLookups(t *testing.T) {
+	repo := openTestSQLRepository(t)
+	if err := repo.Save(sampleSnapshot()); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	st, err := repo.StudentByID(2)
+	if err != nil || st.Name() != "Bob" {
+		t.Errorf("StudentByID(2) = %v, %v", st, err)
+	}
+	if _, err := repo.StudentByID(99); err == nil {
+		t.Error("expected error for missing student")
+	}
+
+	d, err := repo.DriveByID(10, 20)
+	if err != nil || d.RoleName != "Engineer" || !reflect.DeepEqual(d.Applications, []int{1}) {
+		t.Errorf("DriveByID(10, 20) = %+v, %v", d, err)
+	}
+	if _, err := repo.DriveByID(11, 20); err == nil {
+		t.Error("expected error for drive under the wrong company")
+	}
+
+	apps, err := repo.ApplicationsByStudent(1)
+	if err != nil || len(apps) != 1 || apps[0].Status != internal.ShortListed {
+		t.Errorf("ApplicationsByStudent(1) = %v, %v", apps, err)
+	}
+}
+
+func TestSQLRepository_MigrationsAreIdempotent(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := migrations[len(migrations)-1].version
+
+	path := filepath.Join(t.TempDir(), "portal.db")
+	for i := 0; i < 2; i++ {
+		repo, err := OpenSQLRepository(path)
+		if err != nil {
+			t.Fatalf("open #%d failed: %v", i+1, err)
+		}
+		v, err := repo.SchemaVersion()
+		if err != nil || v != latest {
+			t.Errorf("SchemaVersion() = %d, %v; want %d", v, err, latest)
+		}
+		repo.Close()
+	}
+}
@@ -2,12 +2,20 @@ package internal
 
 import (
 	"fmt"
+	"time"
 )
 
 type PlacementRegistrar struct {
 	companies    []*Company
 	applications []*Application
 	applicants   []*Applicant
+	offers       []*Offer
+	offerPolicy  OfferPolicy
+	offerWindow  time.Duration
+	attendance   func(studentID int) (float64, bool)
+	now          func() time.Time
+	notify       func(studentID int, n Notice)
+	gradeScale   *GradeScale // nil is the DefaultGradeScale
 }
 
 type ReportByStudent struct {
@@ -31,6 +39,9 @@ type FullPlacementReport struct {
 	totalOffersMade       int
 	allOffersMade         []*Application
 	totalOffersByCatagory map[JobCategory]int
+	studentsPlaced        int
+	averageCTC            float64
+	highestCTC            int
 }
 
 func (pr PlacementRegistrar) GenerateReportByDrive() ReportByDrive {
@@ -52,7 +63,7 @@ func (pr PlacementRegistrar) GenerateReportByStudent() []ReportByStudent {
 		report := ReportByStudent{}
 		report.applicant = e
 		for _, d := range pr.AllDrives() {
-			if d.eligibility.checkEligibility(e) {
+			if len(d.eligibility.Evaluate(pr.ProfileOf(e))) == 0 {
 				report.eligibileRoles = append(report.eligibileRoles, d)
 			}
 		}
@@ -61,21 +72,12 @@ func (pr PlacementRegistrar) GenerateReportByStudent() []ReportByStudent {
 				report.offersRecived = append(report.offersRecived, a)
 			}
 		}
-		if len(report.offersRecived) > 0 {
-			firstOfferDriveID := report.offersRecived[0].driveId
-			for _, d := range pr.AllDrives() {
-				if d.ID() == firstOfferDriveID {
-					report.finalOffer = d
-					report.ctcForFinalOffer = d.CTC()
-					break
-				}
-			}
-		} else {
-			report.finalOffer = nil
-			report.ctcForFinalOffer = 0
+		if offer, err := pr.FinalOffer(e.ID()); err == nil {
+			report.finalOffer = pr.driveByID(offer.driveID)
+		}
+		if report.finalOffer != nil {
+			report.ctcForFinalOffer = report.finalOffer.CTC()
 		}
-		// report.finalOffer = e.DrivesAppliedFor()[0]
-		// report.ctcForFinalOffer = report.finalOffer.CTC()
 		ReportsByStudent = append(ReportsByStudent, report)
 	}
 	return ReportsByStudent
@@ -106,6 +108,23 @@ func (pr PlacementRegistrar) GenerateFullReport() FullPlacementReport {
 		}
 	}
 	report.totalOffersByCatagory = allOffersBycatagory
+
+	// CTC statistics only count the offer each student accepted.
+	totalCTC := 0
+	for _, a := range pr.applicants {
+		offer, err := pr.FinalOffer(a.ID())
+		if err != nil {
+			continue
+		}
+		if d := pr.driveByID(offer.driveID); d != nil {
+			report.studentsPlaced++
+			totalCTC += d.CTC()
+			report.highestCTC = max(report.highestCTC, d.CTC())
+		}
+	}
+	if report.studentsPlaced > 0 {
+		report.averageCTC = float64(totalCTC) / float64(report.studentsPlaced)
+	}
 	return report
 }
 
@@ -117,6 +136,33 @@ func (pr PlacementRegistrar) AllDrives() []*Drive {
 	return alldrives
 }
 
+func (pr PlacementRegistrar) Companies() []*Company {
+	return pr.companies
+}
+
+func (pr PlacementRegistrar) Applicants() []*Applicant {
+	return pr.applicants
+}
+
+func (pr PlacementRegistrar) Applications() []*Application {
+	return pr.applications
+}
+
+// AddApplicant registers a student for placements. A student can only be registered once.
+func (pr *PlacementRegistrar) AddApplicant(applicant *Applicant) error {
+	if _, err := pr.ApplicantByID(applicant.ID()); err == nil {
+		return conflictf("applicant with id %d already registered", applicant.ID())
+	}
+	if err := pr.GradeScale().CheckResults(applicant.Results()...); err != nil {
+		return err
+	}
+	if pr.gradeScale != nil {
+		pr.applyGradeScale(applicant)
+	}
+	pr.applicants = append(pr.applicants, applicant)
+	return nil
+}
+
 func (pr *PlacementRegistrar) AddCompany(company *Company) { // AddCompany will help us add new company to PlacementRegistrar
 	pr.companies = append(pr.companies, company)
 }
@@ -127,7 +173,7 @@ func (pr *PlacementRegistrar) GetCompanyByID(id int) (*Company, error) {
 			return pr.companies[i], nil
 		}
 	}
-	return nil, fmt.Errorf("company with id %d is not found", id)
+	return nil, notFoundf("company with id %d is not found", id)
 }
 
 func (pr *PlacementRegistrar) UpdateCompany(UpdatedCompany *Company) error {
@@ -142,18 +188,21 @@ func (pr *PlacementRegistrar) UpdateCompany(UpdatedCompany *Company) error {
 		}
 	}
 
-	return fmt.Errorf("company with id %d not found to update", UpdatedCompany.id)
+	return notFoundf("company with id %d not found to update", UpdatedCompany.id)
 }
 
+// AddDriveToCompany adds drive to a company, announcing it to eligible
+// applicants if it is already open.
 func (pr *PlacementRegistrar) AddDriveToCompany(companyID int, drive *Drive) error {
 	for i := range pr.companies {
 		if pr.companies[i].id == companyID {
 			pr.companies[i].drives = append(pr.companies[i].drives, drive)
+			pr.AnnounceOpenDrives()
 			return nil
 
 		}
 	}
-	return fmt.Errorf("company with id %d not found", companyID)
+	return notFoundf("company with id %d not found", companyID)
 }
 
 func (pr *PlacementRegistrar) ApplicantByID(studentID int) (*Applicant, error) {
@@ -162,7 +211,7 @@ func (pr *PlacementRegistrar) ApplicantByID(studentID int) (*Applicant, error) {
 			return pr.applicants[i], nil
 		}
 	}
-	return nil, fmt.Errorf("applicant with id %d not found", studentID)
+	return nil, notFoundf("applicant with id %d not found", studentID)
 }
 
 func (pr *PlacementRegistrar) CompanyByID(id int) (*Company, error) {
@@ -171,7 +220,7 @@ func (pr *PlacementRegistrar) CompanyByID(id int) (*Company, error) {
 			return pr.companies[i], nil
 		}
 	}
-	return nil, fmt.Errorf("company with id %d not found", id)
+	return nil, notFoundf("company with id %d not found", id)
 }
 
 func (pr *PlacementRegistrar) DriveByID(companyID, driveID int) (*Drive, error) {
@@ -186,13 +235,13 @@ func (pr *PlacementRegistrar) DriveByID(companyID, driveID int) (*Drive, error)
 			return drives[i], nil
 		}
 	}
-	return nil, fmt.Errorf("drive by id  %d and company with id %d not found", driveID, companyID)
+	return nil, notFoundf("drive by id  %d and company with id %d not found", driveID, companyID)
 }
 
 func (pr *PlacementRegistrar) ApplyForDrive(studentID, companyID, driveID int) error {
 	applicant, err := pr.ApplicantByID(studentID)
 	if err != nil {
-		return fmt.Errorf("applicant not found: %v", err)
+		return fmt.Errorf("applicant not found: %w", err)
 	}
 	drive, err := pr.DriveByID(companyID, driveID)
 	if err != nil {
@@ -200,11 +249,19 @@ func (pr *PlacementRegistrar) ApplyForDrive(studentID, companyID, driveID int) e
 	}
 
 	if drive.HasApplied(studentID) {
-		return fmt.Errorf("applicant applied already")
+		return conflictf("applicant applied already")
 	}
 
-	if !drive.eligibility.checkEligibility(applicant) {
-		return fmt.Errorf("applicant is not meet the criteria ")
+	if err := pr.checkAcceptingApplications(drive); err != nil {
+		return err
+	}
+
+	if err := drive.eligibility.Check(pr.ProfileOf(applicant)); err != nil {
+		return err
+	}
+
+	if err := pr.OfferPolicy().Check(studentID, drive, pr.HeldOffers(studentID)); err != nil {
+		return err
 	}
 
 	application := &Application{
@@ -226,13 +283,45 @@ func (pr *PlacementRegistrar) ApplyForDrive(studentID, companyID, driveID int) e
 	return nil
 }
 
+// UpdateApplicationStatus moves a student's application for a drive to
+// newStatus without attributing the change to anyone.
 func (pr *PlacementRegistrar) UpdateApplicationStatus(studentID, driverID int, newStatus ApplicationStatus) error {
-	for i := range pr.applications {
-		app := pr.applications[i]
-		if app.Student.id == studentID && app.driveId == driverID {
-			app.status = newStatus
-			return nil
+	return pr.TransitionApplication(studentID, driverID, newStatus, "")
+}
+
+// TransitionApplication moves a student's application for a drive to
+// newStatus, rejecting moves the transition table does not allow and
+// recording actor in the application's history. Selecting an application
+// issues the student an offer. Statuses are frozen once the drive's results
+// are published.
+func (pr *PlacementRegistrar) TransitionApplication(studentID, driveID int, newStatus ApplicationStatus, actor string) error {
+	app, err := pr.ApplicationFor(studentID, driveID)
+	if err != nil {
+		return err
+	}
+	if drive := pr.driveByID(driveID); drive != nil {
+		if err := pr.checkResultsPending(drive); err != nil {
+			return err
+		}
+	}
+	now := pr.Now().UTC()
+	from := app.status
+	if err := app.transition(newStatus, actor, now); err != nil {
+		return err
+	}
+	if newStatus == Selected {
+		pr.issueOffer(app, now)
+	}
+	pr.notifyStatus(app, from)
+	return nil
+}
+
+// ApplicationFor returns a student's application for a drive.
+func (pr *PlacementRegistrar) ApplicationFor(studentID, driveID int) (*Application, error) {
+	for _, app := range pr.applications {
+		if app.Student.id == studentID && app.driveId == driveID {
+			return app, nil
 		}
 	}
-	return fmt.Errorf("student with id %d status cannot be updated for drive with id %d", studentID, driverID)
+	return nil, notFoundf("no application of student %d for drive %d", studentID, driveID)
 }
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -412,12 +413,12 @@ func TestPlacementRegistrar_UpdateApplicationStatus(t *testing.T) {
 		app := &Application{id: 1, driveId: 600, Applicant: a, status: Applied}
 		pr := &PlacementRegistrar{applications: []*Application{app}}
 
-		err := pr.UpdateApplicationStatus(60, 600, Selected)
+		err := pr.UpdateApplicationStatus(60, 600, ShortListed)
 		if err != nil {
 			t.Error("UpdateApplicationStatus failed")
 		}
 
-		if app.status != Selected {
+		if app.status != ShortListed {
 			t.Error("Application status not updated")
 		}
 	})
@@ -435,7 +436,7 @@ func TestPlacementRegistrar_UpdateApplicationStatus(t *testing.T) {
 		app := &Application{id: 1, driveId: 600, Applicant: a, status: Applied}
 		pr := &PlacementRegistrar{applications: []*Application{app}}
 
-		statuses := []ApplicationStatus{ShortListed, Cleared, Selected, Rejected}
+		statuses := []ApplicationStatus{ShortListed, Cleared, Selected}
 		for _, status := range statuses {
 			err := pr.UpdateApplicationStatus(60, 600, status)
 			if err != nil {
@@ -445,6 +446,32 @@ func TestPlacementRegistrar_UpdateApplicationStatus(t *testing.T) {
 				t.Errorf("Status not updated to %d", status)
 			}
 		}
+		if len(app.History()) != len(statuses) {
+			t.Errorf("expected %d history entries, got %d", len(statuses), len(app.History()))
+		}
+	})
+
+	t.Run("should reject illegal transitions", func(t *testing.T) {
+		a := NewApplicant(Student{id: 60}, AcademicRecord{})
+		app := &Application{id: 1, driveId: 600, Applicant: a, status: Applied}
+		pr := &PlacementRegistrar{applications: []*Application{app}}
+
+		err := pr.UpdateApplicationStatus(60, 600, Selected)
+		var te *TransitionError
+		if !errors.As(err, &te) || te.From != Applied || te.To != Selected {
+			t.Errorf("expected TransitionError from applied to selected, got %v", err)
+		}
+		if !errors.Is(err, ErrInvalidTransition) || !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrInvalidTransition and ErrConflict, got %v", err)
+		}
+
+		_ = pr.TransitionApplication(60, 600, Rejected, "officer")
+		if err := pr.TransitionApplication(60, 600, Selected, "officer"); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("rejected application should not move to selected, got %v", err)
+		}
+		if app.status != Rejected || len(app.History()) != 1 || app.History()[0].Actor != "officer" {
+			t.Errorf("unexpected application state %v, history %+v", app.status, app.History())
+		}
 	})
 
 	t.Run("should match both student ID and drive ID", func(t *testing.T) {
@@ -454,12 +481,12 @@ func TestPlacementRegistrar_UpdateApplicationStatus(t *testing.T) {
 		app2 := &Application{id: 2, driveId: 601, Applicant: a2, status: Applied}
 		pr := &PlacementRegistrar{applications: []*Application{app1, app2}}
 
-		err := pr.UpdateApplicationStatus(60, 600, Selected)
+		err := pr.UpdateApplicationStatus(60, 600, ShortListed)
 		if err != nil {
 			t.Error("UpdateApplicationStatus failed")
 		}
 
-		if app1.status != Selected {
+		if app1.status != ShortListed {
 			t.Error("First application status not updated")
 		}
 		if app2.status != Applied {
@@ -619,6 +646,7 @@ func TestPlacementRegistrar_GenerateReportByStudent(t *testing.T) {
 			applicants:   []*Applicant{a},
 			companies:    []*Company{{id: 90, drives: []*Drive{d}}},
 			applications: []*Application{app},
+			offers:       []*Offer{{id: 1, applicationID: 1, studentID: 90, driveID: d.ID(), status: OfferAccepted}},
 		}
 
 		reports := pr.GenerateReportByStudent()
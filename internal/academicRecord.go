@@ -5,6 +5,8 @@ type AcademicRecord struct {
 	Semesters map[int]*SemesterResult `json:"semesters"`
 	CGPA      float64                 `json:"cgpa"`
 	Status    string                  // "At Risk", "Dean's List", "Normal"
+
+	scale *GradeScale // nil reads grades on the DefaultGradeScale
 }
 
 func NewAcademicRecord(studentId int) *AcademicRecord {
@@ -17,6 +19,7 @@ func NewAcademicRecord(studentId int) *AcademicRecord {
 func (ar *AcademicRecord) AddResult(courseResult CourseResult, semester int) {
 	if ar.Semesters[semester] == nil {
 		ar.Semesters[semester] = NewSemesterResult(ar.StudentId, semester)
+		ar.Semesters[semester].scale = ar.scale
 	}
 	ar.Semesters[semester].AddCourseResult(courseResult)
 	ar.calculateCGPA()
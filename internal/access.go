@@ -0,0 +1,179 @@
+package internal
+
+import "fmt"
+
+// Role is what a user of the portal is allowed to act as.
+type Role int
+
+const (
+	RoleStudent Role = iota + 1
+	RoleTeacher
+	RolePlacementOfficer
+	RoleAdmin
+	// RoleHOD is a head of department, who decides leave for any course.
+	RoleHOD
+)
+
+var roleStrings = map[Role]string{
+	RoleStudent:          "student",
+	RoleTeacher:          "teacher",
+	RolePlacementOfficer: "placement_officer",
+	RoleAdmin:            "admin",
+	RoleHOD:              "hod",
+}
+
+func (r Role) String() string {
+	return roleStrings[r]
+}
+
+// ParseRole is the inverse of Role.String.
+func ParseRole(s string) (Role, error) {
+	for role, name := range roleStrings {
+		if name == s {
+			return role, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid role %q", s)
+}
+
+// Principal identifies who is calling a service method.
+type Principal struct {
+	Role      Role
+	StudentID int    // set when Role is RoleStudent
+	TeacherID string // set when Role is RoleTeacher
+	Username  string // account the principal logged in as, if any
+}
+
+func (p Principal) String() string {
+	switch p.Role {
+	case RoleStudent:
+		return fmt.Sprintf("student %d", p.StudentID)
+	case RoleTeacher:
+		return fmt.Sprintf("teacher %s", p.TeacherID)
+	}
+	return p.Role.String()
+}
+
+// Actor names the principal in audit trails.
+func (p Principal) Actor() string {
+	if p.Username != "" {
+		return p.Username
+	}
+	return p.String()
+}
+
+// Action is an operation guarded by a Policy.
+type Action string
+
+const (
+	ActionViewStudents            Action = "students:view"
+	ActionManageStudents          Action = "students:manage"
+	ActionViewCourses             Action = "courses:view"
+	ActionManageCourses           Action = "courses:manage"
+	ActionViewTeachers            Action = "teachers:view"
+	ActionManageTeachers          Action = "teachers:manage"
+	ActionViewEnrollments         Action = "enrollments:view"
+	ActionManageEnrollments       Action = "enrollments:manage"
+	ActionViewAttendance          Action = "attendance:view"
+	ActionMarkAttendance          Action = "attendance:mark"
+	ActionUploadMarks             Action = "marks:upload"
+	ActionViewCourseResults       Action = "results:view"
+	ActionViewAcademicRecord      Action = "academic_record:view"
+	ActionViewCompanies           Action = "companies:view"
+	ActionManageCompanies         Action = "companies:manage"
+	ActionViewApplicants          Action = "applicants:view"
+	ActionManageApplicants        Action = "applicants:manage"
+	ActionApplyForDrive           Action = "drives:apply"
+	ActionUpdateApplicationStatus Action = "applications:update_status"
+	ActionViewOffers              Action = "offers:view"
+	ActionRespondToOffer          Action = "offers:respond"
+	ActionManageAccounts          Action = "accounts:manage"
+	ActionViewPlacementReports    Action = "placement_reports:view"
+	ActionViewNotifications       Action = "notifications:view"
+	ActionManageSubscriptions     Action = "notifications:subscribe"
+	ActionReadNotifications       Action = "notifications:read"
+	ActionRequestReEvaluation     Action = "re_evaluations:request"
+	ActionModerateGrades          Action = "grades:moderate"
+	ActionRequestLeave            Action = "leave:request"
+	ActionApproveLeave            Action = "leave:approve"
+)
+
+// Resource describes whose data an action touches. Zero fields mean the
+// action is not tied to a particular student or teacher.
+type Resource struct {
+	StudentID int
+	TeacherID string
+}
+
+// Policy decides whether a principal may perform an action on a resource,
+// returning an error wrapping ErrForbidden when it may not.
+type Policy interface {
+	Authorize(p Principal, a Action, r Resource) error
+}
+
+// RolePolicy grants actions per role. Students are additionally limited to
+// resources that belong to them, and teachers to their own courses.
+type RolePolicy struct {
+	Grants map[Role][]Action
+}
+
+// DefaultPolicy returns the portal's standard role permissions.
+func DefaultPolicy() RolePolicy {
+	return RolePolicy{Grants: map[Role][]Action{
+		RoleStudent: {
+			ActionViewStudents, ActionViewCourses, ActionViewTeachers, ActionViewEnrollments,
+			ActionViewAttendance, ActionViewAcademicRecord, ActionViewCompanies,
+			ActionViewApplicants, ActionApplyForDrive, ActionViewOffers, ActionRespondToOffer,
+			ActionViewNotifications, ActionManageSubscriptions, ActionReadNotifications,
+			ActionRequestReEvaluation, ActionRequestLeave,
+		},
+		RoleTeacher: {
+			ActionViewStudents, ActionViewCourses, ActionViewTeachers, ActionViewEnrollments,
+			ActionViewAttendance, ActionMarkAttendance, ActionUploadMarks, ActionViewCourseResults,
+			ActionViewCompanies,
+		},
+		RolePlacementOfficer: {
+			ActionViewStudents, ActionViewCourses, ActionViewAcademicRecord, ActionViewCompanies,
+			ActionManageCompanies, ActionViewApplicants, ActionManageApplicants,
+			ActionApplyForDrive, ActionUpdateApplicationStatus, ActionViewPlacementReports,
+			ActionViewOffers,
+		},
+		RoleAdmin: {
+			ActionViewStudents, ActionManageStudents, ActionViewCourses, ActionManageCourses,
+			ActionViewTeachers, ActionManageTeachers, ActionViewEnrollments, ActionManageEnrollments,
+			ActionViewAttendance, ActionMarkAttendance, ActionUploadMarks, ActionViewCourseResults,
+			ActionViewAcademicRecord, ActionViewCompanies, ActionViewApplicants,
+			ActionManageAccounts, ActionViewPlacementReports, ActionViewOffers,
+			ActionViewNotifications, ActionManageSubscriptions, ActionModerateGrades,
+			ActionApproveLeave,
+		},
+		RoleHOD: {
+			ActionViewStudents, ActionViewCourses, ActionViewTeachers, ActionViewEnrollments,
+			ActionViewAttendance, ActionApproveLeave,
+		},
+	}}
+}
+
+func (rp RolePolicy) Authorize(p Principal, a Action, r Resource) error {
+	granted := false
+	for _, g := range rp.Grants[p.Role] {
+		if g == a {
+			granted = true
+			break
+		}
+	}
+	if !granted {
+		return forbiddenf("%s may not %s", p, a)
+	}
+	switch p.Role {
+	case RoleStudent:
+		if r.StudentID != 0 && r.StudentID != p.StudentID {
+			return forbiddenf("%s may not %s of student %d", p, a, r.StudentID)
+		}
+	case RoleTeacher:
+		if r.TeacherID != "" && r.TeacherID != p.TeacherID {
+			return forbiddenf("%s may not %s of teacher %s", p, a, r.TeacherID)
+		}
+	}
+	return nil
+}
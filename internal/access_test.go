@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRolePolicy_Authorize(t *testing.T) {
+	policy := DefaultPolicy()
+	alice := Principal{Role: RoleStudent, StudentID: 1}
+	smith := Principal{Role: RoleTeacher, TeacherID: "T1"}
+	officer := Principal{Role: RolePlacementOfficer}
+	admin := Principal{Role: RoleAdmin}
+
+	tests := []struct {
+		name    string
+		by      Principal
+		action  Action
+		res     Resource
+		allowed bool
+	}{
+		{"student views own record", alice, ActionViewAcademicRecord, Resource{StudentID: 1}, true},
+		{"student views other record", alice, ActionViewAcademicRecord, Resource{StudentID: 2}, false},
+		{"student updates status", alice, ActionUpdateApplicationStatus, Resource{StudentID: 1}, false},
+		{"teacher marks own course", smith, ActionMarkAttendance, Resource{StudentID: 1, TeacherID: "T1"}, true},
+		{"teacher marks other course", smith, ActionMarkAttendance, Resource{StudentID: 1, TeacherID: "T2"}, false},
+		{"officer updates status", officer, ActionUpdateApplicationStatus, Resource{StudentID: 1}, true},
+		{"officer uploads marks", officer, ActionUploadMarks, Resource{TeacherID: "T1"}, false},
+		{"admin updates status", admin, ActionUpdateApplicationStatus, Resource{StudentID: 1}, false},
+		{"admin manages students", admin, ActionManageStudents, Resource{}, true},
+		{"student accepts own offer", alice, ActionRespondToOffer, Resource{StudentID: 1}, true},
+		{"student accepts other offer", alice, ActionRespondToOffer, Resource{StudentID: 2}, false},
+		{"officer accepts offer", officer, ActionRespondToOffer, Resource{StudentID: 1}, false},
+		{"no role", Principal{}, ActionViewCourses, Resource{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Authorize(tt.by, tt.action, tt.res)
+			if tt.allowed && err != nil {
+				t.Errorf("expected allowed, got %v", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrForbidden) {
+				t.Errorf("expected ErrForbidden, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	for role := range roleStrings {
+		got, err := ParseRole(role.String())
+		if err != nil || got != role {
+			t.Errorf("ParseRole(%q) = %v, %v", role.String(), got, err)
+		}
+	}
+	if _, err := ParseRole("janitor"); err == nil {
+		t.Error("expected error for unknown role")
+	}
+}
+
+func TestPortalService_ScopesStudents(t *testing.T) {
+	ps := NewPortalService(samplePortal(), DefaultPolicy())
+	ps.Portal.Academic.AddStudent(NewStudent(2, "Bob"))
+	alice := Principal{Role: RoleStudent, StudentID: 1}
+
+	students, err := ps.Students(alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(students) != 1 || students[0].ID() != 1 {
+		t.Errorf("student should only see themselves, got %v", students)
+	}
+	if _, err := ps.AcademicRecord(alice, 2); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden reading another record, got %v", err)
+	}
+	if _, err := ps.AcademicRecord(alice, 1); err != nil {
+		t.Errorf("student should read own record: %v", err)
+	}
+
+	drive := ps.Portal.Placement.AllDrives()[0]
+	if err := ps.UpdateApplicationStatus(alice, 1, drive.ID(), Selected); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden updating status as student, got %v", err)
+	}
+	if err := ps.UpdateApplicationStatus(Principal{Role: RoleAdmin}, 1, drive.ID(), Selected); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden updating status as admin, got %v", err)
+	}
+	officer := Principal{Role: RolePlacementOfficer, Username: "officer"}
+	if err := ps.UpdateApplicationStatus(officer, 1, drive.ID(), Cleared); err != nil {
+		t.Errorf("placement officer should update status: %v", err)
+	}
+	history, err := ps.ApplicationHistory(alice, 1, drive.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := history[len(history)-1]; last.To != Cleared || last.Actor != "officer" {
+		t.Errorf("expected the officer's change in the history, got %+v", last)
+	}
+}
+
+func TestPortalService_ForbidsBeforeLookup(t *testing.T) {
+	ps := NewPortalService(samplePortal(), DefaultPolicy())
+	officer := Principal{Role: RolePlacementOfficer}
+
+	if _, err := ps.SessionRoll(officer, 999); !errors.Is(err, ErrForbidden) {
+		t.Errorf("SessionRoll: expected ErrForbidden for a missing session, got %v", err)
+	}
+	if _, err := ps.TakeAttendance(officer, 999, nil); !errors.Is(err, ErrForbidden) {
+		t.Errorf("TakeAttendance: expected ErrForbidden for a missing session, got %v", err)
+	}
+	if _, err := ps.ReviewLeave(officer, 999, true, ""); !errors.Is(err, ErrForbidden) {
+		t.Errorf("ReviewLeave: expected ErrForbidden for a missing request, got %v", err)
+	}
+	if _, err := ps.ReviewReEvaluation(officer, 999, nil, ""); !errors.Is(err, ErrForbidden) {
+		t.Errorf("ReviewReEvaluation: expected ErrForbidden for a missing request, got %v", err)
+	}
+	if _, err := ps.RespondToOffer(officer, 999, true); !errors.Is(err, ErrForbidden) {
+		t.Errorf("RespondToOffer: expected ErrForbidden for a missing offer, got %v", err)
+	}
+	if _, err := ps.AttendanceSummary(Principal{Role: RoleStudent, StudentID: 1}, 2, 999); !errors.Is(err, ErrForbidden) {
+		t.Errorf("AttendanceSummary: expected ErrForbidden for another student's missing enrollment, got %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package internal
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// passwordIterations is the PBKDF2-SHA256 work factor for new hashes. Stored
+// hashes record their own count, so raising it does not break old accounts.
+var passwordIterations = 600_000
+
+const (
+	passwordSaltLen = 16
+	passwordKeyLen  = 32
+	minPasswordLen  = 8
+)
+
+// HashPassword returns a salted PBKDF2-SHA256 hash of password in the form
+// "pbkdf2-sha256$<iterations>$<salt>$<key>".
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, passwordSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, passwordIterations, passwordKeyLen)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawStdEncoding
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", passwordIterations, enc.EncodeToString(salt), enc.EncodeToString(key)), nil
+}
+
+// CheckPassword reports whether password matches a hash made by HashPassword.
+func CheckPassword(hash, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false
+	}
+	iter, err := strconv.Atoi(parts[1])
+	if err != nil || iter <= 0 {
+		return false
+	}
+	enc := base64.RawStdEncoding
+	salt, err := enc.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := enc.DecodeString(parts[3])
+	if err != nil || len(want) == 0 {
+		return false
+	}
+	got, err := pbkdf2.Key(sha256.New, password, salt, iter, len(want))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// Account is a login for the portal. Student and teacher accounts are linked
+// to the Student.id or Teacher.ID they act as.
+type Account struct {
+	Username     string
+	Role         Role
+	StudentID    int
+	TeacherID    string
+	passwordHash string
+}
+
+// NewAccount validates the account details and hashes password.
+func NewAccount(username, password string, role Role, studentID int, teacherID string) (*Account, error) {
+	if username == "" {
+		return nil, invalidf("username is required")
+	}
+	if len(password) < minPasswordLen {
+		return nil, invalidf("password must be at least %d characters", minPasswordLen)
+	}
+	switch role {
+	case RoleStudent:
+		if studentID <= 0 || teacherID != "" {
+			return nil, invalidf("a student account must be linked to a student id only")
+		}
+	case RoleTeacher:
+		if teacherID == "" || studentID != 0 {
+			return nil, invalidf("a teacher account must be linked to a teacher id only")
+		}
+	case RolePlacementOfficer, RoleAdmin, RoleHOD:
+		if studentID != 0 || teacherID != "" {
+			return nil, invalidf("a %s account cannot be linked to a student or teacher", role)
+		}
+	default:
+		return nil, invalidf("invalid role %d", role)
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{Username: username, Role: role, StudentID: studentID, TeacherID: teacherID, passwordHash: hash}, nil
+}
+
+// Principal returns who the account acts as.
+func (a *Account) Principal() Principal {
+	return Principal{Role: a.Role, StudentID: a.StudentID, TeacherID: a.TeacherID, Username: a.Username}
+}
+
+func (a *Account) CheckPassword(password string) bool {
+	return CheckPassword(a.passwordHash, password)
+}
+
+func (a *Account) SetPassword(password string) error {
+	if len(password) < minPasswordLen {
+		return invalidf("password must be at least %d characters", minPasswordLen)
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	a.passwordHash = hash
+	return nil
+}
+
+// AccountRegistry holds the portal's accounts.
+type AccountRegistry struct {
+	accounts []*Account
+}
+
+func (ar *AccountRegistry) Accounts() []*Account {
+	return ar.accounts
+}
+
+func (ar *AccountRegistry) Add(a *Account) error {
+	if _, err := ar.ByUsername(a.Username); err == nil {
+		return conflictf("account %q already exists", a.Username)
+	}
+	ar.accounts = append(ar.accounts, a)
+	return nil
+}
+
+func (ar *AccountRegistry) ByUsername(username string) (*Account, error) {
+	for _, a := range ar.accounts {
+		if a.Username == username {
+			return a, nil
+		}
+	}
+	return nil, notFoundf("account %q not found", username)
+}
+
+// dummyPasswordHash is checked against for unknown usernames, so they take
+// as long to refuse as a wrong password and do not reveal which exist.
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := HashPassword("not anyone's password")
+	return hash
+})
+
+// Authenticate returns the account for username if password matches.
+func (ar *AccountRegistry) Authenticate(username, password string) (*Account, error) {
+	return ar.Credentials(username).Check(password)
+}
+
+// Credentials are what a login is checked against. Looking them up is quick
+// and checking them is slow, so callers that guard the registry with a lock
+// need only hold it for the lookup.
+type Credentials struct {
+	account *Account
+	hash    string
+}
+
+// Credentials looks username up for a login.
+func (ar *AccountRegistry) Credentials(username string) Credentials {
+	a, err := ar.ByUsername(username)
+	if err != nil {
+		return Credentials{hash: dummyPasswordHash()}
+	}
+	return Credentials{account: a, hash: a.passwordHash}
+}
+
+// Check returns the account if password matches.
+func (c Credentials) Check(password string) (*Account, error) {
+	if !CheckPassword(c.hash, password) || c.account == nil {
+		return nil, unauthenticatedf("invalid username or password")
+	}
+	return c.account, nil
+}
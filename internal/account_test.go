@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "pbkdf2-sha256$") || strings.Contains(hash, "correct horse") {
+		t.Errorf("unexpected hash format %q", hash)
+	}
+	if !CheckPassword(hash, "correct horse") {
+		t.Error("expected password to match its hash")
+	}
+	if CheckPassword(hash, "wrong horse") {
+		t.Error("expected wrong password to be rejected")
+	}
+	if other, _ := HashPassword("correct horse"); other == hash {
+		t.Error("expected a fresh salt for every hash")
+	}
+	if CheckPassword("plaintext", "plaintext") {
+		t.Error("expected malformed hash to be rejected")
+	}
+}
+
+func TestNewAccount_Validation(t *testing.T) {
+	tests := []struct {
+		name      string
+		role      Role
+		studentID int
+		teacherID string
+		password  string
+		wantErr   bool
+	}{
+		{"student", RoleStudent, 1, "", "password1", false},
+		{"student without id", RoleStudent, 0, "", "password1", true},
+		{"teacher", RoleTeacher, 0, "T1", "password1", false},
+		{"teacher with student id", RoleTeacher, 1, "T1", "password1", true},
+		{"admin linked to student", RoleAdmin, 1, "", "password1", true},
+		{"short password", RolePlacementOfficer, 0, "", "short", true},
+		{"no role", 0, 0, "", "password1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAccount("user", tt.password, tt.role, tt.studentID, tt.teacherID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewAccount error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPortal_AddAccount(t *testing.T) {
+	p := samplePortal()
+	alice, _ := NewAccount("alice", "password1", RoleStudent, 1, "")
+	if err := p.AddAccount(alice); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddAccount(alice); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict for duplicate username, got %v", err)
+	}
+	ghost, _ := NewAccount("ghost", "password1", RoleTeacher, 0, "T9")
+	if err := p.AddAccount(ghost); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown teacher, got %v", err)
+	}
+
+	if _, err := p.Accounts.Authenticate("alice", "password2"); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated, got %v", err)
+	}
+	if _, err := p.Accounts.Authenticate("nobody", "password1"); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated for an unknown username, got %v", err)
+	}
+	// Unknown usernames are checked against a real hash, at the same cost.
+	if want := fmt.Sprintf("pbkdf2-sha256$%d$", passwordIterations); !strings.HasPrefix(dummyPasswordHash(), want) {
+		t.Errorf("expected the dummy hash to cost %d iterations, got %q", passwordIterations, dummyPasswordHash())
+	}
+	a, err := p.Accounts.Authenticate("alice", "password1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := a.Principal(); got.Role != RoleStudent || got.StudentID != 1 {
+		t.Errorf("unexpected principal %v", got)
+	}
+}
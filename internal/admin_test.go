@@ -162,6 +162,11 @@ func TestSetGraderAndEnrollments(t *testing.T) {
 	}
 }
 func TestLoadStudents_SuccessAndFailure(t *testing.T) {
+	// LoadStudents reads students.json from the working directory, which the
+	// crash subprocesses below inherit.
+	if os.Getenv("BE_CRASH_STUDENT1")+os.Getenv("BE_CRASH_STUDENT2") == "" {
+		t.Chdir(t.TempDir())
+	}
 	// ------------------------ SUCCESS CASE ------------------------
 	studentJSON := `[{"id":7,"name":"JSON Student"}]`
 	_ = os.WriteFile("students.json", []byte(studentJSON), 0644)
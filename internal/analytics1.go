@@ -11,6 +11,12 @@ import (
 )
 
 func GenerateGPAHistogramFromFiles(courseResultsFile, studentsFile string) (map[string]int, error) {
+	return GenerateGPAHistogramWithScale(courseResultsFile, studentsFile, DefaultGradeScale())
+}
+
+// GenerateGPAHistogramWithScale reads grades on scale. CGPAs are bucketed
+// out of 10 whatever the scale, so histograms of different colleges compare.
+func GenerateGPAHistogramWithScale(courseResultsFile, studentsFile string, scale *GradeScale) (map[string]int, error) {
 	// Step 1: Read and parse courseResults.json
 	var courseResults []CourseResult
 	cData, err := os.ReadFile(courseResultsFile)
@@ -20,6 +26,9 @@ func GenerateGPAHistogramFromFiles(courseResultsFile, studentsFile string) (map[
 	if err := json.Unmarshal(cData, &courseResults); err != nil {
 		return nil, err
 	}
+	if err := scale.CheckResults(courseResults...); err != nil {
+		return nil, err
+	}
 
 	// Step 2: Read and parse students.json
 	type studentData struct {
@@ -46,20 +55,21 @@ func GenerateGPAHistogramFromFiles(courseResultsFile, studentsFile string) (map[
 	for _, cr := range courseResults {
 		if _, exists := records[cr.StudentId]; !exists {
 			records[cr.StudentId] = NewAcademicRecord(cr.StudentId)
+			records[cr.StudentId].scale = scale
 		}
 		records[cr.StudentId].AddResult(cr, cr.Semester)
 	}
 
 	// Step 5: Set Status and attach Name
 	for _, record := range records {
-		record.Status = NewGPACalculator().DetermineStatus(record.CGPA)
+		record.Status = NewGPACalculator().DetermineStatus(scale.OnTenPointScale(record.CGPA))
 		// optional: assign Name if needed later
 	}
 
 	// Step 6: Create histogram
 	hist := map[string]int{}
 	for _, record := range records {
-		bucket := getGPABucket(record.CGPA)
+		bucket := getGPABucket(scale.OnTenPointScale(record.CGPA))
 		hist[bucket]++
 	}
 
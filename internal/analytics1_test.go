@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -115,7 +116,7 @@ func TestGenerateGPAHistogramFromFiles_FileNotFound(t *testing.T) {
 }
 
 func TestExportGPAHistogramChart_EmptyOrInvalidData(t *testing.T) {
-	err := ExportGPAHistogramChart(map[string]int{}, "invalid_chart.png")
+	err := ExportGPAHistogramChart(map[string]int{}, filepath.Join(t.TempDir(), "invalid_chart.png"))
 	if err != nil {
 		t.Errorf("Expected no error for empty chart, got: %v", err)
 	}
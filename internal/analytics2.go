@@ -13,20 +13,33 @@ import (
 
 // ExportDeanListChart plots students with GPA > 6
 func ExportDeanListChart(courseResultsFile, studentsFile, outputFile string) error {
-	return exportFilteredGPAChart(courseResultsFile, studentsFile, outputFile, func(gpa float64) bool {
+	return ExportDeanListChartWithScale(courseResultsFile, studentsFile, outputFile, DefaultGradeScale())
+}
+
+// ExportDeanListChartWithScale plots students whose GPA on scale is above 6
+// out of 10.
+func ExportDeanListChartWithScale(courseResultsFile, studentsFile, outputFile string, scale *GradeScale) error {
+	return exportFilteredGPAChart(courseResultsFile, studentsFile, outputFile, scale, func(gpa float64) bool {
 		return gpa > 6.0
 	}, "Dean's List (GPA > 6)")
 }
 
 // ExportAtRiskChart plots students with GPA < 5
 func ExportAtRiskChart(courseResultsFile, studentsFile, outputFile string) error {
-	return exportFilteredGPAChart(courseResultsFile, studentsFile, outputFile, func(gpa float64) bool {
+	return ExportAtRiskChartWithScale(courseResultsFile, studentsFile, outputFile, DefaultGradeScale())
+}
+
+// ExportAtRiskChartWithScale plots students whose GPA on scale is below 5
+// out of 10.
+func ExportAtRiskChartWithScale(courseResultsFile, studentsFile, outputFile string, scale *GradeScale) error {
+	return exportFilteredGPAChart(courseResultsFile, studentsFile, outputFile, scale, func(gpa float64) bool {
 		return gpa < 5.0
 	}, "At-Risk Students (GPA < 5)")
 }
 
-// Helper function to generate student name vs GPA bar chart
-func exportFilteredGPAChart(courseResultsFile, studentsFile, outputFile string, filter func(float64) bool, title string) error {
+// Helper function to generate student name vs GPA bar chart. filter sees the
+// GPA out of 10; the chart shows it on scale.
+func exportFilteredGPAChart(courseResultsFile, studentsFile, outputFile string, scale *GradeScale, filter func(float64) bool, title string) error {
 	// Load course results
 	var courseResults []CourseResult
 	cData, err := os.ReadFile(courseResultsFile)
@@ -62,18 +75,8 @@ func exportFilteredGPAChart(courseResultsFile, studentsFile, outputFile string,
 	}
 	scoreMap := map[int]*agg{}
 
-	gradePoints := map[string]float64{
-		"O":  10.0,
-		"A+": 9.0,
-		"A":  8.0,
-		"B+": 7.0,
-		"B":  6.0,
-		"C":  5.0,
-		"F":  0.0,
-	}
-
 	for _, cr := range courseResults {
-		gp, ok := gradePoints[cr.Grade.String()]
+		gp, ok := scale.Points(cr.Grade)
 		if !ok {
 			continue
 		}
@@ -95,7 +98,7 @@ func exportFilteredGPAChart(courseResultsFile, studentsFile, outputFile string,
 			continue
 		}
 		gpa := data.totalPoints / data.totalCredits
-		if filter(gpa) {
+		if filter(scale.OnTenPointScale(gpa)) {
 			selected = append(selected, record{
 				Name: students[id],
 				GPA:  gpa,
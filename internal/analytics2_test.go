@@ -79,7 +79,7 @@ func checkFile(t *testing.T, filename string) {
 	}
 }
 func TestExportFilteredGPAChart_EmptyData(t *testing.T) {
-	err := exportFilteredGPAChart("testdata/empty_courseResults.json", "testdata/students.json", "out.png", func(gpa float64) bool {
+	err := exportFilteredGPAChart("testdata/empty_courseResults.json", "testdata/students.json", "out.png", DefaultGradeScale(), func(gpa float64) bool {
 		return gpa > 9.5
 	}, "Empty Case")
 	if err == nil {
@@ -7,8 +7,11 @@ import (
 type Applicant struct {
 	Student
 	AcademicRecord
+	Department       string // department or branch, for drives restricted to some
+	GraduationYear   int
 	drivesAppliedFor []*Drive
 	offersReceived   []*Drive
+	finalOffer       *Drive // drive of the offer the applicant accepted
 }
 
 func NewApplicant(st Student, ar AcademicRecord) *Applicant {
@@ -29,13 +32,25 @@ func (a *Applicant) getAllReceivedOffersDrivesAndApplications() ([]*Drive, []*Ap
 	return drarr, pparr
 }
 
+// getFinalOffer returns the CTC of the offer the applicant accepted.
 func (a *Applicant) getFinalOffer() (int, error) {
+	if a.finalOffer != nil {
+		return a.finalOffer.CTC(), nil
+	}
 	drArr, _ := a.getAllReceivedOffersDrivesAndApplications()
 	if len(drArr) == 0 {
 		return -1, fmt.Errorf("no offers yet")
-	} else {
-		return drArr[0].CTC(), nil
 	}
+	return -1, fmt.Errorf("no offer accepted yet")
+}
+
+func (a *Applicant) OffersReceived() []*Drive {
+	return a.offersReceived
+}
+
+// FinalOffer returns the drive whose offer the applicant accepted, or nil.
+func (a *Applicant) FinalOffer() *Drive {
+	return a.finalOffer
 }
 
 func (a *Applicant) DrivesAppliedFor() []*Drive {
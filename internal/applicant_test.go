@@ -368,13 +368,26 @@ func TestApplicant_getFinalOffer(t *testing.T) {
 		}
 	})
 
-	t.Run("should return CTC when offers exist", func(t *testing.T) {
+	t.Run("should fail when no offer has been accepted", func(t *testing.T) {
 		a := NewApplicant(Student{id: 8}, AcademicRecord{})
 		d := &Drive{id: 701, ctc: 100}
 		app := &Application{id: 1, driveId: 701, Applicant: a, status: Selected}
 		d.applications = []*Application{app}
 		a.AddDrivesAppliedFor(d)
 
+		if _, err := a.getFinalOffer(); err == nil || err.Error() != "no offer accepted yet" {
+			t.Errorf("Expected error 'no offer accepted yet', got %v", err)
+		}
+	})
+
+	t.Run("should return CTC of the accepted offer", func(t *testing.T) {
+		a := NewApplicant(Student{id: 8}, AcademicRecord{})
+		d := &Drive{id: 701, ctc: 100}
+		app := &Application{id: 1, driveId: 701, Applicant: a, status: Selected}
+		d.applications = []*Application{app}
+		a.AddDrivesAppliedFor(d)
+		a.finalOffer = d
+
 		ctc, err := a.getFinalOffer()
 		if err != nil {
 			t.Errorf("getFinalOffer should succeed when offers exist, got error: %v", err)
@@ -384,7 +397,7 @@ func TestApplicant_getFinalOffer(t *testing.T) {
 		}
 	})
 
-	t.Run("should return first offer when multiple offers exist", func(t *testing.T) {
+	t.Run("should return the accepted offer when multiple offers exist", func(t *testing.T) {
 		a := NewApplicant(Student{id: 9}, AcademicRecord{})
 		d1 := &Drive{id: 801, ctc: 150}
 		d2 := &Drive{id: 802, ctc: 200}
@@ -394,13 +407,14 @@ func TestApplicant_getFinalOffer(t *testing.T) {
 		d2.applications = []*Application{app2}
 		a.AddDrivesAppliedFor(d1)
 		a.AddDrivesAppliedFor(d2)
+		a.finalOffer = d2
 
 		ctc, err := a.getFinalOffer()
 		if err != nil {
 			t.Errorf("getFinalOffer should succeed with multiple offers, got error: %v", err)
 		}
-		if ctc != 150 {
-			t.Errorf("Expected first offer CTC 150, got %d", ctc)
+		if ctc != 200 {
+			t.Errorf("Expected accepted offer CTC 200, got %d", ctc)
 		}
 	})
 
@@ -410,6 +424,7 @@ func TestApplicant_getFinalOffer(t *testing.T) {
 		app := &Application{id: 1, driveId: 901, Applicant: a, status: Selected}
 		d.applications = []*Application{app}
 		a.AddDrivesAppliedFor(d)
+		a.finalOffer = d
 
 		ctc, err := a.getFinalOffer()
 		if err != nil {
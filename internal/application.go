@@ -1,5 +1,11 @@
 package internal
 
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
 type ApplicationStatus int
 
 const (
@@ -10,11 +16,76 @@ const (
 	Rejected
 )
 
+var applicationStatusStrings = map[ApplicationStatus]string{
+	Applied:     "applied",
+	ShortListed: "shortlisted",
+	Cleared:     "cleared",
+	Selected:    "selected",
+	Rejected:    "rejected",
+}
+
+func (s ApplicationStatus) String() string {
+	return applicationStatusStrings[s]
+}
+
+// ParseApplicationStatus is the inverse of ApplicationStatus.String.
+func ParseApplicationStatus(s string) (ApplicationStatus, error) {
+	for status, name := range applicationStatusStrings {
+		if name == s {
+			return status, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid application status %q", s)
+}
+
+// applicationTransitions lists the statuses each status may move to.
+// Selected and Rejected are final.
+var applicationTransitions = map[ApplicationStatus][]ApplicationStatus{
+	Applied:     {ShortListed, Rejected},
+	ShortListed: {Cleared, Rejected},
+	Cleared:     {Selected, Rejected},
+}
+
+// CanTransitionTo reports whether an application may move from s to next.
+func (s ApplicationStatus) CanTransitionTo(next ApplicationStatus) bool {
+	for _, allowed := range applicationTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrInvalidTransition is matched by errors.Is for every TransitionError.
+var ErrInvalidTransition = errors.New("invalid application status transition")
+
+// TransitionError reports a status change the transition table forbids.
+type TransitionError struct {
+	ApplicationID int
+	From, To      ApplicationStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("application %d cannot move from %s to %s", e.ApplicationID, e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() []error { return []error{ErrInvalidTransition, ErrConflict} }
+
+// StatusChange is one entry in an application's audit trail.
+type StatusChange struct {
+	From  ApplicationStatus `json:"from"`
+	To    ApplicationStatus `json:"to"`
+	At    time.Time         `json:"at"`
+	Actor string            `json:"actor"`
+}
+
 type Application struct {
 	id      int
 	driveId int
 	*Applicant
-	status ApplicationStatus
+	status       ApplicationStatus
+	history      []StatusChange
+	roundResults []RoundResult
 }
 
 func (app *Application) ID() int {
@@ -24,3 +95,22 @@ func (app *Application) ID() int {
 func (app *Application) Status() ApplicationStatus {
 	return app.status
 }
+
+func (app *Application) DriveID() int {
+	return app.driveId
+}
+
+// History returns the application's status changes, oldest first.
+func (app *Application) History() []StatusChange {
+	return app.history
+}
+
+// transition moves the application to next, recording who did it and when.
+func (app *Application) transition(next ApplicationStatus, actor string, at time.Time) error {
+	if !app.status.CanTransitionTo(next) {
+		return &TransitionError{ApplicationID: app.id, From: app.status, To: next}
+	}
+	app.history = append(app.history, StatusChange{From: app.status, To: next, At: at, Actor: actor})
+	app.status = next
+	return nil
+}
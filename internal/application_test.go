@@ -119,3 +119,26 @@ func TestApplicationStatus_Constants(t *testing.T) {
 		}
 	})
 }
+
+func TestApplicationStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to ApplicationStatus
+		want     bool
+	}{
+		{Applied, ShortListed, true},
+		{Applied, Rejected, true},
+		{Applied, Selected, false},
+		{ShortListed, Cleared, true},
+		{ShortListed, Applied, false},
+		{Cleared, Selected, true},
+		{Cleared, Rejected, true},
+		{Selected, Rejected, false},
+		{Rejected, Selected, false},
+		{Rejected, Rejected, false},
+	}
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%s -> %s: got %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+)
+
+// MissingMarks is how an Assessment treats components a student has no
+// marks for.
+type MissingMarks string
+
+const (
+	// MissingZero counts missing marks as zero.
+	MissingZero MissingMarks = "zero"
+	// MissingProrate leaves components without marks out and scales up the
+	// weights of the rest, for students excused from an assessment.
+	MissingProrate MissingMarks = "prorate"
+)
+
+// AssessmentComponent is one part of a course's assessment, such as its
+// quizzes, midterm or lab.
+type AssessmentComponent struct {
+	Name     string  `json:"name"`
+	Weight   float64 `json:"weight"` // percent of the final score
+	MaxMarks float64 `json:"max_marks"`
+	// Count is how many times the component is held, e.g. 4 quizzes; zero
+	// means once. BestOf counts only a student's best attempts; zero counts
+	// them all.
+	Count  int `json:"count,omitempty"`
+	BestOf int `json:"best_of,omitempty"`
+}
+
+func (c AssessmentComponent) attempts() int {
+	if c.Count <= 0 {
+		return 1
+	}
+	return c.Count
+}
+
+func (c AssessmentComponent) counted() int {
+	if c.BestOf <= 0 {
+		return c.attempts()
+	}
+	return c.BestOf
+}
+
+// Assessment declares how a course is marked: its components, with weights
+// adding up to 100, from which each student's final score out of 100 is
+// worked out.
+type Assessment struct {
+	CourseID   int                   `json:"course_id"`
+	Components []AssessmentComponent `json:"components"`
+	Missing    MissingMarks          `json:"missing,omitempty"` // MissingZero if empty
+}
+
+// ParseAssessment reads an assessment from JSON and validates it.
+func ParseAssessment(data []byte) (*Assessment, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var a Assessment
+	if err := dec.Decode(&a); err != nil {
+		return nil, invalidf("invalid assessment: %v", err)
+	}
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Validate checks the components are distinct, with positive weights adding
+// up to 100 and positive maximum marks, and that no more attempts are
+// counted than are held.
+func (a *Assessment) Validate() error {
+	if a.Missing != "" && a.Missing != MissingZero && a.Missing != MissingProrate {
+		return invalidf("unknown treatment of missing marks %q; use %s or %s", a.Missing, MissingZero, MissingProrate)
+	}
+	if len(a.Components) == 0 {
+		return invalidf("assessment of course %d has no components", a.CourseID)
+	}
+	seen := map[string]bool{}
+	total := 0.0
+	for _, c := range a.Components {
+		switch {
+		case strings.TrimSpace(c.Name) == "":
+			return invalidf("assessment of course %d: a component has no name", a.CourseID)
+		case seen[c.Name]:
+			return invalidf("assessment of course %d lists %s twice", a.CourseID, c.Name)
+		case c.Weight <= 0:
+			return invalidf("assessment of course %d: %s needs a positive weight", a.CourseID, c.Name)
+		case c.MaxMarks <= 0:
+			return invalidf("assessment of course %d: %s needs positive maximum marks", a.CourseID, c.Name)
+		case c.Count < 0 || c.BestOf < 0 || c.counted() > c.attempts():
+			return invalidf("assessment of course %d: %s counts the best %d of %d attempts", a.CourseID, c.Name, c.counted(), c.attempts())
+		}
+		seen[c.Name] = true
+		total += c.Weight
+	}
+	if math.Abs(total-100) > 1e-9 {
+		return invalidf("assessment of course %d: weights add up to %g, not 100", a.CourseID, total)
+	}
+	return nil
+}
+
+// Component returns the component called name.
+func (a *Assessment) Component(name string) (AssessmentComponent, bool) {
+	for _, c := range a.Components {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return AssessmentComponent{}, false
+}
+
+// ComponentMark is a student's marks in one attempt at a component.
+type ComponentMark struct {
+	Component string  `json:"component"`
+	Attempt   int     `json:"attempt"` // from 1
+	Marks     float64 `json:"marks"`
+}
+
+// check returns the mark, its attempt defaulted to 1 for components held
+// once, if it fits the assessment.
+func (a *Assessment) check(m ComponentMark) (ComponentMark, error) {
+	c, ok := a.Component(m.Component)
+	if !ok {
+		return m, invalidf("course %d has no component %q", a.CourseID, m.Component)
+	}
+	if m.Attempt == 0 && c.attempts() == 1 {
+		m.Attempt = 1
+	}
+	if m.Attempt < 1 || m.Attempt > c.attempts() {
+		return m, invalidf("%s of course %d is held %d times; attempt %d is out of range", c.Name, a.CourseID, c.attempts(), m.Attempt)
+	}
+	if m.Marks < 0 || m.Marks > c.MaxMarks {
+		return m, invalidf("%s marks %g are not between 0 and %g", c.Name, m.Marks, c.MaxMarks)
+	}
+	return m, nil
+}
+
+// FinalScore works out a final score out of 100 from a student's component
+// marks. Each component scores the average of its best counted attempts as
+// a percentage of its maximum marks, weighted by its share of the course.
+func (a *Assessment) FinalScore(marks []ComponentMark) float64 {
+	total, weights := 0.0, 0.0
+	for _, c := range a.Components {
+		var got []float64
+		for _, m := range marks {
+			if m.Component == c.Name {
+				got = append(got, m.Marks)
+			}
+		}
+		counted := c.counted()
+		if a.Missing == MissingProrate {
+			if len(got) == 0 {
+				continue
+			}
+			counted = min(counted, len(got))
+		}
+		// Missing attempts score zero, so they are counted last.
+		sort.Sort(sort.Reverse(sort.Float64Slice(got)))
+		sum := 0.0
+		for _, v := range got[:min(counted, len(got))] {
+			sum += v
+		}
+		total += c.Weight * sum / float64(counted) / c.MaxMarks * 100
+		weights += c.Weight
+	}
+	if weights == 0 {
+		return 0
+	}
+	return total / weights
+}
+
+// scoreFor is e's FinalScore on the range its grader marks, so a
+// ScaleGrader out of 10 gets a tenth of it.
+func (a *Assessment) scoreFor(e Enrollment) float64 {
+	score := a.FinalScore(e.marks)
+	if g, ok := e.Grader.(ScaleGrader); ok {
+		score = score * g.outOf() / 100
+	}
+	return score
+}
+
+// Complete reports whether marks cover every attempt at every component.
+func (a *Assessment) Complete(marks []ComponentMark) bool {
+	for _, c := range a.Components {
+		got := 0
+		for _, m := range marks {
+			if m.Component == c.Name {
+				got++
+			}
+		}
+		if got < c.attempts() {
+			return false
+		}
+	}
+	return true
+}
+
+// withMark returns marks with m added, replacing any earlier mark for the
+// same attempt.
+func withMark(marks []ComponentMark, m ComponentMark) []ComponentMark {
+	out := make([]ComponentMark, 0, len(marks)+1)
+	for _, old := range marks {
+		if old.Component != m.Component || old.Attempt != m.Attempt {
+			out = append(out, old)
+		}
+	}
+	return append(out, m)
+}
+
+// ComponentMarks returns the component marks uploaded for the enrollment.
+func (e Enrollment) ComponentMarks() []ComponentMark {
+	return append([]ComponentMark(nil), e.marks...)
+}
+
+// Assessment returns the assessment declared for a course.
+func (r *NewRegistrarS) Assessment(courseID int) (*Assessment, bool) {
+	a, ok := r.assessments[courseID]
+	return a, ok
+}
+
+// Assessments returns every declared assessment, by course.
+func (r *NewRegistrarS) Assessments() []*Assessment {
+	out := make([]*Assessment, 0, len(r.assessments))
+	for _, a := range r.assessments {
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CourseID < out[j].CourseID })
+	return out
+}
+
+// SetAssessment declares how a course is marked. Once component marks have
+// been uploaded for the course its assessment can no longer change.
+func (r *NewRegistrarS) SetAssessment(a *Assessment) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	if !r.hasCourse(a.CourseID) {
+		return notFoundf("course with id %d not found", a.CourseID)
+	}
+	for _, e := range r.enroll {
+		if e.Course.Id == a.CourseID && len(e.marks) > 0 {
+			return conflictf("component marks have already been uploaded for course %d", a.CourseID)
+		}
+	}
+	if r.assessments == nil {
+		r.assessments = map[int]*Assessment{}
+	}
+	r.assessments[a.CourseID] = a
+	return nil
+}
+
+func (r *NewRegistrarS) hasCourse(id int) bool {
+	for _, c := range r.Courses() {
+		if c.Id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// UploadComponentMark stores a student's marks in one component of a
+// course. Once every attempt at every component is marked, or the marks have
+// been finalised, the final score is worked out from them and graded and
+// notified like an uploaded mark; until then the marks are only stored.
+func (ts *TeacherService) UploadComponentMark(courseID, studentID int, m ComponentMark) error {
+	r := ts.Registrar.NewRegistrarS
+	a, ok := r.Assessment(courseID)
+	if !ok {
+		return notFoundf("course %d has no assessment components", courseID)
+	}
+	m, err := a.check(m)
+	if err != nil {
+		return err
+	}
+	i, ok := ts.enrollment(courseID, studentID)
+	if !ok {
+		return notFoundf("no valid enrollment found for this teacher, student, and course")
+	}
+	e := r.enroll[i]
+	e.marks = withMark(e.marks, m)
+	if !e.marked && !a.Complete(e.marks) {
+		if err := r.enrollmentAttendance(e).debarment(); err != nil {
+			return err
+		}
+		r.enroll[i].marks = e.marks
+		return nil
+	}
+	e.score, e.marked = a.scoreFor(e.Enrollment), true
+	return ts.upload(i, e)
+}
+
+// FinaliseMarks grades a student's component marks in a course before every
+// component is marked, for example when they were excused from the rest.
+// Missing marks are treated as the assessment says.
+func (ts *TeacherService) FinaliseMarks(courseID, studentID int) error {
+	r := ts.Registrar.NewRegistrarS
+	a, ok := r.Assessment(courseID)
+	if !ok {
+		return notFoundf("course %d has no assessment components", courseID)
+	}
+	i, ok := ts.enrollment(courseID, studentID)
+	if !ok {
+		return notFoundf("no valid enrollment found for this teacher, student, and course")
+	}
+	e := r.enroll[i]
+	if len(e.marks) == 0 {
+		return conflictf("no component marks have been uploaded for student %d in course %d", studentID, courseID)
+	}
+	e.score, e.marked = a.scoreFor(e.Enrollment), true
+	return ts.upload(i, e)
+}
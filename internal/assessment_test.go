@@ -0,0 +1,190 @@
+package internal
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestAssessment_FinalScore(t *testing.T) {
+	a := &Assessment{CourseID: 101, Components: []AssessmentComponent{
+		{Name: "quiz", Weight: 20, MaxMarks: 10, Count: 4, BestOf: 3},
+		{Name: "midterm", Weight: 30, MaxMarks: 50},
+		{Name: "final", Weight: 50, MaxMarks: 100},
+	}}
+	if err := a.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	marks := []ComponentMark{
+		{Component: "quiz", Attempt: 1, Marks: 8}, {Component: "quiz", Attempt: 2, Marks: 6},
+		{Component: "quiz", Attempt: 3, Marks: 10}, {Component: "midterm", Attempt: 1, Marks: 40},
+	}
+	// The missing fourth quiz is the one dropped; the missing final scores zero.
+	if got := a.FinalScore(marks); math.Abs(got-40) > 1e-9 {
+		t.Errorf("expected 16 + 24 + 0 = 40, got %g", got)
+	}
+	if got := a.FinalScore(append(marks, ComponentMark{Component: "quiz", Attempt: 4, Marks: 9})); math.Abs(got-42) > 1e-9 {
+		t.Errorf("expected the best three quizzes to make 18 + 24 = 42, got %g", got)
+	}
+	a.Missing = MissingProrate
+	if got := a.FinalScore(marks); math.Abs(got-80) > 1e-9 {
+		t.Errorf("expected 40 of the 50 weight marked so far to be 80, got %g", got)
+	}
+
+	for _, bad := range []Assessment{
+		{CourseID: 101},
+		{CourseID: 101, Components: []AssessmentComponent{{Name: "final", Weight: 90, MaxMarks: 100}}},
+		{CourseID: 101, Components: []AssessmentComponent{{Name: "final", Weight: 100}}},
+		{CourseID: 101, Components: []AssessmentComponent{{Name: "quiz", Weight: 100, MaxMarks: 10, Count: 2, BestOf: 3}}},
+		{CourseID: 101, Components: []AssessmentComponent{{Name: "lab", Weight: 50, MaxMarks: 10}, {Name: "lab", Weight: 50, MaxMarks: 10}}},
+		{CourseID: 101, Missing: "ignore", Components: []AssessmentComponent{{Name: "final", Weight: 100, MaxMarks: 100}}},
+	} {
+		if err := bad.Validate(); !errors.Is(err, ErrInvalid) {
+			t.Errorf("expected %+v to be refused, got %v", bad, err)
+		}
+	}
+}
+
+func TestUploadComponentMark(t *testing.T) {
+	p := NewPortal()
+	teacher := NewTeacher("T1", "Prof. Smith")
+	alice, bob := NewStudent(1, "Alice"), NewStudent(2, "Bob")
+	math := NewCourse(101, "Math")
+	p.Academic.AddCourse(math)
+	p.Academic.AddTeacher(teacher)
+	cc := NewCreditCourse(math, 4)
+	cc.Semester = 1
+	p.Academic.AddTeacherenrollment(NewTeacherEnrollment(teacher, cc))
+	for _, st := range []Student{alice, bob} {
+		p.Academic.AddStudent(st)
+		p.Academic.AddEnrollnew(NewEnrollNew(st, math, ScaleGrader{}, 0, Attendance{}, teacher))
+	}
+	ts := &TeacherService{Registrar: p.Academic, Teacher: teacher}
+
+	if err := ts.UploadComponentMark(101, 1, ComponentMark{Component: "final", Marks: 80}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a course without components to be refused, got %v", err)
+	}
+	if err := p.Academic.SetAssessment(&Assessment{CourseID: 999, Components: []AssessmentComponent{{Name: "final", Weight: 100, MaxMarks: 100}}}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected an unknown course to be refused, got %v", err)
+	}
+	a := &Assessment{CourseID: 101, Components: []AssessmentComponent{
+		{Name: "quiz", Weight: 40, MaxMarks: 10, Count: 2, BestOf: 1},
+		{Name: "final", Weight: 60, MaxMarks: 100},
+	}}
+	if err := p.Academic.SetAssessment(a); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, bad := range []ComponentMark{
+		{Component: "lab", Marks: 5},
+		{Component: "quiz", Marks: 5},
+		{Component: "quiz", Attempt: 3, Marks: 5},
+		{Component: "quiz", Attempt: 1, Marks: 11},
+	} {
+		if err := ts.UploadComponentMark(101, 1, bad); !errors.Is(err, ErrInvalid) {
+			t.Errorf("expected %+v to be refused, got %v", bad, err)
+		}
+	}
+	if err := ts.UploadStudentMark(101, 1, 90); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected a final score to be refused for a course marked by component, got %v", err)
+	}
+
+	marks := []byte(`[
+		{"course_id": 101, "student_id": 1, "component": "quiz", "attempt": 1, "score": 5},
+		{"course_id": 101, "student_id": 1, "component": "quiz", "attempt": 2, "score": 9}
+	]`)
+	if err := ts.UploadStudentMarksFromJSON(marks); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Academic.CourseResults(1); len(got) != 0 {
+		t.Errorf("expected no grade with the final still to come, got %+v", got)
+	}
+	if msgs := p.Notifications.Inbox().Messages(1); len(msgs) != 0 {
+		t.Errorf("expected no marks notification before every component is marked, got %+v", msgs)
+	}
+	if e := p.Academic.enroll[0]; e.Marked() || len(e.ComponentMarks()) != 2 {
+		t.Errorf("expected the quiz marks stored without a final mark, got %+v", e.Enrollment)
+	}
+	if err := ts.UploadComponentMark(101, 1, ComponentMark{Component: "final", Marks: 80}); err != nil {
+		t.Fatal(err)
+	}
+	results, err := ts.GetCourseResults(101)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Score != 84 || results[0].Grade != "A+" || len(results[0].Components) != 3 {
+		t.Errorf("expected the best quiz and the final to make an A+ on 84, got %+v", results[0])
+	}
+	if got := p.Academic.CourseResults(1); got[0].Grade != Aplus {
+		t.Errorf("expected the transcript to show the A+, got %+v", got)
+	}
+	if msgs := p.Notifications.Inbox().Messages(1); len(msgs) != 1 || msgs[0].Kind != NotifyMarksUploaded {
+		t.Errorf("expected one marks notification once graded, got %+v", msgs)
+	}
+
+	// Bob missed the final; finalising grades what he has.
+	if err := ts.FinaliseMarks(101, 2); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected finalising without marks to be refused, got %v", err)
+	}
+	if err := ts.UploadComponentMark(101, 2, ComponentMark{Component: "quiz", Attempt: 1, Marks: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Academic.CourseResults(2); len(got) != 0 {
+		t.Errorf("expected one quiz alone to stay ungraded, got %+v", got)
+	}
+	if err := ts.FinaliseMarks(101, 2); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Academic.CourseResults(2); len(got) != 1 || got[0].Grade != C {
+		t.Errorf("expected 40 with the final missing to be a C, got %+v", got)
+	}
+	if err := p.Academic.SetAssessment(a); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected the assessment to be fixed once marks are uploaded, got %v", err)
+	}
+
+	snap, err := p.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestorePortal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := restored.Academic.Assessment(101); !ok || !reflect.DeepEqual(got, a) {
+		t.Errorf("expected the assessment to survive a snapshot, got %+v", got)
+	}
+	if e := restored.Academic.enroll[0]; e.Score() != 84 || !reflect.DeepEqual(e.ComponentMarks(), p.Academic.enroll[0].marks) {
+		t.Errorf("expected the component marks to survive a snapshot, got %+v", e.Enrollment)
+	}
+}
+
+func TestUploadComponentMark_ScaleGraderOutOf10(t *testing.T) {
+	p := NewPortal()
+	teacher := NewTeacher("T1", "Prof. Smith")
+	alice := NewStudent(1, "Alice")
+	course := NewCourse(101, "Math")
+	p.Academic.AddCourse(course)
+	p.Academic.AddTeacher(teacher)
+	cc := NewCreditCourse(course, 4)
+	cc.Semester = 1
+	p.Academic.AddTeacherenrollment(NewTeacherEnrollment(teacher, cc))
+	p.Academic.AddStudent(alice)
+	p.Academic.AddEnrollnew(NewEnrollNew(alice, course, ScaleGrader{MaxScore: 10}, 0, Attendance{}, teacher))
+	ts := &TeacherService{Registrar: p.Academic, Teacher: teacher}
+	if err := p.Academic.SetAssessment(&Assessment{CourseID: 101, Components: []AssessmentComponent{
+		{Name: "final", Weight: 100, MaxMarks: 100},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ts.UploadComponentMark(101, 1, ComponentMark{Component: "final", Marks: 84}); err != nil {
+		t.Fatal(err)
+	}
+	if e := p.Academic.enroll[0]; math.Abs(e.Score()-8.4) > 1e-9 {
+		t.Errorf("expected 84%% to score 8.4 out of 10, got %g", e.Score())
+	}
+	if got := p.Academic.CourseResults(1); len(got) != 1 || got[0].Grade != Aplus {
+		t.Errorf("expected 84%% to be an A+ out of 10 as it is out of 100, got %+v", got)
+	}
+}
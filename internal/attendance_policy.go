@@ -0,0 +1,278 @@
+package internal
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultMinimumAttendance is the share of classes students must attend
+// unless another minimum is set.
+const DefaultMinimumAttendance = 75.0
+
+// Counts returns how many classes were attended out of those recorded.
+// Late arrivals count as attended and excused absences are left out.
+// sessionDays holds, by day, how many sessions the student has a record
+// for. Each of those sessions stands for one class also marked by date on
+// its day, earliest first, so that class is counted once, through its
+// session. Classes marked by date beyond them still count.
+func (a Attendance) Counts(sessionDays map[time.Time]int) (attended, held int) {
+	dates := make([]time.Time, 0, len(a.Records))
+	for date := range a.Records {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	paired := map[time.Time]int{}
+	for _, date := range dates {
+		present := a.Records[date]
+		if day := dayOf(date); paired[day] < sessionDays[day] {
+			paired[day]++
+			continue
+		}
+		if !present && a.Excused[date] {
+			continue
+		}
+		held++
+		if present {
+			attended++
+		}
+	}
+	for _, status := range a.Sessions {
+		if status == StatusExcused {
+			continue
+		}
+		held++
+		if status.Attended() {
+			attended++
+		}
+	}
+	return attended, held
+}
+
+// Percentage returns the share of recorded classes attended, counted as
+// Counts does, and false when none have been recorded.
+func (a Attendance) Percentage(sessionDays map[time.Time]int) (float64, bool) {
+	attended, held := a.Counts(sessionDays)
+	if held == 0 {
+		return 0, false
+	}
+	return 100 * float64(attended) / float64(held), true
+}
+
+// AttendanceMinimum is the attendance a course requires. A zero CourseID is
+// the minimum for courses without one of their own.
+type AttendanceMinimum struct {
+	CourseID int     `json:"course_id,omitempty"`
+	Percent  float64 `json:"percent"`
+}
+
+// Condonation excuses a student's attendance shortage in a course, so they
+// are not debarred from its exam.
+type Condonation struct {
+	StudentID int       `json:"student_id"`
+	CourseID  int       `json:"course_id"`
+	Reason    string    `json:"reason"`
+	By        string    `json:"by"`
+	At        time.Time `json:"at"`
+}
+
+// EnrollmentAttendance sums up a student's attendance in a course against
+// its minimum. Students short of the minimum are debarred from the exam
+// unless their shortage is condoned.
+type EnrollmentAttendance struct {
+	StudentID   int     `json:"student_id"`
+	StudentName string  `json:"student_name"`
+	CourseID    int     `json:"course_id"`
+	CourseName  string  `json:"course_name"`
+	TeacherID   string  `json:"teacher_id"`
+	Attended    int     `json:"attended"`
+	Held        int     `json:"held"`
+	Percentage  float64 `json:"percentage"`
+	Minimum     float64 `json:"minimum"`
+	Shortage    bool    `json:"shortage"`
+	Condoned    bool    `json:"condoned"`
+	Debarred    bool    `json:"debarred"`
+}
+
+// CourseAttendance sums up attendance across a course.
+type CourseAttendance struct {
+	CourseID   int                    `json:"course_id"`
+	Attended   int                    `json:"attended"`
+	Held       int                    `json:"held"`
+	Percentage float64                `json:"percentage"`
+	Minimum    float64                `json:"minimum"`
+	Students   []EnrollmentAttendance `json:"students"`
+}
+
+// MinimumAttendance returns the attendance a course requires.
+func (r *NewRegistrarS) MinimumAttendance(courseID int) float64 {
+	if p, ok := r.minAttendance[courseID]; ok {
+		return p
+	}
+	if p, ok := r.minAttendance[0]; ok {
+		return p
+	}
+	return DefaultMinimumAttendance
+}
+
+// AttendanceMinimums returns the minimums set, the default first.
+func (r *NewRegistrarS) AttendanceMinimums() []AttendanceMinimum {
+	out := make([]AttendanceMinimum, 0, len(r.minAttendance))
+	for id, p := range r.minAttendance {
+		out = append(out, AttendanceMinimum{CourseID: id, Percent: p})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CourseID < out[j].CourseID })
+	return out
+}
+
+// SetMinimumAttendance sets the attendance a course requires, or with a
+// zero CourseID the minimum for every course without its own.
+func (r *NewRegistrarS) SetMinimumAttendance(m AttendanceMinimum) error {
+	if m.Percent < 0 || m.Percent > 100 {
+		return invalidf("minimum attendance must be between 0 and 100, got %g", m.Percent)
+	}
+	if m.CourseID != 0 && !r.hasCourse(m.CourseID) {
+		return notFoundf("course with id %d not found", m.CourseID)
+	}
+	if r.minAttendance == nil {
+		r.minAttendance = map[int]float64{}
+	}
+	r.minAttendance[m.CourseID] = m.Percent
+	return nil
+}
+
+func (r *NewRegistrarS) condonation(studentID, courseID int) (Condonation, bool) {
+	for _, c := range r.condonations {
+		if c.StudentID == studentID && c.CourseID == courseID {
+			return c, true
+		}
+	}
+	return Condonation{}, false
+}
+
+// Condonations returns the shortages condoned for a student, or for every
+// student when studentID is zero.
+func (r *NewRegistrarS) Condonations(studentID int) []Condonation {
+	var out []Condonation
+	for _, c := range r.condonations {
+		if studentID == 0 || c.StudentID == studentID {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// CondoneShortage excuses a student's attendance shortage in a course.
+func (r *NewRegistrarS) CondoneShortage(c Condonation) error {
+	if strings.TrimSpace(c.Reason) == "" {
+		return invalidf("a condonation needs a reason")
+	}
+	if _, ok := r.studentEnrollment(c.StudentID, c.CourseID); !ok {
+		return notFoundf("student %d is not enrolled in course %d", c.StudentID, c.CourseID)
+	}
+	if _, ok := r.condonation(c.StudentID, c.CourseID); ok {
+		return conflictf("the shortage of student %d in course %d is already condoned", c.StudentID, c.CourseID)
+	}
+	r.condonations = append(r.condonations, c)
+	return nil
+}
+
+// sessionDays counts, by day, the sessions attendance records.
+func (r *NewRegistrarS) sessionDays(a Attendance) map[time.Time]int {
+	days := make(map[time.Time]int, len(a.Sessions))
+	for id := range a.Sessions {
+		if s, err := r.Session(id); err == nil {
+			days[s.Date]++
+		}
+	}
+	return days
+}
+
+func (r *NewRegistrarS) enrollmentAttendance(e EnrollNew) EnrollmentAttendance {
+	a := EnrollmentAttendance{
+		StudentID:   e.Student.ID(),
+		StudentName: e.Student.Name(),
+		CourseID:    e.Course.Id,
+		CourseName:  e.Course.Name,
+		TeacherID:   e.Teacher.TID(),
+		Minimum:     r.MinimumAttendance(e.Course.Id),
+	}
+	days := r.sessionDays(e.Attend)
+	a.Attended, a.Held = e.Attend.Counts(days)
+	a.Percentage, _ = e.Attend.Percentage(days)
+	// Nobody is short before any class has been recorded.
+	a.Shortage = a.Held > 0 && a.Percentage < a.Minimum
+	_, a.Condoned = r.condonation(a.StudentID, a.CourseID)
+	a.Debarred = a.Shortage && !a.Condoned
+	return a
+}
+
+// EnrollmentAttendance sums up a student's attendance in a course.
+func (r *NewRegistrarS) EnrollmentAttendance(studentID, courseID int) (EnrollmentAttendance, error) {
+	i, ok := r.studentEnrollment(studentID, courseID)
+	if !ok {
+		return EnrollmentAttendance{}, notFoundf("student %d is not enrolled in course %d", studentID, courseID)
+	}
+	return r.enrollmentAttendance(r.enroll[i]), nil
+}
+
+// CourseAttendance sums up attendance in a course across its students. A
+// teacherID limits it to that teacher's students.
+func (r *NewRegistrarS) CourseAttendance(courseID int, teacherID string) (CourseAttendance, error) {
+	c := CourseAttendance{CourseID: courseID, Minimum: r.MinimumAttendance(courseID), Students: []EnrollmentAttendance{}}
+	for _, e := range r.enroll {
+		if e.Course.Id != courseID || (teacherID != "" && e.Teacher.TID() != teacherID) {
+			continue
+		}
+		a := r.enrollmentAttendance(e)
+		c.Attended += a.Attended
+		c.Held += a.Held
+		c.Students = append(c.Students, a)
+	}
+	if len(c.Students) == 0 {
+		return c, notFoundf("no students enrolled in course %d", courseID)
+	}
+	if c.Held > 0 {
+		c.Percentage = 100 * float64(c.Attended) / float64(c.Held)
+	}
+	return c, nil
+}
+
+// AttendanceShortages lists a teacher's students short of their course's
+// minimum attendance, condoned or not, by course and student.
+func (r *NewRegistrarS) AttendanceShortages(teacherID string) []EnrollmentAttendance {
+	out := []EnrollmentAttendance{}
+	for _, e := range r.enroll {
+		if e.Teacher.TID() != teacherID {
+			continue
+		}
+		if a := r.enrollmentAttendance(e); a.Shortage {
+			out = append(out, a)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CourseID != out[j].CourseID {
+			return out[i].CourseID < out[j].CourseID
+		}
+		return out[i].StudentID < out[j].StudentID
+	})
+	return out
+}
+
+// CheckExamEligibility fails if the student is debarred from the course's
+// exam for an attendance shortage that has not been condoned. Marks cannot
+// be entered for debarred students.
+func (r *NewRegistrarS) CheckExamEligibility(studentID, courseID int) error {
+	a, err := r.EnrollmentAttendance(studentID, courseID)
+	if err != nil {
+		return err
+	}
+	return a.debarment()
+}
+
+func (a EnrollmentAttendance) debarment() error {
+	if a.Debarred {
+		return notEligiblef("student %d is debarred from course %d: attendance %.1f%% is below the minimum of %g%%", a.StudentID, a.CourseID, a.Percentage, a.Minimum)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAttendanceShortage(t *testing.T) {
+	p := NewPortal()
+	teacher := NewTeacher("T1", "Prof. Smith")
+	alice, bob := NewStudent(1, "Alice"), NewStudent(2, "Bob")
+	math := NewCourse(101, "Math")
+	p.Academic.AddCourse(math)
+	p.Academic.AddTeacher(teacher)
+	cc := NewCreditCourse(math, 4)
+	cc.Semester = 1
+	p.Academic.AddTeacherenrollment(NewTeacherEnrollment(teacher, cc))
+	day := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	for st, present := range map[Student][]bool{alice: {true, true, false, true}, bob: {true, false, false, true}} {
+		p.Academic.AddStudent(st)
+		p.Academic.AddEnrollnew(NewEnrollNew(st, math, ScaleGrader{}, 0, Attendance{}, teacher))
+		for i, pr := range present {
+			Giveattendence(p.Academic.NewRegistrarS, 101, st.ID(), "T1", pr, day.AddDate(0, 0, i))
+		}
+	}
+
+	course, err := p.Academic.CourseAttendance(101, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if course.Attended != 5 || course.Held != 8 || course.Percentage != 62.5 || course.Minimum != DefaultMinimumAttendance {
+		t.Errorf("expected 5 of 8 classes attended against 75%%, got %+v", course)
+	}
+	// Alice's 75% meets the minimum; Bob's 50% does not.
+	shortages := p.Academic.AttendanceShortages("T1")
+	if len(shortages) != 1 || shortages[0].StudentID != 2 || shortages[0].Percentage != 50 || !shortages[0].Debarred {
+		t.Fatalf("expected Bob to be debarred, got %+v", shortages)
+	}
+
+	ts := &TeacherService{Registrar: p.Academic, Teacher: teacher}
+	if err := ts.UploadStudentMark(101, 2, 80); !errors.Is(err, ErrNotEligible) {
+		t.Errorf("expected marks for a debarred student to be refused, got %v", err)
+	}
+	if err := p.Academic.CheckExamEligibility(2, 101); !errors.Is(err, ErrNotEligible) {
+		t.Errorf("expected Bob to be ineligible for the exam, got %v", err)
+	}
+	if err := ts.UploadStudentMark(101, 1, 80); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, bad := range []AttendanceMinimum{{Percent: 120}, {CourseID: 999, Percent: 60}} {
+		if err := p.Academic.SetMinimumAttendance(bad); err == nil {
+			t.Errorf("expected %+v to be refused", bad)
+		}
+	}
+	if err := p.Academic.SetMinimumAttendance(AttendanceMinimum{CourseID: 101, Percent: 80}); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Academic.AttendanceShortages("T1"); len(got) != 2 {
+		t.Errorf("expected both students short of 80%%, got %+v", got)
+	}
+
+	if err := p.Academic.CondoneShortage(Condonation{StudentID: 2, CourseID: 101}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected a condonation without a reason to be refused, got %v", err)
+	}
+	condoned := Condonation{StudentID: 2, CourseID: 101, Reason: "hospitalised", By: "admin", At: day.AddDate(0, 1, 0)}
+	if err := p.Academic.CondoneShortage(condoned); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Academic.CondoneShortage(condoned); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected a second condonation to be refused, got %v", err)
+	}
+	if err := ts.UploadStudentMark(101, 2, 80); err != nil {
+		t.Errorf("expected a condoned student to be graded, got %v", err)
+	}
+	if a, _ := p.Academic.EnrollmentAttendance(2, 101); !a.Shortage || !a.Condoned || a.Debarred {
+		t.Errorf("expected Bob's shortage to be condoned, got %+v", a)
+	}
+
+	snap, err := p.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestorePortal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := restored.Academic.MinimumAttendance(101); got != 80 {
+		t.Errorf("expected the course minimum to survive a snapshot, got %g", got)
+	}
+	if got := restored.Academic.Condonations(2); !reflect.DeepEqual(got, []Condonation{condoned}) {
+		t.Errorf("expected the condonation to survive a snapshot, got %+v", got)
+	}
+}
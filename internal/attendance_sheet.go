@@ -0,0 +1,208 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AttendanceSheet is a teacher's attendance in a course laid out as in a
+// spreadsheet: a row per student and a column per session.
+type AttendanceSheet struct {
+	CourseID  int
+	TeacherID string
+	Columns   []SheetColumn
+	Rows      []SheetRow
+	// Errors are the rows that could not be read, left out of Rows.
+	Errors []RowError
+}
+
+// SheetColumn names a session by its date and period. A zero Period stands
+// for the teacher's only session of the course that day.
+type SheetColumn struct {
+	Date   time.Time
+	Period int
+}
+
+func (c SheetColumn) String() string {
+	if c.Period == 0 {
+		return c.Date.Format(time.DateOnly)
+	}
+	return fmt.Sprintf("%s/%d", c.Date.Format(time.DateOnly), c.Period)
+}
+
+// SheetRow is a student's attendance, a status per column. An empty status
+// leaves the session as it was.
+type SheetRow struct {
+	Line        int // in the imported file, for errors
+	StudentID   int
+	StudentName string
+	Statuses    []AttendanceStatus
+}
+
+// RowError explains why a row of an attendance sheet was not imported.
+type RowError struct {
+	Line      int    `json:"line"`
+	StudentID int    `json:"student_id,omitempty"`
+	Error     string `json:"error"`
+}
+
+// AttendanceImport reports what importing an attendance sheet did.
+type AttendanceImport struct {
+	// Scheduled are the sessions added to the timetable for columns that
+	// matched none.
+	Scheduled []*ClassSession `json:"scheduled"`
+	Imported  int             `json:"imported"` // rows
+	Errors    []RowError      `json:"errors"`
+}
+
+// ParseAttendanceStatus reads a status as written in a spreadsheet: its
+// name or first letter, in any case. An empty cell is no status.
+func ParseAttendanceStatus(s string) (AttendanceStatus, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, status := range []AttendanceStatus{StatusPresent, StatusAbsent, StatusLate, StatusExcused} {
+		if s == string(status) || s == string(status[:1]) {
+			return status, nil
+		}
+	}
+	if s == "" {
+		return "", nil
+	}
+	return "", invalidf("unknown attendance status %q; use %s, %s, %s or %s", s, StatusPresent, StatusAbsent, StatusLate, StatusExcused)
+}
+
+// MarkSession records one student's attendance at a session.
+func (r *NewRegistrarS) MarkSession(sessionID, studentID int, status AttendanceStatus) error {
+	s, err := r.Session(sessionID)
+	if err != nil {
+		return err
+	}
+	if !status.valid() {
+		return invalidf("unknown attendance status %q; use %s, %s, %s or %s", status, StatusPresent, StatusAbsent, StatusLate, StatusExcused)
+	}
+	for i, e := range r.enroll {
+		if e.Course.Id == s.CourseID && e.Teacher.TID() == s.TeacherID && e.Student.ID() == studentID {
+			r.markSession(i, s, status)
+			return nil
+		}
+	}
+	return notFoundf("student %d is not in teacher %s's class of course %d", studentID, s.TeacherID, s.CourseID)
+}
+
+// AttendanceSheet lays out a teacher's attendance in a course, with a
+// column per session on their timetable and a row per student by id.
+func (r *NewRegistrarS) AttendanceSheet(courseID int, teacherID string) (AttendanceSheet, error) {
+	if !r.teaches(teacherID, courseID) {
+		return AttendanceSheet{}, notEligiblef("teacher %s does not teach course %d", teacherID, courseID)
+	}
+	sheet := AttendanceSheet{CourseID: courseID, TeacherID: teacherID}
+	sessions := r.Sessions(courseID, teacherID)
+	for _, s := range sessions {
+		sheet.Columns = append(sheet.Columns, SheetColumn{Date: s.Date, Period: s.Period})
+	}
+	for _, e := range r.enroll {
+		if e.Course.Id != courseID || e.Teacher.TID() != teacherID {
+			continue
+		}
+		row := SheetRow{StudentID: e.Student.ID(), StudentName: e.Student.Name(), Statuses: make([]AttendanceStatus, len(sessions))}
+		for i, s := range sessions {
+			row.Statuses[i] = e.Attend.Sessions[s.ID]
+		}
+		sheet.Rows = append(sheet.Rows, row)
+	}
+	sort.Slice(sheet.Rows, func(i, j int) bool { return sheet.Rows[i].StudentID < sheet.Rows[j].StudentID })
+	return sheet, nil
+}
+
+// ImportAttendance records the attendance in a sheet. Rows for students
+// outside the teacher's class, or listed twice, are reported and skipped;
+// the rest are imported. Columns matching no session on the teacher's
+// timetable schedule a lecture, in period 1 unless one is given. Nothing
+// is imported if a column is ambiguous or cannot be scheduled.
+func (r *NewRegistrarS) ImportAttendance(sheet AttendanceSheet) (AttendanceImport, error) {
+	if !r.teaches(sheet.TeacherID, sheet.CourseID) {
+		return AttendanceImport{}, notEligiblef("teacher %s does not teach course %d", sheet.TeacherID, sheet.CourseID)
+	}
+	res := AttendanceImport{Scheduled: []*ClassSession{}, Errors: append([]RowError{}, sheet.Errors...)}
+	class := map[int]bool{}
+	for _, e := range r.enroll {
+		if e.Course.Id == sheet.CourseID && e.Teacher.TID() == sheet.TeacherID {
+			class[e.Student.ID()] = true
+		}
+	}
+	var rows []SheetRow
+	seen := map[int]int{}
+	for _, row := range sheet.Rows {
+		switch {
+		case len(row.Statuses) != len(sheet.Columns):
+			res.Errors = append(res.Errors, RowError{row.Line, row.StudentID, fmt.Sprintf("expected %d statuses, got %d", len(sheet.Columns), len(row.Statuses))})
+		case !class[row.StudentID]:
+			res.Errors = append(res.Errors, RowError{row.Line, row.StudentID, fmt.Sprintf("student %d is not in teacher %s's class of course %d", row.StudentID, sheet.TeacherID, sheet.CourseID)})
+		case seen[row.StudentID] != 0:
+			res.Errors = append(res.Errors, RowError{row.Line, row.StudentID, fmt.Sprintf("student %d is already listed on line %d", row.StudentID, seen[row.StudentID])})
+		default:
+			seen[row.StudentID] = row.Line
+			rows = append(rows, row)
+		}
+	}
+	sort.SliceStable(res.Errors, func(i, j int) bool { return res.Errors[i].Line < res.Errors[j].Line })
+	if len(rows) == 0 {
+		return res, nil
+	}
+
+	scheduled := len(r.sessions)
+	sessions, err := r.sheetSessions(sheet)
+	if err != nil {
+		// Unschedule the sessions added for earlier columns.
+		r.sessions = r.sessions[:scheduled]
+		return AttendanceImport{}, err
+	}
+	res.Scheduled = append(res.Scheduled, r.sessions[scheduled:]...)
+	for _, row := range rows {
+		for i, status := range row.Statuses {
+			if status == "" {
+				continue
+			}
+			if err := r.MarkSession(sessions[i], row.StudentID, status); err != nil {
+				return res, err
+			}
+		}
+		res.Imported++
+	}
+	return res, nil
+}
+
+// sheetSessions returns the session each column of the sheet stands for,
+// scheduling those missing from the timetable.
+func (r *NewRegistrarS) sheetSessions(sheet AttendanceSheet) ([]int, error) {
+	ids := make([]int, len(sheet.Columns))
+	used := map[int]SheetColumn{}
+	for i, col := range sheet.Columns {
+		day := dayOf(col.Date)
+		var match []*ClassSession
+		for _, s := range r.Sessions(sheet.CourseID, sheet.TeacherID) {
+			if s.Date.Equal(day) && (col.Period == 0 || s.Period == col.Period) {
+				match = append(match, s)
+			}
+		}
+		switch len(match) {
+		case 0:
+			period := max(col.Period, 1)
+			s, err := r.ScheduleSession(ClassSession{CourseID: sheet.CourseID, TeacherID: sheet.TeacherID, Date: day, Period: period})
+			if err != nil {
+				return nil, err
+			}
+			ids[i] = s.ID
+		case 1:
+			ids[i] = match[0].ID
+		default:
+			return nil, invalidf("course %d has %d sessions on %s; give the period, as in %s/%d", sheet.CourseID, len(match), col, col, match[0].Period)
+		}
+		if other, ok := used[ids[i]]; ok {
+			return nil, invalidf("columns %s and %s are the same session", other, col)
+		}
+		used[ids[i]] = col
+	}
+	return ids, nil
+}
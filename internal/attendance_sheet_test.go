@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestImportAttendance(t *testing.T) {
+	p := NewPortal()
+	teacher := NewTeacher("T1", "Prof. Smith")
+	math := NewCourse(101, "Math")
+	p.Academic.AddCourse(math)
+	p.Academic.AddTeacher(teacher)
+	p.Academic.AddTeacherenrollment(NewTeacherEnrollment(teacher, NewCreditCourse(math, 4)))
+	for _, st := range []Student{NewStudent(1, "Alice"), NewStudent(2, "Bob")} {
+		p.Academic.AddStudent(st)
+		p.Academic.AddEnrollnew(NewEnrollNew(st, math, ScaleGrader{}, 0, Attendance{}, teacher))
+	}
+	day := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	lab, err := p.Academic.ScheduleSession(ClassSession{CourseID: 101, TeacherID: "T1", Date: day, Period: 3, Type: SessionLab})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first column is the lab already on the timetable; the second
+	// schedules a lecture.
+	sheet := AttendanceSheet{CourseID: 101, TeacherID: "T1",
+		Columns: []SheetColumn{{Date: day}, {Date: day.AddDate(0, 0, 1)}},
+		Rows: []SheetRow{
+			{Line: 2, StudentID: 1, Statuses: []AttendanceStatus{StatusPresent, StatusLate}},
+			{Line: 3, StudentID: 9, Statuses: []AttendanceStatus{StatusPresent, StatusPresent}},
+			{Line: 4, StudentID: 2, Statuses: []AttendanceStatus{StatusAbsent, ""}},
+			{Line: 5, StudentID: 1, Statuses: []AttendanceStatus{StatusAbsent, StatusAbsent}},
+		},
+		Errors: []RowError{{Line: 6, Error: "invalid student id \"x\""}},
+	}
+	res, err := p.Academic.ImportAttendance(sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Imported != 2 || len(res.Scheduled) != 1 || res.Scheduled[0].Period != 1 || res.Scheduled[0].Type != SessionLecture {
+		t.Errorf("expected 2 rows imported and a lecture scheduled, got %+v", res)
+	}
+	var lines []int
+	for _, e := range res.Errors {
+		lines = append(lines, e.Line)
+	}
+	if !reflect.DeepEqual(lines, []int{3, 5, 6}) {
+		t.Errorf("expected lines 3, 5 and 6 to be reported, got %+v", res.Errors)
+	}
+
+	out, err := p.Academic.AttendanceSheet(101, "T1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SheetRow{
+		{StudentID: 1, StudentName: "Alice", Statuses: []AttendanceStatus{StatusPresent, StatusLate}},
+		{StudentID: 2, StudentName: "Bob", Statuses: []AttendanceStatus{StatusAbsent, ""}},
+	}
+	if !reflect.DeepEqual(out.Rows, want) || len(out.Columns) != 2 || out.Columns[0].Period != lab.Period {
+		t.Errorf("expected the imported grid back, got %+v", out)
+	}
+
+	// A second session that day makes a column without a period ambiguous,
+	// and nothing is imported or scheduled.
+	if _, err := p.Academic.ScheduleSession(ClassSession{CourseID: 101, TeacherID: "T1", Date: day, Period: 5}); err != nil {
+		t.Fatal(err)
+	}
+	sessions := len(p.Academic.Sessions(101, "T1"))
+	sheet = AttendanceSheet{CourseID: 101, TeacherID: "T1",
+		Columns: []SheetColumn{{Date: day.AddDate(0, 0, 7)}, {Date: day}},
+		Rows:    []SheetRow{{Line: 2, StudentID: 2, Statuses: []AttendanceStatus{StatusPresent, StatusPresent}}},
+	}
+	if _, err := p.Academic.ImportAttendance(sheet); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected an ambiguous column to be refused, got %v", err)
+	}
+	if got := len(p.Academic.Sessions(101, "T1")); got != sessions {
+		t.Errorf("expected no sessions to be scheduled, got %d more", got-sessions)
+	}
+	if _, err := p.Academic.ImportAttendance(AttendanceSheet{CourseID: 101, TeacherID: "T2"}); !errors.Is(err, ErrNotEligible) {
+		t.Errorf("expected another teacher's import to be refused, got %v", err)
+	}
+}
+
+func TestParseAttendanceStatus(t *testing.T) {
+	for in, want := range map[string]AttendanceStatus{"P": StatusPresent, " late ": StatusLate, "e": StatusExcused, "Absent": StatusAbsent, "": ""} {
+		if got, err := ParseAttendanceStatus(in); err != nil || got != want {
+			t.Errorf("ParseAttendanceStatus(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseAttendanceStatus("x"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected an unknown status to be refused, got %v", err)
+	}
+}
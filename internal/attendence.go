@@ -5,7 +5,9 @@ import (
 )
 
 type Attendance struct {
-	Records map[time.Time]bool
+	Records  map[time.Time]bool
+	Sessions map[int]AttendanceStatus // by ClassSession ID
+	Excused  map[time.Time]bool       // absences in Records excused on leave
 }
 
 // function to give attendence
@@ -20,10 +22,13 @@ func MarkAttendance(attendance *Attendance, date time.Time, present bool) {
 // This function will be called by the teacher to mark attendance for a student in a course.
 // need to check time data type parameter passing
 func Giveattendence(r *NewRegistrarS, courseID int, studentID int, TeacherID string, attendence bool, time time.Time) bool {
-	for _, e := range r.enroll {
+	for i, e := range r.enroll {
 		if e.Course.Id == courseID && e.Student.ID() == studentID && e.Teacher.ID == TeacherID {
 			//r.enroll[i].Attendence = attendence
-			MarkAttendance(&e.Attend, time, attendence)
+			MarkAttendance(&r.enroll[i].Attend, time, attendence)
+			if l := r.approvedLeave(studentID, courseID, time); l != nil && !attendence {
+				r.excuse(l, i, 0, time)
+			}
 			return true
 		}
 	}
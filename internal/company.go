@@ -1,6 +1,6 @@
 package internal
 
-var newID = SeqID()
+var companyIDs idSequence
 
 type Company struct {
 	id     int
@@ -9,7 +9,7 @@ type Company struct {
 }
 
 func NewCompany(name string) *Company {
-	return &Company{id: newID(), name: name, drives: make([]*Drive, 0)}
+	return &Company{id: companyIDs.next(), name: name, drives: make([]*Drive, 0)}
 
 }
 
@@ -7,7 +7,8 @@ type Course struct {
 
 type CreditCourse struct {
 	Course
-	Credits int
+	Credits  int
+	Semester int // when the course is taken; zero if not set
 }
 
 func NewCreditCourse(c Course, credits int) CreditCourse {
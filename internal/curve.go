@@ -0,0 +1,297 @@
+package internal
+
+import (
+	"math"
+	"sort"
+)
+
+// CurveMethod is how a curve places students relative to their class.
+type CurveMethod string
+
+const (
+	// CurveStdDev gives each letter to scores at least Z standard
+	// deviations from the class mean.
+	CurveStdDev CurveMethod = "stddev"
+	// CurvePercentile gives each letter to scores whose percentile rank, the
+	// share of the class scoring strictly lower, is at least Percentile.
+	CurvePercentile CurveMethod = "percentile"
+)
+
+// CurveBand is the threshold for one letter of a curve. The last band takes
+// everyone below the others, so its threshold is ignored.
+type CurveBand struct {
+	Letter     AlphabeticGrade `json:"letter"`
+	Z          float64         `json:"z,omitempty"`
+	Percentile float64         `json:"percentile,omitempty"`
+}
+
+// Curve grades a course relative to its class rather than on fixed score
+// ranges.
+type Curve struct {
+	Method CurveMethod `json:"method"`
+	Bands  []CurveBand `json:"bands"` // best grade first
+}
+
+// DefaultCurve returns a curve over the DefaultGradeScale: for standard
+// deviations, O from one and a half above the mean down to C from one below
+// it; for percentiles, the top 10% get an O and the bottom 10% an F.
+func DefaultCurve(method CurveMethod) Curve {
+	if method == CurvePercentile {
+		return Curve{Method: method, Bands: []CurveBand{
+			{Letter: O, Percentile: 90}, {Letter: Aplus, Percentile: 75}, {Letter: A, Percentile: 55},
+			{Letter: Bplus, Percentile: 35}, {Letter: B, Percentile: 20}, {Letter: C, Percentile: 10}, {Letter: F},
+		}}
+	}
+	return Curve{Method: CurveStdDev, Bands: []CurveBand{
+		{Letter: O, Z: 1.5}, {Letter: Aplus, Z: 1}, {Letter: A, Z: 0.5},
+		{Letter: Bplus, Z: 0}, {Letter: B, Z: -0.5}, {Letter: C, Z: -1}, {Letter: F},
+	}}
+}
+
+// Validate checks c's letters are distinct and on scale, and that its
+// thresholds fall from the best band to the worst.
+func (c Curve) Validate(scale *GradeScale) error {
+	if c.Method != CurveStdDev && c.Method != CurvePercentile {
+		return invalidf("unknown curve method %q; use %s or %s", c.Method, CurveStdDev, CurvePercentile)
+	}
+	if len(c.Bands) == 0 {
+		return invalidf("curve has no grades")
+	}
+	seen := map[AlphabeticGrade]bool{}
+	for i, b := range c.Bands {
+		if _, err := scale.ParseGrade(string(b.Letter)); err != nil {
+			return err
+		}
+		if seen[b.Letter] {
+			return invalidf("curve lists %s twice", b.Letter)
+		}
+		seen[b.Letter] = true
+		if c.Method == CurvePercentile && (b.Percentile < 0 || b.Percentile > 100) {
+			return invalidf("curve: percentile of %s must be between 0 and 100", b.Letter)
+		}
+		if i == 0 || i == len(c.Bands)-1 {
+			continue
+		}
+		if prev := c.Bands[i-1]; c.threshold(b) >= c.threshold(prev) {
+			return invalidf("curve: %s needs a lower threshold than %s above it", b.Letter, prev.Letter)
+		}
+	}
+	return nil
+}
+
+func (c Curve) threshold(b CurveBand) float64 {
+	if c.Method == CurvePercentile {
+		return b.Percentile
+	}
+	return b.Z
+}
+
+// CurveCutoff is the lowest score that earned a letter once a curve was
+// worked out for a class.
+type CurveCutoff struct {
+	Letter   AlphabeticGrade `json:"letter"`
+	MinScore float64         `json:"min_score"`
+}
+
+// CurveGrader grades on the cut-offs a curve gave a course. Until a curve is
+// applied it has none and leaves marks ungraded.
+type CurveGrader struct {
+	Cutoffs []CurveCutoff // best grade first
+}
+
+func (g CurveGrader) letter(score float64) AlphabeticGrade {
+	for _, c := range g.Cutoffs {
+		if score >= c.MinScore {
+			return c.Letter
+		}
+	}
+	if len(g.Cutoffs) == 0 {
+		return ""
+	}
+	return g.Cutoffs[len(g.Cutoffs)-1].Letter
+}
+
+func (g CurveGrader) Grade(e Enrollment) (string, error) {
+	return string(g.letter(e.score)), nil
+}
+
+func (g CurveGrader) LetterGrade(e Enrollment, scale *GradeScale) (AlphabeticGrade, error) {
+	letter := g.letter(e.score)
+	if letter == "" {
+		return "", nil
+	}
+	return scale.ParseGrade(string(letter))
+}
+
+// CurvePreview shows what a curve does to a class: the scores each letter
+// starts at and how many students get it. Students without marks yet, and
+// those debarred for an attendance shortage, are only counted.
+type CurvePreview struct {
+	CourseID  int                     `json:"course_id"`
+	TeacherID string                  `json:"teacher_id,omitempty"` // empty for the whole course
+	Method    CurveMethod             `json:"method"`
+	Students  int                     `json:"students"`
+	Unmarked  int                     `json:"unmarked"`
+	Debarred  int                     `json:"debarred"`
+	Mean      float64                 `json:"mean"`
+	StdDev    float64                 `json:"std_dev"`
+	Cutoffs   []CurveCutoff           `json:"cutoffs"`
+	Counts    map[AlphabeticGrade]int `json:"counts"`
+	Committed bool                    `json:"committed"`
+}
+
+// workOut turns the curve into score cut-offs for the given class scores.
+// Bands a lower band's cut-off already covers are left out, and so are the
+// bands below one whose cut-off falls to zero; otherwise the last band takes
+// every score from zero.
+func (c Curve) workOut(scores []float64) (cutoffs []CurveCutoff, mean, sd float64) {
+	n := float64(len(scores))
+	for _, s := range scores {
+		mean += s
+	}
+	mean /= n
+	for _, s := range scores {
+		sd += (s - mean) * (s - mean)
+	}
+	sd = math.Sqrt(sd / n)
+
+	sorted := append([]float64(nil), scores...)
+	sort.Float64s(sorted)
+	last := len(c.Bands) - 1
+	for _, b := range c.Bands[:last] {
+		cut := mean + b.Z*sd
+		if c.Method == CurvePercentile {
+			// The lowest score with at least b.Percentile of the class
+			// strictly below it.
+			cut = math.Inf(1)
+			for _, s := range sorted {
+				if below := sort.SearchFloat64s(sorted, s); float64(below)*100/n >= b.Percentile {
+					cut = s
+					break
+				}
+			}
+		}
+		if math.IsInf(cut, 1) || (len(cutoffs) > 0 && cut >= cutoffs[len(cutoffs)-1].MinScore) {
+			continue
+		}
+		if cut <= 0 {
+			return append(cutoffs, CurveCutoff{Letter: b.Letter, MinScore: 0}), mean, sd
+		}
+		cutoffs = append(cutoffs, CurveCutoff{Letter: b.Letter, MinScore: cut})
+	}
+	cutoffs = append(cutoffs, CurveCutoff{Letter: c.Bands[last].Letter, MinScore: 0})
+	return cutoffs, mean, sd
+}
+
+// classEnrollments returns the positions of the enrollments in a teacher's
+// class of a course, or with an empty teacherID of every enrollment in the
+// course, whoever teaches it.
+func (r *NewRegistrarS) classEnrollments(courseID int, teacherID string) []int {
+	var out []int
+	for i, e := range r.enroll {
+		if e.Course.Id == courseID && (teacherID == "" || e.Teacher.TID() == teacherID) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// curvedEnrollments returns the positions of the enrollments in a class a
+// curve grades: those with marks whose students are not debarred from the
+// exam. p counts the ones left out.
+func (r *NewRegistrarS) curvedEnrollments(courseID int, teacherID string, p *CurvePreview) ([]int, error) {
+	all := r.classEnrollments(courseID, teacherID)
+	if len(all) == 0 {
+		if teacherID != "" {
+			return nil, notFoundf("teacher %s has no students in course %d", teacherID, courseID)
+		}
+		return nil, notFoundf("no students enrolled in course %d", courseID)
+	}
+	var positions []int
+	for _, pos := range all {
+		switch e := r.enroll[pos]; {
+		case !e.marked:
+			p.Unmarked++
+		case r.enrollmentAttendance(e).debarment() != nil:
+			p.Debarred++
+		default:
+			positions = append(positions, pos)
+		}
+	}
+	if len(positions) == 0 {
+		return nil, conflictf("no marks have been uploaded for course %d that can be graded", courseID)
+	}
+	return positions, nil
+}
+
+// PreviewCurve works out the cut-offs curve gives a teacher's class of a
+// course from the scores of every student in it with marks who may sit its
+// exam, and how many students each letter goes to, without changing
+// anything. An empty teacherID takes the whole course as the class.
+func (r *NewRegistrarS) PreviewCurve(courseID int, teacherID string, curve Curve) (CurvePreview, error) {
+	if err := curve.Validate(r.GradeScale()); err != nil {
+		return CurvePreview{}, err
+	}
+	p := CurvePreview{CourseID: courseID, TeacherID: teacherID, Method: curve.Method, Counts: map[AlphabeticGrade]int{}}
+	positions, err := r.curvedEnrollments(courseID, teacherID, &p)
+	if err != nil {
+		return CurvePreview{}, err
+	}
+	scores := make([]float64, len(positions))
+	for i, pos := range positions {
+		scores[i] = r.enroll[pos].score
+	}
+	p.Students = len(scores)
+	p.Cutoffs, p.Mean, p.StdDev = curve.workOut(scores)
+	g := CurveGrader{Cutoffs: p.Cutoffs}
+	for _, s := range scores {
+		p.Counts[g.letter(s)]++
+	}
+	return p, nil
+}
+
+// ApplyCurve grades a teacher's class of a course, or with an empty
+// teacherID the whole course, on curve: every enrollment in it is given a
+// CurveGrader with the cut-offs PreviewCurve shows, and the letters of those
+// with marks go on the students' transcripts. Students debarred for an
+// attendance shortage are not graded. Marks uploaded later are graded on the
+// same cut-offs; apply the curve again to rework them from the whole class.
+func (r *NewRegistrarS) ApplyCurve(courseID int, teacherID string, curve Curve) (CurvePreview, error) {
+	p, err := r.PreviewCurve(courseID, teacherID, curve)
+	if err != nil {
+		return p, err
+	}
+	g := CurveGrader{Cutoffs: p.Cutoffs}
+	positions, err := r.curvedEnrollments(courseID, teacherID, &CurvePreview{})
+	if err != nil {
+		return p, err
+	}
+	results := make([]CourseResult, len(positions))
+	for i, pos := range positions {
+		e := r.enroll[pos]
+		e.Grader = g
+		if results[i], _, err = r.courseResult(e); err != nil {
+			return p, err
+		}
+	}
+	for _, pos := range r.classEnrollments(courseID, teacherID) {
+		r.enroll[pos].Grader = g
+	}
+	for _, cr := range results {
+		r.recordResult(cr)
+	}
+	p.Committed = true
+	return p, nil
+}
+
+// PreviewCurve shows the teacher what curve would do to their class of a
+// course.
+func (ts *TeacherService) PreviewCurve(courseID int, curve Curve) (CurvePreview, error) {
+	return ts.Registrar.PreviewCurve(courseID, ts.Teacher.TID(), curve)
+}
+
+// ApplyCurve grades the teacher's class of a course on curve. Other
+// teachers' students in the course are left alone.
+func (ts *TeacherService) ApplyCurve(courseID int, curve Curve) (CurvePreview, error) {
+	return ts.Registrar.ApplyCurve(courseID, ts.Teacher.TID(), curve)
+}
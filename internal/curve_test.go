@@ -0,0 +1,221 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// curvedCourse enrolls ten students scoring 10, 20, ... 100 in Math, five
+// with each of two teachers.
+func curvedCourse(t *testing.T) (*Portal, *TeacherService) {
+	t.Helper()
+	p := NewPortal()
+	smith, jones := NewTeacher("T1", "Prof. Smith"), NewTeacher("T2", "Prof. Jones")
+	math := NewCourse(101, "Math")
+	cc := NewCreditCourse(math, 4)
+	cc.Semester = 3
+	for _, teacher := range []Teacher{smith, jones} {
+		p.Academic.AddTeacher(teacher)
+		p.Academic.AddTeacherenrollment(NewTeacherEnrollment(teacher, cc))
+	}
+	for i := 1; i <= 10; i++ {
+		st := NewStudent(i, fmt.Sprintf("Student%d", i))
+		teacher := smith
+		if i%2 == 0 {
+			teacher = jones
+		}
+		p.Academic.AddStudent(st)
+		p.Academic.AddEnrollnew(NewEnrollNew(st, math, CurveGrader{}, float64(i*10), Attendance{}, teacher))
+	}
+	return p, &TeacherService{Registrar: p.Academic, Teacher: smith}
+}
+
+func TestPreviewCurve(t *testing.T) {
+	p, ts := curvedCourse(t)
+	for _, tc := range []struct {
+		method CurveMethod
+		want   map[AlphabeticGrade]int
+	}{
+		{CurveStdDev, map[AlphabeticGrade]int{O: 1, Aplus: 1, A: 2, Bplus: 1, B: 1, C: 2, F: 2}},
+		{CurvePercentile, map[AlphabeticGrade]int{O: 1, Aplus: 1, A: 2, Bplus: 2, B: 2, C: 1, F: 1}},
+	} {
+		preview, err := p.Academic.PreviewCurve(101, "", DefaultCurve(tc.method))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if preview.Students != 10 || preview.Mean != 55 || !reflect.DeepEqual(preview.Counts, tc.want) {
+			t.Errorf("%s: expected counts %v over 10 students, got %+v", tc.method, tc.want, preview)
+		}
+		if preview.Committed {
+			t.Errorf("%s: a preview must not be committed", tc.method)
+		}
+	}
+	if grade, _ := p.Academic.grade(p.Academic.enroll[9].Enrollment); grade != "" {
+		t.Errorf("expected a preview to leave marks ungraded, got %q", grade)
+	}
+	if len(p.Academic.CourseResults(10)) != 0 {
+		t.Error("expected a preview to leave transcripts alone")
+	}
+
+	bad := []Curve{
+		{Method: "bell", Bands: DefaultCurve(CurveStdDev).Bands},
+		{Method: CurveStdDev, Bands: []CurveBand{{Letter: O, Z: 0}, {Letter: A, Z: 1}, {Letter: F}}},
+		{Method: CurvePercentile, Bands: []CurveBand{{Letter: "S", Percentile: 50}, {Letter: F}}},
+		{Method: CurvePercentile, Bands: []CurveBand{{Letter: O, Percentile: 150}, {Letter: F}}},
+	}
+	for _, c := range bad {
+		if _, err := ts.PreviewCurve(101, c); !errors.Is(err, ErrInvalid) {
+			t.Errorf("expected %+v to be refused, got %v", c, err)
+		}
+	}
+	other := &TeacherService{Registrar: p.Academic, Teacher: NewTeacher("T3", "Prof. Brown")}
+	if _, err := other.PreviewCurve(101, DefaultCurve(CurveStdDev)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a teacher without students in the course to be refused, got %v", err)
+	}
+}
+
+func TestApplyCurve(t *testing.T) {
+	p, _ := curvedCourse(t)
+	curve := Curve{Method: CurvePercentile, Bands: []CurveBand{{Letter: O, Percentile: 80}, {Letter: B, Percentile: 30}, {Letter: F}}}
+	preview, err := p.Academic.ApplyCurve(101, "", curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CurveCutoff{{Letter: O, MinScore: 90}, {Letter: B, MinScore: 40}, {Letter: F, MinScore: 0}}
+	if !preview.Committed || !reflect.DeepEqual(preview.Cutoffs, want) {
+		t.Errorf("expected cut-offs %v, got %+v", want, preview)
+	}
+	// Both teachers' students are graded on the class curve.
+	for id, grade := range map[int]AlphabeticGrade{10: O, 9: O, 4: B, 3: F} {
+		results := p.Academic.CourseResults(id)
+		if len(results) != 1 || results[0].Grade != grade || results[0].Semester != 3 {
+			t.Errorf("student %d: expected %s in semester 3, got %+v", id, grade, results)
+		}
+	}
+
+	// Later marks are graded on the committed cut-offs.
+	jones := &TeacherService{Registrar: p.Academic, Teacher: NewTeacher("T2", "Prof. Jones")}
+	if err := jones.UploadStudentMark(101, 2, 95); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Academic.CourseResults(2); got[0].Grade != O {
+		t.Errorf("expected 95 to be an O on the committed curve, got %s", got[0].Grade)
+	}
+
+	snap, err := p.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestorePortal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, ok := restored.Academic.enroll[0].Grader.(CurveGrader); !ok || !reflect.DeepEqual(g.Cutoffs, want) {
+		t.Errorf("expected the curve to survive a snapshot, got %#v", restored.Academic.enroll[0].Grader)
+	}
+}
+
+func TestApplyCurve_TeacherClass(t *testing.T) {
+	p, ts := curvedCourse(t)
+	preview, err := ts.ApplyCurve(101, DefaultCurve(CurvePercentile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.TeacherID != "T1" || preview.Students != 5 || preview.Mean != 50 {
+		t.Errorf("expected the curve over Prof. Smith's five students, got %+v", preview)
+	}
+	for id := 1; id <= 10; id++ {
+		graded := len(p.Academic.CourseResults(id)) == 1
+		if _, curved := p.Academic.enroll[id-1].Grader.(CurveGrader); graded != (id%2 == 1) || !curved {
+			t.Errorf("student %d: expected only Prof. Smith's students graded, got graded %v", id, graded)
+		}
+	}
+	if got := p.Academic.enroll[1].Grader.(CurveGrader); len(got.Cutoffs) != 0 {
+		t.Errorf("expected Prof. Jones's students left off the curve, got %+v", got)
+	}
+
+	ps := NewPortalService(p, DefaultPolicy())
+	smith := Principal{Role: RoleTeacher, TeacherID: "T1"}
+	if _, err := ps.ApplyCurve(smith, "", 101, DefaultCurve(CurveStdDev)); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected a teacher to be refused a curve over the whole course, got %v", err)
+	}
+	if preview, err := ps.ApplyCurve(Principal{Role: RoleAdmin}, "", 101, DefaultCurve(CurveStdDev)); err != nil || preview.Students != 10 {
+		t.Errorf("expected an admin to curve the whole course, got %+v, %v", preview, err)
+	}
+}
+
+func TestApplyCurve_SkipsUnmarked(t *testing.T) {
+	p, ts := curvedCourse(t)
+	late := NewStudent(11, "Student11")
+	p.Academic.AddStudent(late)
+	p.Academic.AddEnrollnew(NewEnrollNew(late, NewCourse(101, "Math"), CurveGrader{}, 0, Attendance{}, ts.Teacher))
+
+	preview, err := p.Academic.ApplyCurve(101, "", DefaultCurve(CurveStdDev))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Students != 10 || preview.Unmarked != 1 || preview.Mean != 55 {
+		t.Errorf("expected the unmarked student left out of the class, got %+v", preview)
+	}
+	if results := p.Academic.CourseResults(11); len(results) != 0 {
+		t.Errorf("an unmarked student should not be graded, got %+v", results)
+	}
+
+	// Their mark, once uploaded, is graded on the committed curve.
+	if err := ts.UploadStudentMark(101, 11, 0); err != nil {
+		t.Fatal(err)
+	}
+	if results := p.Academic.CourseResults(11); len(results) != 1 || results[0].Grade != F {
+		t.Errorf("expected an uploaded zero to be graded, got %+v", results)
+	}
+	if preview, err := p.Academic.PreviewCurve(101, "", DefaultCurve(CurveStdDev)); err != nil || preview.Students != 11 || preview.Unmarked != 0 {
+		t.Errorf("expected an uploaded zero to count as a mark, got %+v, %v", preview, err)
+	}
+}
+
+func TestApplyCurve_SkipsDebarred(t *testing.T) {
+	p, _ := curvedCourse(t)
+	day := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	for i, present := range []bool{true, false, false, false} {
+		Giveattendence(p.Academic.NewRegistrarS, 101, 3, "T1", present, day.AddDate(0, 0, i))
+	}
+
+	preview, err := p.Academic.ApplyCurve(101, "", DefaultCurve(CurveStdDev))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Students != 9 || preview.Debarred != 1 {
+		t.Errorf("expected the debarred student left out of the class, got %+v", preview)
+	}
+	if results := p.Academic.CourseResults(3); len(results) != 0 {
+		t.Errorf("a debarred student should not be graded on the curve, got %+v", results)
+	}
+
+	if err := p.Academic.CondoneShortage(Condonation{StudentID: 3, CourseID: 101, Reason: "medical"}); err != nil {
+		t.Fatal(err)
+	}
+	if preview, err = p.Academic.ApplyCurve(101, "", DefaultCurve(CurveStdDev)); err != nil || preview.Students != 10 || preview.Debarred != 0 {
+		t.Errorf("expected a condoned student back on the curve, got %+v, %v", preview, err)
+	}
+	if results := p.Academic.CourseResults(3); len(results) != 1 {
+		t.Errorf("expected the condoned student graded, got %+v", results)
+	}
+}
+
+func TestCurve_WorkOutFloor(t *testing.T) {
+	// With scores of 0, 0 and 90 the C band, a standard deviation below the
+	// mean, would start below zero, so it takes everyone the bands above it
+	// leave and nobody fails.
+	curve := Curve{Method: CurveStdDev, Bands: []CurveBand{{Letter: O, Z: 1}, {Letter: C, Z: -1}, {Letter: F}}}
+	cutoffs, _, _ := curve.workOut([]float64{0, 0, 90})
+	if len(cutoffs) != 2 || cutoffs[0].Letter != O || cutoffs[1] != (CurveCutoff{Letter: C, MinScore: 0}) {
+		t.Errorf("expected O then C from zero, got %+v", cutoffs)
+	}
+	cutoffs, _, _ = DefaultCurve(CurveStdDev).workOut([]float64{40, 50, 60})
+	if last := cutoffs[len(cutoffs)-1]; last != (CurveCutoff{Letter: F, MinScore: 0}) {
+		t.Errorf("expected F to start at zero, got %+v", cutoffs)
+	}
+}
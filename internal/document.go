@@ -3,9 +3,9 @@ package internal
 import "time"
 
 type Document struct {
-	Title      string
-	Filename   string // e.g. "assignment.pdf"
-	Content    []byte // the raw file data
-	MimeType   string // e.g. "application/pdf"
-	UploadedAt time.Time
+	Title      string    `json:"title"`
+	Filename   string    `json:"filename"`  // e.g. "assignment.pdf"
+	Content    []byte    `json:"content"`   // the raw file data
+	MimeType   string    `json:"mime_type"` // e.g. "application/pdf"
+	UploadedAt time.Time `json:"uploaded_at"`
 }
@@ -1,7 +1,6 @@
 package internal
 
 import (
-	"errors"
 	"fmt"
 	"time"
 )
@@ -41,5 +40,5 @@ func (ts *TeacherService) UploadFile(
 		}
 	}
 
-	return errors.New("no valid enrollment found for this teacher, student, and course")
+	return notFoundf("no valid enrollment found for this teacher, student, and course")
 }
@@ -6,7 +6,7 @@ import (
 )
 
 // Automatically generated ID generator
-var nextID = SeqID()
+var driveIDs idSequence
 
 // Enum for job category
 type JobCategory int
@@ -31,15 +31,16 @@ func (jc JobCategory) String() string {
 	return JobCategoryStringMap[jc]
 }
 
-// Eligibility struct
-type Eligibility struct {
-	requirement float64
+// ParseJobCategory is the inverse of JobCategory.String.
+func ParseJobCategory(s string) (JobCategory, error) {
+	for jc, name := range JobCategoryStringMap {
+		if name == s {
+			return jc, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid job category %q", s)
 }
 
-// func (el Eligibility) checkEligibility(applicant *Applicant) bool {
-// 	panic("unimplemented")
-// }
-
 // Drive struct
 type Drive struct {
 	id           int
@@ -50,29 +51,17 @@ type Drive struct {
 	ctc          int
 	jobCategory  JobCategory
 	applications []*Application
+	rounds       []Round
+
+	announcedAt        time.Time
+	resultsPublishedAt time.Time
+	archivedAt         time.Time
 }
 
 // --- Constructor Functions ---
 
-func NewEligibility(minimumGPA float64) *Eligibility {
-	return &Eligibility{requirement: minimumGPA}
-}
-
 func NewDrive(startDate time.Time, endDate time.Time, roleName string, minimumGPA float64, ctc int, jobCategory JobCategory) *Drive {
-	return &Drive{id: nextID(), startDate: startDate, endDate: endDate, roleName: roleName, eligibility: *NewEligibility(minimumGPA), ctc: ctc, jobCategory: jobCategory}
-}
-
-// Elegibility setters and Getters
-func (el *Eligibility) Requirement() float64 {
-	return el.requirement
-}
-
-func (el *Eligibility) ChangeRequirement(newReq float64) {
-	el.requirement = newReq
-}
-
-func (el *Eligibility) CheckEligibility(applicant *Applicant) bool {
-	return applicant.CGPA >= el.requirement
+	return &Drive{id: driveIDs.next(), startDate: startDate, endDate: endDate, roleName: roleName, eligibility: *NewEligibility(minimumGPA), ctc: ctc, jobCategory: jobCategory}
 }
 
 // --- Drive Getters ---
@@ -127,6 +116,11 @@ func (dr *Drive) SetEligibility(minimumGPA float64) {
 	dr.eligibility.ChangeRequirement(minimumGPA)
 }
 
+// AddCriteria adds eligibility criteria beyond the minimum CGPA.
+func (dr *Drive) AddCriteria(criteria ...Criterion) {
+	dr.eligibility.AddCriteria(criteria...)
+}
+
 func (dr *Drive) SetCTC(ctc int) {
 	dr.ctc = ctc
 }
@@ -158,7 +152,7 @@ func (dr *Drive) GetApplicationByID(id int) (*Application, error) {
 			return e, nil
 		}
 	}
-	return nil, fmt.Errorf("no such application for given id")
+	return nil, notFoundf("no such application for given id")
 }
 
 func (dr *Drive) getSelectedApplications() []*Application {
@@ -180,12 +174,3 @@ func (dr *Drive) getShortlistedApplications() []*Application {
 	}
 	return arr
 }
-
-// Elegibility functions
-func (el *Eligibility) checkEligibility(applicant *Applicant) bool {
-	if el.requirement >= applicant.CGPA {
-		return false
-	} else {
-		return true
-	}
-}
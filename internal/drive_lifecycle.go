@@ -0,0 +1,186 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+)
+
+// DriveState is where a drive is in its lifecycle.
+type DriveState int
+
+const (
+	// DriveDraft drives have not opened for applications yet.
+	DriveDraft DriveState = iota
+	// DriveOpen drives accept applications.
+	DriveOpen
+	// DriveClosed drives no longer accept applications but are still
+	// selecting candidates.
+	DriveClosed
+	// DriveResultsPublished drives have announced their results; application
+	// statuses are final.
+	DriveResultsPublished
+	// DriveArchived drives are kept for reports only.
+	DriveArchived
+)
+
+var driveStateNames = map[DriveState]string{
+	DriveDraft:            "draft",
+	DriveOpen:             "open",
+	DriveClosed:           "closed",
+	DriveResultsPublished: "results published",
+	DriveArchived:         "archived",
+}
+
+func (s DriveState) String() string {
+	if name, ok := driveStateNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("DriveState(%d)", int(s))
+}
+
+// ParseDriveState is the inverse of DriveState.String.
+func ParseDriveState(s string) (DriveState, error) {
+	for st, name := range driveStateNames {
+		if name == s {
+			return st, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid drive state %q", s)
+}
+
+func (dr Drive) ResultsPublishedAt() time.Time {
+	return dr.resultsPublishedAt
+}
+
+func (dr Drive) ArchivedAt() time.Time {
+	return dr.archivedAt
+}
+
+// ApplicationsCloseAt returns when the application window ends: the end of
+// the day of EndDate, since EndDate is the last day applications are taken.
+// It is zero when the drive has no end date.
+func (dr Drive) ApplicationsCloseAt() time.Time {
+	if dr.endDate.IsZero() {
+		return time.Time{}
+	}
+	y, m, d := dr.endDate.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, dr.endDate.Location())
+}
+
+// State returns the drive's state at now. Publishing results and archiving
+// are explicit steps; before that the state follows the application window,
+// which a missing start or end date leaves unbounded on that side.
+func (dr Drive) State(now time.Time) DriveState {
+	switch {
+	case !dr.archivedAt.IsZero():
+		return DriveArchived
+	case !dr.resultsPublishedAt.IsZero():
+		return DriveResultsPublished
+	case !dr.startDate.IsZero() && now.Before(dr.startDate):
+		return DriveDraft
+	case !dr.endDate.IsZero() && !now.Before(dr.ApplicationsCloseAt()):
+		return DriveClosed
+	}
+	return DriveOpen
+}
+
+// SetClock replaces the clock the registrar reads the current time from,
+// for application windows, status history and offers. A nil clock restores
+// time.Now.
+func (pr *PlacementRegistrar) SetClock(now func() time.Time) {
+	pr.now = now
+}
+
+// Now returns the current time according to the registrar's clock.
+func (pr *PlacementRegistrar) Now() time.Time {
+	if pr.now == nil {
+		return time.Now()
+	}
+	return pr.now()
+}
+
+// DriveState returns the state of a drive now.
+func (pr *PlacementRegistrar) DriveState(drive *Drive) DriveState {
+	return drive.State(pr.Now())
+}
+
+// checkAcceptingApplications fails unless drive is open for applications.
+func (pr *PlacementRegistrar) checkAcceptingApplications(drive *Drive) error {
+	switch state := pr.DriveState(drive); state {
+	case DriveOpen:
+		return nil
+	case DriveDraft:
+		return conflictf("drive %d opens for applications on %s", drive.id, drive.startDate.Format(time.DateOnly))
+	case DriveClosed:
+		return conflictf("drive %d closed for applications on %s", drive.id, drive.endDate.Format(time.DateOnly))
+	default:
+		return conflictf("drive %d is %s and no longer accepts applications", drive.id, state)
+	}
+}
+
+// checkResultsPending fails once a drive's results are published, after
+// which its applications can no longer change.
+func (pr *PlacementRegistrar) checkResultsPending(drive *Drive) error {
+	if state := pr.DriveState(drive); state == DriveResultsPublished || state == DriveArchived {
+		return conflictf("drive %d is %s; its applications can no longer change", drive.id, state)
+	}
+	return nil
+}
+
+// PublishResults announces a drive's results, closing it for applications
+// if it was still open and freezing the status of every application.
+func (pr *PlacementRegistrar) PublishResults(companyID, driveID int) error {
+	drive, err := pr.DriveByID(companyID, driveID)
+	if err != nil {
+		return err
+	}
+	switch state := pr.DriveState(drive); state {
+	case DriveOpen, DriveClosed:
+	default:
+		return conflictf("drive %d is %s; only open or closed drives can publish results", driveID, state)
+	}
+	drive.resultsPublishedAt = pr.Now().UTC()
+	return nil
+}
+
+// ArchiveDrive archives a drive whose results have been published.
+func (pr *PlacementRegistrar) ArchiveDrive(companyID, driveID int) error {
+	drive, err := pr.DriveByID(companyID, driveID)
+	if err != nil {
+		return err
+	}
+	if state := pr.DriveState(drive); state != DriveResultsPublished {
+		return conflictf("drive %d is %s; only drives with published results can be archived", driveID, state)
+	}
+	drive.archivedAt = pr.Now().UTC()
+	return nil
+}
+
+// AnnouncedAt returns when the drive's opening was announced to students.
+func (dr Drive) AnnouncedAt() time.Time {
+	return dr.announcedAt
+}
+
+// AnnounceOpenDrives notifies every applicant who may apply to each open drive
+// that has not been announced yet, and returns the drives it announced.
+// Drives added while open are announced straight away; call it
+// periodically to announce drafts once their start date arrives.
+func (pr *PlacementRegistrar) AnnounceOpenDrives() []*Drive {
+	var announced []*Drive
+	for _, d := range pr.AllDrives() {
+		if d == nil || !d.announcedAt.IsZero() || pr.DriveState(d) != DriveOpen {
+			continue
+		}
+		d.announcedAt = pr.Now().UTC()
+		announced = append(announced, d)
+		if pr.notify == nil {
+			continue
+		}
+		for _, a := range pr.applicants {
+			if pr.mayApply(a, d) {
+				pr.notify(a.ID(), &DriveNotification{drive: *d})
+			}
+		}
+	}
+	return announced
+}
@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDrive_State(t *testing.T) {
+	start := time.Date(2025, time.July, 1, 9, 0, 0, 0, time.UTC)
+	d := NewDrive(start, time.Date(2025, time.July, 15, 0, 0, 0, 0, time.UTC), "Engineer", 6, 1000000, Dream)
+	cases := []struct {
+		now  time.Time
+		want DriveState
+	}{
+		{start.Add(-time.Minute), DriveDraft},
+		{start, DriveOpen},
+		{time.Date(2025, time.July, 15, 23, 59, 0, 0, time.UTC), DriveOpen},
+		{time.Date(2025, time.July, 16, 0, 0, 0, 0, time.UTC), DriveClosed},
+	}
+	for _, c := range cases {
+		if got := d.State(c.now); got != c.want {
+			t.Errorf("State(%v) = %s, want %s", c.now, got, c.want)
+		}
+	}
+	if got := (&Drive{}).State(start); got != DriveOpen {
+		t.Errorf("a drive without dates should always be open, got %s", got)
+	}
+	if st, err := ParseDriveState("results published"); err != nil || st != DriveResultsPublished {
+		t.Errorf("ParseDriveState = %v, %v", st, err)
+	}
+}
+
+func TestPlacementRegistrar_DriveLifecycle(t *testing.T) {
+	pr, company, drives := tieredRegistrar(t)
+	d := drives[Dream]
+	d.SetStartDate(time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC))
+	d.SetEndDate(time.Date(2025, time.July, 15, 0, 0, 0, 0, time.UTC))
+	now := time.Date(2025, time.June, 30, 12, 0, 0, 0, time.UTC)
+	pr.SetClock(func() time.Time { return now })
+	if err := pr.AddApplicant(NewApplicant(NewStudent(2, "Bob"), AcademicRecord{StudentId: 2, CGPA: 9})); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pr.ApplyForDrive(1, company.ID(), d.ID()); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict applying to a draft drive, got %v", err)
+	}
+	if err := pr.PublishResults(company.ID(), d.ID()); !errors.Is(err, ErrConflict) {
+		t.Errorf("a draft drive should not publish results, got %v", err)
+	}
+
+	now = time.Date(2025, time.July, 10, 12, 0, 0, 0, time.UTC)
+	if err := pr.ApplyForDrive(1, company.ID(), d.ID()); err != nil {
+		t.Fatal(err)
+	}
+	if err := pr.UpdateApplicationStatus(1, d.ID(), ShortListed); err != nil {
+		t.Fatal(err)
+	}
+	if app, _ := pr.ApplicationFor(1, d.ID()); !app.History()[0].At.Equal(now) {
+		t.Errorf("status history should use the registrar's clock, got %v", app.History()[0].At)
+	}
+
+	now = time.Date(2025, time.July, 20, 12, 0, 0, 0, time.UTC)
+	if err := pr.ApplyForDrive(2, company.ID(), d.ID()); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict applying after the window, got %v", err)
+	}
+	if err := pr.ArchiveDrive(company.ID(), d.ID()); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict archiving before results are published, got %v", err)
+	}
+	if err := pr.PublishResults(company.ID(), d.ID()); err != nil {
+		t.Fatal(err)
+	}
+	if got := pr.DriveState(d); got != DriveResultsPublished || !d.ResultsPublishedAt().Equal(now) {
+		t.Errorf("expected published results at %v, got %s at %v", now, got, d.ResultsPublishedAt())
+	}
+	if err := pr.UpdateApplicationStatus(1, d.ID(), Cleared); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict changing a status after publishing, got %v", err)
+	}
+	if err := pr.SetDriveRounds(company.ID(), d.ID(), []Round{{Name: "HR", Kind: HRInterview}}); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict changing rounds after publishing, got %v", err)
+	}
+	if err := pr.ArchiveDrive(company.ID(), d.ID()); err != nil {
+		t.Fatal(err)
+	}
+	if got := pr.DriveState(d); got != DriveArchived {
+		t.Errorf("expected an archived drive, got %s", got)
+	}
+}
+
+func TestPlacementRegistrar_AnnounceOpenDrives_OfferPolicy(t *testing.T) {
+	pr, company, drives := tieredRegistrar(t)
+	if err := pr.AddApplicant(NewApplicant(NewStudent(2, "Bob"), AcademicRecord{StudentId: 2, CGPA: 9})); err != nil {
+		t.Fatal(err)
+	}
+	selectFor(t, pr, company, drives[Dream])
+	pr.AnnounceOpenDrives()
+	var told []int
+	pr.SetNotifier(func(studentID int, n Notice) {
+		if _, ok := n.(*DriveNotification); ok {
+			told = append(told, studentID)
+		}
+	})
+	day := NewDrive(time.Now(), time.Now().AddDate(0, 0, 7), "Analyst", 0, 500000, Day)
+	if err := pr.AddDriveToCompany(company.ID(), day); err != nil {
+		t.Fatal(err)
+	}
+	if len(told) != 1 || told[0] != 2 {
+		t.Errorf("expected Bob alone told of the Day drive, not Alice whose Dream offer blocks it, got %v", told)
+	}
+}
@@ -0,0 +1,274 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ApplicantProfile is what a drive's eligibility criteria are checked
+// against: the applicant's academic record plus what the portal knows of
+// their attendance and programme.
+type ApplicantProfile struct {
+	StudentID      int
+	CGPA           float64
+	Backlogs       int     // courses whose latest result is an F
+	Semester       int     // latest semester with results
+	Attendance     float64 // percentage of classes attended across courses
+	HasAttendance  bool
+	Department     string
+	GraduationYear int
+}
+
+// NewApplicantProfile builds a profile from the applicant's academic record.
+// Attendance is left unknown; PlacementRegistrar.ProfileOf fills it in.
+func NewApplicantProfile(a *Applicant) ApplicantProfile {
+	p := ApplicantProfile{
+		StudentID:      a.ID(),
+		CGPA:           a.CGPA,
+		Department:     a.Department,
+		GraduationYear: a.GraduationYear,
+	}
+	semesters := make([]int, 0, len(a.Semesters))
+	for sem := range a.Semesters {
+		semesters = append(semesters, sem)
+	}
+	slices.Sort(semesters)
+	latest := map[int]AlphabeticGrade{}
+	for _, sem := range semesters {
+		if a.Semesters[sem] == nil {
+			continue
+		}
+		p.Semester = sem
+		for _, cr := range a.Semesters[sem].Courses {
+			latest[cr.CourseId] = cr.Grade
+		}
+	}
+	scale := a.GradeScale()
+	for _, grade := range latest {
+		if !scale.Passes(grade) {
+			p.Backlogs++
+		}
+	}
+	return p
+}
+
+// Criterion is one condition a student must meet to apply for a drive.
+type Criterion interface {
+	// Check returns why p fails the criterion, or "" when it passes.
+	Check(p ApplicantProfile) string
+	String() string
+}
+
+// MinCGPA requires a CGPA of at least the given value.
+type MinCGPA float64
+
+func (c MinCGPA) Check(p ApplicantProfile) string {
+	if p.CGPA >= float64(c) {
+		return ""
+	}
+	return fmt.Sprintf("CGPA %.2f is below the minimum %.2f", p.CGPA, float64(c))
+}
+
+func (c MinCGPA) String() string { return fmt.Sprintf("CGPA >= %.2f", float64(c)) }
+
+// MaxBacklogs caps the number of active backlogs.
+type MaxBacklogs int
+
+func (c MaxBacklogs) Check(p ApplicantProfile) string {
+	if p.Backlogs <= int(c) {
+		return ""
+	}
+	return fmt.Sprintf("%d active backlogs, at most %d allowed", p.Backlogs, int(c))
+}
+
+func (c MaxBacklogs) String() string { return fmt.Sprintf("backlogs <= %d", int(c)) }
+
+// MinAttendance requires an attendance percentage of at least the given value.
+type MinAttendance float64
+
+func (c MinAttendance) Check(p ApplicantProfile) string {
+	if !p.HasAttendance {
+		return fmt.Sprintf("no attendance recorded, at least %.1f%% required", float64(c))
+	}
+	if p.Attendance >= float64(c) {
+		return ""
+	}
+	return fmt.Sprintf("attendance %.1f%% is below the minimum %.1f%%", p.Attendance, float64(c))
+}
+
+func (c MinAttendance) String() string { return fmt.Sprintf("attendance >= %.1f%%", float64(c)) }
+
+// AllowedSemesters limits the drive to students currently in one of the
+// listed semesters.
+type AllowedSemesters []int
+
+func (c AllowedSemesters) Check(p ApplicantProfile) string {
+	if slices.Contains(c, p.Semester) {
+		return ""
+	}
+	return fmt.Sprintf("semester %d is not one of %s", p.Semester, joinInts(c))
+}
+
+func (c AllowedSemesters) String() string { return "semester in " + joinInts(c) }
+
+// GraduationYears limits the drive to the listed graduating batches.
+type GraduationYears []int
+
+func (c GraduationYears) Check(p ApplicantProfile) string {
+	if slices.Contains(c, p.GraduationYear) {
+		return ""
+	}
+	if p.GraduationYear == 0 {
+		return fmt.Sprintf("graduation year is not recorded, must be one of %s", joinInts(c))
+	}
+	return fmt.Sprintf("graduation year %d is not one of %s", p.GraduationYear, joinInts(c))
+}
+
+func (c GraduationYears) String() string { return "graduation year in " + joinInts(c) }
+
+// Departments limits the drive to students of the listed departments or
+// branches, compared case-insensitively.
+type Departments []string
+
+func (c Departments) Check(p ApplicantProfile) string {
+	for _, d := range c {
+		if strings.EqualFold(d, p.Department) {
+			return ""
+		}
+	}
+	if p.Department == "" {
+		return fmt.Sprintf("department is not recorded, must be one of %s", strings.Join(c, ", "))
+	}
+	return fmt.Sprintf("department %q is not one of %s", p.Department, strings.Join(c, ", "))
+}
+
+func (c Departments) String() string { return "department in " + strings.Join(c, ", ") }
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// CriterionFailure is a criterion an applicant did not meet, and why.
+type CriterionFailure struct {
+	Criterion Criterion
+	Reason    string
+}
+
+// EligibilityError lists every criterion of a drive a student failed. It
+// matches ErrNotEligible.
+type EligibilityError struct {
+	StudentID int
+	Failures  []CriterionFailure
+}
+
+func (e *EligibilityError) Error() string {
+	reasons := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		reasons[i] = f.Reason
+	}
+	return fmt.Sprintf("student %d is not eligible: %s", e.StudentID, strings.Join(reasons, "; "))
+}
+
+func (e *EligibilityError) Unwrap() error { return ErrNotEligible }
+
+// Eligibility is the set of criteria a student must meet to apply for a
+// drive. Every drive has a minimum CGPA; other criteria are optional.
+type Eligibility struct {
+	criteria []Criterion
+}
+
+func NewEligibility(minimumGPA float64, criteria ...Criterion) *Eligibility {
+	return &Eligibility{criteria: append([]Criterion{MinCGPA(minimumGPA)}, criteria...)}
+}
+
+// Requirement returns the minimum CGPA.
+func (el *Eligibility) Requirement() float64 {
+	for _, c := range el.criteria {
+		if cgpa, ok := c.(MinCGPA); ok {
+			return float64(cgpa)
+		}
+	}
+	return 0
+}
+
+func (el *Eligibility) ChangeRequirement(newReq float64) {
+	for i, c := range el.criteria {
+		if _, ok := c.(MinCGPA); ok {
+			el.criteria[i] = MinCGPA(newReq)
+			return
+		}
+	}
+	el.criteria = append([]Criterion{MinCGPA(newReq)}, el.criteria...)
+}
+
+// Criteria returns every criterion, the minimum CGPA first.
+func (el *Eligibility) Criteria() []Criterion {
+	return el.criteria
+}
+
+func (el *Eligibility) AddCriteria(criteria ...Criterion) {
+	el.criteria = append(el.criteria, criteria...)
+}
+
+// Evaluate returns the criteria p fails, in order.
+func (el *Eligibility) Evaluate(p ApplicantProfile) []CriterionFailure {
+	var failures []CriterionFailure
+	for _, c := range el.criteria {
+		if reason := c.Check(p); reason != "" {
+			failures = append(failures, CriterionFailure{Criterion: c, Reason: reason})
+		}
+	}
+	return failures
+}
+
+// Check returns an *EligibilityError when p fails any criterion.
+func (el *Eligibility) Check(p ApplicantProfile) error {
+	if failures := el.Evaluate(p); len(failures) > 0 {
+		return &EligibilityError{StudentID: p.StudentID, Failures: failures}
+	}
+	return nil
+}
+
+// CheckEligibility reports whether the applicant meets every criterion,
+// judged on their academic record alone.
+func (el *Eligibility) CheckEligibility(applicant *Applicant) bool {
+	return len(el.Evaluate(NewApplicantProfile(applicant))) == 0
+}
+
+// SetAttendanceSource tells the registrar how to look up a student's
+// attendance percentage for eligibility checks.
+func (pr *PlacementRegistrar) SetAttendanceSource(attendance func(studentID int) (float64, bool)) {
+	pr.attendance = attendance
+}
+
+// ProfileOf returns the profile a's eligibility is judged on.
+func (pr *PlacementRegistrar) ProfileOf(a *Applicant) ApplicantProfile {
+	p := NewApplicantProfile(a)
+	if pr.attendance != nil {
+		p.Attendance, p.HasAttendance = pr.attendance(a.ID())
+	}
+	return p
+}
+
+// AttendancePercentage returns the share of classes a student attended
+// across all their courses, and false when none have been recorded.
+func (r *NewRegistrarS) AttendancePercentage(studentID int) (float64, bool) {
+	attended, total := 0, 0
+	for _, e := range r.enroll {
+		if e.Student.id != studentID {
+			continue
+		}
+		a, held := e.Attend.Counts(r.sessionDays(e.Attend))
+		attended += a
+		total += held
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return 100 * float64(attended) / float64(total), true
+}
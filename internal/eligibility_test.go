@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewApplicantProfile(t *testing.T) {
+	record := NewAcademicRecord(1)
+	record.AddResult(NewCourseResult(1, 101, "Math", F, 1, 4), 1)
+	record.AddResult(NewCourseResult(1, 102, "Physics", F, 1, 4), 1)
+	record.AddResult(NewCourseResult(1, 101, "Math", B, 2, 4), 2) // cleared on the retake
+	a := NewApplicant(NewStudent(1, "Alice"), *record)
+	a.Department, a.GraduationYear = "CSE", 2026
+
+	p := NewApplicantProfile(a)
+	if p.Backlogs != 1 || p.Semester != 2 || p.Department != "CSE" || p.GraduationYear != 2026 || p.HasAttendance {
+		t.Errorf("unexpected profile: %+v", p)
+	}
+}
+
+func TestEligibility_Evaluate(t *testing.T) {
+	el := NewEligibility(7, MaxBacklogs(0), MinAttendance(75), AllowedSemesters{7, 8},
+		GraduationYears{2026}, Departments{"CSE", "ECE"})
+	good := ApplicantProfile{CGPA: 7, Semester: 7, Attendance: 80, HasAttendance: true, Department: "cse", GraduationYear: 2026}
+	if failures := el.Evaluate(good); len(failures) != 0 {
+		t.Errorf("expected no failures, got %+v", failures)
+	}
+
+	bad := ApplicantProfile{StudentID: 3, CGPA: 6.5, Backlogs: 2, Semester: 5, HasAttendance: false, Department: "ME", GraduationYear: 2027}
+	failures := el.Evaluate(bad)
+	if len(failures) != 6 {
+		t.Fatalf("expected every criterion to fail, got %+v", failures)
+	}
+	if _, ok := failures[1].Criterion.(MaxBacklogs); !ok || failures[1].Reason != "2 active backlogs, at most 0 allowed" {
+		t.Errorf("unexpected backlog failure: %+v", failures[1])
+	}
+	err := el.Check(bad)
+	var eligErr *EligibilityError
+	if !errors.Is(err, ErrNotEligible) || !errors.As(err, &eligErr) || len(eligErr.Failures) != 6 {
+		t.Errorf("expected an EligibilityError, got %v", err)
+	}
+}
+
+func TestApplyForDrive_ReportsFailedCriteria(t *testing.T) {
+	p := NewPortal()
+	alice := NewStudent(1, "Alice")
+	math := NewCourse(101, "Math")
+	p.Academic.AddStudent(alice)
+	att := Attendance{}
+	day := time.Date(2025, time.July, 1, 9, 0, 0, 0, time.UTC)
+	for i := range 4 {
+		MarkAttendance(&att, day.AddDate(0, 0, i), i != 0)
+	}
+	p.Academic.AddEnrollnew(NewEnrollNew(alice, math, LetterGrader{}, 8, att, NewTeacher("T1", "Prof. Smith")))
+
+	company := NewCompany("Acme")
+	drive := NewDrive(day, day.AddDate(0, 0, 14), "Engineer", 6, 1000000, Dream)
+	drive.AddCriteria(MinAttendance(80), Departments{"ECE"})
+	company.AddDrive(drive)
+	p.Placement.AddCompany(company)
+	p.Placement.SetClock(func() time.Time { return day })
+	applicant := NewApplicant(alice, AcademicRecord{StudentId: 1, CGPA: 8})
+	applicant.Department = "CSE"
+	if err := p.Placement.AddApplicant(applicant); err != nil {
+		t.Fatal(err)
+	}
+
+	err := p.Placement.ApplyForDrive(1, company.ID(), drive.ID())
+	var eligErr *EligibilityError
+	if !errors.As(err, &eligErr) || len(eligErr.Failures) != 2 {
+		t.Fatalf("expected attendance and department failures, got %v", err)
+	}
+	if eligErr.Failures[0].Reason != "attendance 75.0% is below the minimum 80.0%" {
+		t.Errorf("unexpected reason: %q", eligErr.Failures[0].Reason)
+	}
+
+	drive.eligibility = *NewEligibility(6, MinAttendance(75))
+	if err := p.Placement.ApplyForDrive(1, company.ID(), drive.ID()); err != nil {
+		t.Errorf("expected Alice to be eligible, got %v", err)
+	}
+}
+
+func TestCriterionRecord_RoundTrip(t *testing.T) {
+	rule, err := ParseRule("cgpa >= 7.5 && department == 'CSE'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	criteria := []Criterion{
+		MinCGPA(7.5), MaxBacklogs(1), MinAttendance(75), AllowedSemesters{7, 8}, GraduationYears{2026}, Departments{"CSE"}, rule,
+	}
+	for _, c := range criteria {
+		rec, err := EncodeCriterion(c)
+		if err != nil {
+			t.Fatalf("EncodeCriterion(%v): %v", c, err)
+		}
+		got, err := DecodeCriterion(rec)
+		if err != nil || got.String() != c.String() {
+			t.Errorf("DecodeCriterion(%+v) = %v, %v; want %v", rec, got, err, c)
+		}
+	}
+	if _, err := DecodeCriterion(CriterionRecord{Kind: "height"}); err == nil {
+		t.Error("expected an error for an unknown criterion kind")
+	}
+}
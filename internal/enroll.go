@@ -4,7 +4,9 @@ type Enrollment struct {
 	Student
 	Course
 	Grader
-	score float64
+	score  float64
+	marks  []ComponentMark // the marks score was worked out from, if any
+	marked bool            // a final mark has been uploaded
 }
 
 type EnrollNew struct {
@@ -25,18 +27,30 @@ func NewEnrollNew(st Student, c Course, g Grader, score float64, attend Attendan
 			Course:  c,
 			Grader:  g,
 			score:   score,
+			marked:  score != 0,
 		},
 		Attend:  attend,
 		Teacher: t,
 	}
 }
 
+// Score returns the mark uploaded for this enrollment.
+func (e Enrollment) Score() float64 {
+	return e.score
+}
+
+// Marked reports whether a final mark has been uploaded for the enrollment.
+// Enrollments made with a non-zero score count as marked.
+func (e Enrollment) Marked() bool {
+	return e.marked
+}
+
 func NewTeacherEnrollment(t Teacher, c CreditCourse) TeacherEnrollment {
 	return TeacherEnrollment{Teacher: t, CreditCourse: c}
 }
 
 func NewEnrollment(st Student, c Course, g Grader, score float64) Enrollment {
-	return Enrollment{Student: st, Course: c, Grader: g, score: score}
+	return Enrollment{Student: st, Course: c, Grader: g, score: score, marked: score != 0}
 }
 
 // made enrool function to check with teacher maping with course (changes may be like checking in main while adding or passing teacher map from rigister to enroll function)
@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error kinds returned (wrapped) by the registrars and services, so callers
+// such as the HTTP API can tell a missing record from a rejected request.
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrConflict        = errors.New("conflict")
+	ErrNotEligible     = errors.New("not eligible")
+	ErrForbidden       = errors.New("forbidden")
+	ErrInvalid         = errors.New("invalid")
+	ErrUnauthenticated = errors.New("unauthenticated")
+)
+
+// kindError keeps the existing human readable message while letting
+// errors.Is match on the kind.
+type kindError struct {
+	kind error
+	msg  string
+}
+
+func (e *kindError) Error() string { return e.msg }
+func (e *kindError) Unwrap() error { return e.kind }
+
+func notFoundf(format string, args ...any) error {
+	return &kindError{kind: ErrNotFound, msg: fmt.Sprintf(format, args...)}
+}
+
+func conflictf(format string, args ...any) error {
+	return &kindError{kind: ErrConflict, msg: fmt.Sprintf(format, args...)}
+}
+
+func notEligiblef(format string, args ...any) error {
+	return &kindError{kind: ErrNotEligible, msg: fmt.Sprintf(format, args...)}
+}
+
+func forbiddenf(format string, args ...any) error {
+	return &kindError{kind: ErrForbidden, msg: fmt.Sprintf(format, args...)}
+}
+
+func unauthenticatedf(format string, args ...any) error {
+	return &kindError{kind: ErrUnauthenticated, msg: fmt.Sprintf(format, args...)}
+}
+
+func invalidf(format string, args ...any) error {
+	return &kindError{kind: ErrInvalid, msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,280 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// GradeBand is one letter of a GradeScale.
+type GradeBand struct {
+	Letter AlphabeticGrade `json:"letter"`
+	Points float64         `json:"points"`
+	Pass   bool            `json:"pass"`
+	// MinScore is the lowest percentage that earns the letter; the band runs
+	// up to the MinScore of the band above it.
+	MinScore float64 `json:"min_score"`
+}
+
+// GradeScale maps letter grades to grade points, whether they pass, and the
+// scores that earn them. Colleges on different scales, such as a 4.0 GPA or
+// S/A/B/C/D/E/F, load their own; DefaultGradeScale is the portal's 10-point
+// O to F scale.
+type GradeScale struct {
+	Name  string      `json:"name"`
+	Bands []GradeBand `json:"bands"` // best grade first
+}
+
+// DefaultGradeScale returns the 10-point scale used unless another is
+// configured.
+func DefaultGradeScale() *GradeScale {
+	return &GradeScale{Name: "10-point", Bands: []GradeBand{
+		{Letter: O, Points: 10, Pass: true, MinScore: 90},
+		{Letter: Aplus, Points: 9, Pass: true, MinScore: 80},
+		{Letter: A, Points: 8, Pass: true, MinScore: 70},
+		{Letter: Bplus, Points: 7, Pass: true, MinScore: 60},
+		{Letter: B, Points: 6, Pass: true, MinScore: 50},
+		{Letter: C, Points: 5, Pass: true, MinScore: 40},
+		{Letter: F, Points: 0, Pass: false, MinScore: 0},
+	}}
+}
+
+// ParseGradeScale reads a grade scale from JSON and validates it.
+func ParseGradeScale(data []byte) (*GradeScale, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var s GradeScale
+	if err := dec.Decode(&s); err != nil {
+		return nil, invalidf("invalid grade scale: %v", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Validate checks the scale lists distinct letters best first, with points
+// and minimum scores that never rise, and that every score from 0 to 100
+// earns a letter.
+func (s *GradeScale) Validate() error {
+	if len(s.Bands) == 0 {
+		return invalidf("grade scale %q has no grades", s.Name)
+	}
+	seen := map[AlphabeticGrade]bool{}
+	for i, b := range s.Bands {
+		switch {
+		case strings.TrimSpace(string(b.Letter)) == "":
+			return invalidf("grade scale %q: grade %d has no letter", s.Name, i+1)
+		case seen[b.Letter]:
+			return invalidf("grade scale %q lists %s twice", s.Name, b.Letter)
+		case b.Points < 0:
+			return invalidf("grade scale %q: %s has negative points", s.Name, b.Letter)
+		case b.MinScore < 0 || b.MinScore > 100:
+			return invalidf("grade scale %q: minimum score of %s must be between 0 and 100", s.Name, b.Letter)
+		}
+		seen[b.Letter] = true
+		if i == 0 {
+			continue
+		}
+		prev := s.Bands[i-1]
+		if b.Points > prev.Points {
+			return invalidf("grade scale %q: %s is worth more than %s above it", s.Name, b.Letter, prev.Letter)
+		}
+		if b.MinScore >= prev.MinScore {
+			return invalidf("grade scale %q: %s needs a lower minimum score than %s above it", s.Name, b.Letter, prev.Letter)
+		}
+	}
+	if last := s.Bands[len(s.Bands)-1]; last.MinScore != 0 {
+		return invalidf("grade scale %q: the lowest grade %s must start at a score of 0", s.Name, last.Letter)
+	}
+	return nil
+}
+
+// Band returns the band of letter.
+func (s *GradeScale) Band(letter AlphabeticGrade) (GradeBand, bool) {
+	for _, b := range s.Bands {
+		if b.Letter == letter {
+			return b, true
+		}
+	}
+	return GradeBand{}, false
+}
+
+// Points returns the grade points of letter, and false if the letter is not
+// on the scale.
+func (s *GradeScale) Points(letter AlphabeticGrade) (float64, bool) {
+	b, ok := s.Band(letter)
+	return b.Points, ok
+}
+
+// Passes reports whether letter is a passing grade. Letters not on the
+// scale do not pass.
+func (s *GradeScale) Passes(letter AlphabeticGrade) bool {
+	b, ok := s.Band(letter)
+	return ok && b.Pass
+}
+
+// ParseGrade checks that letter is on the scale.
+func (s *GradeScale) ParseGrade(letter string) (AlphabeticGrade, error) {
+	if _, ok := s.Band(AlphabeticGrade(letter)); !ok {
+		return "", invalidf("grade %q is not on the %s scale", letter, s.Name)
+	}
+	return AlphabeticGrade(letter), nil
+}
+
+// ForScore returns the band a percentage score earns.
+func (s *GradeScale) ForScore(score float64) GradeBand {
+	for _, b := range s.Bands {
+		if score >= b.MinScore {
+			return b
+		}
+	}
+	return s.Bands[len(s.Bands)-1]
+}
+
+// MaxPoints returns the points of the best grade.
+func (s *GradeScale) MaxPoints() float64 {
+	return s.Bands[0].Points
+}
+
+// OnTenPointScale converts a GPA on this scale to one out of 10, so
+// thresholds such as the dean's list apply whatever the scale.
+func (s *GradeScale) OnTenPointScale(gpa float64) float64 {
+	if top := s.MaxPoints(); top > 0 {
+		return gpa * 10 / top
+	}
+	return 0
+}
+
+// CheckResults fails unless every result's grade is on the scale.
+func (s *GradeScale) CheckResults(results ...CourseResult) error {
+	for _, cr := range results {
+		if _, ok := s.Band(cr.Grade); !ok {
+			return invalidf("grade %q of student %d in course %d is not on the %s scale", cr.Grade, cr.StudentId, cr.CourseId, s.Name)
+		}
+	}
+	return nil
+}
+
+func (s *GradeScale) String() string {
+	letters := make([]string, len(s.Bands))
+	for i, b := range s.Bands {
+		letters[i] = fmt.Sprintf("%s=%g", b.Letter, b.Points)
+	}
+	return fmt.Sprintf("%s (%s)", s.Name, strings.Join(letters, ", "))
+}
+
+// Results returns every course result in the record.
+func (ar *AcademicRecord) Results() []CourseResult {
+	var out []CourseResult
+	for _, sem := range ar.Semesters {
+		if sem == nil {
+			continue
+		}
+		for _, cr := range sem.Courses {
+			out = append(out, cr)
+		}
+	}
+	return out
+}
+
+// GradeScale returns the scale the record's grade points are read from.
+func (ar *AcademicRecord) GradeScale() *GradeScale {
+	if ar.scale == nil {
+		return DefaultGradeScale()
+	}
+	return ar.scale
+}
+
+// SetGradeScale changes the scale the record is read on and recalculates
+// every SGPA and the CGPA. A nil scale restores the default.
+func (ar *AcademicRecord) SetGradeScale(s *GradeScale) {
+	ar.useScale(s)
+	for _, sem := range ar.Semesters {
+		if sem != nil {
+			sem.calculateSGPA()
+		}
+	}
+	ar.calculateCGPA()
+}
+
+// useScale sets the record's scale without recalculating, for records whose
+// SGPA and CGPA were stored on it.
+func (ar *AcademicRecord) useScale(s *GradeScale) {
+	ar.scale = s
+	for _, sem := range ar.Semesters {
+		if sem != nil {
+			sem.scale = s
+		}
+	}
+}
+
+// SetGradeScale makes s the scale for new results and academic records. A
+// nil scale restores the default.
+func (r *NewRegistrarS) SetGradeScale(s *GradeScale) {
+	r.gradeScale = s
+}
+
+// GradeScale returns the registrar's grade scale.
+func (r *NewRegistrarS) GradeScale() *GradeScale {
+	if r.gradeScale == nil {
+		return DefaultGradeScale()
+	}
+	return r.gradeScale
+}
+
+// SetGradeScale reads every applicant's record on s, recalculating their
+// CGPA and status, and applies s to applicants added later. It changes
+// nothing if a recorded grade is not on s.
+func (pr *PlacementRegistrar) SetGradeScale(s *GradeScale) error {
+	scale := s
+	if scale == nil {
+		scale = DefaultGradeScale()
+	}
+	for _, a := range pr.applicants {
+		if err := scale.CheckResults(a.Results()...); err != nil {
+			return err
+		}
+	}
+	pr.gradeScale = s
+	for _, a := range pr.applicants {
+		pr.applyGradeScale(a)
+	}
+	return nil
+}
+
+// GradeScale returns the scale applicants' records are read on.
+func (pr *PlacementRegistrar) GradeScale() *GradeScale {
+	if pr.gradeScale == nil {
+		return DefaultGradeScale()
+	}
+	return pr.gradeScale
+}
+
+func (pr *PlacementRegistrar) applyGradeScale(a *Applicant) {
+	a.AcademicRecord.SetGradeScale(pr.gradeScale)
+	a.Status = NewGPACalculator().DetermineStatus(a.GradeScale().OnTenPointScale(a.CGPA))
+}
+
+// GradeScale returns the portal's grade scale.
+func (p *Portal) GradeScale() *GradeScale {
+	return p.Academic.GradeScale()
+}
+
+// SetGradeScale switches the portal to a new grade scale, recalculating
+// every applicant's CGPA on it. It fails if the scale is invalid or a grade
+// already recorded is not on it.
+func (p *Portal) SetGradeScale(s *GradeScale) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	if err := s.CheckResults(p.Academic.results...); err != nil {
+		return err
+	}
+	if err := p.Placement.SetGradeScale(s); err != nil {
+		return err
+	}
+	p.Academic.SetGradeScale(s)
+	return nil
+}
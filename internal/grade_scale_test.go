@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+const fourPointScale = `{"name": "4.0 GPA", "bands": [
+	{"letter": "A", "points": 4, "pass": true, "min_score": 90},
+	{"letter": "B", "points": 3, "pass": true, "min_score": 80},
+	{"letter": "C", "points": 2, "pass": true, "min_score": 70},
+	{"letter": "D", "points": 1, "pass": true, "min_score": 60},
+	{"letter": "F", "points": 0, "pass": false, "min_score": 0}
+]}`
+
+const sevenLetterScale = `{"name": "S to F", "bands": [
+	{"letter": "S", "points": 10, "pass": true, "min_score": 90},
+	{"letter": "A", "points": 9, "pass": true, "min_score": 80},
+	{"letter": "B", "points": 8, "pass": true, "min_score": 70},
+	{"letter": "C", "points": 7, "pass": true, "min_score": 60},
+	{"letter": "D", "points": 6, "pass": true, "min_score": 50},
+	{"letter": "E", "points": 5, "pass": true, "min_score": 40},
+	{"letter": "F", "points": 0, "pass": false, "min_score": 0}
+]}`
+
+func TestParseGradeScale(t *testing.T) {
+	s, err := ParseGradeScale([]byte(fourPointScale))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := s.Points("B"); !ok || p != 3 || s.MaxPoints() != 4 {
+		t.Errorf("expected B to be worth 3 of 4 points, got %v, %v", p, ok)
+	}
+	if s.Passes("F") || !s.Passes("D") || s.Passes("A+") {
+		t.Error("expected D to pass and F and unknown letters not to")
+	}
+	if b := s.ForScore(85); b.Letter != "B" {
+		t.Errorf("expected 85 to earn a B, got %s", b.Letter)
+	}
+	if b := s.ForScore(12); b.Letter != "F" {
+		t.Errorf("expected 12 to earn an F, got %s", b.Letter)
+	}
+	if _, err := s.ParseGrade("O"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected O to be off the 4.0 scale, got %v", err)
+	}
+	if got := s.OnTenPointScale(3); got != 7.5 {
+		t.Errorf("expected 3.0 to be 7.5 out of 10, got %v", got)
+	}
+
+	for name, data := range map[string]string{
+		"unknown field":  `{"name": "x", "grades": []}`,
+		"no bands":       `{"name": "x", "bands": []}`,
+		"duplicate":      `{"name": "x", "bands": [{"letter": "A", "points": 4, "pass": true, "min_score": 50}, {"letter": "A", "points": 0, "min_score": 0}]}`,
+		"points rise":    `{"name": "x", "bands": [{"letter": "A", "points": 3, "pass": true, "min_score": 50}, {"letter": "F", "points": 4, "min_score": 0}]}`,
+		"scores overlap": `{"name": "x", "bands": [{"letter": "A", "points": 4, "pass": true, "min_score": 50}, {"letter": "F", "points": 0, "min_score": 50}]}`,
+		"gap at zero":    `{"name": "x", "bands": [{"letter": "A", "points": 4, "pass": true, "min_score": 50}, {"letter": "F", "points": 0, "min_score": 10}]}`,
+		"above 100":      `{"name": "x", "bands": [{"letter": "A", "points": 4, "pass": true, "min_score": 101}, {"letter": "F", "points": 0, "min_score": 0}]}`,
+	} {
+		if _, err := ParseGradeScale([]byte(data)); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: expected an invalid scale, got %v", name, err)
+		}
+	}
+}
+
+func TestAcademicRecord_GradeScale(t *testing.T) {
+	s, err := ParseGradeScale([]byte(sevenLetterScale))
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := NewAcademicRecord(1)
+	record.SetGradeScale(s)
+	record.AddResult(NewCourseResult(1, 101, "Math", "S", 1, 4), 1)
+	record.AddResult(NewCourseResult(1, 102, "Physics", "E", 1, 4), 1)
+	if sgpa := record.Semesters[1].SGPA; sgpa != 7.5 {
+		t.Errorf("expected an SGPA of 7.5 on the S to F scale, got %v", sgpa)
+	}
+
+	record.AddResult(NewCourseResult(1, 103, "Chemistry", "F", 2, 3), 2)
+	a := NewApplicant(NewStudent(1, "Alice"), *record)
+	if p := NewApplicantProfile(a); p.Backlogs != 1 {
+		t.Errorf("expected the F to count as a backlog, got %d", p.Backlogs)
+	}
+	// E is the lowest pass on this scale even though the default has no E.
+	if !s.Passes("E") {
+		t.Error("expected E to pass")
+	}
+}
+
+func TestPortal_SetGradeScale(t *testing.T) {
+	p := NewPortal()
+	record := NewAcademicRecord(1)
+	record.AddResult(NewCourseResult(1, 101, "Math", A, 1, 4), 1)
+	if err := p.Placement.AddApplicant(NewApplicant(NewStudent(1, "Alice"), *record)); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := ParseGradeScale([]byte(sevenLetterScale))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Bob's A+ is not on the S to F scale, so it is refused.
+	bob := NewAcademicRecord(2)
+	bob.AddResult(NewCourseResult(2, 101, "Math", Aplus, 1, 4), 1)
+	if err := p.Placement.AddApplicant(NewApplicant(NewStudent(2, "Bob"), *bob)); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetGradeScale(s); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected A+ to be off the S to F scale, got %v", err)
+	}
+	if p.GradeScale().Name != DefaultGradeScale().Name {
+		t.Errorf("expected a refused scale to change nothing, got %s", p.GradeScale().Name)
+	}
+
+	four, err := ParseGradeScale([]byte(fourPointScale))
+	if err != nil {
+		t.Fatal(err)
+	}
+	four.Bands = append([]GradeBand{{Letter: Aplus, Points: 4, Pass: true, MinScore: 95}}, four.Bands...)
+	if err := p.SetGradeScale(four); err != nil {
+		t.Fatal(err)
+	}
+	alice, _ := p.Placement.ApplicantByID(1)
+	if math.Abs(alice.CGPA-4*4.0/5) > 1e-9 || alice.GradeScale() != four {
+		t.Errorf("expected Alice's CGPA recalculated on the 4.0 scale, got %v", alice.CGPA)
+	}
+	unknown := NewAcademicRecord(3)
+	unknown.AddResult(NewCourseResult(3, 101, "Math", "S", 1, 4), 1)
+	if err := p.Placement.AddApplicant(NewApplicant(NewStudent(3, "Carol"), *unknown)); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected an S to be refused on the 4.0 scale, got %v", err)
+	}
+
+	snap, err := p.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestorePortal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.GradeScale().Name != "4.0 GPA" || restored.Academic.GradeScale().Name != "4.0 GPA" {
+		t.Errorf("expected the scale to survive a snapshot, got %s", restored.GradeScale())
+	}
+}
@@ -40,3 +40,38 @@ func (LetterGrader) Grade(e Enrollment) (string, error) {
 		return "F", nil
 	}
 }
+
+// AlphabeticGrader is a Grader whose grades are letters on a GradeScale,
+// so they can go on a student's transcript.
+type AlphabeticGrader interface {
+	Grader
+	LetterGrade(e Enrollment, scale *GradeScale) (AlphabeticGrade, error)
+}
+
+// ScaleGrader grades a mark out of MaxScore with the letter of the grade
+// scale band its percentage falls in.
+type ScaleGrader struct {
+	MaxScore float64 // zero means marks out of 100
+}
+
+func (g ScaleGrader) outOf() float64 {
+	if g.MaxScore <= 0 {
+		return 100
+	}
+	return g.MaxScore
+}
+
+// Grade grades on the DefaultGradeScale. Registrars grade on their own
+// scale through LetterGrade.
+func (g ScaleGrader) Grade(e Enrollment) (string, error) {
+	letter, err := g.LetterGrade(e, DefaultGradeScale())
+	return string(letter), err
+}
+
+func (g ScaleGrader) LetterGrade(e Enrollment, scale *GradeScale) (AlphabeticGrade, error) {
+	top := g.outOf()
+	if e.score < 0 || e.score > top {
+		return "", invalidf("score %g is not between 0 and %g", e.score, top)
+	}
+	return scale.ForScore(e.score * 100 / top).Letter, nil
+}
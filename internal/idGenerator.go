@@ -7,3 +7,21 @@ func SeqID() func() int {
 		return i
 	}
 }
+
+// idSequence works like SeqID but can be moved forward, so that ids handed
+// out after restoring saved state never collide with the restored ones.
+type idSequence struct {
+	last int
+}
+
+func (s *idSequence) next() int {
+	s.last++
+	return s.last
+}
+
+// reserve marks every id up to and including id as taken.
+func (s *idSequence) reserve(id int) {
+	if id > s.last {
+		s.last = id
+	}
+}
@@ -0,0 +1,255 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LeaveKind is why a student is away.
+type LeaveKind string
+
+const (
+	LeaveMedical LeaveKind = "medical"
+	// LeaveEvent is leave to represent the college at an event.
+	LeaveEvent LeaveKind = "event"
+)
+
+// LeaveStatus is where a leave request stands.
+type LeaveStatus string
+
+const (
+	LeavePending  LeaveStatus = "pending"
+	LeaveApproved LeaveStatus = "approved"
+	LeaveRejected LeaveStatus = "rejected"
+)
+
+// LeaveChange is an entry in a leave request's audit trail.
+type LeaveChange struct {
+	Status LeaveStatus `json:"status"`
+	At     time.Time   `json:"at"`
+	Actor  string      `json:"actor"`
+	Note   string      `json:"note,omitempty"`
+}
+
+// ExcusedAbsence is an absence excused under approved leave: a session, or
+// with a zero SessionID attendance marked by date.
+type ExcusedAbsence struct {
+	CourseID  int       `json:"course_id"`
+	SessionID int       `json:"session_id,omitempty"`
+	Date      time.Time `json:"date"`
+}
+
+// LeaveRequest is a student's request to have their absences between two
+// dates excused, backed by a supporting document such as a medical
+// certificate. Approved leave excuses the absences already marked and any
+// marked later.
+type LeaveRequest struct {
+	ID        int `json:"id"`
+	StudentID int `json:"student_id"`
+	// CourseID limits the leave to one course, whose teacher may decide it;
+	// zero covers all the student's courses and needs a head of department.
+	CourseID int         `json:"course_id,omitempty"`
+	Kind     LeaveKind   `json:"kind"`
+	From     time.Time   `json:"from"` // dates, inclusive
+	To       time.Time   `json:"to"`
+	Reason   string      `json:"reason"`
+	Document Document    `json:"document"`
+	Status   LeaveStatus `json:"status"`
+	// History records who filed and decided the request, oldest first.
+	History []LeaveChange    `json:"history"`
+	Excused []ExcusedAbsence `json:"excused,omitempty"`
+}
+
+// covers reports whether the leave excuses absences from a course on the
+// day of t.
+func (l *LeaveRequest) covers(courseID int, t time.Time) bool {
+	day := dayOf(t)
+	return (l.CourseID == 0 || l.CourseID == courseID) && !day.Before(l.From) && !day.After(l.To)
+}
+
+// dayOf returns the day of t as midnight UTC, the form sessions, leave and
+// attendance marks are matched by.
+func dayOf(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// LeaveNotification tells a student how their leave request was decided.
+type LeaveNotification struct {
+	LeaveRequest
+	Note string
+}
+
+func (n *LeaveNotification) Send() interface{} { return n.Message() }
+
+func (n *LeaveNotification) Message() Message {
+	dates := fmt.Sprintf("%s to %s", n.From.Format(time.DateOnly), n.To.Format(time.DateOnly))
+	m := Message{Kind: NotifyLeave, Subject: fmt.Sprintf("Leave request %s", n.Status)}
+	if n.Status == LeaveApproved {
+		m.Body = fmt.Sprintf("Your %s leave from %s was approved; %d absences were excused.", n.Kind, dates, len(n.Excused))
+	} else {
+		m.Body = fmt.Sprintf("Your %s leave from %s was rejected.", n.Kind, dates)
+	}
+	if n.Note != "" {
+		m.Body += " " + n.Note
+	}
+	return m
+}
+
+// FileLeave records a student's leave request. A student cannot ask for
+// leave that overlaps a request of theirs still pending or approved.
+func (r *NewRegistrarS) FileLeave(l LeaveRequest, actor string, at time.Time) (*LeaveRequest, error) {
+	switch {
+	case l.Kind != LeaveMedical && l.Kind != LeaveEvent:
+		return nil, invalidf("unknown leave kind %q; use %s or %s", l.Kind, LeaveMedical, LeaveEvent)
+	case strings.TrimSpace(l.Reason) == "":
+		return nil, invalidf("a leave request needs a reason")
+	case l.From.IsZero() || l.To.IsZero():
+		return nil, invalidf("a leave request needs the dates it runs from and to")
+	case l.Document.Filename == "" || len(l.Document.Content) == 0:
+		return nil, invalidf("a leave request needs a supporting document")
+	}
+	l.From, l.To = dayOf(l.From), dayOf(l.To)
+	if l.To.Before(l.From) {
+		return nil, invalidf("leave cannot end on %s before it starts on %s", l.To.Format(time.DateOnly), l.From.Format(time.DateOnly))
+	}
+	enrolled := false
+	for _, e := range r.enroll {
+		enrolled = enrolled || (e.Student.ID() == l.StudentID && (l.CourseID == 0 || e.Course.Id == l.CourseID))
+	}
+	if !enrolled && l.CourseID != 0 {
+		return nil, notFoundf("student %d is not enrolled in course %d", l.StudentID, l.CourseID)
+	}
+	if !enrolled {
+		return nil, notFoundf("student %d is not enrolled in any course", l.StudentID)
+	}
+	for _, other := range r.leaves {
+		if other.StudentID != l.StudentID || other.Status == LeaveRejected {
+			continue
+		}
+		sameCourses := other.CourseID == 0 || l.CourseID == 0 || other.CourseID == l.CourseID
+		if sameCourses && !other.To.Before(l.From) && !l.To.Before(other.From) {
+			return nil, conflictf("leave request %d already covers some of these days", other.ID)
+		}
+	}
+	l.Document.UploadedAt = at
+	l.ID = len(r.leaves) + 1
+	l.Status = LeavePending
+	l.History = []LeaveChange{{Status: LeavePending, At: at, Actor: actor}}
+	l.Excused = nil
+	r.leaves = append(r.leaves, &l)
+	return &l, nil
+}
+
+// Leave returns the leave request with the given id.
+func (r *NewRegistrarS) Leave(id int) (*LeaveRequest, error) {
+	if id < 1 || id > len(r.leaves) {
+		return nil, notFoundf("leave request %d not found", id)
+	}
+	return r.leaves[id-1], nil
+}
+
+// Leaves returns the leave requests of a student, or of every student when
+// studentID is zero, oldest first. An empty status matches all.
+func (r *NewRegistrarS) Leaves(studentID int, status LeaveStatus) []*LeaveRequest {
+	out := []*LeaveRequest{}
+	for _, l := range r.leaves {
+		if (studentID == 0 || l.StudentID == studentID) && (status == "" || l.Status == status) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// TeacherLeaves returns the leave requests for single courses a teacher may
+// decide: those of students in their class of the course.
+func (r *NewRegistrarS) TeacherLeaves(teacherID string, status LeaveStatus) []*LeaveRequest {
+	out := []*LeaveRequest{}
+	for _, l := range r.Leaves(0, status) {
+		if l.CourseID == 0 {
+			continue
+		}
+		if i, ok := r.studentEnrollment(l.StudentID, l.CourseID); ok && r.enroll[i].Teacher.TID() == teacherID {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// ReviewLeave decides a pending leave request. Approving it excuses the
+// student's absences it covers, marked by date or per session, so they no
+// longer count towards an attendance shortage; each one is listed on the
+// request.
+func (r *NewRegistrarS) ReviewLeave(id int, approve bool, note, actor string, at time.Time) (*LeaveRequest, error) {
+	l, err := r.Leave(id)
+	if err != nil {
+		return nil, err
+	}
+	if l.Status != LeavePending {
+		return nil, conflictf("leave request %d is already %s", id, l.Status)
+	}
+	l.Status = LeaveRejected
+	if approve {
+		l.Status = LeaveApproved
+		for i, e := range r.enroll {
+			if e.Student.ID() != l.StudentID {
+				continue
+			}
+			for date, present := range e.Attend.Records {
+				if !present && l.covers(e.Course.Id, date) {
+					r.excuse(l, i, 0, date)
+				}
+			}
+			for sessionID, status := range e.Attend.Sessions {
+				if s, err := r.Session(sessionID); err == nil && status == StatusAbsent && l.covers(e.Course.Id, s.Date) {
+					r.excuse(l, i, sessionID, s.Date)
+				}
+			}
+		}
+	}
+	l.History = append(l.History, LeaveChange{Status: l.Status, At: at, Actor: actor, Note: note})
+	if r.notify != nil {
+		r.notify(l.StudentID, &LeaveNotification{LeaveRequest: *l, Note: note})
+	}
+	return l, nil
+}
+
+// approvedLeave returns the student's approved leave covering a course on
+// the day of t, if any.
+func (r *NewRegistrarS) approvedLeave(studentID, courseID int, t time.Time) *LeaveRequest {
+	for _, l := range r.leaves {
+		if l.StudentID == studentID && l.Status == LeaveApproved && l.covers(courseID, t) {
+			return l
+		}
+	}
+	return nil
+}
+
+// excuse marks the absence of the enrollment at position i, at a session or
+// with a zero sessionID on a date, excused under leave l.
+func (r *NewRegistrarS) excuse(l *LeaveRequest, i, sessionID int, date time.Time) {
+	a := &r.enroll[i].Attend
+	if sessionID != 0 {
+		markSession(a, sessionID, StatusExcused)
+	} else {
+		if a.Excused == nil {
+			a.Excused = make(map[time.Time]bool)
+		}
+		a.Excused[date] = true
+	}
+	absence := ExcusedAbsence{CourseID: r.enroll[i].Course.Id, SessionID: sessionID, Date: date}
+	for _, ex := range l.Excused {
+		if ex.CourseID == absence.CourseID && ex.SessionID == sessionID && ex.Date.Equal(date) {
+			return
+		}
+	}
+	l.Excused = append(l.Excused, absence)
+	sort.SliceStable(l.Excused, func(i, j int) bool {
+		if !l.Excused[i].Date.Equal(l.Excused[j].Date) {
+			return l.Excused[i].Date.Before(l.Excused[j].Date)
+		}
+		return l.Excused[i].CourseID < l.Excused[j].CourseID
+	})
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLeaveExcusesAbsences(t *testing.T) {
+	p := NewPortal()
+	var sent []*LeaveNotification
+	p.Academic.SetNotifier(func(_ int, n Notice) {
+		if l, ok := n.(*LeaveNotification); ok {
+			sent = append(sent, l)
+		}
+	})
+	teacher := NewTeacher("T1", "Prof. Smith")
+	alice := NewStudent(1, "Alice")
+	math := NewCourse(101, "Math")
+	p.Academic.AddCourse(math)
+	p.Academic.AddTeacher(teacher)
+	p.Academic.AddStudent(alice)
+	p.Academic.AddTeacherenrollment(NewTeacherEnrollment(teacher, NewCreditCourse(math, 4)))
+	p.Academic.AddEnrollnew(NewEnrollNew(alice, math, ScaleGrader{}, 0, Attendance{}, teacher))
+
+	day := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	for i, present := range []bool{true, false, false, true} {
+		Giveattendence(p.Academic.NewRegistrarS, 101, 1, "T1", present, day.AddDate(0, 0, i))
+	}
+	lab, err := p.Academic.ScheduleSession(ClassSession{CourseID: 101, TeacherID: "T1", Date: day.AddDate(0, 0, 2), Period: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Academic.TakeAttendance(lab.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if a, _ := p.Academic.EnrollmentAttendance(1, 101); a.Attended != 2 || a.Held != 4 || !a.Debarred {
+		t.Fatalf("expected Alice to attend 2 of 4 classes and be debarred, got %+v", a)
+	}
+
+	at := day.AddDate(0, 0, 5)
+	cert := Document{Filename: "certificate.pdf", MimeType: "application/pdf", Content: []byte("%PDF")}
+	leave := LeaveRequest{StudentID: 1, CourseID: 101, Kind: LeaveMedical, From: day.AddDate(0, 0, 1), To: day.AddDate(0, 0, 2), Reason: "flu", Document: cert}
+	for _, bad := range []struct {
+		change func(l *LeaveRequest)
+		kind   error
+	}{
+		{func(l *LeaveRequest) { l.Kind = "holiday" }, ErrInvalid},
+		{func(l *LeaveRequest) { l.Reason = " " }, ErrInvalid},
+		{func(l *LeaveRequest) { l.Document = Document{} }, ErrInvalid},
+		{func(l *LeaveRequest) { l.To = day }, ErrInvalid},
+		{func(l *LeaveRequest) { l.CourseID = 999 }, ErrNotFound},
+	} {
+		l := leave
+		bad.change(&l)
+		if _, err := p.Academic.FileLeave(l, "alice", at); !errors.Is(err, bad.kind) {
+			t.Errorf("expected %+v to fail with %v, got %v", l, bad.kind, err)
+		}
+	}
+	filed, err := p.Academic.FileLeave(leave, "alice", at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filed.ID != 1 || filed.Status != LeavePending || !filed.Document.UploadedAt.Equal(at) {
+		t.Errorf("expected a pending request with its document, got %+v", filed)
+	}
+	// Leave for every course overlaps the one for math.
+	all := leave
+	all.CourseID = 0
+	if _, err := p.Academic.FileLeave(all, "alice", at); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected overlapping leave to be refused, got %v", err)
+	}
+
+	approved, err := p.Academic.ReviewLeave(1, true, "get well soon", "T1", at.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ExcusedAbsence{
+		{CourseID: 101, Date: day.AddDate(0, 0, 1)},
+		{CourseID: 101, Date: day.AddDate(0, 0, 2)},
+		{CourseID: 101, SessionID: lab.ID, Date: day.AddDate(0, 0, 2)},
+	}
+	if !reflect.DeepEqual(approved.Excused, want) {
+		t.Errorf("expected both absences and the lab to be excused, got %+v", approved.Excused)
+	}
+	if len(approved.History) != 2 || approved.History[1].Actor != "T1" || approved.History[1].Status != LeaveApproved {
+		t.Errorf("expected the approval in the history, got %+v", approved.History)
+	}
+	if a, _ := p.Academic.EnrollmentAttendance(1, 101); a.Attended != 2 || a.Held != 2 || a.Debarred {
+		t.Errorf("expected excused absences to lift the shortage, got %+v", a)
+	}
+	if len(sent) != 1 || sent[0].Status != LeaveApproved {
+		t.Errorf("expected Alice to be told of the approval, got %+v", sent)
+	}
+	if _, err := p.Academic.ReviewLeave(1, false, "", "T1", at); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected a decided request to stay decided, got %v", err)
+	}
+
+	// An absence marked late, within approved leave, is excused at once.
+	tutorial, err := p.Academic.ScheduleSession(ClassSession{CourseID: 101, TeacherID: "T1", Date: day.AddDate(0, 0, 1), Period: 3, Type: SessionTutorial})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Academic.MarkSession(tutorial.ID, 1, StatusAbsent); err != nil {
+		t.Fatal(err)
+	}
+	if sessions, _ := p.Academic.StudentSessions(101, 1, "T1"); sessions[0].Status != StatusExcused {
+		t.Errorf("expected the tutorial absence to be excused, got %+v", sessions)
+	}
+
+	snap, err := p.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestorePortal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := restored.Academic.Leaves(1, ""); len(got) != 1 || !reflect.DeepEqual(*got[0], *approved) {
+		t.Errorf("expected the leave to survive a snapshot, got %+v", got)
+	}
+	if a, _ := restored.Academic.EnrollmentAttendance(1, 101); a.Held != 2 {
+		t.Errorf("expected excused absences to survive a snapshot, got %+v", a)
+	}
+}
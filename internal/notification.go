@@ -0,0 +1,542 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NotificationKind says what a notification is about, so students can
+// choose which ones they receive.
+type NotificationKind string
+
+const (
+	NotifyDriveOpened       NotificationKind = "drive_opened"
+	NotifyApplicationStatus NotificationKind = "application_status"
+	NotifyShortlisted       NotificationKind = "shortlisted"
+	NotifyMarksUploaded     NotificationKind = "marks_uploaded"
+	NotifyDeadline          NotificationKind = "deadline_reminder"
+	NotifyReEvaluation      NotificationKind = "re_evaluation"
+	NotifyLeave             NotificationKind = "leave"
+)
+
+// NotificationKinds lists every kind, in the order they are documented.
+var NotificationKinds = []NotificationKind{NotifyDriveOpened, NotifyApplicationStatus, NotifyShortlisted, NotifyMarksUploaded, NotifyDeadline, NotifyReEvaluation, NotifyLeave}
+
+// ParseNotificationKind checks that s names a NotificationKind.
+func ParseNotificationKind(s string) (NotificationKind, error) {
+	if k := NotificationKind(s); slices.Contains(NotificationKinds, k) {
+		return k, nil
+	}
+	return "", invalidf("unknown notification kind %q", s)
+}
+
+// Message is a notification rendered for delivery to one student.
+type Message struct {
+	ID        int              `json:"id"`
+	StudentID int              `json:"student_id"`
+	Kind      NotificationKind `json:"kind"`
+	DriveID   int              `json:"drive_id,omitempty"`
+	Subject   string           `json:"subject"`
+	Body      string           `json:"body"`
+	CreatedAt time.Time        `json:"created_at"`
+	ReadAt    time.Time        `json:"read_at,omitzero"` // zero while unread
+}
+
+// Unread reports whether the student has not read the message yet.
+func (m Message) Unread() bool {
+	return m.ReadAt.IsZero()
+}
+
+// Notice is a Notification the Dispatcher can deliver. Message describes
+// it; the dispatcher fills in the recipient, id and time.
+type Notice interface {
+	Notification
+	Message() Message
+}
+
+// StatusNotification tells a student their application changed status.
+type StatusNotification struct {
+	DriveID  int
+	RoleName string
+	From, To ApplicationStatus
+}
+
+func (n *StatusNotification) Send() interface{} { return n.Message() }
+
+func (n *StatusNotification) Message() Message {
+	return Message{
+		Kind:    NotifyApplicationStatus,
+		DriveID: n.DriveID,
+		Subject: fmt.Sprintf("Your %s application is now %s", n.RoleName, n.To),
+		Body:    fmt.Sprintf("Your application for %s (drive #%d) moved from %s to %s.", n.RoleName, n.DriveID, n.From, n.To),
+	}
+}
+
+// ShortlistNotification tells a student they were shortlisted for a drive.
+// It is a status change of its own kind so students can find it in their
+// inbox.
+type ShortlistNotification struct {
+	StatusNotification
+}
+
+func (n *ShortlistNotification) Send() interface{} { return n.Message() }
+
+func (n *ShortlistNotification) Message() Message {
+	m := n.StatusNotification.Message()
+	m.Kind = NotifyShortlisted
+	m.Subject = fmt.Sprintf("You were shortlisted for %s", n.RoleName)
+	m.Body = fmt.Sprintf("You just got shortlisted for %s (drive #%d), prepare yourself for further rounds.", n.RoleName, n.DriveID)
+	return m
+}
+
+// MarksNotification tells a student a teacher uploaded their marks.
+type MarksNotification struct {
+	CourseID   int
+	CourseName string
+	Score      float64
+	Grade      string
+}
+
+func (n *MarksNotification) Send() interface{} { return n.Message() }
+
+func (n *MarksNotification) Message() Message {
+	if n.Grade == "" {
+		return Message{
+			Kind:    NotifyMarksUploaded,
+			Subject: fmt.Sprintf("Marks uploaded for %s", n.CourseName),
+			Body:    fmt.Sprintf("You scored %.2f in %s (course #%d). Your grade follows once the course is graded.", n.Score, n.CourseName, n.CourseID),
+		}
+	}
+	return Message{
+		Kind:    NotifyMarksUploaded,
+		Subject: fmt.Sprintf("Marks uploaded for %s", n.CourseName),
+		Body:    fmt.Sprintf("You scored %.2f in %s (course #%d), grade %s.", n.Score, n.CourseName, n.CourseID, n.Grade),
+	}
+}
+
+// Channel names understood by subscriptions.
+const (
+	ChannelInbox   = "inbox"
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+)
+
+var channelNames = []string{ChannelInbox, ChannelEmail, ChannelWebhook}
+
+// Channel delivers messages to students, for example by email.
+type Channel interface {
+	Name() string
+	Deliver(sub Subscription, m Message) error
+}
+
+// Subscription is how a student wants to be notified. Students without one
+// get every kind of notification in their inbox.
+type Subscription struct {
+	StudentID  int                `json:"student_id"`
+	Channels   []string           `json:"channels"`
+	Kinds      []NotificationKind `json:"kinds,omitempty"` // empty means every kind
+	Email      string             `json:"email,omitempty"`
+	WebhookURL string             `json:"webhook_url,omitempty"`
+}
+
+// DefaultSubscription is the subscription of a student who has not chosen one.
+func DefaultSubscription(studentID int) Subscription {
+	return Subscription{StudentID: studentID, Channels: []string{ChannelInbox}}
+}
+
+// Wants reports whether the subscription covers kind.
+func (s Subscription) Wants(kind NotificationKind) bool {
+	return len(s.Kinds) == 0 || slices.Contains(s.Kinds, kind)
+}
+
+// Validate checks the channels and kinds exist and that the address each
+// channel needs is present and well formed.
+func (s Subscription) Validate() error {
+	for _, ch := range s.Channels {
+		if !slices.Contains(channelNames, ch) {
+			return invalidf("unknown notification channel %q", ch)
+		}
+	}
+	for _, k := range s.Kinds {
+		if _, err := ParseNotificationKind(string(k)); err != nil {
+			return err
+		}
+	}
+	if slices.Contains(s.Channels, ChannelEmail) && s.Email == "" {
+		return invalidf("the email channel needs an email address")
+	}
+	if s.Email != "" {
+		// Only a bare address, so nothing else ends up in the mail headers.
+		if a, err := mail.ParseAddress(s.Email); err != nil || a.Address != s.Email {
+			return invalidf("%q is not an email address", s.Email)
+		}
+	}
+	if slices.Contains(s.Channels, ChannelWebhook) {
+		u, err := url.Parse(s.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return invalidf("the webhook channel needs an http or https URL, got %q", s.WebhookURL)
+		}
+		if !publicHost(u.Hostname()) {
+			return invalidf("the webhook channel may not post to %s, which is not a public address", u.Hostname())
+		}
+	}
+	return nil
+}
+
+// publicHost reports whether a webhook may be sent to host. Names are
+// checked again once resolved, when the webhook is delivered.
+func publicHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return true
+	}
+	return PublicAddr(addr)
+}
+
+// cgnat is the shared address space carriers use behind NAT.
+var cgnat = netip.MustParsePrefix("100.64.0.0/10")
+
+// PublicAddr reports whether addr is reachable on the public internet,
+// rather than a loopback, private, link-local or otherwise special address
+// that a student-supplied webhook must not be able to reach.
+func PublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !cgnat.Contains(addr)
+}
+
+// Inbox is the in-app channel: it keeps every message delivered to it.
+type Inbox struct {
+	messages []Message
+}
+
+func (in *Inbox) Name() string { return ChannelInbox }
+
+func (in *Inbox) Deliver(_ Subscription, m Message) error {
+	in.messages = append(in.messages, m)
+	return nil
+}
+
+// Messages returns the messages delivered to a student, oldest first.
+func (in *Inbox) Messages(studentID int) []Message {
+	var out []Message
+	for _, m := range in.messages {
+		if m.StudentID == studentID {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// InboxQuery selects messages from a student's inbox. Zero fields do not
+// filter.
+type InboxQuery struct {
+	StudentID  int
+	DriveID    int
+	Kind       NotificationKind
+	UnreadOnly bool
+	Offset     int
+	Limit      int // zero for no limit
+}
+
+// InboxPage is one page of the messages matching an InboxQuery.
+type InboxPage struct {
+	Messages []Message
+	Total    int // messages matching the query across every page
+	Unread   int // unread messages among them
+}
+
+// Query returns the messages matching q, newest first.
+func (in *Inbox) Query(q InboxQuery) InboxPage {
+	var page InboxPage
+	for i := len(in.messages) - 1; i >= 0; i-- {
+		m := in.messages[i]
+		switch {
+		case m.StudentID != q.StudentID,
+			q.DriveID != 0 && m.DriveID != q.DriveID,
+			q.Kind != "" && m.Kind != q.Kind,
+			q.UnreadOnly && !m.Unread():
+			continue
+		}
+		if m.Unread() {
+			page.Unread++
+		}
+		if page.Total >= q.Offset && (q.Limit == 0 || len(page.Messages) < q.Limit) {
+			page.Messages = append(page.Messages, m)
+		}
+		page.Total++
+	}
+	return page
+}
+
+// MarkRead marks a student's messages read at at. Messages already read keep
+// the time they were first read. It changes nothing unless every id names a
+// message of that student.
+func (in *Inbox) MarkRead(studentID int, ids []int, at time.Time) error {
+	var found []int
+	for _, id := range ids {
+		i := slices.IndexFunc(in.messages, func(m Message) bool { return m.ID == id && m.StudentID == studentID })
+		if i < 0 {
+			return notFoundf("notification %d of student %d not found", id, studentID)
+		}
+		found = append(found, i)
+	}
+	for _, i := range found {
+		if in.messages[i].Unread() {
+			in.messages[i].ReadAt = at
+		}
+	}
+	return nil
+}
+
+// MarkAllRead marks every unread message of a student read at at, and
+// returns how many it marked.
+func (in *Inbox) MarkAllRead(studentID int, at time.Time) int {
+	n := 0
+	for i, m := range in.messages {
+		if m.StudentID == studentID && m.Unread() {
+			in.messages[i].ReadAt = at
+			n++
+		}
+	}
+	return n
+}
+
+// Dispatcher sends notifications to students over the channels they
+// subscribed to. The inbox channel is always available; others are added
+// with AddChannel.
+type Dispatcher struct {
+	inbox         *Inbox
+	channels      map[string]Channel
+	subscriptions map[int]Subscription
+	lastID        int
+	now           func() time.Time
+
+	// OnError is told about deliveries Post or the queue could not make. By
+	// default they are logged.
+	OnError func(err error)
+
+	queue      chan delivery // nil until StartQueue
+	workers    sync.WaitGroup
+	retries    int
+	retryDelay time.Duration
+}
+
+// delivery is a message waiting in the queue to go out over a channel other
+// than the inbox.
+type delivery struct {
+	ch  Channel
+	sub Subscription
+	m   Message
+}
+
+// deliveryQueueSize is how many deliveries may wait in the queue before
+// further ones are dropped and reported.
+const deliveryQueueSize = 1024
+
+func NewDispatcher() *Dispatcher {
+	inbox := &Inbox{}
+	return &Dispatcher{
+		inbox:         inbox,
+		channels:      map[string]Channel{ChannelInbox: inbox},
+		subscriptions: map[int]Subscription{},
+	}
+}
+
+// AddChannel makes c available to subscriptions naming it, replacing any
+// channel of the same name.
+func (d *Dispatcher) AddChannel(c Channel) {
+	d.channels[c.Name()] = c
+}
+
+// SetClock replaces the clock messages are timestamped with. A nil clock
+// restores time.Now.
+func (d *Dispatcher) SetClock(now func() time.Time) {
+	d.now = now
+}
+
+func (d *Dispatcher) clock() time.Time {
+	if d.now == nil {
+		return time.Now().UTC()
+	}
+	return d.now().UTC()
+}
+
+// MarkRead marks a student's inbox messages read now.
+func (d *Dispatcher) MarkRead(studentID int, ids ...int) error {
+	return d.inbox.MarkRead(studentID, ids, d.clock())
+}
+
+// MarkAllRead marks every message in a student's inbox read now, and returns
+// how many were unread.
+func (d *Dispatcher) MarkAllRead(studentID int) int {
+	return d.inbox.MarkAllRead(studentID, d.clock())
+}
+
+func (d *Dispatcher) Inbox() *Inbox {
+	return d.inbox
+}
+
+// Subscribe replaces a student's subscription.
+func (d *Dispatcher) Subscribe(s Subscription) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	d.subscriptions[s.StudentID] = s
+	return nil
+}
+
+// Subscription returns a student's subscription, or the default one.
+func (d *Dispatcher) Subscription(studentID int) Subscription {
+	if s, ok := d.subscriptions[studentID]; ok {
+		return s
+	}
+	return DefaultSubscription(studentID)
+}
+
+// Subscriptions returns every stored subscription, by student id.
+func (d *Dispatcher) Subscriptions() []Subscription {
+	out := make([]Subscription, 0, len(d.subscriptions))
+	for _, s := range d.subscriptions {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StudentID < out[j].StudentID })
+	return out
+}
+
+// StartQueue makes Notify hand deliveries over channels other than the inbox
+// to workers in the background, so a slow mail server or webhook does not
+// hold up the caller. A failed delivery is tried again up to retries times,
+// waiting retryDelay and then twice as long each time, before it goes to
+// OnError. The inbox is still written before Notify returns.
+func (d *Dispatcher) StartQueue(workers, retries int, retryDelay time.Duration) {
+	if d.queue != nil {
+		return
+	}
+	d.queue = make(chan delivery, deliveryQueueSize)
+	d.retries, d.retryDelay = retries, retryDelay
+	for range max(workers, 1) {
+		d.workers.Add(1)
+		go d.work()
+	}
+}
+
+// StopQueue waits for the workers to deliver what is left in the queue.
+// Notify delivers directly again after it.
+func (d *Dispatcher) StopQueue() {
+	if d.queue == nil {
+		return
+	}
+	close(d.queue)
+	d.workers.Wait()
+	d.queue = nil
+}
+
+func (d *Dispatcher) work() {
+	defer d.workers.Done()
+	for job := range d.queue {
+		if err := d.deliver(job); err != nil {
+			d.report(err)
+		}
+	}
+}
+
+// deliver sends a queued message, retrying it if it fails.
+func (d *Dispatcher) deliver(job delivery) error {
+	wait := d.retryDelay
+	for attempt := 0; ; attempt++ {
+		err := job.ch.Deliver(job.sub, job.m)
+		if err == nil {
+			return nil
+		}
+		if attempt >= d.retries {
+			return fmt.Errorf("notifying student %d by %s after %d attempts: %w", job.m.StudentID, job.ch.Name(), attempt+1, err)
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+func (d *Dispatcher) report(err error) {
+	if d.OnError != nil {
+		d.OnError(err)
+	} else {
+		log.Printf("notifications: %v", err)
+	}
+}
+
+// Notify delivers n to a student over each channel they subscribed to, and
+// returns every delivery that failed. Kinds the student opted out of are
+// dropped silently. Once StartQueue has run, deliveries other than to the
+// inbox are only queued here and their failures go to OnError.
+func (d *Dispatcher) Notify(studentID int, n Notice) error {
+	sub := d.Subscription(studentID)
+	m := n.Message()
+	if !sub.Wants(m.Kind) {
+		return nil
+	}
+	d.lastID++
+	m.ID, m.StudentID, m.CreatedAt = d.lastID, studentID, d.clock()
+	var errs []error
+	for _, name := range sub.Channels {
+		ch, ok := d.channels[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("notifying student %d: channel %q is not configured", studentID, name))
+			continue
+		}
+		if d.queue != nil && ch != Channel(d.inbox) {
+			select {
+			case d.queue <- delivery{ch: ch, sub: sub, m: m}:
+			default:
+				errs = append(errs, fmt.Errorf("notifying student %d by %s: delivery queue is full", studentID, name))
+			}
+			continue
+		}
+		if err := ch.Deliver(sub, m); err != nil {
+			errs = append(errs, fmt.Errorf("notifying student %d by %s: %w", studentID, name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Post is Notify for callers that cannot act on a failed delivery, such as
+// the registrars: failures go to OnError.
+func (d *Dispatcher) Post(studentID int, n Notice) {
+	if err := d.Notify(studentID, n); err != nil {
+		d.report(err)
+	}
+}
+
+// SetNotifier tells the registrar where to send notifications about drives
+// and applications.
+func (pr *PlacementRegistrar) SetNotifier(notify func(studentID int, n Notice)) {
+	pr.notify = notify
+}
+
+// notifyStatus tells the applicant their application moved on from from.
+func (pr *PlacementRegistrar) notifyStatus(app *Application, from ApplicationStatus) {
+	if pr.notify == nil {
+		return
+	}
+	n := StatusNotification{DriveID: app.driveId, From: from, To: app.status}
+	if d := pr.driveByID(app.driveId); d != nil {
+		n.RoleName = d.roleName
+	}
+	if app.status == ShortListed {
+		pr.notify(app.Applicant.ID(), &ShortlistNotification{n})
+		return
+	}
+	pr.notify(app.Applicant.ID(), &n)
+}
@@ -0,0 +1,299 @@
+package internal
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingChannel remembers every message delivered to it.
+type recordingChannel struct {
+	name string
+	got  []Message
+	err  error
+}
+
+func (c *recordingChannel) Name() string { return c.name }
+
+func (c *recordingChannel) Deliver(_ Subscription, m Message) error {
+	c.got = append(c.got, m)
+	return c.err
+}
+
+func TestDispatcher_Notify(t *testing.T) {
+	d := NewDispatcher()
+	now := time.Date(2025, time.July, 1, 9, 0, 0, 0, time.UTC)
+	d.SetClock(func() time.Time { return now })
+	email := &recordingChannel{name: ChannelEmail}
+	d.AddChannel(email)
+
+	status := &StatusNotification{DriveID: 3, RoleName: "Engineer", From: Applied, To: ShortListed}
+	if err := d.Notify(1, status); err != nil {
+		t.Fatal(err)
+	}
+	if msgs := d.Inbox().Messages(1); len(msgs) != 1 || msgs[0].ID != 1 || !msgs[0].CreatedAt.Equal(now) || msgs[0].DriveID != 3 {
+		t.Fatalf("expected one inbox message by default, got %+v", msgs)
+	}
+	if len(email.got) != 0 {
+		t.Errorf("the default subscription should not email, got %+v", email.got)
+	}
+
+	sub := Subscription{StudentID: 1, Channels: []string{ChannelEmail}, Kinds: []NotificationKind{NotifyMarksUploaded}, Email: "alice@college.test"}
+	if err := d.Subscribe(sub); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Notify(1, status); err != nil || len(email.got) != 0 {
+		t.Errorf("kinds outside the subscription should be dropped, got %v, %+v", err, email.got)
+	}
+	if err := d.Notify(1, &MarksNotification{CourseID: 101, CourseName: "Math", Score: 91, Grade: "A+"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(email.got) != 1 || email.got[0].Kind != NotifyMarksUploaded || email.got[0].StudentID != 1 {
+		t.Errorf("expected the marks message by email, got %+v", email.got)
+	}
+	if len(d.Inbox().Messages(1)) != 1 {
+		t.Errorf("a subscription without the inbox should not add to it")
+	}
+
+	email.err = errors.New("mailbox full")
+	if err := d.Subscribe(Subscription{StudentID: 2, Channels: []string{ChannelEmail, ChannelWebhook}, Email: "bob@college.test", WebhookURL: "https://example.test/hook"}); err != nil {
+		t.Fatal(err)
+	}
+	err := d.Notify(2, status)
+	if err == nil || !strings.Contains(err.Error(), "mailbox full") || !strings.Contains(err.Error(), `channel "webhook" is not configured`) {
+		t.Errorf("expected both delivery failures, got %v", err)
+	}
+}
+
+// blockingChannel holds every delivery until release is closed, and fails
+// the first failures of them.
+type blockingChannel struct {
+	recordingChannel
+	release  chan struct{}
+	failures int
+}
+
+func (c *blockingChannel) Deliver(sub Subscription, m Message) error {
+	<-c.release
+	if c.failures > 0 {
+		c.failures--
+		return errors.New("webhook unavailable")
+	}
+	return c.recordingChannel.Deliver(sub, m)
+}
+
+func TestDispatcher_Queue(t *testing.T) {
+	d := NewDispatcher()
+	hook := &blockingChannel{recordingChannel: recordingChannel{name: ChannelWebhook}, release: make(chan struct{}), failures: 2}
+	d.AddChannel(hook)
+	var reported []error
+	d.OnError = func(err error) { reported = append(reported, err) }
+	sub := Subscription{StudentID: 1, Channels: []string{ChannelInbox, ChannelWebhook}, WebhookURL: "https://example.test/hook"}
+	if err := d.Subscribe(sub); err != nil {
+		t.Fatal(err)
+	}
+	d.StartQueue(1, 2, time.Millisecond)
+
+	status := &StatusNotification{DriveID: 3, RoleName: "Engineer", From: Applied, To: ShortListed}
+	if err := d.Notify(1, status); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Inbox().Messages(1)) != 1 {
+		t.Error("the inbox should be written before Notify returns")
+	}
+	close(hook.release)
+	d.StopQueue()
+	if len(hook.got) != 1 || len(reported) != 0 {
+		t.Errorf("expected the webhook delivered on its third attempt, got %+v, errors %v", hook.got, reported)
+	}
+
+	hook.failures = 1
+	d.StartQueue(1, 0, time.Millisecond)
+	if err := d.Notify(1, status); err != nil {
+		t.Fatal(err)
+	}
+	d.StopQueue()
+	if len(reported) != 1 || !strings.Contains(reported[0].Error(), "webhook unavailable") {
+		t.Errorf("expected the failed delivery reported, got %v", reported)
+	}
+}
+
+func TestSubscription_Validate(t *testing.T) {
+	bad := []Subscription{
+		{StudentID: 1, Channels: []string{"sms"}},
+		{StudentID: 1, Channels: []string{ChannelInbox}, Kinds: []NotificationKind{"gossip"}},
+		{StudentID: 1, Channels: []string{ChannelEmail}},
+		{StudentID: 1, Channels: []string{ChannelEmail}, Email: "alice"},
+		{StudentID: 1, Channels: []string{ChannelEmail}, Email: "Alice <alice@college.test>"},
+		{StudentID: 1, Channels: []string{ChannelEmail}, Email: "alice@college.test\r\nBcc: eve@example.test"},
+		{StudentID: 1, Channels: []string{ChannelInbox}, Email: "not an address"},
+		{StudentID: 1, Channels: []string{ChannelWebhook}, WebhookURL: "ftp://example.test"},
+		{StudentID: 1, Channels: []string{ChannelWebhook}, WebhookURL: "http://localhost:8080/hook"},
+		{StudentID: 1, Channels: []string{ChannelWebhook}, WebhookURL: "http://127.0.0.1/hook"},
+		{StudentID: 1, Channels: []string{ChannelWebhook}, WebhookURL: "http://10.0.0.5/hook"},
+		{StudentID: 1, Channels: []string{ChannelWebhook}, WebhookURL: "http://169.254.169.254/latest/meta-data"},
+		{StudentID: 1, Channels: []string{ChannelWebhook}, WebhookURL: "http://[::1]/hook"},
+		{StudentID: 1, Channels: []string{ChannelWebhook}, WebhookURL: "http://[::ffff:192.168.1.1]/hook"},
+	}
+	for _, s := range bad {
+		if err := s.Validate(); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Validate(%+v) = %v, want ErrInvalid", s, err)
+		}
+	}
+	good := Subscription{StudentID: 1, Channels: []string{ChannelWebhook}, WebhookURL: "https://203.0.113.7/hook"}
+	if err := good.Validate(); err != nil {
+		t.Errorf("Validate(%+v) = %v, want nil", good, err)
+	}
+}
+
+func TestRegistrars_RaiseNotifications(t *testing.T) {
+	p := NewPortal()
+	alice := NewStudent(1, "Alice")
+	math := NewCourse(101, "Math")
+	teacher := NewTeacher("T1", "Prof. Smith")
+	p.Academic.AddStudent(alice)
+	p.Academic.AddTeacher(teacher)
+	p.Academic.AddTeacherenrollment(NewTeacherEnrollment(teacher, NewCreditCourse(math, 4)))
+	p.Academic.Enrollnew(NewEnrollNew(alice, math, LetterGrader{}, 0, Attendance{}, teacher))
+	if err := p.Placement.AddApplicant(NewApplicant(alice, AcademicRecord{StudentId: 1, CGPA: 8})); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, time.June, 30, 12, 0, 0, 0, time.UTC)
+	p.Placement.SetClock(func() time.Time { return now })
+
+	company := NewCompany("Acme")
+	p.Placement.AddCompany(company)
+	open := NewDrive(now.AddDate(0, 0, -1), now.AddDate(0, 0, 7), "Engineer", 6, 1000000, Dream)
+	strict := NewDrive(now.AddDate(0, 0, -1), now.AddDate(0, 0, 7), "Researcher", 9, 1500000, Dream)
+	draft := NewDrive(now.AddDate(0, 0, 2), now.AddDate(0, 0, 9), "Analyst", 6, 900000, Day)
+	for _, d := range []*Drive{open, strict, draft} {
+		if err := p.Placement.AddDriveToCompany(company.ID(), d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Placement.ApplyForDrive(1, company.ID(), open.ID()); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Placement.UpdateApplicationStatus(1, open.ID(), ShortListed); err != nil {
+		t.Fatal(err)
+	}
+	ts := &TeacherService{Registrar: p.Academic, Teacher: teacher}
+	if err := ts.UploadStudentMark(101, 1, 93); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.AddDate(0, 0, 3)
+	if got := p.Placement.AnnounceOpenDrives(); len(got) != 1 || got[0] != draft {
+		t.Errorf("expected only the draft drive to be announced once it opened, got %v", got)
+	}
+	if got := p.Placement.AnnounceOpenDrives(); len(got) != 0 {
+		t.Errorf("drives should be announced once, got %v", got)
+	}
+
+	var kinds []NotificationKind
+	for _, m := range p.Notifications.Inbox().Messages(1) {
+		kinds = append(kinds, m.Kind)
+	}
+	want := []NotificationKind{NotifyDriveOpened, NotifyShortlisted, NotifyMarksUploaded, NotifyDriveOpened}
+	if strings.Join(kindStrings(kinds), ",") != strings.Join(kindStrings(want), ",") {
+		t.Errorf("inbox kinds = %v, want %v (the ineligible drive should not be announced to Alice)", kinds, want)
+	}
+}
+
+func kindStrings(kinds []NotificationKind) []string {
+	out := make([]string, len(kinds))
+	for i, k := range kinds {
+		out[i] = string(k)
+	}
+	return out
+}
+
+func TestPortalSnapshot_Notifications(t *testing.T) {
+	original := samplePortal()
+	sub := Subscription{StudentID: 1, Channels: []string{ChannelInbox, ChannelEmail}, Kinds: []NotificationKind{NotifyShortlisted}, Email: "alice@college.test"}
+	if err := original.Notifications.Subscribe(sub); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := original.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestorePortal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs := restored.Notifications.Inbox().Messages(1)
+	if len(msgs) != 1 || msgs[0].Kind != NotifyShortlisted {
+		t.Fatalf("expected the shortlisting message to survive, got %+v", msgs)
+	}
+	if got := restored.Notifications.Subscription(1); got.Email != sub.Email || len(got.Channels) != 2 {
+		t.Errorf("subscription not restored: %+v", got)
+	}
+	restored.Notifications.SetClock(nil)
+	restored.Notifications.Subscribe(DefaultSubscription(1))
+	if err := restored.Notifications.Notify(1, &StatusNotification{To: Cleared}); err != nil {
+		t.Fatal(err)
+	}
+	if msgs := restored.Notifications.Inbox().Messages(1); msgs[len(msgs)-1].ID != msgs[0].ID+1 {
+		t.Errorf("message ids should continue after a restore, got %+v", msgs)
+	}
+}
+
+func TestInbox_QueryAndMarkRead(t *testing.T) {
+	d := NewDispatcher()
+	now := time.Date(2025, time.July, 1, 9, 0, 0, 0, time.UTC)
+	d.SetClock(func() time.Time { return now })
+	for i, drive := range []int{1, 2, 1, 1} {
+		if err := d.Notify(1, &StatusNotification{DriveID: drive, RoleName: "Engineer", From: Applied, To: ShortListed}); err != nil {
+			t.Fatal(err)
+		}
+		if i == 1 {
+			_ = d.Notify(2, &MarksNotification{CourseName: "Math"})
+		}
+	}
+	_ = d.Notify(1, &MarksNotification{CourseName: "Math"})
+	ids := func(page InboxPage) []int {
+		var out []int
+		for _, m := range page.Messages {
+			out = append(out, m.ID)
+		}
+		return out
+	}
+
+	page := d.Inbox().Query(InboxQuery{StudentID: 1})
+	if got := ids(page); !slices.Equal(got, []int{6, 5, 4, 2, 1}) || page.Total != 5 || page.Unread != 5 {
+		t.Errorf("expected student 1's messages newest first, got %v (%d, %d unread)", got, page.Total, page.Unread)
+	}
+	page = d.Inbox().Query(InboxQuery{StudentID: 1, DriveID: 1, Kind: NotifyApplicationStatus, Offset: 1, Limit: 1})
+	if got := ids(page); !slices.Equal(got, []int{4}) || page.Total != 3 {
+		t.Errorf("expected the second of drive 1's status changes, got %v of %d", got, page.Total)
+	}
+
+	now = now.Add(time.Hour)
+	if err := d.MarkRead(1, 4, 3); !errors.Is(err, ErrNotFound) {
+		t.Errorf("marking another student's message should fail, got %v", err)
+	}
+	if page := d.Inbox().Query(InboxQuery{StudentID: 1, UnreadOnly: true}); page.Total != 5 {
+		t.Errorf("a failed MarkRead should mark nothing, %d unread", page.Total)
+	}
+	if err := d.MarkRead(1, 4); err != nil {
+		t.Fatal(err)
+	}
+	page = d.Inbox().Query(InboxQuery{StudentID: 1, DriveID: 1})
+	if page.Unread != 2 || !page.Messages[1].ReadAt.Equal(now) {
+		t.Errorf("expected message 4 read at %v, got %+v", now, page.Messages)
+	}
+	later := now.Add(time.Hour)
+	now = later
+	if n := d.MarkAllRead(1); n != 4 {
+		t.Errorf("MarkAllRead marked %d, want 4", n)
+	}
+	if m := d.Inbox().Query(InboxQuery{StudentID: 1, DriveID: 1, Offset: 1, Limit: 1}).Messages[0]; m.ReadAt.Equal(later) {
+		t.Errorf("messages already read should keep when they were read")
+	}
+	if page := d.Inbox().Query(InboxQuery{StudentID: 2, UnreadOnly: true}); page.Total != 1 {
+		t.Errorf("other students' messages should stay unread, got %d", page.Total)
+	}
+}
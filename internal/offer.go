@@ -0,0 +1,202 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+)
+
+// OfferStatus is where an offer stands in the student's response.
+type OfferStatus int
+
+const (
+	OfferPending OfferStatus = iota
+	OfferAccepted
+	OfferDeclined
+	OfferExpired
+)
+
+var offerStatusStrings = map[OfferStatus]string{
+	OfferPending:  "pending",
+	OfferAccepted: "accepted",
+	OfferDeclined: "declined",
+	OfferExpired:  "expired",
+}
+
+func (s OfferStatus) String() string {
+	return offerStatusStrings[s]
+}
+
+// ParseOfferStatus is the inverse of OfferStatus.String.
+func ParseOfferStatus(s string) (OfferStatus, error) {
+	for status, name := range offerStatusStrings {
+		if name == s {
+			return status, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid offer status %q", s)
+}
+
+// DefaultOfferResponseWindow is how long a student has to respond to an
+// offer unless the registrar is configured otherwise.
+const DefaultOfferResponseWindow = 7 * 24 * time.Hour
+
+// Offer is made to a student when their application is selected. The
+// student accepts or declines it before RespondBy; an offer left pending
+// past its deadline expires.
+type Offer struct {
+	id            int
+	applicationID int
+	studentID     int
+	driveID       int
+	status        OfferStatus
+	issuedAt      time.Time
+	respondBy     time.Time
+	respondedAt   time.Time
+}
+
+func (o *Offer) ID() int                { return o.id }
+func (o *Offer) ApplicationID() int     { return o.applicationID }
+func (o *Offer) StudentID() int         { return o.studentID }
+func (o *Offer) DriveID() int           { return o.driveID }
+func (o *Offer) Status() OfferStatus    { return o.status }
+func (o *Offer) IssuedAt() time.Time    { return o.issuedAt }
+func (o *Offer) RespondBy() time.Time   { return o.respondBy }
+func (o *Offer) RespondedAt() time.Time { return o.respondedAt }
+
+// StatusAt returns the offer's status as it stands at now: a pending offer
+// past its deadline reads as expired even before ExpireOffers records it.
+func (o *Offer) StatusAt(now time.Time) OfferStatus {
+	if o.status == OfferPending && now.After(o.respondBy) {
+		return OfferExpired
+	}
+	return o.status
+}
+
+// SetOfferResponseWindow changes how long students have to respond to
+// offers issued from now on. Zero restores DefaultOfferResponseWindow.
+func (pr *PlacementRegistrar) SetOfferResponseWindow(window time.Duration) {
+	pr.offerWindow = window
+}
+
+func (pr *PlacementRegistrar) OfferResponseWindow() time.Duration {
+	if pr.offerWindow <= 0 {
+		return DefaultOfferResponseWindow
+	}
+	return pr.offerWindow
+}
+
+// issueOffer makes an offer for a newly selected application.
+func (pr *PlacementRegistrar) issueOffer(app *Application, at time.Time) *Offer {
+	offer := &Offer{
+		id:            len(pr.offers) + 1,
+		applicationID: app.id,
+		studentID:     app.Applicant.ID(),
+		driveID:       app.driveId,
+		status:        OfferPending,
+		issuedAt:      at,
+		respondBy:     at.Add(pr.OfferResponseWindow()),
+	}
+	pr.offers = append(pr.offers, offer)
+	if d := pr.driveByID(app.driveId); d != nil {
+		app.Applicant.offersReceived = append(app.Applicant.offersReceived, d)
+	}
+	return offer
+}
+
+// AllOffers returns every offer made, oldest first.
+func (pr *PlacementRegistrar) AllOffers() []*Offer {
+	return pr.offers
+}
+
+// OffersFor returns the offers made to a student, oldest first.
+func (pr *PlacementRegistrar) OffersFor(studentID int) []*Offer {
+	var out []*Offer
+	for _, o := range pr.offers {
+		if o.studentID == studentID {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+func (pr *PlacementRegistrar) OfferByID(id int) (*Offer, error) {
+	for _, o := range pr.offers {
+		if o.id == id {
+			return o, nil
+		}
+	}
+	return nil, notFoundf("offer with id %d not found", id)
+}
+
+// ExpireOffers marks every offer still pending after its deadline as
+// expired and returns them.
+func (pr *PlacementRegistrar) ExpireOffers(now time.Time) []*Offer {
+	var expired []*Offer
+	for _, o := range pr.offers {
+		if o.status == OfferPending && now.After(o.respondBy) {
+			o.status = OfferExpired
+			expired = append(expired, o)
+		}
+	}
+	return expired
+}
+
+// AcceptOffer accepts a pending offer, making it the student's final
+// offer. An offer the student accepted earlier is released as declined.
+func (pr *PlacementRegistrar) AcceptOffer(offerID int) error {
+	offer, err := pr.pendingOffer(offerID)
+	if err != nil {
+		return err
+	}
+	now := pr.Now().UTC()
+	for _, o := range pr.offers {
+		if o.studentID == offer.studentID && o.status == OfferAccepted {
+			o.status, o.respondedAt = OfferDeclined, now
+		}
+	}
+	offer.status, offer.respondedAt = OfferAccepted, now
+	if a, err := pr.ApplicantByID(offer.studentID); err == nil {
+		a.finalOffer = pr.driveByID(offer.driveID)
+	}
+	return nil
+}
+
+// DeclineOffer declines a pending offer.
+func (pr *PlacementRegistrar) DeclineOffer(offerID int) error {
+	offer, err := pr.pendingOffer(offerID)
+	if err != nil {
+		return err
+	}
+	offer.status, offer.respondedAt = OfferDeclined, pr.Now().UTC()
+	return nil
+}
+
+func (pr *PlacementRegistrar) pendingOffer(offerID int) (*Offer, error) {
+	offer, err := pr.OfferByID(offerID)
+	if err != nil {
+		return nil, err
+	}
+	if status := offer.StatusAt(pr.Now()); status != OfferPending {
+		return nil, conflictf("offer %d is already %s", offer.id, status)
+	}
+	return offer, nil
+}
+
+// FinalOffer returns the offer a student has accepted.
+func (pr *PlacementRegistrar) FinalOffer(studentID int) (*Offer, error) {
+	for _, o := range pr.offers {
+		if o.studentID == studentID && o.status == OfferAccepted {
+			return o, nil
+		}
+	}
+	return nil, notFoundf("student %d has not accepted an offer", studentID)
+}
+
+func (pr *PlacementRegistrar) driveByID(id int) *Drive {
+	for _, d := range pr.AllDrives() {
+		if d.ID() == id {
+			return d
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package internal
+
+import "strings"
+
+// OfferPolicy decides whether a student who already holds offers may apply
+// to another drive. Check returns an error wrapping ErrNotEligible that
+// explains which offer blocks the application.
+type OfferPolicy interface {
+	Check(studentID int, drive *Drive, offers []*Drive) error
+}
+
+// TierPolicy restricts applications by the JobCategory of the offers a
+// student holds.
+type TierPolicy struct {
+	// OpenTo lists, for each category of offer held, the categories a
+	// student may still apply to. Categories missing from the map place no
+	// restriction.
+	OpenTo map[JobCategory][]JobCategory
+	// MaxOffers caps how many offers of a category a student may hold
+	// before they can no longer apply to drives of that category. Zero or a
+	// missing entry means no cap.
+	MaxOffers map[JobCategory]int
+}
+
+// DefaultOfferPolicy returns the college's placement rules: a Dream offer
+// leaves only Super Dream and Marquee drives open, a Super Dream offer only
+// Marquee drives, a Marquee offer ends the student's placement season, and
+// a student may hold at most two Day Company offers.
+func DefaultOfferPolicy() TierPolicy {
+	return TierPolicy{
+		OpenTo: map[JobCategory][]JobCategory{
+			Day:        {Day, Dream, SuperDream, Marquee},
+			Dream:      {SuperDream, Marquee},
+			SuperDream: {Marquee},
+			Marquee:    {},
+		},
+		MaxOffers: map[JobCategory]int{Day: 2},
+	}
+}
+
+func (tp TierPolicy) Check(studentID int, drive *Drive, offers []*Drive) error {
+	held := map[JobCategory]int{}
+	for _, offer := range offers {
+		held[offer.JobCategory()]++
+		open, restricted := tp.OpenTo[offer.JobCategory()]
+		if !restricted || containsCategory(open, drive.JobCategory()) {
+			continue
+		}
+		if len(open) == 0 {
+			return notEligiblef("student %d holds a %s offer from drive %d and may not apply to any further drives",
+				studentID, offer.JobCategory(), offer.ID())
+		}
+		return notEligiblef("student %d holds a %s offer from drive %d and may only apply to %s drives, not %s",
+			studentID, offer.JobCategory(), offer.ID(), joinCategories(open), drive.JobCategory())
+	}
+	if limit := tp.MaxOffers[drive.JobCategory()]; limit > 0 && held[drive.JobCategory()] >= limit {
+		return notEligiblef("student %d already holds %d %s offers, the most allowed",
+			studentID, held[drive.JobCategory()], drive.JobCategory())
+	}
+	return nil
+}
+
+func containsCategory(categories []JobCategory, jc JobCategory) bool {
+	for _, c := range categories {
+		if c == jc {
+			return true
+		}
+	}
+	return false
+}
+
+func joinCategories(categories []JobCategory) string {
+	names := make([]string, len(categories))
+	for i, c := range categories {
+		names[i] = c.String()
+	}
+	return strings.Join(names, " or ")
+}
+
+// SetOfferPolicy replaces the policy ApplyForDrive enforces. A nil policy
+// restores DefaultOfferPolicy.
+func (pr *PlacementRegistrar) SetOfferPolicy(policy OfferPolicy) {
+	pr.offerPolicy = policy
+}
+
+func (pr *PlacementRegistrar) OfferPolicy() OfferPolicy {
+	if pr.offerPolicy == nil {
+		return DefaultOfferPolicy()
+	}
+	return pr.offerPolicy
+}
+
+// mayApply reports whether an applicant meets a drive's criteria and the
+// offer policy lets them apply to it, as ApplyForDrive checks.
+func (pr *PlacementRegistrar) mayApply(a *Applicant, d *Drive) bool {
+	return len(d.eligibility.Evaluate(pr.ProfileOf(a))) == 0 && pr.OfferPolicy().Check(a.ID(), d, pr.HeldOffers(a.ID())) == nil
+}
+
+// HeldOffers returns the drives of the offers a student holds: those still
+// pending a response and the one they accepted. Declined and expired offers
+// no longer restrict where the student may apply.
+func (pr *PlacementRegistrar) HeldOffers(studentID int) []*Drive {
+	var held []*Drive
+	now := pr.Now()
+	for _, o := range pr.OffersFor(studentID) {
+		if status := o.StatusAt(now); status != OfferPending && status != OfferAccepted {
+			continue
+		}
+		if d := pr.driveByID(o.driveID); d != nil {
+			held = append(held, d)
+		}
+	}
+	return held
+}
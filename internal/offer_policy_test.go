@@ -0,0 +1,88 @@
+package internal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tieredRegistrar returns a registrar with one drive per job category and a
+// single applicant.
+func tieredRegistrar(t *testing.T) (*PlacementRegistrar, *Company, map[JobCategory]*Drive) {
+	t.Helper()
+	pr := &PlacementRegistrar{}
+	company := NewCompany("Acme")
+	pr.AddCompany(company)
+	drives := map[JobCategory]*Drive{}
+	for _, jc := range []JobCategory{Day, Dream, SuperDream, Marquee} {
+		d := NewDrive(time.Now(), time.Now().AddDate(0, 0, 7), jc.String()+" role", 0, 1000000, jc)
+		company.AddDrive(d)
+		drives[jc] = d
+	}
+	if err := pr.AddApplicant(NewApplicant(NewStudent(1, "Alice"), AcademicRecord{StudentId: 1, CGPA: 9})); err != nil {
+		t.Fatal(err)
+	}
+	return pr, company, drives
+}
+
+// selectFor applies student 1 to d and walks the application to Selected.
+func selectFor(t *testing.T, pr *PlacementRegistrar, company *Company, d *Drive) {
+	t.Helper()
+	if err := pr.ApplyForDrive(1, company.ID(), d.ID()); err != nil {
+		t.Fatal(err)
+	}
+	for _, st := range []ApplicationStatus{ShortListed, Cleared, Selected} {
+		if err := pr.UpdateApplicationStatus(1, d.ID(), st); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestApplyForDrive_OfferTiers(t *testing.T) {
+	pr, company, drives := tieredRegistrar(t)
+	selectFor(t, pr, company, drives[Dream])
+
+	err := pr.ApplyForDrive(1, company.ID(), drives[Day].ID())
+	if !errors.Is(err, ErrNotEligible) || !strings.Contains(err.Error(), "Super Dream or Marquee") {
+		t.Errorf("expected a Dream offer to block Day drives with an explanation, got %v", err)
+	}
+	selectFor(t, pr, company, drives[Marquee])
+	err = pr.ApplyForDrive(1, company.ID(), drives[SuperDream].ID())
+	if !errors.Is(err, ErrNotEligible) || !strings.Contains(err.Error(), "any further drives") {
+		t.Errorf("expected a Marquee offer to block every drive, got %v", err)
+	}
+}
+
+func TestTierPolicy_Check(t *testing.T) {
+	day := func() *Drive { return NewDrive(time.Now(), time.Now(), "Support", 0, 300000, Day) }
+	dream := NewDrive(time.Now(), time.Now(), "Engineer", 0, 1000000, Dream)
+	policy := DefaultOfferPolicy()
+
+	if err := policy.Check(1, day(), []*Drive{day()}); err != nil {
+		t.Errorf("one Day offer should not block another Day drive: %v", err)
+	}
+	if err := policy.Check(1, day(), []*Drive{day(), day()}); !errors.Is(err, ErrNotEligible) {
+		t.Errorf("expected the Day cap to apply, got %v", err)
+	}
+	if err := policy.Check(1, dream, []*Drive{day(), day()}); err != nil {
+		t.Errorf("Day offers should not block Dream drives: %v", err)
+	}
+
+	custom := TierPolicy{MaxOffers: map[JobCategory]int{Dream: 1}}
+	if err := custom.Check(1, dream, []*Drive{NewDrive(time.Now(), time.Now(), "Analyst", 0, 900000, Dream)}); !errors.Is(err, ErrNotEligible) {
+		t.Errorf("expected a custom Dream cap to apply, got %v", err)
+	}
+	if err := custom.Check(1, day(), []*Drive{dream}); err != nil {
+		t.Errorf("categories missing from OpenTo should not restrict: %v", err)
+	}
+}
+
+func TestPlacementRegistrar_SetOfferPolicy(t *testing.T) {
+	pr, company, drives := tieredRegistrar(t)
+	pr.SetOfferPolicy(TierPolicy{})
+	selectFor(t, pr, company, drives[Marquee])
+	if err := pr.ApplyForDrive(1, company.ID(), drives[Day].ID()); err != nil {
+		t.Errorf("an empty policy should not restrict applications: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPlacementRegistrar_OfferLifecycle(t *testing.T) {
+	pr, company, drives := tieredRegistrar(t)
+	selectFor(t, pr, company, drives[Day])
+	selectFor(t, pr, company, drives[Dream])
+
+	offers := pr.OffersFor(1)
+	if len(offers) != 2 || offers[0].Status() != OfferPending || offers[1].DriveID() != drives[Dream].ID() {
+		t.Fatalf("expected two pending offers, got %+v", offers)
+	}
+	if got := offers[0].RespondBy().Sub(offers[0].IssuedAt()); got != DefaultOfferResponseWindow {
+		t.Errorf("expected the default response window, got %v", got)
+	}
+	if _, err := pr.FinalOffer(1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected no final offer before accepting, got %v", err)
+	}
+
+	if err := pr.AcceptOffer(offers[0].ID()); err != nil {
+		t.Fatal(err)
+	}
+	if err := pr.AcceptOffer(offers[0].ID()); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict accepting twice, got %v", err)
+	}
+	if err := pr.AcceptOffer(offers[1].ID()); err != nil {
+		t.Fatal(err)
+	}
+	if offers[0].Status() != OfferDeclined {
+		t.Errorf("accepting a second offer should release the first, got %s", offers[0].Status())
+	}
+	final, err := pr.FinalOffer(1)
+	if err != nil || final.DriveID() != drives[Dream].ID() {
+		t.Errorf("expected the Dream offer to be final, got %+v, %v", final, err)
+	}
+	if ctc, err := pr.applicants[0].getFinalOffer(); err != nil || ctc != drives[Dream].CTC() {
+		t.Errorf("getFinalOffer = %d, %v", ctc, err)
+	}
+	if err := pr.DeclineOffer(99); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown offer, got %v", err)
+	}
+}
+
+func TestPlacementRegistrar_OffersExpire(t *testing.T) {
+	pr, company, drives := tieredRegistrar(t)
+	pr.SetOfferResponseWindow(time.Hour)
+	selectFor(t, pr, company, drives[Dream])
+
+	offer := pr.OffersFor(1)[0]
+	if expired := pr.ExpireOffers(time.Now()); len(expired) != 0 {
+		t.Errorf("offer should not expire before its deadline: %+v", expired)
+	}
+	offer.respondBy = time.Now().Add(-time.Minute)
+	if err := pr.AcceptOffer(offer.ID()); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict accepting an expired offer, got %v", err)
+	}
+	if offer.Status() != OfferPending || offer.StatusAt(time.Now()) != OfferExpired {
+		t.Errorf("expected the offer to read as expired without being rewritten, got %s", offer.Status())
+	}
+	if len(pr.OffersFor(1)) != 1 || offer.Status() != OfferPending {
+		t.Errorf("listing offers should not expire them, got %s", offer.Status())
+	}
+	if err := pr.ApplyForDrive(1, company.ID(), drives[Day].ID()); err != nil {
+		t.Errorf("an expired offer should no longer restrict applications: %v", err)
+	}
+	if expired := pr.ExpireOffers(time.Now()); len(expired) != 1 || offer.Status() != OfferExpired {
+		t.Errorf("ExpireOffers should record the lapsed offer, got %+v", expired)
+	}
+}
+
+func TestGenerateFullReport_UsesAcceptedOffers(t *testing.T) {
+	pr, company, drives := tieredRegistrar(t)
+	selectFor(t, pr, company, drives[Dream])
+
+	if rep := pr.GenerateFullReport(); rep.studentsPlaced != 0 {
+		t.Errorf("pending offers should not count as placements, got %d", rep.studentsPlaced)
+	}
+	if err := pr.AcceptOffer(pr.OffersFor(1)[0].ID()); err != nil {
+		t.Fatal(err)
+	}
+	rep := pr.GenerateFullReport()
+	if rep.studentsPlaced != 1 || rep.highestCTC != drives[Dream].CTC() || rep.averageCTC != float64(drives[Dream].CTC()) {
+		t.Errorf("unexpected CTC statistics: %+v", rep)
+	}
+	if reports := pr.GenerateReportByStudent(); reports[0].finalOffer != drives[Dream] {
+		t.Errorf("report should use the accepted offer, got %v", reports[0].finalOffer)
+	}
+}
@@ -0,0 +1,71 @@
+package internal
+
+// Portal groups the academic and placement registrars that make up one
+// running session of the student portal, along with its user accounts.
+type Portal struct {
+	Academic  *RegistrarWithDocs
+	Placement *PlacementRegistrar
+	Accounts  *AccountRegistry
+	// Notifications delivers the notifications both registrars raise.
+	Notifications *Dispatcher
+	// Reminders sends deadline reminders for the placement registrar.
+	Reminders *ReminderScheduler
+}
+
+// NewPortal returns a portal with empty registrars.
+func NewPortal() *Portal {
+	p := &Portal{
+		Academic:  &RegistrarWithDocs{NewRegistrarS: &NewRegistrarS{}},
+		Placement: &PlacementRegistrar{},
+		Accounts:  &AccountRegistry{},
+
+		Notifications: NewDispatcher(),
+	}
+	p.Reminders = NewReminderScheduler(p.Placement)
+	p.Placement.SetAttendanceSource(p.Academic.AttendancePercentage)
+	p.Notifications.SetClock(p.Placement.Now)
+	p.Placement.SetNotifier(p.Notifications.Post)
+	p.Academic.SetNotifier(p.Notifications.Post)
+	p.Academic.SetResultRecorder(p.Placement.RecordResult)
+	return p
+}
+
+// replace takes on the records of other, keeping p's registrars, the hooks
+// between them, its settings and its notification channels.
+func (p *Portal) replace(other *Portal) {
+	ac := p.Academic.NewRegistrarS
+	notify, recordResults := ac.notify, ac.recordResults
+	*ac = *other.Academic.NewRegistrarS
+	ac.notify, ac.recordResults = notify, recordResults
+	p.Academic.enrollWithDocs = other.Academic.enrollWithDocs
+
+	pr := *p.Placement
+	*p.Placement = *other.Placement
+	p.Placement.offerPolicy, p.Placement.offerWindow = pr.offerPolicy, pr.offerWindow
+	p.Placement.attendance, p.Placement.now, p.Placement.notify = pr.attendance, pr.now, pr.notify
+
+	*p.Accounts = *other.Accounts
+	*p.Notifications.inbox = *other.Notifications.inbox
+	p.Notifications.subscriptions, p.Notifications.lastID = other.Notifications.subscriptions, other.Notifications.lastID
+	p.Reminders.sent = other.Reminders.sent
+}
+
+// AddAccount registers a, checking that the student or teacher it is linked
+// to exists.
+func (p *Portal) AddAccount(a *Account) error {
+	switch a.Role {
+	case RoleStudent:
+		if FindStudentByID(p.Academic.Students(), a.StudentID) == nil {
+			return notFoundf("student with id %d not found", a.StudentID)
+		}
+	case RoleTeacher:
+		found := false
+		for _, t := range p.Academic.Teachers() {
+			found = found || t.ID == a.TeacherID
+		}
+		if !found {
+			return notFoundf("teacher with id %s not found", a.TeacherID)
+		}
+	}
+	return p.Accounts.Add(a)
+}
@@ -0,0 +1,895 @@
+package internal
+
+import "time"
+
+// PortalService is the entry point for callers acting on behalf of a user.
+// Every method takes the calling Principal and checks it against the Policy
+// before touching the registrars.
+type PortalService struct {
+	Portal *Portal
+	Policy Policy
+}
+
+// NewPortalService returns a service over p guarded by policy.
+func NewPortalService(p *Portal, policy Policy) *PortalService {
+	return &PortalService{Portal: p, Policy: policy}
+}
+
+func (ps *PortalService) allowed(by Principal, a Action, r Resource) bool {
+	return ps.Policy.Authorize(by, a, r) == nil
+}
+
+// Students lists the students by may see.
+func (ps *PortalService) Students(by Principal) ([]Student, error) {
+	if err := ps.Policy.Authorize(by, ActionViewStudents, Resource{}); err != nil {
+		return nil, err
+	}
+	var out []Student
+	for _, st := range ps.Portal.Academic.Students() {
+		if ps.allowed(by, ActionViewStudents, Resource{StudentID: st.ID()}) {
+			out = append(out, st)
+		}
+	}
+	return out, nil
+}
+
+func (ps *PortalService) Student(by Principal, id int) (Student, error) {
+	if err := ps.Policy.Authorize(by, ActionViewStudents, Resource{StudentID: id}); err != nil {
+		return Student{}, err
+	}
+	return ps.findStudent(id)
+}
+
+func (ps *PortalService) AddStudent(by Principal, st Student) error {
+	if err := ps.Policy.Authorize(by, ActionManageStudents, Resource{}); err != nil {
+		return err
+	}
+	if FindStudentByID(ps.Portal.Academic.Students(), st.ID()) != nil {
+		return conflictf("student with id %d already exists", st.ID())
+	}
+	ps.Portal.Academic.AddStudent(st)
+	return nil
+}
+
+func (ps *PortalService) Courses(by Principal) ([]Course, error) {
+	if err := ps.Policy.Authorize(by, ActionViewCourses, Resource{}); err != nil {
+		return nil, err
+	}
+	return ps.Portal.Academic.Courses(), nil
+}
+
+func (ps *PortalService) AddCourse(by Principal, c Course) error {
+	if err := ps.Policy.Authorize(by, ActionManageCourses, Resource{}); err != nil {
+		return err
+	}
+	if _, err := ps.findCourse(c.Id); err == nil {
+		return conflictf("course with id %d already exists", c.Id)
+	}
+	ps.Portal.Academic.AddCourse(c)
+	return nil
+}
+
+// GradeScale returns the scale grades are read on.
+func (ps *PortalService) GradeScale(by Principal) (*GradeScale, error) {
+	if err := ps.Policy.Authorize(by, ActionViewCourses, Resource{}); err != nil {
+		return nil, err
+	}
+	return ps.Portal.GradeScale(), nil
+}
+
+// SetGradeScale switches the portal to scale, recalculating every
+// applicant's CGPA on it.
+func (ps *PortalService) SetGradeScale(by Principal, scale *GradeScale) error {
+	if err := ps.Policy.Authorize(by, ActionManageCourses, Resource{}); err != nil {
+		return err
+	}
+	return ps.Portal.SetGradeScale(scale)
+}
+
+// Assessment returns how a course is marked.
+func (ps *PortalService) Assessment(by Principal, courseID int) (*Assessment, error) {
+	if err := ps.Policy.Authorize(by, ActionViewCourses, Resource{}); err != nil {
+		return nil, err
+	}
+	if _, err := ps.findCourse(courseID); err != nil {
+		return nil, err
+	}
+	a, ok := ps.Portal.Academic.Assessment(courseID)
+	if !ok {
+		return nil, notFoundf("course %d has no assessment components", courseID)
+	}
+	return a, nil
+}
+
+// SetAssessment declares the components a course is marked on.
+func (ps *PortalService) SetAssessment(by Principal, a *Assessment) error {
+	if err := ps.Policy.Authorize(by, ActionManageCourses, Resource{}); err != nil {
+		return err
+	}
+	return ps.Portal.Academic.SetAssessment(a)
+}
+
+func (ps *PortalService) Teachers(by Principal) ([]Teacher, error) {
+	if err := ps.Policy.Authorize(by, ActionViewTeachers, Resource{}); err != nil {
+		return nil, err
+	}
+	return ps.Portal.Academic.Teachers(), nil
+}
+
+func (ps *PortalService) AddTeacher(by Principal, t Teacher) error {
+	if err := ps.Policy.Authorize(by, ActionManageTeachers, Resource{}); err != nil {
+		return err
+	}
+	if _, err := ps.findTeacher(t.ID); err == nil {
+		return conflictf("teacher with id %s already exists", t.ID)
+	}
+	ps.Portal.Academic.AddTeacher(t)
+	return nil
+}
+
+// AssignCourse assigns a course to a teacher, worth credits in the given
+// semester. Courses graded on the grade scale need both to go on
+// transcripts.
+func (ps *PortalService) AssignCourse(by Principal, teacherID string, courseID, credits, semester int) error {
+	if err := ps.Policy.Authorize(by, ActionManageTeachers, Resource{TeacherID: teacherID}); err != nil {
+		return err
+	}
+	teacher, err := ps.findTeacher(teacherID)
+	if err != nil {
+		return err
+	}
+	course, err := ps.findCourse(courseID)
+	if err != nil {
+		return err
+	}
+	if credits < 0 || semester < 0 {
+		return invalidf("credits and semester must not be negative")
+	}
+	cc := NewCreditCourse(course, credits)
+	cc.Semester = semester
+	ps.Portal.Academic.AddTeacherenrollment(NewTeacherEnrollment(teacher, cc))
+	return nil
+}
+
+// Enrollments lists the enrollments by may see: their own as a student, the
+// courses they teach as a teacher, everything as an admin.
+func (ps *PortalService) Enrollments(by Principal) ([]EnrollNew, error) {
+	if err := ps.Policy.Authorize(by, ActionViewEnrollments, Resource{}); err != nil {
+		return nil, err
+	}
+	var out []EnrollNew
+	for _, e := range ps.Portal.Academic.EnrollnewList() {
+		if ps.allowed(by, ActionViewEnrollments, Resource{StudentID: e.Student.ID(), TeacherID: e.Teacher.ID}) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// Enroll enrolls a student in a course taught by teacherID.
+func (ps *PortalService) Enroll(by Principal, studentID, courseID int, teacherID string, grader Grader) (EnrollNew, error) {
+	if err := ps.Policy.Authorize(by, ActionManageEnrollments, Resource{StudentID: studentID, TeacherID: teacherID}); err != nil {
+		return EnrollNew{}, err
+	}
+	student, err := ps.findStudent(studentID)
+	if err != nil {
+		return EnrollNew{}, err
+	}
+	course, err := ps.findCourse(courseID)
+	if err != nil {
+		return EnrollNew{}, err
+	}
+	teacher, err := ps.findTeacher(teacherID)
+	if err != nil {
+		return EnrollNew{}, err
+	}
+	for _, e := range ps.Portal.Academic.EnrollnewList() {
+		if e.Student.ID() == studentID && e.Course.Id == courseID && e.Teacher.ID == teacherID {
+			return EnrollNew{}, conflictf("student %d is already enrolled in course %d", studentID, courseID)
+		}
+	}
+	enrollment, ok := Enroll(NewEnrollment(student, course, grader, 0), Attendance{}, teacher, ps.Portal.Academic.Teachermap)
+	if !ok {
+		return EnrollNew{}, notEligiblef("teacher %s does not teach course %d", teacherID, courseID)
+	}
+	ps.Portal.Academic.AddEnrollnew(enrollment)
+	return enrollment, nil
+}
+
+func (ps *PortalService) MarkAttendance(by Principal, courseID, studentID int, teacherID string, present bool, date time.Time) error {
+	if err := ps.Policy.Authorize(by, ActionMarkAttendance, Resource{StudentID: studentID, TeacherID: teacherID}); err != nil {
+		return err
+	}
+	if !Giveattendence(ps.Portal.Academic.NewRegistrarS, courseID, studentID, teacherID, present, date) {
+		return notFoundf("no enrollment of student %d in course %d with teacher %s", studentID, courseID, teacherID)
+	}
+	return nil
+}
+
+func (ps *PortalService) Attendance(by Principal, courseID, studentID int, teacherID string) (map[time.Time]bool, error) {
+	if err := ps.Policy.Authorize(by, ActionViewAttendance, Resource{StudentID: studentID, TeacherID: teacherID}); err != nil {
+		return nil, err
+	}
+	records, found := FetchAttendance(ps.Portal.Academic.NewRegistrarS, courseID, studentID, teacherID)
+	if !found {
+		return nil, notFoundf("no enrollment of student %d in course %d with teacher %s", studentID, courseID, teacherID)
+	}
+	return records, nil
+}
+
+// ScheduleSession adds a session to a course's timetable for one of its
+// teachers.
+func (ps *PortalService) ScheduleSession(by Principal, s ClassSession) (*ClassSession, error) {
+	if err := ps.Policy.Authorize(by, ActionMarkAttendance, Resource{TeacherID: s.TeacherID}); err != nil {
+		return nil, err
+	}
+	return ps.Portal.Academic.ScheduleSession(s)
+}
+
+// Timetable returns a course's sessions, optionally only one teacher's.
+func (ps *PortalService) Timetable(by Principal, courseID int, teacherID string) ([]*ClassSession, error) {
+	if err := ps.Policy.Authorize(by, ActionViewCourses, Resource{}); err != nil {
+		return nil, err
+	}
+	if !ps.Portal.Academic.hasCourse(courseID) {
+		return nil, notFoundf("course with id %d not found", courseID)
+	}
+	return ps.Portal.Academic.Sessions(courseID, teacherID), nil
+}
+
+// SessionRoll returns a session's roll to the teacher holding it.
+func (ps *PortalService) SessionRoll(by Principal, sessionID int) (SessionRoll, error) {
+	if err := ps.Policy.Authorize(by, ActionMarkAttendance, Resource{}); err != nil {
+		return SessionRoll{}, err
+	}
+	s, err := ps.Portal.Academic.Session(sessionID)
+	if err != nil {
+		return SessionRoll{}, err
+	}
+	if err := ps.Policy.Authorize(by, ActionMarkAttendance, Resource{TeacherID: s.TeacherID}); err != nil {
+		return SessionRoll{}, err
+	}
+	return ps.Portal.Academic.SessionRoll(sessionID)
+}
+
+// TakeAttendance records a session's attendance for its whole class and
+// returns the roll.
+func (ps *PortalService) TakeAttendance(by Principal, sessionID int, roll map[int]AttendanceStatus) (SessionRoll, error) {
+	if err := ps.Policy.Authorize(by, ActionMarkAttendance, Resource{}); err != nil {
+		return SessionRoll{}, err
+	}
+	s, err := ps.Portal.Academic.Session(sessionID)
+	if err != nil {
+		return SessionRoll{}, err
+	}
+	if err := ps.Policy.Authorize(by, ActionMarkAttendance, Resource{TeacherID: s.TeacherID}); err != nil {
+		return SessionRoll{}, err
+	}
+	if err := ps.Portal.Academic.TakeAttendance(sessionID, roll); err != nil {
+		return SessionRoll{}, err
+	}
+	return ps.Portal.Academic.SessionRoll(sessionID)
+}
+
+// StudentSessions returns a student's attendance at the sessions of a
+// course taken so far.
+func (ps *PortalService) StudentSessions(by Principal, courseID, studentID int, teacherID string) ([]SessionAttendance, error) {
+	if err := ps.Policy.Authorize(by, ActionViewAttendance, Resource{StudentID: studentID, TeacherID: teacherID}); err != nil {
+		return nil, err
+	}
+	sessions, found := ps.Portal.Academic.StudentSessions(courseID, studentID, teacherID)
+	if !found {
+		return nil, notFoundf("no enrollment of student %d in course %d with teacher %s", studentID, courseID, teacherID)
+	}
+	return sessions, nil
+}
+
+// AttendanceSheet lays out a teacher's attendance in a course for export.
+func (ps *PortalService) AttendanceSheet(by Principal, courseID int, teacherID string) (AttendanceSheet, error) {
+	if err := ps.Policy.Authorize(by, ActionMarkAttendance, Resource{TeacherID: teacherID}); err != nil {
+		return AttendanceSheet{}, err
+	}
+	return ps.Portal.Academic.AttendanceSheet(courseID, teacherID)
+}
+
+// ImportAttendance records the attendance in a teacher's sheet.
+func (ps *PortalService) ImportAttendance(by Principal, sheet AttendanceSheet) (AttendanceImport, error) {
+	if err := ps.Policy.Authorize(by, ActionMarkAttendance, Resource{TeacherID: sheet.TeacherID}); err != nil {
+		return AttendanceImport{}, err
+	}
+	return ps.Portal.Academic.ImportAttendance(sheet)
+}
+
+// AttendanceSummary returns a student's attendance in a course against its
+// minimum, and whether they are debarred from its exam.
+func (ps *PortalService) AttendanceSummary(by Principal, studentID, courseID int) (EnrollmentAttendance, error) {
+	if err := ps.Policy.Authorize(by, ActionViewAttendance, Resource{StudentID: studentID}); err != nil {
+		return EnrollmentAttendance{}, err
+	}
+	a, err := ps.Portal.Academic.EnrollmentAttendance(studentID, courseID)
+	if err != nil {
+		return a, err
+	}
+	if err := ps.Policy.Authorize(by, ActionViewAttendance, Resource{StudentID: studentID, TeacherID: a.TeacherID}); err != nil {
+		return EnrollmentAttendance{}, err
+	}
+	return a, nil
+}
+
+// CourseAttendance sums up attendance among a teacher's students in a
+// course.
+func (ps *PortalService) CourseAttendance(by Principal, teacherID string, courseID int) (CourseAttendance, error) {
+	if err := ps.Policy.Authorize(by, ActionMarkAttendance, Resource{TeacherID: teacherID}); err != nil {
+		return CourseAttendance{}, err
+	}
+	if _, err := ps.findTeacher(teacherID); err != nil {
+		return CourseAttendance{}, err
+	}
+	return ps.Portal.Academic.CourseAttendance(courseID, teacherID)
+}
+
+// AttendanceShortages lists a teacher's students short of the minimum
+// attendance.
+func (ps *PortalService) AttendanceShortages(by Principal, teacherID string) ([]EnrollmentAttendance, error) {
+	if err := ps.Policy.Authorize(by, ActionMarkAttendance, Resource{TeacherID: teacherID}); err != nil {
+		return nil, err
+	}
+	if _, err := ps.findTeacher(teacherID); err != nil {
+		return nil, err
+	}
+	return ps.Portal.Academic.AttendanceShortages(teacherID), nil
+}
+
+// SetMinimumAttendance sets the attendance a course, or with a zero course
+// id every course, requires.
+func (ps *PortalService) SetMinimumAttendance(by Principal, m AttendanceMinimum) error {
+	if err := ps.Policy.Authorize(by, ActionManageCourses, Resource{}); err != nil {
+		return err
+	}
+	return ps.Portal.Academic.SetMinimumAttendance(m)
+}
+
+// CondoneShortage excuses a student's attendance shortage in a course so
+// they are no longer debarred.
+func (ps *PortalService) CondoneShortage(by Principal, studentID, courseID int, reason string) (Condonation, error) {
+	if err := ps.Policy.Authorize(by, ActionManageEnrollments, Resource{StudentID: studentID}); err != nil {
+		return Condonation{}, err
+	}
+	c := Condonation{StudentID: studentID, CourseID: courseID, Reason: reason, By: by.Actor(), At: ps.Portal.Placement.Now().UTC()}
+	if err := ps.Portal.Academic.CondoneShortage(c); err != nil {
+		return Condonation{}, err
+	}
+	return c, nil
+}
+
+// FileLeave records a student's leave request.
+func (ps *PortalService) FileLeave(by Principal, l LeaveRequest) (*LeaveRequest, error) {
+	if err := ps.Policy.Authorize(by, ActionRequestLeave, Resource{StudentID: l.StudentID}); err != nil {
+		return nil, err
+	}
+	return ps.Portal.Academic.FileLeave(l, by.Actor(), ps.Portal.Placement.Now().UTC())
+}
+
+// StudentLeaves returns a student's leave requests.
+func (ps *PortalService) StudentLeaves(by Principal, studentID int) ([]*LeaveRequest, error) {
+	if err := ps.Policy.Authorize(by, ActionViewAttendance, Resource{StudentID: studentID}); err != nil {
+		return nil, err
+	}
+	return ps.Portal.Academic.Leaves(studentID, ""), nil
+}
+
+// TeacherLeaves returns the leave requests a teacher may decide.
+func (ps *PortalService) TeacherLeaves(by Principal, teacherID string, status LeaveStatus) ([]*LeaveRequest, error) {
+	if err := ps.Policy.Authorize(by, ActionMarkAttendance, Resource{TeacherID: teacherID}); err != nil {
+		return nil, err
+	}
+	if _, err := ps.findTeacher(teacherID); err != nil {
+		return nil, err
+	}
+	return ps.Portal.Academic.TeacherLeaves(teacherID, status), nil
+}
+
+// Leaves returns every leave request, for heads of department.
+func (ps *PortalService) Leaves(by Principal, status LeaveStatus) ([]*LeaveRequest, error) {
+	if err := ps.Policy.Authorize(by, ActionApproveLeave, Resource{}); err != nil {
+		return nil, err
+	}
+	return ps.Portal.Academic.Leaves(0, status), nil
+}
+
+// ReviewLeave approves or rejects a pending leave request. Heads of
+// department decide any request; a teacher decides those for a single
+// course they teach the student.
+func (ps *PortalService) ReviewLeave(by Principal, id int, approve bool, note string) (*LeaveRequest, error) {
+	hod := ps.allowed(by, ActionApproveLeave, Resource{})
+	if !hod {
+		if err := ps.Policy.Authorize(by, ActionMarkAttendance, Resource{}); err != nil {
+			return nil, err
+		}
+	}
+	l, err := ps.Portal.Academic.Leave(id)
+	if err != nil {
+		return nil, err
+	}
+	if !hod {
+		i, ok := ps.Portal.Academic.studentEnrollment(l.StudentID, l.CourseID)
+		if l.CourseID == 0 || !ok {
+			return nil, forbiddenf("%s may not decide leave for every course of student %d", by, l.StudentID)
+		}
+		if err := ps.Policy.Authorize(by, ActionMarkAttendance, Resource{TeacherID: ps.Portal.Academic.enroll[i].Teacher.TID()}); err != nil {
+			return nil, err
+		}
+	}
+	return ps.Portal.Academic.ReviewLeave(id, approve, note, by.Actor(), ps.Portal.Placement.Now().UTC())
+}
+
+// TeacherService returns a TeacherService acting as teacherID, provided by
+// may upload marks on that teacher's behalf.
+func (ps *PortalService) TeacherService(by Principal, teacherID string) (*TeacherService, error) {
+	if err := ps.Policy.Authorize(by, ActionUploadMarks, Resource{TeacherID: teacherID}); err != nil {
+		return nil, err
+	}
+	teacher, err := ps.findTeacher(teacherID)
+	if err != nil {
+		return nil, err
+	}
+	return &TeacherService{Registrar: ps.Portal.Academic, Teacher: teacher}, nil
+}
+
+func (ps *PortalService) UploadStudentMark(by Principal, teacherID string, courseID, studentID int, score float64) error {
+	ts, err := ps.TeacherService(by, teacherID)
+	if err != nil {
+		return err
+	}
+	return ts.UploadStudentMark(courseID, studentID, score)
+}
+
+// UploadComponentMark uploads a student's marks in one component of a
+// course's assessment on the teacher's behalf.
+func (ps *PortalService) UploadComponentMark(by Principal, teacherID string, courseID, studentID int, m ComponentMark) error {
+	ts, err := ps.TeacherService(by, teacherID)
+	if err != nil {
+		return err
+	}
+	return ts.UploadComponentMark(courseID, studentID, m)
+}
+
+// FinaliseMarks grades a student's component marks in a course on the
+// teacher's behalf before every component is marked.
+func (ps *PortalService) FinaliseMarks(by Principal, teacherID string, courseID, studentID int) error {
+	ts, err := ps.TeacherService(by, teacherID)
+	if err != nil {
+		return err
+	}
+	return ts.FinaliseMarks(courseID, studentID)
+}
+
+// PreviewCurve shows a teacher the cut-offs and grade counts curve would
+// give their class of a course, without grading it. With an empty
+// teacherID it previews the whole course, which needs permission to
+// moderate grades.
+func (ps *PortalService) PreviewCurve(by Principal, teacherID string, courseID int, curve Curve) (CurvePreview, error) {
+	if teacherID == "" {
+		if err := ps.Policy.Authorize(by, ActionModerateGrades, Resource{}); err != nil {
+			return CurvePreview{}, err
+		}
+		return ps.Portal.Academic.PreviewCurve(courseID, "", curve)
+	}
+	ts, err := ps.TeacherService(by, teacherID)
+	if err != nil {
+		return CurvePreview{}, err
+	}
+	return ts.PreviewCurve(courseID, curve)
+}
+
+// ApplyCurve grades a teacher's class of a course on curve. With an empty
+// teacherID it grades the whole course, whoever teaches it, which needs
+// permission to moderate grades.
+func (ps *PortalService) ApplyCurve(by Principal, teacherID string, courseID int, curve Curve) (CurvePreview, error) {
+	if teacherID == "" {
+		if err := ps.Policy.Authorize(by, ActionModerateGrades, Resource{}); err != nil {
+			return CurvePreview{}, err
+		}
+		return ps.Portal.Academic.ApplyCurve(courseID, "", curve)
+	}
+	ts, err := ps.TeacherService(by, teacherID)
+	if err != nil {
+		return CurvePreview{}, err
+	}
+	return ts.ApplyCurve(courseID, curve)
+}
+
+func (ps *PortalService) CourseResults(by Principal, teacherID string, courseID int) ([]StudentResult, error) {
+	if err := ps.Policy.Authorize(by, ActionViewCourseResults, Resource{TeacherID: teacherID}); err != nil {
+		return nil, err
+	}
+	teacher, err := ps.findTeacher(teacherID)
+	if err != nil {
+		return nil, err
+	}
+	ts := TeacherService{Registrar: ps.Portal.Academic, Teacher: teacher}
+	return ts.GetCourseResults(courseID)
+}
+
+// Transcript returns the academic record built from the marks teachers
+// uploaded for a student in courses graded on the grade scale.
+func (ps *PortalService) Transcript(by Principal, studentID int) (*AcademicRecord, error) {
+	if err := ps.Policy.Authorize(by, ActionViewAcademicRecord, Resource{StudentID: studentID}); err != nil {
+		return nil, err
+	}
+	if _, err := ps.findStudent(studentID); err != nil {
+		return nil, err
+	}
+	return ps.Portal.Academic.Transcript(studentID), nil
+}
+
+// FileReEvaluation asks for a student's mark in a course to be looked at
+// again. Only the student may ask.
+func (ps *PortalService) FileReEvaluation(by Principal, studentID, courseID int, reason string) (*ReEvaluation, error) {
+	if err := ps.Policy.Authorize(by, ActionRequestReEvaluation, Resource{StudentID: studentID}); err != nil {
+		return nil, err
+	}
+	if _, err := ps.findStudent(studentID); err != nil {
+		return nil, err
+	}
+	return ps.Portal.Academic.FileReEvaluation(studentID, courseID, reason, ps.Portal.Placement.Now().UTC())
+}
+
+// StudentReEvaluations returns the re-evaluation requests a student filed.
+func (ps *PortalService) StudentReEvaluations(by Principal, studentID int) ([]*ReEvaluation, error) {
+	if err := ps.Policy.Authorize(by, ActionViewAcademicRecord, Resource{StudentID: studentID}); err != nil {
+		return nil, err
+	}
+	if _, err := ps.findStudent(studentID); err != nil {
+		return nil, err
+	}
+	return ps.Portal.Academic.ReEvaluations(studentID, ""), nil
+}
+
+// TeacherReEvaluations returns the re-evaluation requests against a
+// teacher's marks, for them or the moderation committee to review.
+func (ps *PortalService) TeacherReEvaluations(by Principal, teacherID string) ([]*ReEvaluation, error) {
+	if err := ps.Policy.Authorize(by, ActionUploadMarks, Resource{TeacherID: teacherID}); err != nil {
+		return nil, err
+	}
+	if _, err := ps.findTeacher(teacherID); err != nil {
+		return nil, err
+	}
+	return ps.Portal.Academic.ReEvaluations(0, teacherID), nil
+}
+
+// ReviewReEvaluation decides a re-evaluation request, revising the mark
+// unless rev is nil. The teacher who gave the mark or the moderation
+// committee may review it.
+func (ps *PortalService) ReviewReEvaluation(by Principal, id int, rev *Revision, note string) (*ReEvaluation, error) {
+	moderator := ps.allowed(by, ActionModerateGrades, Resource{})
+	if !moderator {
+		if err := ps.Policy.Authorize(by, ActionUploadMarks, Resource{}); err != nil {
+			return nil, err
+		}
+	}
+	re, err := ps.Portal.Academic.ReEvaluation(id)
+	if err != nil {
+		return nil, err
+	}
+	if !moderator {
+		if err := ps.Policy.Authorize(by, ActionUploadMarks, Resource{TeacherID: re.TeacherID}); err != nil {
+			return nil, err
+		}
+	}
+	return ps.Portal.Academic.ReviewReEvaluation(id, rev, note, by.Actor(), ps.Portal.Placement.Now().UTC())
+}
+
+// GradeHistory returns the marks replaced on re-evaluation for a student.
+func (ps *PortalService) GradeHistory(by Principal, studentID int) ([]GradeChange, error) {
+	if err := ps.Policy.Authorize(by, ActionViewAcademicRecord, Resource{StudentID: studentID}); err != nil {
+		return nil, err
+	}
+	if _, err := ps.findStudent(studentID); err != nil {
+		return nil, err
+	}
+	return ps.Portal.Academic.GradeHistory(studentID), nil
+}
+
+// AcademicRecord returns the academic record a student registered for
+// placements with.
+func (ps *PortalService) AcademicRecord(by Principal, studentID int) (*AcademicRecord, error) {
+	if err := ps.Policy.Authorize(by, ActionViewAcademicRecord, Resource{StudentID: studentID}); err != nil {
+		return nil, err
+	}
+	applicant, err := ps.Portal.Placement.ApplicantByID(studentID)
+	if err != nil {
+		return nil, err
+	}
+	return &applicant.AcademicRecord, nil
+}
+
+func (ps *PortalService) Companies(by Principal) ([]*Company, error) {
+	if err := ps.Policy.Authorize(by, ActionViewCompanies, Resource{}); err != nil {
+		return nil, err
+	}
+	return ps.Portal.Placement.Companies(), nil
+}
+
+func (ps *PortalService) AddCompany(by Principal, name string) (*Company, error) {
+	if err := ps.Policy.Authorize(by, ActionManageCompanies, Resource{}); err != nil {
+		return nil, err
+	}
+	c := NewCompany(name)
+	ps.Portal.Placement.AddCompany(c)
+	return c, nil
+}
+
+func (ps *PortalService) AddDrive(by Principal, companyID int, d *Drive) error {
+	if err := ps.Policy.Authorize(by, ActionManageCompanies, Resource{}); err != nil {
+		return err
+	}
+	return ps.Portal.Placement.AddDriveToCompany(companyID, d)
+}
+
+func (ps *PortalService) Drive(by Principal, companyID, driveID int) (*Drive, error) {
+	if err := ps.Policy.Authorize(by, ActionViewCompanies, Resource{}); err != nil {
+		return nil, err
+	}
+	return ps.Portal.Placement.DriveByID(companyID, driveID)
+}
+
+// Applicants lists the applicants by may see.
+func (ps *PortalService) Applicants(by Principal) ([]*Applicant, error) {
+	if err := ps.Policy.Authorize(by, ActionViewApplicants, Resource{}); err != nil {
+		return nil, err
+	}
+	var out []*Applicant
+	for _, a := range ps.Portal.Placement.Applicants() {
+		if ps.allowed(by, ActionViewApplicants, Resource{StudentID: a.ID()}) {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+// AddApplicant registers a known student for placements, building their
+// academic record from results.
+func (ps *PortalService) AddApplicant(by Principal, studentID int, results []CourseResult, department string, graduationYear int) (*Applicant, error) {
+	if err := ps.Policy.Authorize(by, ActionManageApplicants, Resource{StudentID: studentID}); err != nil {
+		return nil, err
+	}
+	student, err := ps.findStudent(studentID)
+	if err != nil {
+		return nil, err
+	}
+	scale := ps.Portal.GradeScale()
+	if err := scale.CheckResults(results...); err != nil {
+		return nil, err
+	}
+	record := NewAcademicRecord(studentID)
+	record.SetGradeScale(scale)
+	for _, cr := range results {
+		if cr.StudentId != studentID {
+			return nil, notEligiblef("course result for student %d does not belong to student %d", cr.StudentId, studentID)
+		}
+		record.AddResult(cr, cr.Semester)
+	}
+	record.Status = NewGPACalculator().DetermineStatus(scale.OnTenPointScale(record.CGPA))
+	applicant := NewApplicant(student, *record)
+	applicant.Department, applicant.GraduationYear = department, graduationYear
+	if err := ps.Portal.Placement.AddApplicant(applicant); err != nil {
+		return nil, err
+	}
+	return applicant, nil
+}
+
+func (ps *PortalService) ApplyForDrive(by Principal, studentID, companyID, driveID int) error {
+	if err := ps.Policy.Authorize(by, ActionApplyForDrive, Resource{StudentID: studentID}); err != nil {
+		return err
+	}
+	return ps.Portal.Placement.ApplyForDrive(studentID, companyID, driveID)
+}
+
+func (ps *PortalService) UpdateApplicationStatus(by Principal, studentID, driveID int, status ApplicationStatus) error {
+	if err := ps.Policy.Authorize(by, ActionUpdateApplicationStatus, Resource{StudentID: studentID}); err != nil {
+		return err
+	}
+	return ps.Portal.Placement.TransitionApplication(studentID, driveID, status, by.Actor())
+}
+
+// ApplicationHistory returns the audit trail of a student's application.
+func (ps *PortalService) ApplicationHistory(by Principal, studentID, driveID int) ([]StatusChange, error) {
+	if err := ps.Policy.Authorize(by, ActionViewApplicants, Resource{StudentID: studentID}); err != nil {
+		return nil, err
+	}
+	app, err := ps.Portal.Placement.ApplicationFor(studentID, driveID)
+	if err != nil {
+		return nil, err
+	}
+	return app.History(), nil
+}
+
+// StudentPlacementService returns the placement view of a drive for the
+// calling student.
+func (ps *PortalService) StudentPlacementService(by Principal, companyID, driveID int) (*StudentPlacementService, error) {
+	if by.Role != RoleStudent {
+		return nil, forbiddenf("%s is not a student", by)
+	}
+	if err := ps.Policy.Authorize(by, ActionApplyForDrive, Resource{StudentID: by.StudentID}); err != nil {
+		return nil, err
+	}
+	student, err := ps.findStudent(by.StudentID)
+	if err != nil {
+		return nil, err
+	}
+	drive, err := ps.Portal.Placement.DriveByID(companyID, driveID)
+	if err != nil {
+		return nil, err
+	}
+	return NewStudentPlacementService(student, *drive), nil
+}
+
+func (ps *PortalService) findStudent(id int) (Student, error) {
+	st := FindStudentByID(ps.Portal.Academic.Students(), id)
+	if st == nil {
+		return Student{}, notFoundf("student with id %d not found", id)
+	}
+	return *st, nil
+}
+
+func (ps *PortalService) findCourse(id int) (Course, error) {
+	for _, c := range ps.Portal.Academic.Courses() {
+		if c.Id == id {
+			return c, nil
+		}
+	}
+	return Course{}, notFoundf("course with id %d not found", id)
+}
+
+func (ps *PortalService) findTeacher(id string) (Teacher, error) {
+	for _, t := range ps.Portal.Academic.Teachers() {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return Teacher{}, notFoundf("teacher with id %s not found", id)
+}
+
+// Credentials looks username up for a login. Checking the password against
+// them is slow and needs nothing of the portal.
+func (ps *PortalService) Credentials(username string) Credentials {
+	return ps.Portal.Accounts.Credentials(username)
+}
+
+func (ps *PortalService) Accounts(by Principal) ([]*Account, error) {
+	if err := ps.Policy.Authorize(by, ActionManageAccounts, Resource{}); err != nil {
+		return nil, err
+	}
+	return ps.Portal.Accounts.Accounts(), nil
+}
+
+// AddAccount registers an account made by NewAccount, checking that the
+// student or teacher it is linked to exists. Making it hashes its password,
+// which is slow, so it is done before rather than here.
+func (ps *PortalService) AddAccount(by Principal, a *Account) error {
+	if err := ps.Policy.Authorize(by, ActionManageAccounts, Resource{}); err != nil {
+		return err
+	}
+	return ps.Portal.AddAccount(a)
+}
+
+// SetDriveRounds defines the selection rounds of a drive.
+func (ps *PortalService) SetDriveRounds(by Principal, companyID, driveID int, rounds []Round) error {
+	if err := ps.Policy.Authorize(by, ActionManageCompanies, Resource{}); err != nil {
+		return err
+	}
+	return ps.Portal.Placement.SetDriveRounds(companyID, driveID, rounds)
+}
+
+func (ps *PortalService) RecordRoundResult(by Principal, studentID, companyID, driveID, round int, score float64) (RoundResult, error) {
+	if err := ps.Policy.Authorize(by, ActionUpdateApplicationStatus, Resource{StudentID: studentID}); err != nil {
+		return RoundResult{}, err
+	}
+	return ps.Portal.Placement.RecordRoundResult(studentID, companyID, driveID, round, score, by.Actor())
+}
+
+// PublishResults announces a drive's results, after which its application
+// statuses are final.
+func (ps *PortalService) PublishResults(by Principal, companyID, driveID int) error {
+	if err := ps.Policy.Authorize(by, ActionUpdateApplicationStatus, Resource{}); err != nil {
+		return err
+	}
+	return ps.Portal.Placement.PublishResults(companyID, driveID)
+}
+
+func (ps *PortalService) ArchiveDrive(by Principal, companyID, driveID int) error {
+	if err := ps.Policy.Authorize(by, ActionManageCompanies, Resource{}); err != nil {
+		return err
+	}
+	return ps.Portal.Placement.ArchiveDrive(companyID, driveID)
+}
+
+func (ps *PortalService) RoundFunnel(by Principal, companyID, driveID int) ([]RoundFunnel, error) {
+	if err := ps.Policy.Authorize(by, ActionViewPlacementReports, Resource{}); err != nil {
+		return nil, err
+	}
+	return ps.Portal.Placement.RoundFunnel(companyID, driveID)
+}
+
+func (ps *PortalService) Offers(by Principal, studentID int) ([]*Offer, error) {
+	if err := ps.Policy.Authorize(by, ActionViewOffers, Resource{StudentID: studentID}); err != nil {
+		return nil, err
+	}
+	if _, err := ps.Portal.Placement.ApplicantByID(studentID); err != nil {
+		return nil, err
+	}
+	return ps.Portal.Placement.OffersFor(studentID), nil
+}
+
+// RespondToOffer accepts or declines an offer on behalf of the student it
+// was made to.
+func (ps *PortalService) RespondToOffer(by Principal, offerID int, accept bool) (*Offer, error) {
+	if err := ps.Policy.Authorize(by, ActionRespondToOffer, Resource{}); err != nil {
+		return nil, err
+	}
+	offer, err := ps.Portal.Placement.OfferByID(offerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ps.Policy.Authorize(by, ActionRespondToOffer, Resource{StudentID: offer.StudentID()}); err != nil {
+		return nil, err
+	}
+	if accept {
+		err = ps.Portal.Placement.AcceptOffer(offerID)
+	} else {
+		err = ps.Portal.Placement.DeclineOffer(offerID)
+	}
+	return offer, err
+}
+
+// Notifications returns the messages in a student's inbox that match q,
+// newest first.
+func (ps *PortalService) Notifications(by Principal, q InboxQuery) (InboxPage, error) {
+	if err := ps.Policy.Authorize(by, ActionViewNotifications, Resource{StudentID: q.StudentID}); err != nil {
+		return InboxPage{}, err
+	}
+	if _, err := ps.findStudent(q.StudentID); err != nil {
+		return InboxPage{}, err
+	}
+	return ps.Portal.Notifications.Inbox().Query(q), nil
+}
+
+// MarkNotificationsRead marks messages in a student's inbox read. Only the
+// student may do so.
+func (ps *PortalService) MarkNotificationsRead(by Principal, studentID int, ids ...int) error {
+	if err := ps.Policy.Authorize(by, ActionReadNotifications, Resource{StudentID: studentID}); err != nil {
+		return err
+	}
+	return ps.Portal.Notifications.MarkRead(studentID, ids...)
+}
+
+// MarkAllNotificationsRead marks a student's whole inbox read and returns how
+// many messages were unread.
+func (ps *PortalService) MarkAllNotificationsRead(by Principal, studentID int) (int, error) {
+	if err := ps.Policy.Authorize(by, ActionReadNotifications, Resource{StudentID: studentID}); err != nil {
+		return 0, err
+	}
+	return ps.Portal.Notifications.MarkAllRead(studentID), nil
+}
+
+func (ps *PortalService) Subscription(by Principal, studentID int) (Subscription, error) {
+	if err := ps.Policy.Authorize(by, ActionViewNotifications, Resource{StudentID: studentID}); err != nil {
+		return Subscription{}, err
+	}
+	if _, err := ps.findStudent(studentID); err != nil {
+		return Subscription{}, err
+	}
+	return ps.Portal.Notifications.Subscription(studentID), nil
+}
+
+// Subscribe replaces how a student is notified.
+func (ps *PortalService) Subscribe(by Principal, sub Subscription) error {
+	if err := ps.Policy.Authorize(by, ActionManageSubscriptions, Resource{StudentID: sub.StudentID}); err != nil {
+		return err
+	}
+	if _, err := ps.findStudent(sub.StudentID); err != nil {
+		return err
+	}
+	return ps.Portal.Notifications.Subscribe(sub)
+}
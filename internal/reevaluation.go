@@ -0,0 +1,220 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReEvaluationStatus is where a re-evaluation request stands.
+type ReEvaluationStatus string
+
+const (
+	ReEvaluationPending ReEvaluationStatus = "pending"
+	// ReEvaluationUpheld means the reviewer kept the original mark.
+	ReEvaluationUpheld ReEvaluationStatus = "upheld"
+	// ReEvaluationRevised means the reviewer changed the mark.
+	ReEvaluationRevised ReEvaluationStatus = "revised"
+)
+
+// ReEvaluation is a student's request to have their mark in a course looked
+// at again by its teacher or the moderation committee.
+type ReEvaluation struct {
+	ID        int                `json:"id"`
+	StudentID int                `json:"student_id"`
+	CourseID  int                `json:"course_id"`
+	TeacherID string             `json:"teacher_id"`
+	Reason    string             `json:"reason"`
+	Status    ReEvaluationStatus `json:"status"`
+	// Score and Grade are the mark contested.
+	Score      float64   `json:"score"`
+	Grade      string    `json:"grade"`
+	FiledAt    time.Time `json:"filed_at"`
+	ReviewedBy string    `json:"reviewed_by,omitempty"`
+	ReviewedAt time.Time `json:"reviewed_at,omitzero"`
+	Note       string    `json:"note,omitempty"`
+}
+
+// Revision is the mark a reviewer awards: a new final score or, for courses
+// marked by component, new marks in one component.
+type Revision struct {
+	Score     float64 `json:"score"`
+	Component string  `json:"component,omitempty"`
+	Attempt   int     `json:"attempt,omitempty"`
+}
+
+// GradeChange records a mark replaced on re-evaluation, keeping the
+// original.
+type GradeChange struct {
+	StudentID      int       `json:"student_id"`
+	CourseID       int       `json:"course_id"`
+	ReEvaluationID int       `json:"re_evaluation_id"`
+	OldScore       float64   `json:"old_score"`
+	OldGrade       string    `json:"old_grade"`
+	NewScore       float64   `json:"new_score"`
+	NewGrade       string    `json:"new_grade"`
+	At             time.Time `json:"at"`
+	Actor          string    `json:"actor"`
+}
+
+// ReEvaluationNotification tells a student how their re-evaluation request
+// was decided.
+type ReEvaluationNotification struct {
+	ReEvaluation
+	CourseName string
+	NewGrade   string
+}
+
+func (n *ReEvaluationNotification) Send() interface{} { return n.Message() }
+
+func (n *ReEvaluationNotification) Message() Message {
+	m := Message{Kind: NotifyReEvaluation, Subject: fmt.Sprintf("Re-evaluation of %s %s", n.CourseName, n.Status)}
+	if n.Status == ReEvaluationRevised {
+		m.Body = fmt.Sprintf("Your mark in %s (course #%d) was revised from %s to %s.", n.CourseName, n.CourseID, n.Grade, n.NewGrade)
+	} else {
+		m.Body = fmt.Sprintf("Your mark in %s (course #%d) was reviewed and stays at %s.", n.CourseName, n.CourseID, n.Grade)
+	}
+	if n.Note != "" {
+		m.Body += " " + n.Note
+	}
+	return m
+}
+
+// studentEnrollment returns the position of the student's enrollment in a
+// course.
+func (r *NewRegistrarS) studentEnrollment(studentID, courseID int) (int, bool) {
+	for i, e := range r.enroll {
+		if e.Student.ID() == studentID && e.Course.Id == courseID {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// FileReEvaluation records a student's request to have their graded mark
+// in a course re-evaluated. A student may have one request per course
+// pending at a time.
+func (r *NewRegistrarS) FileReEvaluation(studentID, courseID int, reason string, at time.Time) (*ReEvaluation, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, invalidf("a re-evaluation request needs a reason")
+	}
+	i, ok := r.studentEnrollment(studentID, courseID)
+	if !ok {
+		return nil, notFoundf("student %d is not enrolled in course %d", studentID, courseID)
+	}
+	e := r.enroll[i]
+	grade, err := r.grade(e.Enrollment)
+	if err != nil {
+		return nil, err
+	}
+	if grade == "" {
+		return nil, notEligiblef("student %d has not been graded in course %d yet", studentID, courseID)
+	}
+	for _, re := range r.reEvaluations {
+		if re.StudentID == studentID && re.CourseID == courseID && re.Status == ReEvaluationPending {
+			return nil, conflictf("re-evaluation %d of course %d is already pending", re.ID, courseID)
+		}
+	}
+	re := &ReEvaluation{
+		ID:        len(r.reEvaluations) + 1,
+		StudentID: studentID,
+		CourseID:  courseID,
+		TeacherID: e.Teacher.TID(),
+		Reason:    reason,
+		Status:    ReEvaluationPending,
+		Score:     e.score,
+		Grade:     grade,
+		FiledAt:   at,
+	}
+	r.reEvaluations = append(r.reEvaluations, re)
+	return re, nil
+}
+
+// ReEvaluation returns the re-evaluation request with the given id.
+func (r *NewRegistrarS) ReEvaluation(id int) (*ReEvaluation, error) {
+	if id < 1 || id > len(r.reEvaluations) {
+		return nil, notFoundf("re-evaluation %d not found", id)
+	}
+	return r.reEvaluations[id-1], nil
+}
+
+// ReEvaluations returns the requests filed by a student, or for a teacher's
+// courses, oldest first. A zero studentID or empty teacherID matches all.
+func (r *NewRegistrarS) ReEvaluations(studentID int, teacherID string) []*ReEvaluation {
+	var out []*ReEvaluation
+	for _, re := range r.reEvaluations {
+		if (studentID == 0 || re.StudentID == studentID) && (teacherID == "" || re.TeacherID == teacherID) {
+			out = append(out, re)
+		}
+	}
+	return out
+}
+
+// GradeHistory returns the marks replaced on re-evaluation for a student,
+// oldest first.
+func (r *NewRegistrarS) GradeHistory(studentID int) []GradeChange {
+	var out []GradeChange
+	for _, c := range r.gradeHistory {
+		if c.StudentID == studentID {
+			out = append(out, c)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out
+}
+
+// ReviewReEvaluation decides a pending request. Without a revision the
+// mark is upheld; otherwise the revised mark is uploaded on behalf of the
+// course's teacher, so the new grade reaches the transcript and the
+// student's SGPA and CGPA, and the original is kept in the grade history.
+// The student is sent a ReEvaluationNotification either way, rather than
+// the usual one for uploaded marks.
+func (r *RegistrarWithDocs) ReviewReEvaluation(id int, rev *Revision, note, actor string, at time.Time) (*ReEvaluation, error) {
+	re, err := r.ReEvaluation(id)
+	if err != nil {
+		return nil, err
+	}
+	if re.Status != ReEvaluationPending {
+		return nil, conflictf("re-evaluation %d is already %s", id, re.Status)
+	}
+	i, ok := r.studentEnrollment(re.StudentID, re.CourseID)
+	if !ok {
+		return nil, notFoundf("student %d is not enrolled in course %d", re.StudentID, re.CourseID)
+	}
+	old := r.enroll[i]
+	oldGrade, err := r.grade(old.Enrollment)
+	if err != nil {
+		return nil, err
+	}
+	status := ReEvaluationUpheld
+	newGrade := oldGrade
+	if rev != nil {
+		ts := &TeacherService{Registrar: r, Teacher: old.Teacher, quiet: true}
+		if rev.Component != "" {
+			err = ts.UploadComponentMark(re.CourseID, re.StudentID, ComponentMark{Component: rev.Component, Attempt: rev.Attempt, Marks: rev.Score})
+		} else {
+			err = ts.UploadStudentMark(re.CourseID, re.StudentID, rev.Score)
+		}
+		if err != nil {
+			return nil, err
+		}
+		e := r.enroll[i]
+		if newGrade, err = r.grade(e.Enrollment); err != nil {
+			return nil, err
+		}
+		if e.score != old.score {
+			status = ReEvaluationRevised
+			r.gradeHistory = append(r.gradeHistory, GradeChange{
+				StudentID: re.StudentID, CourseID: re.CourseID, ReEvaluationID: re.ID,
+				OldScore: old.score, OldGrade: oldGrade, NewScore: e.score, NewGrade: newGrade,
+				At: at, Actor: actor,
+			})
+		}
+	}
+	re.Status, re.ReviewedBy, re.ReviewedAt, re.Note = status, actor, at, note
+	if r.notify != nil {
+		r.notify(re.StudentID, &ReEvaluationNotification{ReEvaluation: *re, CourseName: old.Course.Name, NewGrade: newGrade})
+	}
+	return re, nil
+}
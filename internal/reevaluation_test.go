@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReEvaluation(t *testing.T) {
+	p := NewPortal()
+	teacher := NewTeacher("T1", "Prof. Smith")
+	alice := NewStudent(1, "Alice")
+	math, art := NewCourse(101, "Math"), NewCourse(102, "Art")
+	p.Academic.AddStudent(alice)
+	p.Academic.AddTeacher(teacher)
+	for _, c := range []Course{math, art} {
+		cc := NewCreditCourse(c, 4)
+		cc.Semester = 1
+		p.Academic.AddTeacherenrollment(NewTeacherEnrollment(teacher, cc))
+	}
+	p.Academic.AddEnrollnew(NewEnrollNew(alice, math, ScaleGrader{}, 0, Attendance{}, teacher))
+	p.Academic.AddEnrollnew(NewEnrollNew(alice, art, CurveGrader{}, 0, Attendance{}, teacher))
+	if err := p.Placement.AddApplicant(NewApplicant(alice, *NewAcademicRecord(1))); err != nil {
+		t.Fatal(err)
+	}
+	var sent []Message
+	p.Academic.SetNotifier(func(studentID int, n Notice) { sent = append(sent, n.Message()) })
+	ts := &TeacherService{Registrar: p.Academic, Teacher: teacher}
+	if err := ts.UploadStudentMark(101, 1, 75); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.UploadStudentMark(102, 1, 60); err != nil {
+		t.Fatal(err)
+	}
+	filed := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+
+	if _, err := p.Academic.FileReEvaluation(1, 101, " ", filed); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected a request without a reason to be refused, got %v", err)
+	}
+	if _, err := p.Academic.FileReEvaluation(1, 103, "recount", filed); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a course the student is not enrolled in to be refused, got %v", err)
+	}
+	if _, err := p.Academic.FileReEvaluation(1, 102, "recount", filed); !errors.Is(err, ErrNotEligible) {
+		t.Errorf("expected an ungraded mark to be refused, got %v", err)
+	}
+	re, err := p.Academic.FileReEvaluation(1, 101, "question 3 was not marked", filed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if re.ID != 1 || re.Status != ReEvaluationPending || re.Grade != "A" || re.Score != 75 || re.TeacherID != "T1" {
+		t.Errorf("expected a pending request against the A, got %+v", re)
+	}
+	if _, err := p.Academic.FileReEvaluation(1, 101, "again", filed); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected a second pending request to be refused, got %v", err)
+	}
+
+	reviewed := filed.Add(48 * time.Hour)
+	before := len(sent)
+	if _, err := p.Academic.ReviewReEvaluation(1, &Revision{Score: 85}, "question 3 added", "committee", reviewed); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != before+1 {
+		t.Errorf("expected only the re-evaluation notice for a revision, got %+v", sent[before:])
+	}
+	if re.Status != ReEvaluationRevised || re.ReviewedBy != "committee" || !re.ReviewedAt.Equal(reviewed) {
+		t.Errorf("expected the request to be revised by the committee, got %+v", re)
+	}
+	want := []GradeChange{{StudentID: 1, CourseID: 101, ReEvaluationID: 1, OldScore: 75, OldGrade: "A", NewScore: 85, NewGrade: "A+", At: reviewed, Actor: "committee"}}
+	if got := p.Academic.GradeHistory(1); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the A to be kept in the history, got %+v", got)
+	}
+	applicant, _ := p.Placement.ApplicantByID(1)
+	if sem := applicant.Semesters[1]; sem.Courses[101].Grade != Aplus || sem.SGPA != 9 {
+		t.Errorf("expected the A+ to reach the placement record's SGPA, got %+v", sem)
+	}
+	if last := sent[len(sent)-1]; last.Kind != NotifyReEvaluation || last.Body != "Your mark in Math (course #101) was revised from A to A+. question 3 added" {
+		t.Errorf("expected the student to be told of the revision, got %+v", last)
+	}
+	if _, err := p.Academic.ReviewReEvaluation(1, nil, "", "committee", reviewed); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected a decided request to stay decided, got %v", err)
+	}
+
+	if _, err := p.Academic.FileReEvaluation(1, 101, "still too low", reviewed); err != nil {
+		t.Fatal(err)
+	}
+	if upheld, err := p.Academic.ReviewReEvaluation(2, nil, "", "T1", reviewed); err != nil || upheld.Status != ReEvaluationUpheld {
+		t.Errorf("expected the mark to be upheld, got %+v, %v", upheld, err)
+	}
+	if len(p.Academic.GradeHistory(1)) != 1 || len(p.Academic.ReEvaluations(1, "")) != 2 {
+		t.Error("expected an upheld mark to leave the history alone")
+	}
+
+	snap, err := p.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestorePortal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := restored.Academic.ReEvaluations(0, "T1"); len(got) != 2 || !reflect.DeepEqual(*got[0], *re) {
+		t.Errorf("expected the requests to survive a snapshot, got %+v", got)
+	}
+	if got := restored.Academic.GradeHistory(1); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the history to survive a snapshot, got %+v", got)
+	}
+}
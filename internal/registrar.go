@@ -19,6 +19,23 @@ type NewRegistrarS struct {
 	teacher    []Teacher           // List of teachers
 	Teachermap []TeacherEnrollment // list of Map of teachers with their courses
 	enroll     []EnrollNew         // List of enrollments (students with courses) with additional teacher and attendance information
+	notify     func(studentID int, n Notice)
+	gradeScale *GradeScale // nil is the DefaultGradeScale
+	// results are the transcript entries recorded from uploaded marks.
+	results       []CourseResult
+	recordResults func(CourseResult)
+	assessments   map[int]*Assessment // by course
+	reEvaluations []*ReEvaluation     // by id, from 1
+	gradeHistory  []GradeChange
+	minAttendance map[int]float64 // by course; 0 is the default
+	condonations  []Condonation
+	sessions      []*ClassSession // the timetable, by id from 1
+	leaves        []*LeaveRequest // by id, from 1
+}
+
+// SetNotifier tells the registrar where to send notifications about marks.
+func (r *NewRegistrarS) SetNotifier(notify func(studentID int, n Notice)) {
+	r.notify = notify
 }
 
 type RegistrarWithDocs struct {
@@ -85,6 +102,23 @@ func (r *Registrar) Enrollments() []Enrollment {
 	return r.enrollments
 }
 
+func (r *Registrar) Students() []Student {
+	return r.students
+}
+
+func (r *Registrar) Courses() []Course {
+	return r.courses
+}
+
+func (r *NewRegistrarS) Teachers() []Teacher {
+	return r.teacher
+}
+
+// EnrollnewList returns the enrollments that carry teacher and attendance information.
+func (r *NewRegistrarS) EnrollnewList() []EnrollNew {
+	return r.enroll
+}
+
 type StudentData struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
@@ -97,37 +131,51 @@ type courseData struct {
 }
 
 func (regis *Registrar) LoadCourses() {
+	if err := regis.LoadCoursesFrom("courses.json"); err != nil {
+		log.Fatal("Failed to load courses.json:", err)
+	}
+}
+
+// LoadCoursesFrom adds every course in the given JSON file to the registrar.
+func (regis *Registrar) LoadCoursesFrom(path string) error {
 	var coursesData []courseData
-	data, err := os.ReadFile("courses.json")
+	data, err := os.ReadFile(path)
 	if err != nil {
-		log.Fatal("Failed to read courses.json:", err)
+		return err
 	}
-	err = json.Unmarshal(data, &coursesData)
-	if err != nil {
-		log.Fatal("Failed to unmarshal courses:", err)
+	if err := json.Unmarshal(data, &coursesData); err != nil {
+		return err
 	}
 	for _, course := range coursesData {
 		regis.AddCourse(NewCourse(course.ID, course.Name))
 	}
+	return nil
 }
 
 func (r *Registrar) LoadStudents() {
-	var students []StudentData
-	data, err := os.ReadFile("students.json")
-	if err != nil {
-		log.Fatal("Failed to read students.json:", err)
+	r.students = nil
+	if err := r.LoadStudentsFrom("students.json"); err != nil {
+		log.Fatal("Failed to load students.json:", err)
 	}
+}
 
-	err = json.Unmarshal(data, &students)
+// LoadStudentsFrom adds every student in the given JSON file to the registrar.
+func (r *Registrar) LoadStudentsFrom(path string) error {
+	var students []StudentData
+	data, err := os.ReadFile(path)
 	if err != nil {
-		log.Fatal("Failed to unmarshal students:", err)
+		return err
+	}
+	if err := json.Unmarshal(data, &students); err != nil {
+		return err
 	}
-	r.students = make([]Student, 0, len(students))
 	for _, sd := range students {
-		student := NewStudent(sd.ID, sd.Name)
-		r.AddStudent(student)
+		if sd.ID <= 0 {
+			return fmt.Errorf("student id must be positive, got %d", sd.ID)
+		}
+		r.AddStudent(NewStudent(sd.ID, sd.Name))
 	}
-
+	return nil
 }
 
 func (r *Registrar) DisplayStudents() {
@@ -0,0 +1,192 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DefaultReminderOffsets are how long before a deadline reminders go out.
+var DefaultReminderOffsets = []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, 2 * time.Hour}
+
+// DeadlineReminder tells a student a deadline is near: a drive they can
+// still apply to is about to close, or an offer is waiting for their answer.
+type DeadlineReminder struct {
+	DriveID  int
+	OfferID  int // zero for application deadlines
+	RoleName string
+	Deadline time.Time
+	Left     time.Duration
+}
+
+// DaysLeft returns the whole days left before the deadline.
+func (r *DeadlineReminder) DaysLeft() int {
+	return int(r.Left / (24 * time.Hour))
+}
+
+func (r *DeadlineReminder) Send() interface{} {
+	return r.DaysLeft()
+}
+
+func (r *DeadlineReminder) Message() Message {
+	left, at := formatLeft(r.Left), r.Deadline.Format(time.RFC1123)
+	if r.OfferID != 0 {
+		return Message{
+			Kind:    NotifyDeadline,
+			DriveID: r.DriveID,
+			Subject: fmt.Sprintf("Your %s offer expires in %s", r.RoleName, left),
+			Body:    fmt.Sprintf("Accept or decline offer #%d for %s (drive #%d) by %s.", r.OfferID, r.RoleName, r.DriveID, at),
+		}
+	}
+	return Message{
+		Kind:    NotifyDeadline,
+		DriveID: r.DriveID,
+		Subject: fmt.Sprintf("Applications for %s close in %s", r.RoleName, left),
+		Body:    fmt.Sprintf("You are eligible for %s (drive #%d) and have not applied yet. Applications close %s.", r.RoleName, r.DriveID, at),
+	}
+}
+
+// formatLeft renders a time left in the largest whole unit: days, hours or
+// minutes.
+func formatLeft(d time.Duration) string {
+	unit := func(n int, name string) string {
+		if n == 1 {
+			return "1 " + name
+		}
+		return fmt.Sprintf("%d %ss", n, name)
+	}
+	switch {
+	case d >= 24*time.Hour:
+		return unit(int(d/(24*time.Hour)), "day")
+	case d >= time.Hour:
+		return unit(int(d/time.Hour), "hour")
+	default:
+		return unit(max(int(d/time.Minute), 1), "minute")
+	}
+}
+
+// Reminder records a reminder the scheduler sent.
+type Reminder struct {
+	Key       string    `json:"key"`
+	StudentID int       `json:"student_id"`
+	DriveID   int       `json:"drive_id"`
+	OfferID   int       `json:"offer_id,omitempty"`
+	Deadline  time.Time `json:"deadline"`
+	SentAt    time.Time `json:"sent_at"`
+}
+
+// ReminderScheduler reminds students of approaching deadlines: eligible
+// applicants who have not applied to an open drive before its application
+// window closes, and students with a pending offer before it expires.
+//
+// Each reminder goes out once per offset, when the deadline comes within
+// that offset. The scheduler remembers what it sent, and the portal
+// snapshot keeps that record, so reminders are not repeated after a
+// restart. Run it periodically; it reads the time from the registrar's
+// clock.
+type ReminderScheduler struct {
+	registrar *PlacementRegistrar
+	sent      map[string]Reminder
+
+	// Offsets are how long before each deadline to send reminders. Nil uses
+	// DefaultReminderOffsets.
+	Offsets []time.Duration
+}
+
+func NewReminderScheduler(pr *PlacementRegistrar) *ReminderScheduler {
+	return &ReminderScheduler{registrar: pr, sent: map[string]Reminder{}}
+}
+
+// Sent returns every reminder sent so far, by key.
+func (rs *ReminderScheduler) Sent() []Reminder {
+	out := make([]Reminder, 0, len(rs.sent))
+	for _, r := range rs.sent {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+func (rs *ReminderScheduler) offsets() []time.Duration {
+	offsets := rs.Offsets
+	if offsets == nil {
+		offsets = DefaultReminderOffsets
+	}
+	return offsets
+}
+
+// due returns the smallest offset the deadline has come within, or false
+// when it is further away than every offset or already past. Only the
+// smallest counts, so a scheduler that starts a day before a deadline sends
+// the one day reminder rather than the week's one as well.
+func (rs *ReminderScheduler) due(left time.Duration) (time.Duration, bool) {
+	var best time.Duration
+	found := false
+	for _, o := range rs.offsets() {
+		if left > 0 && left <= o && (!found || o < best) {
+			best, found = o, true
+		}
+	}
+	return best, found
+}
+
+// Run announces drives that have opened and sends the reminders that are
+// due, returning the reminders it sent.
+func (rs *ReminderScheduler) Run() []Reminder {
+	pr := rs.registrar
+	pr.AnnounceOpenDrives()
+	now := pr.Now()
+	var sent []Reminder
+	send := func(r Reminder, offset time.Duration, n *DeadlineReminder) {
+		r.Key = fmt.Sprintf("%s:%s", r.Key, offset)
+		if _, ok := rs.sent[r.Key]; ok {
+			return
+		}
+		r.SentAt = now.UTC()
+		rs.sent[r.Key] = r
+		sent = append(sent, r)
+		if pr.notify != nil {
+			pr.notify(r.StudentID, n)
+		}
+	}
+
+	for _, d := range pr.AllDrives() {
+		if d == nil || pr.DriveState(d) != DriveOpen {
+			continue
+		}
+		closes := d.ApplicationsCloseAt()
+		if closes.IsZero() {
+			continue
+		}
+		left := closes.Sub(now)
+		offset, ok := rs.due(left)
+		if !ok {
+			continue
+		}
+		for _, a := range pr.applicants {
+			if d.HasApplied(a.ID()) || !pr.mayApply(a, d) {
+				continue
+			}
+			r := Reminder{Key: fmt.Sprintf("drive:%d:student:%d", d.id, a.ID()), StudentID: a.ID(), DriveID: d.id, Deadline: closes}
+			send(r, offset, &DeadlineReminder{DriveID: d.id, RoleName: d.roleName, Deadline: closes, Left: left})
+		}
+	}
+
+	for _, o := range pr.offers {
+		if o.status != OfferPending || o.respondBy.IsZero() {
+			continue
+		}
+		left := o.respondBy.Sub(now)
+		offset, ok := rs.due(left)
+		if !ok {
+			continue
+		}
+		n := &DeadlineReminder{DriveID: o.driveID, OfferID: o.id, Deadline: o.respondBy, Left: left}
+		if d := pr.driveByID(o.driveID); d != nil {
+			n.RoleName = d.roleName
+		}
+		r := Reminder{Key: fmt.Sprintf("offer:%d", o.id), StudentID: o.studentID, DriveID: o.driveID, OfferID: o.id, Deadline: o.respondBy}
+		send(r, offset, n)
+	}
+	return sent
+}
@@ -0,0 +1,164 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func reminderPortal(t *testing.T) (p *Portal, now *time.Time, company *Company, drive *Drive) {
+	t.Helper()
+	p = NewPortal()
+	clock := time.Date(2025, time.July, 1, 9, 0, 0, 0, time.UTC)
+	now = &clock
+	p.Placement.SetClock(func() time.Time { return *now })
+	for _, s := range []struct {
+		id   int
+		name string
+		cgpa float64
+	}{{1, "Alice", 9}, {2, "Bob", 8}, {3, "Carol", 5}} {
+		st := NewStudent(s.id, s.name)
+		p.Academic.AddStudent(st)
+		if err := p.Placement.AddApplicant(NewApplicant(st, AcademicRecord{StudentId: s.id, CGPA: s.cgpa})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	company = NewCompany("Acme")
+	p.Placement.AddCompany(company)
+	// Applications close at the end of July 15th.
+	drive = NewDrive(clock, time.Date(2025, time.July, 15, 0, 0, 0, 0, time.UTC), "Engineer", 6, 1000000, Dream)
+	if err := p.Placement.AddDriveToCompany(company.ID(), drive); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Placement.ApplyForDrive(1, company.ID(), drive.ID()); err != nil {
+		t.Fatal(err)
+	}
+	return p, now, company, drive
+}
+
+func TestReminderScheduler_DriveDeadlines(t *testing.T) {
+	p, now, _, drive := reminderPortal(t)
+	closes := drive.ApplicationsCloseAt()
+
+	*now = closes.Add(-8 * 24 * time.Hour)
+	if sent := p.Reminders.Run(); len(sent) != 0 {
+		t.Errorf("nothing is due eight days out, sent %+v", sent)
+	}
+	*now = closes.Add(-6 * 24 * time.Hour)
+	sent := p.Reminders.Run()
+	if len(sent) != 1 || sent[0].StudentID != 2 || sent[0].Key != fmt.Sprintf("drive:%d:student:2:168h0m0s", drive.ID()) {
+		t.Fatalf("expected a week's reminder for Bob only, got %+v", sent)
+	}
+	if sent := p.Reminders.Run(); len(sent) != 0 {
+		t.Errorf("reminders should not repeat, sent %+v", sent)
+	}
+
+	// A restart must not forget what was already sent.
+	snap, err := p.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestorePortal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored.Placement.SetClock(func() time.Time { return *now })
+	if sent := restored.Reminders.Run(); len(sent) != 0 {
+		t.Errorf("reminders should not repeat after a restore, sent %+v", sent)
+	}
+
+	*now = closes.Add(-20 * time.Hour)
+	if sent := restored.Reminders.Run(); len(sent) != 1 || !strings.HasSuffix(sent[0].Key, ":24h0m0s") {
+		t.Errorf("expected the one day reminder, got %+v", sent)
+	}
+	msgs := restored.Notifications.Inbox().Messages(2)
+	if len(msgs) != 3 || msgs[2].Kind != NotifyDeadline || msgs[2].Subject != "Applications for Engineer close in 20 hours" {
+		t.Errorf("unexpected messages for Bob: %+v", msgs)
+	}
+	if len(restored.Notifications.Inbox().Messages(3)) != 0 {
+		t.Errorf("ineligible students should not be reminded")
+	}
+
+	*now = closes.Add(time.Hour)
+	if sent := restored.Reminders.Run(); len(sent) != 0 {
+		t.Errorf("nothing is due once the drive closed, sent %+v", sent)
+	}
+}
+
+func TestReminderScheduler_OfferPolicy(t *testing.T) {
+	p, now, company, drive := reminderPortal(t)
+	p.Placement.SetOfferResponseWindow(30 * 24 * time.Hour)
+	for _, st := range []ApplicationStatus{ShortListed, Cleared, Selected} {
+		if err := p.Placement.UpdateApplicationStatus(1, drive.ID(), st); err != nil {
+			t.Fatal(err)
+		}
+	}
+	day := NewDrive(*now, time.Date(2025, time.July, 5, 0, 0, 0, 0, time.UTC), "Analyst", 6, 500000, Day)
+	if err := p.Placement.AddDriveToCompany(company.ID(), day); err != nil {
+		t.Fatal(err)
+	}
+	*now = day.ApplicationsCloseAt().Add(-time.Hour)
+	var reminded []int
+	for _, r := range p.Reminders.Run() {
+		if r.DriveID == day.ID() {
+			reminded = append(reminded, r.StudentID)
+		}
+	}
+	if len(reminded) != 1 || reminded[0] != 2 {
+		t.Errorf("expected Bob alone reminded, not Alice whose Dream offer blocks Day drives, got %v", reminded)
+	}
+}
+
+func TestReminderScheduler_StartsLate(t *testing.T) {
+	p, now, _, drive := reminderPortal(t)
+	p.Reminders.Offsets = []time.Duration{72 * time.Hour, 2 * time.Hour}
+	*now = drive.ApplicationsCloseAt().Add(-90 * time.Minute)
+	sent := p.Reminders.Run()
+	if len(sent) != 1 || !strings.HasSuffix(sent[0].Key, ":2h0m0s") {
+		t.Errorf("only the tightest offset reached should be sent, got %+v", sent)
+	}
+}
+
+func TestReminderScheduler_OfferDeadlines(t *testing.T) {
+	p, now, _, drive := reminderPortal(t)
+	for _, st := range []ApplicationStatus{ShortListed, Cleared, Selected} {
+		if err := p.Placement.UpdateApplicationStatus(1, drive.ID(), st); err != nil {
+			t.Fatal(err)
+		}
+	}
+	offer := p.Placement.OffersFor(1)[0]
+	*now = offer.RespondBy().Add(-30 * time.Minute)
+	var offerReminders []Reminder
+	for _, r := range p.Reminders.Run() {
+		if r.OfferID != 0 {
+			offerReminders = append(offerReminders, r)
+		}
+	}
+	if len(offerReminders) != 1 || offerReminders[0].StudentID != 1 || offerReminders[0].OfferID != offer.ID() {
+		t.Fatalf("expected a reminder for Alice's offer, got %+v", offerReminders)
+	}
+	msgs := p.Notifications.Inbox().Messages(1)
+	if last := msgs[len(msgs)-1]; last.Subject != "Your Engineer offer expires in 30 minutes" {
+		t.Errorf("unexpected offer reminder: %+v", last)
+	}
+
+	if err := p.Placement.AcceptOffer(offer.ID()); err != nil {
+		t.Fatal(err)
+	}
+	p.Reminders.Offsets = []time.Duration{time.Hour}
+	if sent := p.Reminders.Run(); len(sent) != 0 {
+		t.Errorf("answered offers need no reminder, sent %+v", sent)
+	}
+}
+
+func TestDeadlineReminder_DaysLeft(t *testing.T) {
+	n := NewResultNotification(5)
+	r := &DeadlineReminder{Left: 5*24*time.Hour + time.Hour}
+	if r.Send() != n.Send() {
+		t.Errorf("Send() = %v, want %v days left like ResultNotification", r.Send(), n.Send())
+	}
+	if got := formatLeft(24 * time.Hour); got != "1 day" {
+		t.Errorf("formatLeft(24h) = %q", got)
+	}
+}
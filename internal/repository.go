@@ -0,0 +1,46 @@
+package internal
+
+import "errors"
+
+// ErrNoSnapshot is returned by a Repository that has nothing saved yet.
+var ErrNoSnapshot = errors.New("no saved portal snapshot")
+
+// Repository saves and restores the whole state of a portal session.
+type Repository interface {
+	Save(s *Snapshot) error
+	Load() (*Snapshot, error)
+}
+
+// SavePortal snapshots p and writes it to repo.
+func SavePortal(repo Repository, p *Portal) error {
+	s, err := p.Snapshot()
+	if err != nil {
+		return err
+	}
+	return repo.Save(s)
+}
+
+// LoadPortal restores the portal saved in repo. If nothing has been saved yet
+// an empty portal is returned.
+func LoadPortal(repo Repository) (*Portal, error) {
+	s, err := repo.Load()
+	if errors.Is(err, ErrNoSnapshot) {
+		return NewPortal(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return RestorePortal(s)
+}
+
+// RevertPortal puts p back as it was last saved to repo. It works in place,
+// so whoever holds p or its registrars sees the change, and keeps p's clock,
+// policies and notification channels.
+func RevertPortal(repo Repository, p *Portal) error {
+	saved, err := LoadPortal(repo)
+	if err != nil {
+		return err
+	}
+	p.replace(saved)
+	return nil
+}
@@ -0,0 +1,190 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+)
+
+// RoundKind is the type of a selection round.
+type RoundKind int
+
+const (
+	AptitudeTest RoundKind = iota
+	GroupDiscussion
+	TechnicalInterview
+	HRInterview
+)
+
+var roundKindStrings = map[RoundKind]string{
+	AptitudeTest:       "aptitude",
+	GroupDiscussion:    "group_discussion",
+	TechnicalInterview: "technical",
+	HRInterview:        "hr",
+}
+
+func (k RoundKind) String() string {
+	return roundKindStrings[k]
+}
+
+// ParseRoundKind is the inverse of RoundKind.String.
+func ParseRoundKind(s string) (RoundKind, error) {
+	for kind, name := range roundKindStrings {
+		if name == s {
+			return kind, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid round kind %q", s)
+}
+
+// Round is one stage of a drive's selection pipeline. Applicants scoring at
+// least PassScore move on to the next round; everyone else is rejected.
+type Round struct {
+	Name      string    `json:"name"`
+	Kind      RoundKind `json:"kind"`
+	PassScore float64   `json:"pass_score"`
+}
+
+// RoundResult is an applicant's outcome in one round.
+type RoundResult struct {
+	Round      int       `json:"round"` // 1-based position in the drive's rounds
+	Score      float64   `json:"score"`
+	Passed     bool      `json:"passed"`
+	RecordedAt time.Time `json:"recorded_at"`
+	Actor      string    `json:"actor"`
+}
+
+// RoundFunnel counts how applicants fared in one round of a drive.
+type RoundFunnel struct {
+	Number  int
+	Round   Round
+	Reached int // applicants who got to this round
+	Passed  int
+	Failed  int
+	Pending int // reached the round but have no result yet
+}
+
+func (dr Drive) Rounds() []Round {
+	return dr.rounds
+}
+
+// SetRounds replaces the drive's selection pipeline. It cannot be changed
+// once any applicant has a round result.
+func (dr *Drive) SetRounds(rounds []Round) error {
+	for _, app := range dr.applications {
+		if len(app.roundResults) > 0 {
+			return conflictf("drive %d already has round results recorded", dr.id)
+		}
+	}
+	for i, r := range rounds {
+		if r.Name == "" {
+			return invalidf("round %d has no name", i+1)
+		}
+	}
+	dr.rounds = append([]Round(nil), rounds...)
+	return nil
+}
+
+// SetDriveRounds replaces the selection pipeline of a drive whose results
+// are not yet published.
+func (pr *PlacementRegistrar) SetDriveRounds(companyID, driveID int, rounds []Round) error {
+	drive, err := pr.DriveByID(companyID, driveID)
+	if err != nil {
+		return err
+	}
+	if err := pr.checkResultsPending(drive); err != nil {
+		return err
+	}
+	return drive.SetRounds(rounds)
+}
+
+// RoundResults returns the applicant's results in the drive's rounds, in order.
+func (app *Application) RoundResults() []RoundResult {
+	return app.roundResults
+}
+
+// RecordRoundResult records a student's score in the round they are
+// currently in. Passing a round shortlists the application, passing the
+// last round clears it, and failing any round rejects it.
+func (pr *PlacementRegistrar) RecordRoundResult(studentID, companyID, driveID, round int, score float64, actor string) (RoundResult, error) {
+	drive, err := pr.DriveByID(companyID, driveID)
+	if err != nil {
+		return RoundResult{}, err
+	}
+	if len(drive.rounds) == 0 {
+		return RoundResult{}, conflictf("drive %d has no selection rounds", driveID)
+	}
+	if err := pr.checkResultsPending(drive); err != nil {
+		return RoundResult{}, err
+	}
+	app, err := pr.ApplicationFor(studentID, driveID)
+	if err != nil {
+		return RoundResult{}, err
+	}
+	if app.status == Rejected || app.status == Selected {
+		return RoundResult{}, conflictf("application %d is already %s", app.id, app.status)
+	}
+	current := len(app.roundResults) + 1
+	if current > len(drive.rounds) {
+		return RoundResult{}, conflictf("student %d has completed every round of drive %d", studentID, driveID)
+	}
+	if round != current {
+		return RoundResult{}, conflictf("student %d is in round %d of drive %d, not round %d", studentID, current, driveID, round)
+	}
+
+	now := pr.Now().UTC()
+	result := RoundResult{Round: round, Score: score, Passed: score >= drive.rounds[round-1].PassScore, RecordedAt: now, Actor: actor}
+	var next []ApplicationStatus
+	switch {
+	case !result.Passed:
+		next = []ApplicationStatus{Rejected}
+	case app.status == Applied:
+		next = []ApplicationStatus{ShortListed}
+	}
+	if result.Passed && round == len(drive.rounds) {
+		next = append(next, Cleared)
+	}
+	from := app.status
+	for _, status := range next {
+		if err := app.transition(status, actor, now); err != nil {
+			return RoundResult{}, err
+		}
+	}
+	app.roundResults = append(app.roundResults, result)
+	if len(next) > 0 {
+		pr.notifyStatus(app, from)
+	}
+	return result, nil
+}
+
+// RoundFunnel reports, round by round, how many applicants to a drive
+// reached, passed, failed or are still waiting on each round.
+func (pr *PlacementRegistrar) RoundFunnel(companyID, driveID int) ([]RoundFunnel, error) {
+	drive, err := pr.DriveByID(companyID, driveID)
+	if err != nil {
+		return nil, err
+	}
+	funnel := make([]RoundFunnel, len(drive.rounds))
+	for i, r := range drive.rounds {
+		funnel[i] = RoundFunnel{Number: i + 1, Round: r}
+	}
+	for _, app := range drive.applications {
+		for _, res := range app.roundResults {
+			if res.Round < 1 || res.Round > len(funnel) {
+				continue
+			}
+			stage := &funnel[res.Round-1]
+			stage.Reached++
+			if res.Passed {
+				stage.Passed++
+			} else {
+				stage.Failed++
+			}
+		}
+		current := len(app.roundResults) + 1
+		if current <= len(funnel) && app.status != Rejected && app.status != Selected {
+			funnel[current-1].Reached++
+			funnel[current-1].Pending++
+		}
+	}
+	return funnel, nil
+}
@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// roundsRegistrar returns a registrar with one three-round drive and the
+// given students applied to it.
+func roundsRegistrar(t *testing.T, studentIDs ...int) (*PlacementRegistrar, *Company, *Drive) {
+	t.Helper()
+	pr := &PlacementRegistrar{}
+	company := NewCompany("Acme")
+	drive := NewDrive(time.Now(), time.Now().AddDate(0, 0, 7), "Engineer", 0, 1000000, Dream)
+	company.AddDrive(drive)
+	pr.AddCompany(company)
+	rounds := []Round{
+		{Name: "Aptitude", Kind: AptitudeTest, PassScore: 60},
+		{Name: "Technical", Kind: TechnicalInterview, PassScore: 7},
+		{Name: "HR", Kind: HRInterview, PassScore: 5},
+	}
+	if err := drive.SetRounds(rounds); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range studentIDs {
+		record := AcademicRecord{StudentId: id, CGPA: 9}
+		if err := pr.AddApplicant(NewApplicant(NewStudent(id, "Student"), record)); err != nil {
+			t.Fatal(err)
+		}
+		if err := pr.ApplyForDrive(id, company.ID(), drive.ID()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return pr, company, drive
+}
+
+func TestRecordRoundResult_AdvancesAndRejects(t *testing.T) {
+	pr, company, drive := roundsRegistrar(t, 1, 2)
+
+	if _, err := pr.RecordRoundResult(1, company.ID(), drive.ID(), 2, 9, "officer"); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict for skipping a round, got %v", err)
+	}
+	scores := []float64{75, 8, 6}
+	for i, score := range scores {
+		res, err := pr.RecordRoundResult(1, company.ID(), drive.ID(), i+1, score, "officer")
+		if err != nil || !res.Passed {
+			t.Fatalf("round %d: %+v, %v", i+1, res, err)
+		}
+	}
+	app, _ := pr.ApplicationFor(1, drive.ID())
+	if app.Status() != Cleared || len(app.RoundResults()) != 3 {
+		t.Errorf("expected cleared after all rounds, got %s with %d results", app.Status(), len(app.RoundResults()))
+	}
+	if _, err := pr.RecordRoundResult(1, company.ID(), drive.ID(), 4, 1, "officer"); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict after the last round, got %v", err)
+	}
+
+	if res, err := pr.RecordRoundResult(2, company.ID(), drive.ID(), 1, 40, "officer"); err != nil || res.Passed {
+		t.Fatalf("expected a failed round, got %+v, %v", res, err)
+	}
+	app, _ = pr.ApplicationFor(2, drive.ID())
+	if app.Status() != Rejected {
+		t.Errorf("expected rejected after failing, got %s", app.Status())
+	}
+	if _, err := pr.RecordRoundResult(2, company.ID(), drive.ID(), 2, 9, "officer"); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict for a rejected application, got %v", err)
+	}
+	if err := drive.SetRounds(nil); !errors.Is(err, ErrConflict) {
+		t.Errorf("rounds should be fixed once results exist, got %v", err)
+	}
+}
+
+func TestRecordRoundResult_NoRounds(t *testing.T) {
+	pr, company, drive := roundsRegistrar(t, 1)
+	drive.rounds = nil
+	if _, err := pr.RecordRoundResult(1, company.ID(), drive.ID(), 1, 50, "officer"); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestRoundFunnel(t *testing.T) {
+	pr, company, drive := roundsRegistrar(t, 1, 2, 3, 4)
+	record := func(studentID, round int, score float64) {
+		t.Helper()
+		if _, err := pr.RecordRoundResult(studentID, company.ID(), drive.ID(), round, score, "officer"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	record(1, 1, 80)
+	record(1, 2, 9)
+	record(2, 1, 70)
+	record(3, 1, 10)
+
+	funnel, err := pr.RoundFunnel(company.ID(), drive.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RoundFunnel{
+		{Number: 1, Reached: 4, Passed: 2, Failed: 1, Pending: 1},
+		{Number: 2, Reached: 2, Passed: 1, Pending: 1},
+		{Number: 3, Reached: 1, Pending: 1},
+	}
+	for i, w := range want {
+		got := funnel[i]
+		got.Round = Round{}
+		if got != w {
+			t.Errorf("round %d: got %+v, want %+v", i+1, got, w)
+		}
+	}
+}
+
+func TestParseRoundKind(t *testing.T) {
+	for kind := range roundKindStrings {
+		got, err := ParseRoundKind(kind.String())
+		if err != nil || got != kind {
+			t.Errorf("ParseRoundKind(%q) = %v, %v", kind.String(), got, err)
+		}
+	}
+	if _, err := ParseRoundKind("coding"); err == nil {
+		t.Error("expected error for unknown kind")
+	}
+}
@@ -0,0 +1,461 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rule is an eligibility criterion written as an expression over an
+// applicant's profile, for example
+//
+//	cgpa >= 7.5 && backlogs == 0 && attendance >= 75
+//
+// Rules compare the variables cgpa, backlogs, attendance, semester and
+// graduation_year with numbers, and department with quoted strings
+// (case-insensitively). Comparisons combine with &&, || and !, and group
+// with parentheses.
+type Rule struct {
+	source string
+	root   ruleNode
+}
+
+// RuleError reports a malformed rule and the 1-based column it was found at.
+// It matches ErrInvalid.
+type RuleError struct {
+	Rule   string
+	Column int
+	Msg    string
+}
+
+func (e *RuleError) Error() string {
+	return fmt.Sprintf("rule %q: column %d: %s", e.Rule, e.Column, e.Msg)
+}
+
+func (e *RuleError) Unwrap() error { return ErrInvalid }
+
+// ParseRule parses and type-checks source.
+func ParseRule(source string) (*Rule, error) {
+	p := &ruleParser{source: source}
+	if err := p.lex(); err != nil {
+		return nil, err
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorAt(tok.pos, "unexpected %s", tok)
+	}
+	if root.typ() != ruleBool {
+		return nil, p.errorAt(root.pos(), "rule must be a condition, not a %s", root.typ())
+	}
+	return &Rule{source: source, root: root}, nil
+}
+
+func (r *Rule) String() string { return r.source }
+
+// Eval reports whether p satisfies the rule. It fails when the rule needs a
+// value the profile does not have, such as unrecorded attendance.
+func (r *Rule) Eval(p ApplicantProfile) (bool, error) {
+	v, err := r.root.eval(p)
+	if err != nil {
+		return false, err
+	}
+	return v.b, nil
+}
+
+// Check explains which parts of the rule p fails. A rule joined by && at
+// the top level is reported part by part.
+func (r *Rule) Check(p ApplicantProfile) string {
+	var failed []string
+	for _, part := range conjuncts(r.root) {
+		v, err := part.eval(p)
+		if err != nil {
+			return fmt.Sprintf("rule %q cannot be checked: %v", r.source, err)
+		}
+		if !v.b {
+			failed = append(failed, r.source[part.pos()-1:part.end()-1])
+		}
+	}
+	if len(failed) == 0 {
+		return ""
+	}
+	return "rule not met: " + strings.Join(failed, ", ")
+}
+
+func conjuncts(n ruleNode) []ruleNode {
+	if b, ok := n.(*ruleBinary); ok && b.op == "&&" {
+		return append(conjuncts(b.left), conjuncts(b.right)...)
+	}
+	return []ruleNode{n}
+}
+
+type ruleType int
+
+const (
+	ruleNumber ruleType = iota
+	ruleString
+	ruleBool
+)
+
+func (t ruleType) String() string {
+	return [...]string{"number", "string", "condition"}[t]
+}
+
+type ruleValue struct {
+	n float64
+	s string
+	b bool
+}
+
+// ruleVariables are the names a rule can use and how to read them.
+var ruleVariables = map[string]struct {
+	typ  ruleType
+	read func(p ApplicantProfile) (ruleValue, error)
+}{
+	"cgpa":     {ruleNumber, func(p ApplicantProfile) (ruleValue, error) { return ruleValue{n: p.CGPA}, nil }},
+	"backlogs": {ruleNumber, func(p ApplicantProfile) (ruleValue, error) { return ruleValue{n: float64(p.Backlogs)}, nil }},
+	"semester": {ruleNumber, func(p ApplicantProfile) (ruleValue, error) { return ruleValue{n: float64(p.Semester)}, nil }},
+	"attendance": {ruleNumber, func(p ApplicantProfile) (ruleValue, error) {
+		if !p.HasAttendance {
+			return ruleValue{}, fmt.Errorf("attendance is not recorded")
+		}
+		return ruleValue{n: p.Attendance}, nil
+	}},
+	"graduation_year": {ruleNumber, func(p ApplicantProfile) (ruleValue, error) {
+		if p.GraduationYear == 0 {
+			return ruleValue{}, fmt.Errorf("graduation year is not recorded")
+		}
+		return ruleValue{n: float64(p.GraduationYear)}, nil
+	}},
+	"department": {ruleString, func(p ApplicantProfile) (ruleValue, error) {
+		if p.Department == "" {
+			return ruleValue{}, fmt.Errorf("department is not recorded")
+		}
+		return ruleValue{s: p.Department}, nil
+	}},
+}
+
+// ruleNode is a type-checked node of a parsed rule. pos and end are the
+// 1-based columns of its first character and just past its last.
+type ruleNode interface {
+	typ() ruleType
+	pos() int
+	end() int
+	eval(p ApplicantProfile) (ruleValue, error)
+}
+
+type ruleSpan struct{ start, stop int }
+
+func (s ruleSpan) pos() int { return s.start }
+func (s ruleSpan) end() int { return s.stop }
+
+type ruleLiteral struct {
+	ruleSpan
+	t ruleType
+	v ruleValue
+}
+
+func (n *ruleLiteral) typ() ruleType                            { return n.t }
+func (n *ruleLiteral) eval(ApplicantProfile) (ruleValue, error) { return n.v, nil }
+
+type ruleVariable struct {
+	ruleSpan
+	name string
+}
+
+func (n *ruleVariable) typ() ruleType { return ruleVariables[n.name].typ }
+func (n *ruleVariable) eval(p ApplicantProfile) (ruleValue, error) {
+	return ruleVariables[n.name].read(p)
+}
+
+type ruleNot struct {
+	ruleSpan
+	operand ruleNode
+}
+
+func (n *ruleNot) typ() ruleType { return ruleBool }
+func (n *ruleNot) eval(p ApplicantProfile) (ruleValue, error) {
+	v, err := n.operand.eval(p)
+	return ruleValue{b: !v.b}, err
+}
+
+type ruleBinary struct {
+	ruleSpan
+	op          string
+	left, right ruleNode
+}
+
+func (n *ruleBinary) typ() ruleType { return ruleBool }
+
+func (n *ruleBinary) eval(p ApplicantProfile) (ruleValue, error) {
+	l, err := n.left.eval(p)
+	if err != nil {
+		return ruleValue{}, err
+	}
+	// && and || short-circuit, so a missing value on the side that does
+	// not matter does not fail the rule.
+	switch n.op {
+	case "&&":
+		if !l.b {
+			return l, nil
+		}
+		return n.right.eval(p)
+	case "||":
+		if l.b {
+			return l, nil
+		}
+		return n.right.eval(p)
+	}
+	r, err := n.right.eval(p)
+	if err != nil {
+		return ruleValue{}, err
+	}
+	if n.left.typ() == ruleString {
+		eq := strings.EqualFold(l.s, r.s)
+		return ruleValue{b: eq == (n.op == "==")}, nil
+	}
+	var b bool
+	switch n.op {
+	case "==":
+		b = l.n == r.n
+	case "!=":
+		b = l.n != r.n
+	case "<":
+		b = l.n < r.n
+	case "<=":
+		b = l.n <= r.n
+	case ">":
+		b = l.n > r.n
+	case ">=":
+		b = l.n >= r.n
+	}
+	return ruleValue{b: b}, nil
+}
+
+type ruleTokenKind int
+
+const (
+	tokEOF ruleTokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type ruleToken struct {
+	kind ruleTokenKind
+	text string
+	pos  int // 1-based column
+	end  int
+}
+
+func (t ruleToken) String() string {
+	if t.kind == tokEOF {
+		return "end of rule"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+type ruleParser struct {
+	source string
+	tokens []ruleToken
+	next   int
+}
+
+func (p *ruleParser) errorAt(column int, format string, args ...any) error {
+	return &RuleError{Rule: p.source, Column: column, Msg: fmt.Sprintf(format, args...)}
+}
+
+var ruleOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!"}
+
+func (p *ruleParser) lex() error {
+	src := p.source
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')':
+			kind := tokLParen
+			if c == ')' {
+				kind = tokRParen
+			}
+			p.tokens = append(p.tokens, ruleToken{kind: kind, text: string(c), pos: i + 1, end: i + 2})
+			i++
+		case c >= '0' && c <= '9' || c == '.':
+			j := i
+			for j < len(src) && (src[j] >= '0' && src[j] <= '9' || src[j] == '.') {
+				j++
+			}
+			p.tokens = append(p.tokens, ruleToken{kind: tokNumber, text: src[i:j], pos: i + 1, end: j + 1})
+			i = j
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			j := i
+			for j < len(src) && (src[j] == '_' || src[j] >= 'a' && src[j] <= 'z' || src[j] >= 'A' && src[j] <= 'Z' || src[j] >= '0' && src[j] <= '9') {
+				j++
+			}
+			p.tokens = append(p.tokens, ruleToken{kind: tokIdent, text: src[i:j], pos: i + 1, end: j + 1})
+			i = j
+		case c == '"' || c == '\'':
+			j := strings.IndexByte(src[i+1:], c)
+			if j < 0 {
+				return p.errorAt(i+1, "unterminated string")
+			}
+			p.tokens = append(p.tokens, ruleToken{kind: tokString, text: src[i+1 : i+1+j], pos: i + 1, end: i + j + 3})
+			i += j + 2
+		default:
+			op := ""
+			for _, candidate := range ruleOperators {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				switch c {
+				case '=', '&', '|':
+					return p.errorAt(i+1, "unexpected character %q, did you mean %q?", c, string([]byte{c, c}))
+				}
+				return p.errorAt(i+1, "unexpected character %q", c)
+			}
+			p.tokens = append(p.tokens, ruleToken{kind: tokOp, text: op, pos: i + 1, end: i + len(op) + 1})
+			i += len(op)
+		}
+	}
+	p.tokens = append(p.tokens, ruleToken{kind: tokEOF, pos: len(src) + 1, end: len(src) + 1})
+	return nil
+}
+
+func (p *ruleParser) peek() ruleToken { return p.tokens[p.next] }
+
+func (p *ruleParser) take() ruleToken {
+	tok := p.tokens[p.next]
+	if tok.kind != tokEOF {
+		p.next++
+	}
+	return tok
+}
+
+func (p *ruleParser) parseOr() (ruleNode, error) {
+	return p.parseLogical("||", p.parseAnd)
+}
+
+func (p *ruleParser) parseAnd() (ruleNode, error) {
+	return p.parseLogical("&&", p.parseNot)
+}
+
+func (p *ruleParser) parseLogical(op string, operand func() (ruleNode, error)) (ruleNode, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOp && p.peek().text == op {
+		tok := p.take()
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		for _, side := range []ruleNode{left, right} {
+			if side.typ() != ruleBool {
+				return nil, p.errorAt(side.pos(), "%s needs conditions on both sides, got a %s", tok.text, side.typ())
+			}
+		}
+		left = &ruleBinary{ruleSpan: ruleSpan{left.pos(), right.end()}, op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseNot() (ruleNode, error) {
+	if tok := p.peek(); tok.kind == tokOp && tok.text == "!" {
+		p.take()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if operand.typ() != ruleBool {
+			return nil, p.errorAt(operand.pos(), "! needs a condition, got a %s", operand.typ())
+		}
+		return &ruleNot{ruleSpan: ruleSpan{tok.pos, operand.end()}, operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *ruleParser) parseComparison() (ruleNode, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	if tok.kind != tokOp {
+		return left, nil
+	}
+	switch tok.text {
+	case "==", "!=", "<", "<=", ">", ">=":
+	default:
+		return left, nil
+	}
+	p.take()
+	right, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if left.typ() == ruleBool || right.typ() == ruleBool {
+		return nil, p.errorAt(tok.pos, "%s compares values, not conditions", tok.text)
+	}
+	if left.typ() != right.typ() {
+		return nil, p.errorAt(right.pos(), "cannot compare a %s with a %s", left.typ(), right.typ())
+	}
+	if left.typ() == ruleString && tok.text != "==" && tok.text != "!=" {
+		return nil, p.errorAt(tok.pos, "strings can only be compared with == or !=")
+	}
+	return &ruleBinary{ruleSpan: ruleSpan{left.pos(), right.end()}, op: tok.text, left: left, right: right}, nil
+}
+
+func (p *ruleParser) parsePrimary() (ruleNode, error) {
+	tok := p.take()
+	span := ruleSpan{tok.pos, tok.end}
+	switch tok.kind {
+	case tokNumber:
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorAt(tok.pos, "invalid number %q", tok.text)
+		}
+		return &ruleLiteral{ruleSpan: span, t: ruleNumber, v: ruleValue{n: n}}, nil
+	case tokString:
+		return &ruleLiteral{ruleSpan: span, t: ruleString, v: ruleValue{s: tok.text}}, nil
+	case tokIdent:
+		switch tok.text {
+		case "true", "false":
+			return &ruleLiteral{ruleSpan: span, t: ruleBool, v: ruleValue{b: tok.text == "true"}}, nil
+		}
+		if _, ok := ruleVariables[tok.text]; !ok {
+			return nil, p.errorAt(tok.pos, "unknown variable %q (known: cgpa, backlogs, attendance, semester, graduation_year, department)", tok.text)
+		}
+		return &ruleVariable{ruleSpan: span, name: tok.text}, nil
+	case tokLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		closing := p.take()
+		if closing.kind != tokRParen {
+			return nil, p.errorAt(closing.pos, "expected ) to close the ( at column %d, got %s", tok.pos, closing)
+		}
+		// Re-span the node so Check reports the parentheses too.
+		return &ruleGroup{ruleSpan: ruleSpan{tok.pos, closing.end}, inner: inner}, nil
+	}
+	return nil, p.errorAt(tok.pos, "expected a value, got %s", tok)
+}
+
+// ruleGroup is a parenthesised expression.
+type ruleGroup struct {
+	ruleSpan
+	inner ruleNode
+}
+
+func (n *ruleGroup) typ() ruleType                              { return n.inner.typ() }
+func (n *ruleGroup) eval(p ApplicantProfile) (ruleValue, error) { return n.inner.eval(p) }
@@ -0,0 +1,101 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRule_Eval(t *testing.T) {
+	p := ApplicantProfile{CGPA: 8, Backlogs: 1, Semester: 7, Attendance: 80, HasAttendance: true, Department: "CSE", GraduationYear: 2026}
+	cases := []struct {
+		rule string
+		want bool
+	}{
+		{"cgpa >= 7.5 && backlogs == 0 && attendance >= 75", false},
+		{"cgpa >= 7.5 && backlogs <= 1 && attendance >= 75", true},
+		{"cgpa > 9 || department == 'cse'", true},
+		{`!(department == "ECE") && semester != 8`, true},
+		{"graduation_year < 2026 || (cgpa >= 8 && !false)", true},
+		{"backlogs > 0 && attendance < 50", false},
+	}
+	for _, c := range cases {
+		r, err := ParseRule(c.rule)
+		if err != nil {
+			t.Fatalf("ParseRule(%q): %v", c.rule, err)
+		}
+		if got, err := r.Eval(p); err != nil || got != c.want {
+			t.Errorf("%q = %v, %v; want %v", c.rule, got, err, c.want)
+		}
+	}
+}
+
+func TestParseRule_Errors(t *testing.T) {
+	cases := []struct {
+		rule   string
+		column int
+	}{
+		{"cgpa >= ", 9},
+		{"cgpa >= 7.5 &&", 15},
+		{"cgpa >= 7.5 & backlogs == 0", 13},
+		{"height > 170", 1},
+		{"cgpa >= 7.5 && department", 16},
+		{"department >= 'CSE'", 12},
+		{"cgpa == 'CSE'", 9},
+		{"(cgpa >= 7.5", 13},
+		{"cgpa >= 7.5)", 12},
+		{"department == 'CSE", 15},
+		{"cgpa", 1},
+		{"cgpa >= 1.2.3", 9},
+	}
+	for _, c := range cases {
+		_, err := ParseRule(c.rule)
+		var ruleErr *RuleError
+		if !errors.As(err, &ruleErr) || !errors.Is(err, ErrInvalid) {
+			t.Errorf("ParseRule(%q) = %v, want a RuleError", c.rule, err)
+			continue
+		}
+		if ruleErr.Column != c.column {
+			t.Errorf("ParseRule(%q) reported column %d (%s), want %d", c.rule, ruleErr.Column, ruleErr.Msg, c.column)
+		}
+	}
+}
+
+func TestRule_Check(t *testing.T) {
+	r, err := ParseRule("cgpa >= 7.5 && backlogs == 0 && (attendance >= 75 || semester == 8)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := ApplicantProfile{CGPA: 8, Backlogs: 2, Semester: 7, Attendance: 60, HasAttendance: true}
+	if got, want := r.Check(p), "rule not met: backlogs == 0, (attendance >= 75 || semester == 8)"; got != want {
+		t.Errorf("Check = %q, want %q", got, want)
+	}
+	p.HasAttendance = false
+	p.Backlogs = 0
+	if got := r.Check(p); got == "" {
+		t.Error("a rule needing unrecorded attendance should not pass")
+	}
+	p.Semester = 8
+	p.HasAttendance = true
+	p.Attendance = 90
+	if got := r.Check(p); got != "" {
+		t.Errorf("expected the rule to pass, got %q", got)
+	}
+}
+
+func TestApplyForDrive_RuleCriterion(t *testing.T) {
+	pr, company, drives := tieredRegistrar(t)
+	rule, err := ParseRule("cgpa >= 9.5 || department == 'CSE'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	drives[Day].AddCriteria(rule)
+	err = pr.ApplyForDrive(1, company.ID(), drives[Day].ID())
+	var eligErr *EligibilityError
+	if !errors.As(err, &eligErr) || len(eligErr.Failures) != 1 || eligErr.Failures[0].Criterion != rule {
+		t.Fatalf("expected the rule to fail, got %v", err)
+	}
+	pr.applicants[0].Department = "cse"
+	if err := pr.ApplyForDrive(1, company.ID(), drives[Day].ID()); err != nil {
+		t.Errorf("expected the rule to pass, got %v", err)
+	}
+}
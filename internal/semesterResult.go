@@ -1,35 +1,26 @@
 package internal
 
 import (
-	"encoding/json"
 	"errors"
-	"fmt"
 )
 
-type AlphabeticGrade uint8
+// AlphabeticGrade is a letter grade, such as "A+". Which letters exist and
+// what they are worth is set by the GradeScale in use.
+type AlphabeticGrade string
 
+// Letters of the DefaultGradeScale.
 const (
-	O AlphabeticGrade = iota
-	Aplus
-	A
-	Bplus
-	B
-	C
-	F
+	O     AlphabeticGrade = "O"
+	Aplus AlphabeticGrade = "A+"
+	A     AlphabeticGrade = "A"
+	Bplus AlphabeticGrade = "B+"
+	B     AlphabeticGrade = "B"
+	C     AlphabeticGrade = "C"
+	F     AlphabeticGrade = "F"
 )
 
-var alphabeticGradeStrings = map[AlphabeticGrade]string{
-	O:     "O",
-	Aplus: "A+",
-	A:     "A",
-	Bplus: "B+",
-	B:     "B",
-	C:     "C",
-	F:     "F",
-}
-
 func (a AlphabeticGrade) String() string {
-	return alphabeticGradeStrings[a]
+	return string(a)
 }
 
 type SemesterResult struct {
@@ -37,6 +28,8 @@ type SemesterResult struct {
 	StudentId int                  `json:"student_id"`
 	Courses   map[int]CourseResult `json:"courses"`
 	SGPA      float64              `json:"sgpa"`
+
+	scale *GradeScale // nil reads grades on the DefaultGradeScale
 }
 
 func NewSemesterResult(studentId, semester int) *SemesterResult {
@@ -76,42 +69,10 @@ func (sr *SemesterResult) calculateSGPA() {
 }
 
 func (sr *SemesterResult) getGradePoints(grade AlphabeticGrade) float64 {
-	gradePoints := map[AlphabeticGrade]float64{
-		O:     10.0,
-		Aplus: 9.0,
-		A:     8.0,
-		Bplus: 7.0,
-		B:     6.0,
-		C:     5.0,
-		F:     0.0,
-	}
-	return gradePoints[grade]
-}
-func (a *AlphabeticGrade) UnmarshalJSON(data []byte) error {
-	// Trim quotes
-	var gradeStr string
-	if err := json.Unmarshal(data, &gradeStr); err != nil {
-		return err
-	}
-
-	switch gradeStr {
-	case "O":
-		*a = O
-	case "A+":
-		*a = Aplus
-	case "A":
-		*a = A
-	case "B+":
-		*a = Bplus
-	case "B":
-		*a = B
-	case "C":
-		*a = C
-	case "F":
-		*a = F
-	default:
-		return fmt.Errorf("invalid grade string: %s", gradeStr)
+	scale := sr.scale
+	if scale == nil {
+		scale = DefaultGradeScale()
 	}
-
-	return nil
+	points, _ := scale.Points(grade)
+	return points
 }
@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Session is the content of a signed session token.
+type Session struct {
+	ID        string    `json:"sid"`
+	Username  string    `json:"sub"`
+	ExpiresAt time.Time `json:"exp"`
+}
+
+// SessionManager issues and verifies HMAC-signed session tokens. Tokens are
+// self-contained, so only revoked sessions are remembered, and only until
+// they would have expired anyway.
+type SessionManager struct {
+	key []byte
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	revoked map[string]time.Time
+}
+
+// NewSessionManager returns a manager signing with key whose tokens are valid
+// for ttl.
+func NewSessionManager(key []byte, ttl time.Duration) *SessionManager {
+	return &SessionManager{key: key, ttl: ttl, now: time.Now, revoked: make(map[string]time.Time)}
+}
+
+// NewSessionKey returns a random key suitable for NewSessionManager.
+func NewSessionKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Issue returns a new token for username.
+func (m *SessionManager) Issue(username string) (string, Session, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", Session{}, err
+	}
+	s := Session{ID: hex.EncodeToString(id), Username: username, ExpiresAt: m.now().Add(m.ttl).UTC()}
+	payload, err := json.Marshal(s)
+	if err != nil {
+		return "", Session{}, err
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(m.sign(payload)), s, nil
+}
+
+// Verify checks token's signature, expiry and revocation and returns its session.
+func (m *SessionManager) Verify(token string) (Session, error) {
+	enc := base64.RawURLEncoding
+	payloadPart, sigPart, ok := strings.Cut(token, ".")
+	if !ok {
+		return Session{}, unauthenticatedf("malformed session token")
+	}
+	payload, err := enc.DecodeString(payloadPart)
+	if err != nil {
+		return Session{}, unauthenticatedf("malformed session token")
+	}
+	sig, err := enc.DecodeString(sigPart)
+	if err != nil || !hmac.Equal(sig, m.sign(payload)) {
+		return Session{}, unauthenticatedf("invalid session token")
+	}
+	var s Session
+	if err := json.Unmarshal(payload, &s); err != nil {
+		return Session{}, unauthenticatedf("malformed session token")
+	}
+	if !m.now().Before(s.ExpiresAt) {
+		return Session{}, unauthenticatedf("session expired")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, revoked := m.revoked[s.ID]; revoked {
+		return Session{}, unauthenticatedf("session revoked")
+	}
+	return s, nil
+}
+
+// Revoke invalidates token before its expiry, as on logout.
+func (m *SessionManager) Revoke(token string) error {
+	s, err := m.Verify(token)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	for id, exp := range m.revoked {
+		if !now.Before(exp) {
+			delete(m.revoked, id)
+		}
+	}
+	m.revoked[s.ID] = s.ExpiresAt
+	return nil
+}
+
+func (m *SessionManager) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, m.key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
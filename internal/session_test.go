@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSessionManager(t *testing.T) {
+	now := time.Date(2025, time.July, 1, 9, 0, 0, 0, time.UTC)
+	m := NewSessionManager([]byte("test key"), time.Hour)
+	m.now = func() time.Time { return now }
+
+	token, s, err := m.Issue("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("unexpected expiry %v", s.ExpiresAt)
+	}
+	got, err := m.Verify(token)
+	if err != nil || got.Username != "alice" {
+		t.Fatalf("Verify = %v, %v", got, err)
+	}
+
+	other := NewSessionManager([]byte("other key"), time.Hour)
+	if _, err := other.Verify(token); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("expected token signed with another key to be rejected, got %v", err)
+	}
+	if _, err := m.Verify(token + "x"); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("expected tampered token to be rejected, got %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := m.Verify(token); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("expected expired token to be rejected, got %v", err)
+	}
+}
+
+func TestSessionManager_Revoke(t *testing.T) {
+	m := NewSessionManager([]byte("test key"), time.Hour)
+	first, _, _ := m.Issue("alice")
+	second, _, _ := m.Issue("alice")
+	if err := m.Revoke(first); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Verify(first); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("expected revoked token to be rejected, got %v", err)
+	}
+	if _, err := m.Verify(second); err != nil {
+		t.Errorf("other sessions should stay valid: %v", err)
+	}
+}
@@ -0,0 +1,950 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SnapshotSchemaVersion is the version written by Portal.Snapshot. Bump it
+// whenever the shape of Snapshot changes and register a migration from the
+// previous version in snapshotMigrations.
+const SnapshotSchemaVersion = 20
+
+// ErrSnapshotVersion is returned when a snapshot cannot be read by this build.
+var ErrSnapshotVersion = errors.New("unsupported snapshot schema version")
+
+// snapshotMigrations upgrade a raw snapshot from the keyed version to the next one.
+var snapshotMigrations = map[int]func(raw map[string]json.RawMessage) error{
+	// Version 2 added accounts; older portals have none.
+	1: func(raw map[string]json.RawMessage) error {
+		raw["accounts"] = json.RawMessage("[]")
+		return nil
+	},
+	// Version 3 added application status history; older applications have
+	// none recorded, which decodes as an empty history.
+	2: func(raw map[string]json.RawMessage) error {
+		return nil
+	},
+	// Version 4 added selection rounds to drives and round results to
+	// applications; older drives have a single implicit stage.
+	3: func(raw map[string]json.RawMessage) error {
+		return nil
+	},
+	// Version 5 added offers. Applications already selected are treated as
+	// accepted offers, which is how reports used to read them.
+	4: func(raw map[string]json.RawMessage) error {
+		var apps []ApplicationRecord
+		if a, ok := raw["applications"]; ok {
+			if err := json.Unmarshal(a, &apps); err != nil {
+				return err
+			}
+		}
+		offers := []OfferRecord{}
+		for _, app := range apps {
+			if app.Status == Selected {
+				offers = append(offers, OfferRecord{
+					ID: len(offers) + 1, ApplicationID: app.ID, StudentID: app.StudentID, DriveID: app.DriveID, Status: OfferAccepted,
+				})
+			}
+		}
+		var err error
+		raw["offers"], err = json.Marshal(offers)
+		return err
+	},
+	// Version 6 added eligibility criteria beyond the minimum CGPA to drives
+	// and department and graduation year to applicants; older drives only
+	// have the minimum CGPA and older applicants neither.
+	5: func(raw map[string]json.RawMessage) error {
+		return nil
+	},
+	// Version 7 added rule criteria, stored by their expression.
+	6: func(raw map[string]json.RawMessage) error {
+		return nil
+	},
+	// Version 8 added when a drive's results were published and when it was
+	// archived; older drives have done neither.
+	7: func(raw map[string]json.RawMessage) error {
+		return nil
+	},
+	// Version 9 added notifications, subscriptions and when each drive was
+	// announced. Existing drives are treated as announced at the time of the
+	// snapshot so upgrading does not notify students of old drives.
+	8: func(raw map[string]json.RawMessage) error {
+		var savedAt time.Time
+		if s, ok := raw["saved_at"]; ok {
+			if err := json.Unmarshal(s, &savedAt); err != nil {
+				return err
+			}
+		}
+		var companies []map[string]json.RawMessage
+		if c, ok := raw["companies"]; ok {
+			if err := json.Unmarshal(c, &companies); err != nil {
+				return err
+			}
+		}
+		announced, err := json.Marshal(savedAt)
+		if err != nil {
+			return err
+		}
+		for _, c := range companies {
+			var drives []map[string]json.RawMessage
+			if d, ok := c["drives"]; ok {
+				if err := json.Unmarshal(d, &drives); err != nil {
+					return err
+				}
+			}
+			for _, d := range drives {
+				d["announced_at"] = announced
+			}
+			if c["drives"], err = json.Marshal(drives); err != nil {
+				return err
+			}
+		}
+		raw["companies"], err = json.Marshal(companies)
+		return err
+	},
+	// Version 10 added the deadline reminders already sent; older portals
+	// have sent none.
+	9: func(raw map[string]json.RawMessage) error {
+		return nil
+	},
+	// Version 11 added when a notification was read; older ones are unread.
+	10: func(raw map[string]json.RawMessage) error {
+		return nil
+	},
+	// Version 12 added the grade scale; older portals use the default one.
+	11: func(raw map[string]json.RawMessage) error {
+		return nil
+	},
+	// Version 13 added course semesters, the scale grader's maximum score and
+	// transcripts recorded from uploaded marks; older portals recorded none.
+	12: func(raw map[string]json.RawMessage) error {
+		return nil
+	},
+	// Version 14 added curve graders and their cut-offs.
+	13: func(raw map[string]json.RawMessage) error {
+		return nil
+	},
+	// Version 15 added course assessments and component marks; older
+	// courses take a single final score.
+	14: func(raw map[string]json.RawMessage) error {
+		return nil
+	},
+	// Version 16 added re-evaluation requests and the grade history.
+	15: func(raw map[string]json.RawMessage) error {
+		return nil
+	},
+	// Version 17 added minimum attendance and condoned shortages; older
+	// portals require the default minimum.
+	16: func(raw map[string]json.RawMessage) error {
+		return nil
+	},
+	// Version 18 added class sessions and attendance taken per session; older
+	// attendance was marked by date.
+	17: func(raw map[string]json.RawMessage) error {
+		return nil
+	},
+	// Version 19 added leave requests and absences excused on leave.
+	18: func(raw map[string]json.RawMessage) error {
+		return nil
+	},
+	// Version 20 recorded which enrollments have a final mark; older ones
+	// count as marked when they have a score or component marks.
+	19: func(raw map[string]json.RawMessage) error {
+		infer := func(e *EnrollmentRecord) {
+			e.Marked = e.Score != 0 || len(e.Marks) > 0
+		}
+		var enrollments []EnrollmentRecord
+		var enrollNew []EnrollNewRecord
+		var documents []DocumentsRecord
+		for key, v := range map[string]any{"enrollments": &enrollments, "enroll_new": &enrollNew, "documents": &documents} {
+			if r, ok := raw[key]; ok {
+				if err := json.Unmarshal(r, v); err != nil {
+					return err
+				}
+			}
+		}
+		for i := range enrollments {
+			infer(&enrollments[i])
+		}
+		for i := range enrollNew {
+			infer(&enrollNew[i].Enrollment)
+		}
+		for i := range documents {
+			infer(&documents[i].Enrollment.Enrollment)
+		}
+		var err error
+		for key, v := range map[string]any{"enrollments": enrollments, "enroll_new": enrollNew, "documents": documents} {
+			if raw[key], err = json.Marshal(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// Snapshot is the serialisable state of a whole Portal.
+type Snapshot struct {
+	SchemaVersion      int                       `json:"schema_version"`
+	SavedAt            time.Time                 `json:"saved_at"`
+	Students           []StudentData             `json:"students"`
+	Courses            []CourseRecord            `json:"courses"`
+	Enrollments        []EnrollmentRecord        `json:"enrollments"`
+	Teachers           []TeacherRecord           `json:"teachers"`
+	TeacherEnrollments []TeacherEnrollmentRecord `json:"teacher_enrollments"`
+	EnrollNew          []EnrollNewRecord         `json:"enroll_new"`
+	Documents          []DocumentsRecord         `json:"documents"`
+	Companies          []CompanyRecord           `json:"companies"`
+	Applicants         []ApplicantRecord         `json:"applicants"`
+	Applications       []ApplicationRecord       `json:"applications"`
+	Accounts           []AccountRecord           `json:"accounts"`
+	Offers             []OfferRecord             `json:"offers"`
+	Subscriptions      []Subscription            `json:"subscriptions"`
+	Notifications      []Message                 `json:"notifications"`
+	Reminders          []Reminder                `json:"reminders"`
+	GradeScale         *GradeScale               `json:"grade_scale,omitempty"` // nil is the DefaultGradeScale
+	Transcripts        []CourseResult            `json:"transcripts"`
+	Assessments        []*Assessment             `json:"assessments,omitempty"`
+	ReEvaluations      []ReEvaluation            `json:"re_evaluations,omitempty"`
+	GradeHistory       []GradeChange             `json:"grade_history,omitempty"`
+	AttendanceMinimums []AttendanceMinimum       `json:"attendance_minimums,omitempty"`
+	Condonations       []Condonation             `json:"condonations,omitempty"`
+	Sessions           []ClassSession            `json:"sessions,omitempty"`
+	Leaves             []LeaveRequest            `json:"leaves,omitempty"`
+}
+
+type CourseRecord struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Credits  int    `json:"credits,omitempty"`
+	Semester int    `json:"semester,omitempty"`
+}
+
+type TeacherRecord struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type TeacherEnrollmentRecord struct {
+	Teacher TeacherRecord `json:"teacher"`
+	Course  CourseRecord  `json:"course"`
+}
+
+// GraderRecord names one of the known Grader implementations.
+type GraderRecord struct {
+	Kind     string        `json:"kind,omitempty"`
+	PassMark float64       `json:"pass_mark,omitempty"`
+	MaxScore float64       `json:"max_score,omitempty"`
+	Cutoffs  []CurveCutoff `json:"cutoffs,omitempty"`
+}
+
+type EnrollmentRecord struct {
+	Student StudentData     `json:"student"`
+	Course  CourseRecord    `json:"course"`
+	Grader  GraderRecord    `json:"grader"`
+	Score   float64         `json:"score"`
+	Marks   []ComponentMark `json:"marks,omitempty"`
+	Marked  bool            `json:"marked,omitempty"`
+}
+
+type AttendanceRecord struct {
+	Date    time.Time `json:"date"`
+	Present bool      `json:"present"`
+	Excused bool      `json:"excused,omitempty"` // on leave
+}
+
+type EnrollNewRecord struct {
+	Enrollment EnrollmentRecord   `json:"enrollment"`
+	Teacher    TeacherRecord      `json:"teacher"`
+	Attendance []AttendanceRecord `json:"attendance"`
+	Sessions   []SessionMark      `json:"sessions,omitempty"`
+}
+
+type DocumentRecord struct {
+	Title      string    `json:"title"`
+	Filename   string    `json:"filename"`
+	Content    []byte    `json:"content"`
+	MimeType   string    `json:"mime_type"`
+	UploadedAt time.Time `json:"uploaded_at"`
+}
+
+type DocumentsRecord struct {
+	Enrollment EnrollNewRecord  `json:"enrollment"`
+	Documents  []DocumentRecord `json:"documents"`
+}
+
+type DriveRecord struct {
+	ID           int         `json:"id"`
+	StartDate    time.Time   `json:"start_date"`
+	EndDate      time.Time   `json:"end_date"`
+	RoleName     string      `json:"role_name"`
+	MinimumGPA   float64     `json:"minimum_gpa"`
+	CTC          int         `json:"ctc"`
+	JobCategory  JobCategory `json:"job_category"`
+	Applications []int       `json:"applications"`
+	Rounds       []Round     `json:"rounds,omitempty"`
+	// Criteria holds eligibility criteria other than the minimum CGPA.
+	Criteria           []CriterionRecord `json:"criteria,omitempty"`
+	AnnouncedAt        time.Time         `json:"announced_at"`
+	ResultsPublishedAt time.Time         `json:"results_published_at"`
+	ArchivedAt         time.Time         `json:"archived_at"`
+}
+
+// CriterionRecord is the serialisable form of a Criterion.
+type CriterionRecord struct {
+	Kind    string   `json:"kind"`
+	Value   float64  `json:"value,omitempty"`
+	Numbers []int    `json:"numbers,omitempty"`
+	Names   []string `json:"names,omitempty"`
+	// Expression is the source of a rule criterion.
+	Expression string `json:"expression,omitempty"`
+}
+
+type CompanyRecord struct {
+	ID     int           `json:"id"`
+	Name   string        `json:"name"`
+	Drives []DriveRecord `json:"drives"`
+}
+
+type ApplicantRecord struct {
+	Student          StudentData    `json:"student"`
+	AcademicRecord   AcademicRecord `json:"academic_record"`
+	DrivesAppliedFor []int          `json:"drives_applied_for"`
+	OffersReceived   []int          `json:"offers_received"`
+	Department       string         `json:"department,omitempty"`
+	GraduationYear   int            `json:"graduation_year,omitempty"`
+}
+
+type ApplicationRecord struct {
+	ID           int               `json:"id"`
+	DriveID      int               `json:"drive_id"`
+	StudentID    int               `json:"student_id"`
+	Status       ApplicationStatus `json:"status"`
+	History      []StatusChange    `json:"history,omitempty"`
+	RoundResults []RoundResult     `json:"round_results,omitempty"`
+}
+
+type OfferRecord struct {
+	ID            int         `json:"id"`
+	ApplicationID int         `json:"application_id"`
+	StudentID     int         `json:"student_id"`
+	DriveID       int         `json:"drive_id"`
+	Status        OfferStatus `json:"status"`
+	IssuedAt      time.Time   `json:"issued_at"`
+	RespondBy     time.Time   `json:"respond_by"`
+	RespondedAt   time.Time   `json:"responded_at"`
+}
+
+type AccountRecord struct {
+	Username     string `json:"username"`
+	Role         string `json:"role"`
+	StudentID    int    `json:"student_id,omitempty"`
+	TeacherID    string `json:"teacher_id,omitempty"`
+	PasswordHash string `json:"password_hash"`
+}
+
+// EncodeSnapshot serialises s as indented JSON.
+func EncodeSnapshot(s *Snapshot) ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// DecodeSnapshot parses a snapshot written by this or an older build,
+// running any migrations needed to bring it up to SnapshotSchemaVersion.
+func DecodeSnapshot(data []byte) (*Snapshot, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	var version int
+	if v, ok := raw["schema_version"]; ok {
+		if err := json.Unmarshal(v, &version); err != nil {
+			return nil, err
+		}
+	}
+	if version <= 0 || version > SnapshotSchemaVersion {
+		return nil, fmt.Errorf("%w: got %d, this build reads up to %d", ErrSnapshotVersion, version, SnapshotSchemaVersion)
+	}
+	for ; version < SnapshotSchemaVersion; version++ {
+		migrate, ok := snapshotMigrations[version]
+		if !ok {
+			return nil, fmt.Errorf("%w: no migration from version %d", ErrSnapshotVersion, version)
+		}
+		if err := migrate(raw); err != nil {
+			return nil, fmt.Errorf("migrating snapshot from version %d: %w", version, err)
+		}
+	}
+	raw["schema_version"], _ = json.Marshal(version)
+
+	upgraded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(upgraded, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// EncodeGrader describes g as a GraderRecord.
+func EncodeGrader(g Grader) (GraderRecord, error) {
+	switch g := g.(type) {
+	case nil:
+		return GraderRecord{}, nil
+	case PercentageGrader:
+		return GraderRecord{Kind: "percentage"}, nil
+	case PassFailGrader:
+		return GraderRecord{Kind: "pass_fail", PassMark: g.PassMark}, nil
+	case LetterGrader:
+		return GraderRecord{Kind: "letter"}, nil
+	case ScaleGrader:
+		return GraderRecord{Kind: "scale", MaxScore: g.MaxScore}, nil
+	case CurveGrader:
+		return GraderRecord{Kind: "curve", Cutoffs: g.Cutoffs}, nil
+	}
+	return GraderRecord{}, fmt.Errorf("grader %T cannot be saved", g)
+}
+
+// EncodeCriterion describes c as a CriterionRecord.
+func EncodeCriterion(c Criterion) (CriterionRecord, error) {
+	switch c := c.(type) {
+	case MinCGPA:
+		return CriterionRecord{Kind: "min_cgpa", Value: float64(c)}, nil
+	case MaxBacklogs:
+		return CriterionRecord{Kind: "max_backlogs", Value: float64(c)}, nil
+	case MinAttendance:
+		return CriterionRecord{Kind: "min_attendance", Value: float64(c)}, nil
+	case AllowedSemesters:
+		return CriterionRecord{Kind: "semesters", Numbers: c}, nil
+	case GraduationYears:
+		return CriterionRecord{Kind: "graduation_years", Numbers: c}, nil
+	case Departments:
+		return CriterionRecord{Kind: "departments", Names: c}, nil
+	case *Rule:
+		return CriterionRecord{Kind: "rule", Expression: c.String()}, nil
+	}
+	return CriterionRecord{}, fmt.Errorf("criterion %T cannot be saved", c)
+}
+
+// DecodeCriterion returns the Criterion described by r.
+func DecodeCriterion(r CriterionRecord) (Criterion, error) {
+	switch r.Kind {
+	case "min_cgpa":
+		return MinCGPA(r.Value), nil
+	case "max_backlogs":
+		return MaxBacklogs(int(r.Value)), nil
+	case "min_attendance":
+		return MinAttendance(r.Value), nil
+	case "semesters":
+		return AllowedSemesters(r.Numbers), nil
+	case "graduation_years":
+		return GraduationYears(r.Numbers), nil
+	case "departments":
+		return Departments(r.Names), nil
+	case "rule":
+		return ParseRule(r.Expression)
+	}
+	return nil, fmt.Errorf("unknown criterion kind %q", r.Kind)
+}
+
+// DecodeGrader returns the Grader described by r.
+func DecodeGrader(r GraderRecord) (Grader, error) {
+	switch r.Kind {
+	case "":
+		return nil, nil
+	case "percentage":
+		return PercentageGrader{}, nil
+	case "pass_fail":
+		return PassFailGrader{PassMark: r.PassMark}, nil
+	case "letter":
+		return LetterGrader{}, nil
+	case "scale":
+		if r.MaxScore < 0 {
+			return nil, fmt.Errorf("scale grader maximum score must not be negative, got %g", r.MaxScore)
+		}
+		return ScaleGrader{MaxScore: r.MaxScore}, nil
+	case "curve":
+		return CurveGrader{Cutoffs: r.Cutoffs}, nil
+	}
+	return nil, fmt.Errorf("unknown grader kind %q", r.Kind)
+}
+
+func studentRecord(s Student) StudentData {
+	return StudentData{ID: s.id, Name: s.name}
+}
+
+func courseRecord(c Course) CourseRecord {
+	return CourseRecord{ID: c.Id, Name: c.Name}
+}
+
+func teacherRecord(t Teacher) TeacherRecord {
+	return TeacherRecord{ID: t.ID, Name: t.Name}
+}
+
+func enrollmentRecord(e Enrollment) (EnrollmentRecord, error) {
+	g, err := EncodeGrader(e.Grader)
+	if err != nil {
+		return EnrollmentRecord{}, fmt.Errorf("enrollment of student %d in course %d: %w", e.Student.id, e.Course.Id, err)
+	}
+	return EnrollmentRecord{Student: studentRecord(e.Student), Course: courseRecord(e.Course), Grader: g, Score: e.score, Marks: e.marks, Marked: e.marked}, nil
+}
+
+func enrollNewRecord(e EnrollNew) (EnrollNewRecord, error) {
+	er, err := enrollmentRecord(e.Enrollment)
+	if err != nil {
+		return EnrollNewRecord{}, err
+	}
+	rec := EnrollNewRecord{Enrollment: er, Teacher: teacherRecord(e.Teacher)}
+	for date, present := range e.Attend.Records {
+		rec.Attendance = append(rec.Attendance, AttendanceRecord{Date: date, Present: present, Excused: e.Attend.Excused[date]})
+	}
+	sort.Slice(rec.Attendance, func(i, j int) bool {
+		return rec.Attendance[i].Date.Before(rec.Attendance[j].Date)
+	})
+	for id, status := range e.Attend.Sessions {
+		rec.Sessions = append(rec.Sessions, SessionMark{SessionID: id, Status: status})
+	}
+	sort.Slice(rec.Sessions, func(i, j int) bool { return rec.Sessions[i].SessionID < rec.Sessions[j].SessionID })
+	return rec, nil
+}
+
+func idsOfDrives(drives []*Drive) []int {
+	ids := make([]int, 0, len(drives))
+	for _, d := range drives {
+		ids = append(ids, d.id)
+	}
+	return ids
+}
+
+// Snapshot captures the current state of both registrars.
+func (p *Portal) Snapshot() (*Snapshot, error) {
+	s := &Snapshot{SchemaVersion: SnapshotSchemaVersion, SavedAt: time.Now().UTC()}
+
+	if ac := p.Academic; ac != nil && ac.NewRegistrarS != nil {
+		for _, st := range ac.students {
+			s.Students = append(s.Students, studentRecord(st))
+		}
+		for _, c := range ac.courses {
+			s.Courses = append(s.Courses, courseRecord(c))
+		}
+		for _, e := range ac.enrollments {
+			rec, err := enrollmentRecord(e)
+			if err != nil {
+				return nil, err
+			}
+			s.Enrollments = append(s.Enrollments, rec)
+		}
+		for _, t := range ac.teacher {
+			s.Teachers = append(s.Teachers, teacherRecord(t))
+		}
+		for _, te := range ac.Teachermap {
+			c := courseRecord(te.Course)
+			c.Credits, c.Semester = te.Credits, te.Semester
+			s.TeacherEnrollments = append(s.TeacherEnrollments, TeacherEnrollmentRecord{Teacher: teacherRecord(te.Teacher), Course: c})
+		}
+		for _, e := range ac.enroll {
+			rec, err := enrollNewRecord(e)
+			if err != nil {
+				return nil, err
+			}
+			s.EnrollNew = append(s.EnrollNew, rec)
+		}
+		for _, e := range ac.enrollWithDocs {
+			rec, err := enrollNewRecord(e.EnrollNew)
+			if err != nil {
+				return nil, err
+			}
+			docs := DocumentsRecord{Enrollment: rec}
+			for _, d := range e.Documents {
+				docs.Documents = append(docs.Documents, DocumentRecord(d))
+			}
+			s.Documents = append(s.Documents, docs)
+		}
+		s.Transcripts = ac.results
+		s.Assessments = ac.Assessments()
+		for _, re := range ac.reEvaluations {
+			s.ReEvaluations = append(s.ReEvaluations, *re)
+		}
+		s.GradeHistory = ac.gradeHistory
+		s.AttendanceMinimums = ac.AttendanceMinimums()
+		s.Condonations = ac.condonations
+		for _, cs := range ac.sessions {
+			s.Sessions = append(s.Sessions, *cs)
+		}
+		for _, l := range ac.leaves {
+			s.Leaves = append(s.Leaves, *l)
+		}
+	}
+
+	if pr := p.Placement; pr != nil {
+		for _, c := range pr.companies {
+			if c == nil {
+				continue
+			}
+			cr := CompanyRecord{ID: c.id, Name: c.name}
+			for _, d := range c.drives {
+				dr := DriveRecord{
+					ID:          d.id,
+					StartDate:   d.startDate,
+					EndDate:     d.endDate,
+					RoleName:    d.roleName,
+					MinimumGPA:  d.eligibility.Requirement(),
+					CTC:         d.ctc,
+					JobCategory: d.jobCategory,
+					Rounds:      d.rounds,
+
+					AnnouncedAt:        d.announcedAt,
+					ResultsPublishedAt: d.resultsPublishedAt,
+					ArchivedAt:         d.archivedAt,
+				}
+				for _, c := range d.eligibility.criteria {
+					if _, ok := c.(MinCGPA); ok {
+						continue
+					}
+					rec, err := EncodeCriterion(c)
+					if err != nil {
+						return nil, fmt.Errorf("drive %d: %w", d.id, err)
+					}
+					dr.Criteria = append(dr.Criteria, rec)
+				}
+				for _, app := range d.applications {
+					dr.Applications = append(dr.Applications, app.id)
+				}
+				cr.Drives = append(cr.Drives, dr)
+			}
+			s.Companies = append(s.Companies, cr)
+		}
+		for _, a := range pr.applicants {
+			s.Applicants = append(s.Applicants, ApplicantRecord{
+				Student:          studentRecord(a.Student),
+				AcademicRecord:   a.AcademicRecord,
+				DrivesAppliedFor: idsOfDrives(a.drivesAppliedFor),
+				OffersReceived:   idsOfDrives(a.offersReceived),
+				Department:       a.Department,
+				GraduationYear:   a.GraduationYear,
+			})
+		}
+		for _, app := range pr.applications {
+			s.Applications = append(s.Applications, ApplicationRecord{
+				ID:           app.id,
+				DriveID:      app.driveId,
+				StudentID:    app.Applicant.id,
+				Status:       app.status,
+				History:      app.history,
+				RoundResults: app.roundResults,
+			})
+		}
+		for _, o := range pr.offers {
+			s.Offers = append(s.Offers, OfferRecord{
+				ID:            o.id,
+				ApplicationID: o.applicationID,
+				StudentID:     o.studentID,
+				DriveID:       o.driveID,
+				Status:        o.status,
+				IssuedAt:      o.issuedAt,
+				RespondBy:     o.respondBy,
+				RespondedAt:   o.respondedAt,
+			})
+		}
+	}
+
+	if p.Accounts != nil {
+		for _, a := range p.Accounts.accounts {
+			s.Accounts = append(s.Accounts, AccountRecord{
+				Username:     a.Username,
+				Role:         a.Role.String(),
+				StudentID:    a.StudentID,
+				TeacherID:    a.TeacherID,
+				PasswordHash: a.passwordHash,
+			})
+		}
+	}
+
+	if p.Notifications != nil {
+		s.Subscriptions = p.Notifications.Subscriptions()
+		s.Notifications = p.Notifications.inbox.messages
+	}
+	if p.Reminders != nil {
+		s.Reminders = p.Reminders.Sent()
+	}
+	s.GradeScale = p.Placement.gradeScale
+	return s, nil
+}
+
+func restoreStudent(sd StudentData) (Student, error) {
+	if sd.ID <= 0 {
+		return Student{}, fmt.Errorf("student id must be positive, got %d", sd.ID)
+	}
+	return Student{id: sd.ID, name: sd.Name}, nil
+}
+
+func restoreEnrollment(r EnrollmentRecord) (Enrollment, error) {
+	st, err := restoreStudent(r.Student)
+	if err != nil {
+		return Enrollment{}, err
+	}
+	g, err := DecodeGrader(r.Grader)
+	if err != nil {
+		return Enrollment{}, err
+	}
+	e := NewEnrollment(st, NewCourse(r.Course.ID, r.Course.Name), g, r.Score)
+	e.marks, e.marked = r.Marks, r.Marked
+	return e, nil
+}
+
+func restoreEnrollNew(r EnrollNewRecord) (EnrollNew, error) {
+	e, err := restoreEnrollment(r.Enrollment)
+	if err != nil {
+		return EnrollNew{}, err
+	}
+	att := Attendance{Records: make(map[time.Time]bool, len(r.Attendance))}
+	for _, a := range r.Attendance {
+		att.Records[a.Date] = a.Present
+		if a.Excused {
+			if att.Excused == nil {
+				att.Excused = make(map[time.Time]bool)
+			}
+			att.Excused[a.Date] = true
+		}
+	}
+	for _, m := range r.Sessions {
+		markSession(&att, m.SessionID, m.Status)
+	}
+	return EnrollNew{Enrollment: e, Attend: att, Teacher: NewTeacher(r.Teacher.ID, r.Teacher.Name)}, nil
+}
+
+// RestorePortal rebuilds a portal from a snapshot produced by Portal.Snapshot.
+func RestorePortal(s *Snapshot) (*Portal, error) {
+	if s.SchemaVersion != SnapshotSchemaVersion {
+		return nil, fmt.Errorf("%w: got %d, want %d", ErrSnapshotVersion, s.SchemaVersion, SnapshotSchemaVersion)
+	}
+	p := NewPortal()
+	ac := p.Academic
+	scale := DefaultGradeScale()
+	if s.GradeScale != nil {
+		if err := s.GradeScale.Validate(); err != nil {
+			return nil, err
+		}
+		scale = s.GradeScale
+		ac.gradeScale, p.Placement.gradeScale = s.GradeScale, s.GradeScale
+	}
+
+	for _, sd := range s.Students {
+		st, err := restoreStudent(sd)
+		if err != nil {
+			return nil, err
+		}
+		ac.AddStudent(st)
+	}
+	for _, c := range s.Courses {
+		ac.AddCourse(NewCourse(c.ID, c.Name))
+	}
+	for _, r := range s.Enrollments {
+		e, err := restoreEnrollment(r)
+		if err != nil {
+			return nil, err
+		}
+		ac.Enroll(e)
+	}
+	for _, t := range s.Teachers {
+		ac.AddTeacher(NewTeacher(t.ID, t.Name))
+	}
+	for _, te := range s.TeacherEnrollments {
+		course := NewCreditCourse(NewCourse(te.Course.ID, te.Course.Name), te.Course.Credits)
+		course.Semester = te.Course.Semester
+		ac.AddTeacherenrollment(NewTeacherEnrollment(NewTeacher(te.Teacher.ID, te.Teacher.Name), course))
+	}
+	for _, r := range s.EnrollNew {
+		e, err := restoreEnrollNew(r)
+		if err != nil {
+			return nil, err
+		}
+		ac.AddEnrollnew(e)
+	}
+	for _, r := range s.Documents {
+		e, err := restoreEnrollNew(r.Enrollment)
+		if err != nil {
+			return nil, err
+		}
+		withDocs := EnrollnewWithDocs{EnrollNew: e}
+		for _, d := range r.Documents {
+			withDocs.Documents = append(withDocs.Documents, Document(d))
+		}
+		ac.EnrollnewWithDocs(withDocs)
+	}
+	if err := scale.CheckResults(s.Transcripts...); err != nil {
+		return nil, err
+	}
+	ac.results = s.Transcripts
+	for _, a := range s.Assessments {
+		if err := a.Validate(); err != nil {
+			return nil, err
+		}
+		if ac.assessments == nil {
+			ac.assessments = map[int]*Assessment{}
+		}
+		ac.assessments[a.CourseID] = a
+	}
+	for i, re := range s.ReEvaluations {
+		if re.ID != i+1 {
+			return nil, fmt.Errorf("re-evaluation %d is out of order", re.ID)
+		}
+		ac.reEvaluations = append(ac.reEvaluations, &re)
+	}
+	ac.gradeHistory = s.GradeHistory
+	for _, m := range s.AttendanceMinimums {
+		if err := ac.SetMinimumAttendance(m); err != nil {
+			return nil, err
+		}
+	}
+	ac.condonations = s.Condonations
+	for i, cs := range s.Sessions {
+		if cs.ID != i+1 {
+			return nil, fmt.Errorf("session %d is out of order", cs.ID)
+		}
+		ac.sessions = append(ac.sessions, &cs)
+	}
+	for i, l := range s.Leaves {
+		if l.ID != i+1 {
+			return nil, fmt.Errorf("leave request %d is out of order", l.ID)
+		}
+		ac.leaves = append(ac.leaves, &l)
+	}
+
+	pr := p.Placement
+	drives := make(map[int]*Drive)
+	driveApps := make(map[int][]int)
+	for _, cr := range s.Companies {
+		c := &Company{id: cr.ID, name: cr.Name, drives: make([]*Drive, 0, len(cr.Drives))}
+		companyIDs.reserve(cr.ID)
+		for _, dr := range cr.Drives {
+			criteria := make([]Criterion, 0, len(dr.Criteria))
+			for _, rec := range dr.Criteria {
+				c, err := DecodeCriterion(rec)
+				if err != nil {
+					return nil, fmt.Errorf("drive %d: %w", dr.ID, err)
+				}
+				criteria = append(criteria, c)
+			}
+			d := &Drive{
+				id:          dr.ID,
+				startDate:   dr.StartDate,
+				endDate:     dr.EndDate,
+				roleName:    dr.RoleName,
+				eligibility: *NewEligibility(dr.MinimumGPA, criteria...),
+				ctc:         dr.CTC,
+				jobCategory: dr.JobCategory,
+				rounds:      dr.Rounds,
+
+				announcedAt:        dr.AnnouncedAt,
+				resultsPublishedAt: dr.ResultsPublishedAt,
+				archivedAt:         dr.ArchivedAt,
+			}
+			driveIDs.reserve(dr.ID)
+			drives[d.id] = d
+			driveApps[d.id] = dr.Applications
+			c.AddDrive(d)
+		}
+		pr.AddCompany(c)
+	}
+
+	lookupDrives := func(ids []int) ([]*Drive, error) {
+		out := make([]*Drive, 0, len(ids))
+		for _, id := range ids {
+			d, ok := drives[id]
+			if !ok {
+				return nil, fmt.Errorf("snapshot references unknown drive %d", id)
+			}
+			out = append(out, d)
+		}
+		return out, nil
+	}
+
+	applicants := make(map[int]*Applicant)
+	for _, r := range s.Applicants {
+		st, err := restoreStudent(r.Student)
+		if err != nil {
+			return nil, err
+		}
+		a := NewApplicant(st, r.AcademicRecord)
+		a.Department, a.GraduationYear = r.Department, r.GraduationYear
+		if a.Semesters == nil {
+			a.Semesters = make(map[int]*SemesterResult)
+		}
+		if err := scale.CheckResults(a.Results()...); err != nil {
+			return nil, err
+		}
+		a.useScale(s.GradeScale)
+		if a.drivesAppliedFor, err = lookupDrives(r.DrivesAppliedFor); err != nil {
+			return nil, err
+		}
+		if a.offersReceived, err = lookupDrives(r.OffersReceived); err != nil {
+			return nil, err
+		}
+		applicants[st.id] = a
+		pr.applicants = append(pr.applicants, a)
+	}
+
+	apps := make(map[int]*Application)
+	for _, r := range s.Applications {
+		a, ok := applicants[r.StudentID]
+		if !ok {
+			return nil, fmt.Errorf("application %d references unknown applicant %d", r.ID, r.StudentID)
+		}
+		app := &Application{id: r.ID, driveId: r.DriveID, Applicant: a, status: r.Status, history: r.History, roundResults: r.RoundResults}
+		apps[app.id] = app
+		pr.applications = append(pr.applications, app)
+	}
+	for id, appIDs := range driveApps {
+		for _, appID := range appIDs {
+			app, ok := apps[appID]
+			if !ok {
+				return nil, fmt.Errorf("drive %d references unknown application %d", id, appID)
+			}
+			drives[id].AppendApplication(app)
+		}
+	}
+	for _, r := range s.Offers {
+		if _, ok := apps[r.ApplicationID]; !ok {
+			return nil, fmt.Errorf("offer %d references unknown application %d", r.ID, r.ApplicationID)
+		}
+		o := &Offer{
+			id: r.ID, applicationID: r.ApplicationID, studentID: r.StudentID, driveID: r.DriveID,
+			status: r.Status, issuedAt: r.IssuedAt, respondBy: r.RespondBy, respondedAt: r.RespondedAt,
+		}
+		if o.status == OfferAccepted {
+			if a, ok := applicants[o.studentID]; ok {
+				a.finalOffer = drives[o.driveID]
+			}
+		}
+		pr.offers = append(pr.offers, o)
+	}
+
+	for _, r := range s.Accounts {
+		role, err := ParseRole(r.Role)
+		if err != nil {
+			return nil, fmt.Errorf("account %q: %w", r.Username, err)
+		}
+		a := &Account{Username: r.Username, Role: role, StudentID: r.StudentID, TeacherID: r.TeacherID, passwordHash: r.PasswordHash}
+		if err := p.Accounts.Add(a); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, sub := range s.Subscriptions {
+		if err := p.Notifications.Subscribe(sub); err != nil {
+			return nil, fmt.Errorf("subscription of student %d: %w", sub.StudentID, err)
+		}
+	}
+	p.Notifications.inbox.messages = s.Notifications
+	for _, m := range s.Notifications {
+		p.Notifications.lastID = max(p.Notifications.lastID, m.ID)
+	}
+	for _, r := range s.Reminders {
+		p.Reminders.sent[r.Key] = r
+	}
+	return p, nil
+}
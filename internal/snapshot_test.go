@@ -0,0 +1,233 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func samplePortal() *Portal {
+	p := NewPortal()
+	alice := NewStudent(1, "Alice")
+	math := NewCourse(101, "Math")
+	teacher := NewTeacher("T1", "Prof. Smith")
+
+	p.Academic.AddStudent(alice)
+	p.Academic.AddCourse(math)
+	p.Academic.Enroll(NewEnrollment(alice, math, LetterGrader{}, 8.5))
+	p.Academic.AddTeacher(teacher)
+	p.Academic.AddTeacherenrollment(NewTeacherEnrollment(teacher, NewCreditCourse(math, 4)))
+
+	day := time.Date(2025, time.July, 1, 9, 0, 0, 0, time.UTC)
+	att := Attendance{}
+	MarkAttendance(&att, day, true)
+	MarkAttendance(&att, day.AddDate(0, 0, 1), false)
+	p.Academic.AddEnrollnew(NewEnrollNew(alice, math, PassFailGrader{PassMark: 5}, 7, att, teacher))
+
+	company := NewCompany("Acme")
+	drive := NewDrive(day, day.AddDate(0, 0, 14), "Engineer", 6.0, 1200000, Dream)
+	drive.AddCriteria(MaxBacklogs(0), Departments{"CSE"})
+	company.AddDrive(drive)
+	p.Placement.AddCompany(company)
+
+	record := NewAcademicRecord(1)
+	record.AddResult(NewCourseResult(1, 101, "Math", Aplus, 1, 4), 1)
+	applicant := NewApplicant(alice, *record)
+	applicant.Department, applicant.GraduationYear = "CSE", 2026
+	p.Placement.applicants = append(p.Placement.applicants, applicant)
+	p.Placement.SetClock(func() time.Time { return day.AddDate(0, 0, 3) })
+	_ = p.Placement.ApplyForDrive(1, company.ID(), drive.ID())
+	_ = drive.SetRounds([]Round{{Name: "Aptitude", Kind: AptitudeTest, PassScore: 50}, {Name: "HR", Kind: HRInterview, PassScore: 5}})
+	_, _ = p.Placement.RecordRoundResult(1, company.ID(), drive.ID(), 1, 72, "officer")
+	return p
+}
+
+func TestPortalSnapshot_RoundTrip(t *testing.T) {
+	original := samplePortal()
+	snap, err := original.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	data, err := EncodeSnapshot(snap)
+	if err != nil {
+		t.Fatalf("EncodeSnapshot failed: %v", err)
+	}
+	decoded, err := DecodeSnapshot(data)
+	if err != nil {
+		t.Fatalf("DecodeSnapshot failed: %v", err)
+	}
+	restored, err := RestorePortal(decoded)
+	if err != nil {
+		t.Fatalf("RestorePortal failed: %v", err)
+	}
+
+	ac := restored.Academic
+	if len(ac.students) != 1 || ac.students[0].Name() != "Alice" {
+		t.Errorf("students not restored: %v", ac.students)
+	}
+	if len(ac.Teachermap) != 1 || ac.Teachermap[0].Credits != 4 {
+		t.Errorf("teacher map not restored: %v", ac.Teachermap)
+	}
+	if len(ac.enroll) != 1 {
+		t.Fatalf("expected 1 enrollment, got %d", len(ac.enroll))
+	}
+	e := ac.enroll[0]
+	if e.score != 7 || e.Grader != (PassFailGrader{PassMark: 5}) {
+		t.Errorf("score/grader not restored: %v %v", e.score, e.Grader)
+	}
+	if len(e.Attend.Records) != 2 {
+		t.Errorf("expected 2 attendance records, got %d", len(e.Attend.Records))
+	}
+
+	pr := restored.Placement
+	if len(pr.applications) != 1 || pr.applications[0].Status() != ShortListed {
+		t.Fatalf("application status not restored: %v", pr.applications)
+	}
+	if h := pr.applications[0].History(); len(h) != 1 || h[0].To != ShortListed || h[0].Actor != "officer" {
+		t.Errorf("application history not restored: %+v", h)
+	}
+	if r := pr.applications[0].RoundResults(); len(r) != 1 || r[0].Score != 72 || !r[0].Passed {
+		t.Errorf("round results not restored: %+v", r)
+	}
+	drive := pr.AllDrives()[0]
+	if len(drive.Rounds()) != 2 || drive.Rounds()[1].Kind != HRInterview {
+		t.Errorf("drive rounds not restored: %+v", drive.Rounds())
+	}
+	if c := drive.Eligibility().Criteria(); len(c) != 3 || c[0] != MinCGPA(6) || c[1] != MaxBacklogs(0) {
+		t.Errorf("drive criteria not restored: %v", c)
+	}
+	if a := pr.applicants[0]; a.Department != "CSE" || a.GraduationYear != 2026 {
+		t.Errorf("applicant programme not restored: %q %d", a.Department, a.GraduationYear)
+	}
+	if len(drive.Applications()) != 1 || drive.Applications()[0] != pr.applications[0] {
+		t.Error("drive applications should point at the restored applications")
+	}
+	if pr.applicants[0].CGPA != original.Placement.applicants[0].CGPA {
+		t.Errorf("CGPA not restored: got %v", pr.applicants[0].CGPA)
+	}
+	if len(pr.applicants[0].DrivesAppliedFor()) != 1 {
+		t.Error("drives applied for not restored")
+	}
+}
+
+func TestRestorePortal_ReservesIDs(t *testing.T) {
+	snap := &Snapshot{
+		SchemaVersion: SnapshotSchemaVersion,
+		Companies:     []CompanyRecord{{ID: 5000, Name: "Old", Drives: []DriveRecord{{ID: 7000}}}},
+	}
+	if _, err := RestorePortal(snap); err != nil {
+		t.Fatalf("RestorePortal failed: %v", err)
+	}
+	if c := NewCompany("New"); c.ID() <= 5000 {
+		t.Errorf("new company id %d collides with restored ids", c.ID())
+	}
+	if d := NewDrive(time.Now(), time.Now(), "Role", 0, 0, Day); d.ID() <= 7000 {
+		t.Errorf("new drive id %d collides with restored ids", d.ID())
+	}
+}
+
+func TestDecodeSnapshot_RejectsUnknownVersion(t *testing.T) {
+	for _, data := range []string{`{}`, `{"schema_version": 99}`} {
+		if _, err := DecodeSnapshot([]byte(data)); !errors.Is(err, ErrSnapshotVersion) {
+			t.Errorf("DecodeSnapshot(%s) = %v, want ErrSnapshotVersion", data, err)
+		}
+	}
+}
+
+func TestDecodeSnapshot_MigratesVersion1(t *testing.T) {
+	s, err := DecodeSnapshot([]byte(`{"schema_version": 1, "students": [{"id": 1, "name": "Alice"}]}`))
+	if err != nil {
+		t.Fatalf("DecodeSnapshot failed: %v", err)
+	}
+	if s.SchemaVersion != SnapshotSchemaVersion || len(s.Students) != 1 || s.Accounts == nil {
+		t.Errorf("unexpected migrated snapshot: %+v", s)
+	}
+}
+
+func TestDecodeSnapshot_MigratesSelectionsToOffers(t *testing.T) {
+	s, err := DecodeSnapshot([]byte(`{"schema_version": 4, "applications": [
+		{"id": 1, "drive_id": 10, "student_id": 1, "status": 3},
+		{"id": 2, "drive_id": 10, "student_id": 2, "status": 1}
+	]}`))
+	if err != nil {
+		t.Fatalf("DecodeSnapshot failed: %v", err)
+	}
+	if len(s.Offers) != 1 || s.Offers[0].ApplicationID != 1 || s.Offers[0].Status != OfferAccepted {
+		t.Errorf("expected the selected application to become an accepted offer, got %+v", s.Offers)
+	}
+}
+
+func TestDecodeSnapshot_MigratesMarkedEnrollments(t *testing.T) {
+	s, err := DecodeSnapshot([]byte(`{"schema_version": 19,
+		"enrollments": [{"student": {"id": 1}, "course": {"id": 101}, "grader": {}, "score": 7}],
+		"enroll_new": [
+			{"enrollment": {"student": {"id": 1}, "course": {"id": 101}, "grader": {}, "score": 0}, "teacher": {"id": "T1"}},
+			{"enrollment": {"student": {"id": 2}, "course": {"id": 101}, "grader": {}, "score": 0,
+				"marks": [{"component": "quiz", "attempt": 1, "marks": 0}]}, "teacher": {"id": "T1"}}
+		]}`))
+	if err != nil {
+		t.Fatalf("DecodeSnapshot failed: %v", err)
+	}
+	if !s.Enrollments[0].Marked || s.EnrollNew[0].Enrollment.Marked || !s.EnrollNew[1].Enrollment.Marked {
+		t.Errorf("expected enrollments with a score or marks to count as marked, got %+v and %+v", s.Enrollments, s.EnrollNew)
+	}
+}
+
+func TestPortalSnapshot_Offers(t *testing.T) {
+	p := samplePortal()
+	drive := p.Placement.AllDrives()[0]
+	company := p.Placement.Companies()[0]
+	if _, err := p.Placement.RecordRoundResult(1, company.ID(), drive.ID(), 2, 8, "officer"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Placement.TransitionApplication(1, drive.ID(), Selected, "officer"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Placement.AcceptOffer(1); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := p.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestorePortal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offers := restored.Placement.OffersFor(1)
+	if len(offers) != 1 || offers[0].Status() != OfferAccepted || offers[0].RespondBy().IsZero() {
+		t.Fatalf("offers not restored: %+v", offers)
+	}
+	if final := restored.Placement.applicants[0].FinalOffer(); final == nil || final.ID() != drive.ID() {
+		t.Errorf("final offer not restored: %v", final)
+	}
+}
+
+func TestPortalSnapshot_Accounts(t *testing.T) {
+	p := samplePortal()
+	alice, _ := NewAccount("alice", "password1", RoleStudent, 1, "")
+	if err := p.AddAccount(alice); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := p.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestorePortal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := restored.Accounts.Authenticate("alice", "password1"); err != nil {
+		t.Errorf("restored account should accept its password: %v", err)
+	}
+}
+
+func TestRestorePortal_DanglingApplication(t *testing.T) {
+	snap := &Snapshot{
+		SchemaVersion: SnapshotSchemaVersion,
+		Applications:  []ApplicationRecord{{ID: 1, DriveID: 1, StudentID: 42}},
+	}
+	if _, err := RestorePortal(snap); err == nil {
+		t.Error("expected error for application without applicant")
+	}
+}
@@ -1,6 +1,9 @@
 package internal
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 type Student struct {
 	id   int
@@ -20,3 +23,20 @@ func (s Student) ID() int      { return s.id }
 func (s Student) Display() {
 	fmt.Printf("Student #%d : %s\n", s.id, s.name)
 }
+
+// MarshalJSON encodes a student in the same shape as students.json.
+func (s Student) MarshalJSON() ([]byte, error) {
+	return json.Marshal(StudentData{ID: s.id, Name: s.name})
+}
+
+func (s *Student) UnmarshalJSON(data []byte) error {
+	var sd StudentData
+	if err := json.Unmarshal(data, &sd); err != nil {
+		return err
+	}
+	if sd.ID <= 0 {
+		return fmt.Errorf("student id must be positive, got %d", sd.ID)
+	}
+	s.id, s.name = sd.ID, sd.Name
+	return nil
+}
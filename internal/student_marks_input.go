@@ -4,4 +4,8 @@ type StudentMarkInput struct {
 	CourseID  int     `json:"course_id"`
 	StudentID int     `json:"student_id"`
 	Score     float64 `json:"score"`
+	// Component and Attempt name the part of the course's assessment Score
+	// is the marks for; without them Score is the final score.
+	Component string `json:"component,omitempty"`
+	Attempt   int    `json:"attempt,omitempty"`
 }
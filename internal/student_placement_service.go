@@ -1,6 +1,9 @@
 package internal
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // StudentPlacementService is responsible for handling the placement process for a student in a specific drive.
 // It encapsulates the student, the drive they are applying to, and the applicant information.
@@ -42,8 +45,7 @@ func (s *StudentPlacementService) CompaniesApplicable() []string {
 	eligibleCompanies := make(map[string]struct{})
 	for _, company := range s.PlacementRegistrar.companies {
 		for _, drive := range company.drives {
-			elig := drive.Eligibility()
-			if elig.CheckEligibility(&s.applicant) {
+			if len(drive.eligibility.Evaluate(s.PlacementRegistrar.ProfileOf(&s.applicant))) == 0 {
 				eligibleCompanies[company.name] = struct{}{}
 				break // No need to check other drives for this company
 			}
@@ -78,22 +80,19 @@ func (s *StudentPlacementService) GetDrive() Drive {
 	return s.drive
 }
 
-// Here i am trying to view the shortlisted status i.e the status after shortlisting
-func (s *StudentPlacementService) ViewShortlistStatus(id int) {
-	applications := s.drive.getShortlistedApplications()
-	for _, v := range applications {
-		if v.id == id {
-			fmt.Println("You just got shortlisted,prepare yourself for further rounds")
-		}
+// ViewShortlistStatus returns the "you were shortlisted" message stored in
+// the student's inbox for this drive, and whether there is one.
+func (s *StudentPlacementService) ViewShortlistStatus(inbox *Inbox) (Message, bool) {
+	page := inbox.Query(InboxQuery{StudentID: s.student.id, DriveID: s.drive.id, Kind: NotifyShortlisted, Limit: 1})
+	if len(page.Messages) == 0 {
+		return Message{}, false
 	}
-
+	return page.Messages[0], true
 }
 
-//TO-DO function to give students notifications related to new drives and results etc.
-//Trying to create a notification interface and then creating different types of notifications
-//and then sending them to the student
-// TO-DO: Automatic trigger for notifications if last date of application is near
-// Dont know how to do this, so will leave it for now
+// Notification is something to tell students about, such as a new drive.
+// Types that also implement Notice can be delivered by a Dispatcher. The
+// ReminderScheduler sends DeadlineReminders as application deadlines near.
 
 type Notification interface {
 	Send() interface{}
@@ -108,6 +107,16 @@ func (d *DriveNotification) Send() interface{} {
 	return d.drive
 }
 
+func (d *DriveNotification) Message() Message {
+	return Message{
+		Kind:    NotifyDriveOpened,
+		DriveID: d.drive.id,
+		Subject: fmt.Sprintf("New drive open: %s (%s)", d.drive.roleName, d.drive.jobCategory),
+		Body: fmt.Sprintf("Applications for %s (%s, CTC %d) are open until %s.",
+			d.drive.roleName, d.drive.jobCategory, d.drive.ctc, d.drive.endDate.Format(time.DateOnly)),
+	}
+}
+
 // NewDriveNotification creates a new DriveNotification for a given drive.
 func NewDriveNotification(drive Drive) Notification {
 	return &DriveNotification{drive: drive}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -31,8 +32,6 @@ func TestStudentPlacementService_Apply(t *testing.T) {
 	service.PlacementRegistrar.applicants = []*Applicant{&service.applicant}
 	service.PlacementRegistrar.companies = []*Company{&service.Company}
 	service.PlacementRegistrar.applications = nil
-	service.drive = service.drive
-	service.Company = service.Company
 	err := service.Apply()
 	if err != nil {
 		t.Errorf("Apply returned error: %v", err)
@@ -70,8 +69,23 @@ func TestStudentPlacementService_ViewOfferDetails(t *testing.T) {
 
 func TestStudentPlacementService_ViewShortlistStatus(t *testing.T) {
 	service := createTestPlacementService()
-	// Just ensure it doesn't panic
-	service.ViewShortlistStatus(service.student.ID())
+	service.PlacementRegistrar.applicants = []*Applicant{&service.applicant}
+	service.PlacementRegistrar.companies = []*Company{&service.Company}
+	d := NewDispatcher()
+	service.PlacementRegistrar.SetNotifier(d.Post)
+	if err := service.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := service.ViewShortlistStatus(d.Inbox()); ok {
+		t.Error("expected no shortlist message before shortlisting")
+	}
+	if err := service.PlacementRegistrar.UpdateApplicationStatus(1, service.drive.ID(), ShortListed); err != nil {
+		t.Fatal(err)
+	}
+	m, ok := service.ViewShortlistStatus(d.Inbox())
+	if !ok || m.Kind != NotifyShortlisted || m.DriveID != service.drive.ID() || !strings.Contains(m.Body, "prepare yourself for further rounds") {
+		t.Errorf("expected the stored shortlist message, got %+v, %v", m, ok)
+	}
 }
 
 func TestDriveNotification_Send(t *testing.T) {
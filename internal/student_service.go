@@ -2,7 +2,6 @@ package internal
 
 import (
 	"encoding/json"
-	"errors"
 
 	"os"
 )
@@ -15,7 +14,7 @@ func UpdateStudentName(students []Student, id int, newName string) error {
 			return nil
 		}
 	}
-	return errors.New("student not found")
+	return notFoundf("student not found")
 }
 
 // FindStudentByID returns a pointer to the student with the given ID, or nil if not found.
@@ -46,7 +45,7 @@ func DeleteStudentByID(students []Student, id int) ([]Student, error) {
 			return append(students[:i], students[i+1:]...), nil
 		}
 	}
-	return students, errors.New("student not found")
+	return students, notFoundf("student not found")
 }
 
 // SerializeStudents serializes the students slice to JSON and writes it to filename(user input).
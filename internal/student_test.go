@@ -2,6 +2,7 @@ package internal
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -114,7 +115,7 @@ func TestDeleteStudentByID(t *testing.T) {
 func TestSerializeStudents(t *testing.T) {
 	students := createSampleStudents()
 
-	err := SerializeStudents("students.json", students) // No return value, just ensure no panic
+	err := SerializeStudents(filepath.Join(t.TempDir(), "students.json"), students) // No return value, just ensure no panic
 	if err != nil {
 		t.Fatalf("Could not create the json: %v", err)
 	}
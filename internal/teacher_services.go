@@ -9,6 +9,10 @@ import (
 type TeacherService struct {
 	Registrar *RegistrarWithDocs
 	Teacher   Teacher
+
+	// quiet uploads record marks without a MarksNotification, for callers
+	// that tell the student themselves.
+	quiet bool
 }
 
 func (ts *TeacherService) DisplayAttendance(courseID int, studentID int) {
@@ -26,21 +30,64 @@ func (ts *TeacherService) DisplayAttendance(courseID int, studentID int) {
 	fmt.Println("No attendance records found.")
 }
 
+// UploadStudentMark stores a student's score. When the course is graded on
+// the grade scale, the resulting letter is recorded on their transcript too.
+// Courses with an Assessment take marks per component instead.
 func (ts *TeacherService) UploadStudentMark(courseID int, studentID int, score float64) error {
+	r := ts.Registrar.NewRegistrarS
+	if _, ok := r.Assessment(courseID); ok {
+		return conflictf("course %d is marked by component; upload marks for its components", courseID)
+	}
+	i, ok := ts.enrollment(courseID, studentID)
+	if !ok {
+		return notFoundf("no valid enrollment found for this teacher, student, and course")
+	}
+	e := r.enroll[i]
+	e.score, e.marked = score, true
+	return ts.upload(i, e)
+}
+
+// enrollment returns the position of the teacher's enrollment of the student
+// in the course.
+func (ts *TeacherService) enrollment(courseID, studentID int) (int, bool) {
 	for i, e := range ts.Registrar.enroll {
 		if e.Course.Id == courseID && e.Student.ID() == studentID && e.Teacher.TID() == ts.Teacher.TID() {
-			ts.Registrar.enroll[i].score = score
-			grade, err := e.Grader.Grade(ts.Registrar.enroll[i].Enrollment)
-			if err != nil {
-				return err
-			}
-			fmt.Printf("Uploaded score %.2f for student %d in course %d. Grade: %s\n", score, studentID, courseID, grade)
-			return nil
+			return i, true
 		}
 	}
-	return errors.New("no valid enrollment found for this teacher, student, and course")
+	return 0, false
 }
 
+// upload grades e, the enrollment at position i with its new marks, and
+// stores it, recording the result on the transcript and, unless the service
+// is quiet, notifying the student. Nothing changes if it cannot be graded or
+// the student is debarred for an attendance shortage.
+func (ts *TeacherService) upload(i int, e EnrollNew) error {
+	r := ts.Registrar.NewRegistrarS
+	if err := r.enrollmentAttendance(e).debarment(); err != nil {
+		return err
+	}
+	grade, err := r.grade(e.Enrollment)
+	if err != nil {
+		return err
+	}
+	result, onTranscript, err := r.courseResult(e)
+	if err != nil {
+		return err
+	}
+	r.enroll[i].score, r.enroll[i].marks, r.enroll[i].marked = e.score, e.marks, e.marked
+	fmt.Printf("Uploaded score %.2f for student %d in course %d. Grade: %s\n", e.score, e.Student.ID(), e.Course.Id, grade)
+	if onTranscript {
+		r.recordResult(result)
+	}
+	if r.notify != nil && !ts.quiet {
+		r.notify(e.Student.ID(), &MarksNotification{CourseID: e.Course.Id, CourseName: e.Course.Name, Score: e.score, Grade: grade})
+	}
+	return nil
+}
+
+// UploadStudentMarksFromJSON uploads a list of StudentMarkInput. Entries
+// naming a component are marks in that component of the course's assessment.
 func (ts *TeacherService) UploadStudentMarksFromJSON(jsonData []byte) error {
 	var marks []StudentMarkInput
 	if err := json.Unmarshal(jsonData, &marks); err != nil {
@@ -49,7 +96,12 @@ func (ts *TeacherService) UploadStudentMarksFromJSON(jsonData []byte) error {
 
 	var errs []string
 	for _, mark := range marks {
-		err := ts.UploadStudentMark(mark.CourseID, mark.StudentID, mark.Score)
+		var err error
+		if mark.Component != "" {
+			err = ts.UploadComponentMark(mark.CourseID, mark.StudentID, ComponentMark{Component: mark.Component, Attempt: mark.Attempt, Marks: mark.Score})
+		} else {
+			err = ts.UploadStudentMark(mark.CourseID, mark.StudentID, mark.Score)
+		}
 		if err != nil {
 			errs = append(errs, fmt.Sprintf("student %d, course %d: %v", mark.StudentID, mark.CourseID, err))
 		}
@@ -65,7 +117,10 @@ func (ts *TeacherService) GetCourseResults(courseID int) ([]StudentResult, error
 	var results []StudentResult
 	for _, e := range ts.Registrar.enroll {
 		if e.Course.Id == courseID && e.Teacher.TID() == ts.Teacher.TID() {
-			grade, _ := e.Grader.Grade(e.Enrollment)
+			var grade string
+			if e.marked {
+				grade, _ = ts.Registrar.grade(e.Enrollment)
+			}
 			results = append(results, StudentResult{
 				CourseID:    e.Course.Id,
 				CourseName:  e.Course.Name,
@@ -73,11 +128,12 @@ func (ts *TeacherService) GetCourseResults(courseID int) ([]StudentResult, error
 				StudentName: e.Student.Name(),
 				Score:       e.score,
 				Grade:       grade,
+				Components:  e.ComponentMarks(),
 			})
 		}
 	}
 	if len(results) == 0 {
-		return nil, fmt.Errorf("no students found for course %d and teacher %s", courseID, ts.Teacher.TID())
+		return nil, notFoundf("no students found for course %d and teacher %s", courseID, ts.Teacher.TID())
 	}
 	return results, nil
 }
@@ -0,0 +1,238 @@
+package internal
+
+import (
+	"sort"
+	"time"
+)
+
+// SessionType is the kind of class a session is.
+type SessionType string
+
+const (
+	SessionLecture  SessionType = "lecture"
+	SessionLab      SessionType = "lab"
+	SessionTutorial SessionType = "tutorial"
+)
+
+// AttendanceStatus is how a student attended a session.
+type AttendanceStatus string
+
+const (
+	StatusPresent AttendanceStatus = "present"
+	StatusAbsent  AttendanceStatus = "absent"
+	// StatusLate counts as attended.
+	StatusLate AttendanceStatus = "late"
+	// StatusExcused leaves the session out of the student's attendance.
+	StatusExcused AttendanceStatus = "excused"
+)
+
+func (s AttendanceStatus) valid() bool {
+	switch s {
+	case StatusPresent, StatusAbsent, StatusLate, StatusExcused:
+		return true
+	}
+	return false
+}
+
+// Attended reports whether the session counts as attended.
+func (s AttendanceStatus) Attended() bool {
+	return s == StatusPresent || s == StatusLate
+}
+
+// ClassSession is one class on a course's timetable: a lecture, lab or
+// tutorial held by one of its teachers for their students in a period of a
+// day.
+type ClassSession struct {
+	ID        int         `json:"id"`
+	CourseID  int         `json:"course_id"`
+	TeacherID string      `json:"teacher_id"`
+	Date      time.Time   `json:"date"` // midnight UTC
+	Period    int         `json:"period"`
+	Type      SessionType `json:"type"`
+}
+
+// SessionMark is a student's attendance at a session.
+type SessionMark struct {
+	SessionID int              `json:"session_id"`
+	Status    AttendanceStatus `json:"status"`
+}
+
+// RollEntry is a student on a session's roll. Status is empty until
+// attendance is taken.
+type RollEntry struct {
+	StudentID   int              `json:"student_id"`
+	StudentName string           `json:"student_name"`
+	Status      AttendanceStatus `json:"status,omitempty"`
+}
+
+// SessionRoll is the roll of a session.
+type SessionRoll struct {
+	ClassSession
+	Students []RollEntry `json:"students"`
+}
+
+// SessionAttendance is a student's attendance at a session on the
+// timetable.
+type SessionAttendance struct {
+	ClassSession
+	Status AttendanceStatus `json:"status"`
+}
+
+// teaches reports whether the teacher is assigned the course.
+func (r *NewRegistrarS) teaches(teacherID string, courseID int) bool {
+	for _, te := range r.Teachermap {
+		if te.Teacher.ID == teacherID && te.Course.Id == courseID {
+			return true
+		}
+	}
+	return false
+}
+
+// ScheduleSession adds a session to a course's timetable. A lecture is
+// assumed when no type is given. A teacher can hold one session per period.
+func (r *NewRegistrarS) ScheduleSession(s ClassSession) (*ClassSession, error) {
+	if s.Type == "" {
+		s.Type = SessionLecture
+	}
+	switch {
+	case s.Type != SessionLecture && s.Type != SessionLab && s.Type != SessionTutorial:
+		return nil, invalidf("unknown session type %q; use %s, %s or %s", s.Type, SessionLecture, SessionLab, SessionTutorial)
+	case s.Date.IsZero():
+		return nil, invalidf("a session needs a date")
+	case s.Period < 1:
+		return nil, invalidf("periods are numbered from 1, got %d", s.Period)
+	}
+	if !r.teaches(s.TeacherID, s.CourseID) {
+		return nil, notEligiblef("teacher %s does not teach course %d", s.TeacherID, s.CourseID)
+	}
+	s.Date = dayOf(s.Date)
+	for _, other := range r.sessions {
+		if other.TeacherID == s.TeacherID && other.Date.Equal(s.Date) && other.Period == s.Period {
+			return nil, conflictf("teacher %s already holds session %d in period %d on %s", s.TeacherID, other.ID, s.Period, s.Date.Format(time.DateOnly))
+		}
+	}
+	s.ID = len(r.sessions) + 1
+	r.sessions = append(r.sessions, &s)
+	return &s, nil
+}
+
+// Session returns the session with the given id.
+func (r *NewRegistrarS) Session(id int) (*ClassSession, error) {
+	if id < 1 || id > len(r.sessions) {
+		return nil, notFoundf("session %d not found", id)
+	}
+	return r.sessions[id-1], nil
+}
+
+// Sessions returns a course's timetable by date and period. A teacherID
+// limits it to that teacher's sessions.
+func (r *NewRegistrarS) Sessions(courseID int, teacherID string) []*ClassSession {
+	out := []*ClassSession{}
+	for _, s := range r.sessions {
+		if s.CourseID == courseID && (teacherID == "" || s.TeacherID == teacherID) {
+			out = append(out, s)
+		}
+	}
+	sortSessions(out)
+	return out
+}
+
+func sortSessions(s []*ClassSession) {
+	sort.SliceStable(s, func(i, j int) bool {
+		if !s[i].Date.Equal(s[j].Date) {
+			return s[i].Date.Before(s[j].Date)
+		}
+		return s[i].Period < s[j].Period
+	})
+}
+
+// TakeAttendance records the attendance of a session's whole class at
+// once. Students of the session's teacher left out of roll are marked
+// absent. Taking attendance again replaces it.
+func (r *NewRegistrarS) TakeAttendance(sessionID int, roll map[int]AttendanceStatus) error {
+	s, err := r.Session(sessionID)
+	if err != nil {
+		return err
+	}
+	class := map[int]int{}
+	for i, e := range r.enroll {
+		if e.Course.Id == s.CourseID && e.Teacher.TID() == s.TeacherID {
+			class[e.Student.ID()] = i
+		}
+	}
+	for studentID, status := range roll {
+		if !status.valid() {
+			return invalidf("unknown attendance status %q; use %s, %s, %s or %s", status, StatusPresent, StatusAbsent, StatusLate, StatusExcused)
+		}
+		if _, ok := class[studentID]; !ok {
+			return notFoundf("student %d is not in teacher %s's class of course %d", studentID, s.TeacherID, s.CourseID)
+		}
+	}
+	for studentID, i := range class {
+		status, ok := roll[studentID]
+		if !ok {
+			status = StatusAbsent
+		}
+		r.markSession(i, s, status)
+	}
+	return nil
+}
+
+// markSession records the attendance of the enrollment at position i at a
+// session, excusing an absence covered by approved leave.
+func (r *NewRegistrarS) markSession(i int, s *ClassSession, status AttendanceStatus) {
+	if status == StatusAbsent {
+		if l := r.approvedLeave(r.enroll[i].Student.ID(), s.CourseID, s.Date); l != nil {
+			r.excuse(l, i, s.ID, s.Date)
+			return
+		}
+	}
+	markSession(&r.enroll[i].Attend, s.ID, status)
+}
+
+func markSession(a *Attendance, sessionID int, status AttendanceStatus) {
+	if a.Sessions == nil {
+		a.Sessions = make(map[int]AttendanceStatus)
+	}
+	a.Sessions[sessionID] = status
+}
+
+// SessionRoll returns the students of a session's class, by id, with their
+// attendance.
+func (r *NewRegistrarS) SessionRoll(sessionID int) (SessionRoll, error) {
+	s, err := r.Session(sessionID)
+	if err != nil {
+		return SessionRoll{}, err
+	}
+	roll := SessionRoll{ClassSession: *s, Students: []RollEntry{}}
+	for _, e := range r.enroll {
+		if e.Course.Id == s.CourseID && e.Teacher.TID() == s.TeacherID {
+			roll.Students = append(roll.Students, RollEntry{StudentID: e.Student.ID(), StudentName: e.Student.Name(), Status: e.Attend.Sessions[s.ID]})
+		}
+	}
+	sort.Slice(roll.Students, func(i, j int) bool { return roll.Students[i].StudentID < roll.Students[j].StudentID })
+	return roll, nil
+}
+
+// StudentSessions returns a student's attendance at the sessions of their
+// class of a course taken so far, by date and period.
+func (r *NewRegistrarS) StudentSessions(courseID, studentID int, teacherID string) ([]SessionAttendance, bool) {
+	for _, e := range r.enroll {
+		if e.Course.Id != courseID || e.Student.ID() != studentID || e.Teacher.TID() != teacherID {
+			continue
+		}
+		var sessions []*ClassSession
+		for id := range e.Attend.Sessions {
+			if s, err := r.Session(id); err == nil {
+				sessions = append(sessions, s)
+			}
+		}
+		sortSessions(sessions)
+		out := make([]SessionAttendance, 0, len(sessions))
+		for _, s := range sessions {
+			out = append(out, SessionAttendance{ClassSession: *s, Status: e.Attend.Sessions[s.ID]})
+		}
+		return out, true
+	}
+	return nil, false
+}
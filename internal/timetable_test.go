@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTakeAttendance(t *testing.T) {
+	p := NewPortal()
+	teacher := NewTeacher("T1", "Prof. Smith")
+	math := NewCourse(101, "Math")
+	p.Academic.AddCourse(math)
+	p.Academic.AddTeacher(teacher)
+	p.Academic.AddTeacherenrollment(NewTeacherEnrollment(teacher, NewCreditCourse(math, 4)))
+	for _, st := range []Student{NewStudent(1, "Alice"), NewStudent(2, "Bob"), NewStudent(3, "Carol")} {
+		p.Academic.AddStudent(st)
+		p.Academic.AddEnrollnew(NewEnrollNew(st, math, ScaleGrader{}, 0, Attendance{}, teacher))
+	}
+
+	day := time.Date(2025, 7, 1, 9, 30, 0, 0, time.UTC)
+	lecture, err := p.Academic.ScheduleSession(ClassSession{CourseID: 101, TeacherID: "T1", Date: day, Period: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lecture.ID != 1 || lecture.Type != SessionLecture || !lecture.Date.Equal(day.Truncate(24*time.Hour)) {
+		t.Errorf("expected a lecture on the day of %s, got %+v", day, lecture)
+	}
+	// Two sessions on the same day no longer collide.
+	lab, err := p.Academic.ScheduleSession(ClassSession{CourseID: 101, TeacherID: "T1", Date: day, Period: 3, Type: SessionLab})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range []struct {
+		s    ClassSession
+		kind error
+	}{
+		{ClassSession{CourseID: 101, TeacherID: "T1", Date: day, Period: 1}, ErrConflict},
+		{ClassSession{CourseID: 101, TeacherID: "T1", Date: day, Period: 0}, ErrInvalid},
+		{ClassSession{CourseID: 101, TeacherID: "T1", Date: day, Period: 2, Type: "seminar"}, ErrInvalid},
+		{ClassSession{CourseID: 101, TeacherID: "T2", Date: day, Period: 2}, ErrNotEligible},
+	} {
+		if _, err := p.Academic.ScheduleSession(bad.s); !errors.Is(err, bad.kind) {
+			t.Errorf("expected %+v to fail with %v, got %v", bad.s, bad.kind, err)
+		}
+	}
+	if got := p.Academic.Sessions(101, ""); !reflect.DeepEqual(got, []*ClassSession{lecture, lab}) {
+		t.Errorf("expected the lecture then the lab, got %+v", got)
+	}
+
+	if err := p.Academic.TakeAttendance(lecture.ID, map[int]AttendanceStatus{1: StatusPresent, 2: StatusLate}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Academic.TakeAttendance(lab.ID, map[int]AttendanceStatus{1: StatusPresent, 3: StatusExcused}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Academic.TakeAttendance(lab.ID, map[int]AttendanceStatus{4: StatusPresent}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a student outside the class to be refused, got %v", err)
+	}
+	if err := p.Academic.TakeAttendance(lab.ID, map[int]AttendanceStatus{1: "asleep"}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected an unknown status to be refused, got %v", err)
+	}
+	roll, err := p.Academic.SessionRoll(lab.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RollEntry{{1, "Alice", StatusPresent}, {2, "Bob", StatusAbsent}, {3, "Carol", StatusExcused}}
+	if !reflect.DeepEqual(roll.Students, want) {
+		t.Errorf("expected Bob, left out, to be absent, got %+v", roll.Students)
+	}
+
+	// Late counts as attended; excused sessions are not counted at all.
+	for id, want := range map[int][2]int{1: {2, 2}, 2: {1, 2}, 3: {0, 1}} {
+		a, err := p.Academic.EnrollmentAttendance(id, 101)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := [2]int{a.Attended, a.Held}; got != want {
+			t.Errorf("student %d: expected %d of %d sessions attended, got %d of %d", id, want[0], want[1], got[0], got[1])
+		}
+	}
+	sessions, ok := p.Academic.StudentSessions(101, 2, "T1")
+	if !ok || len(sessions) != 2 || sessions[0].Status != StatusLate || sessions[1].Status != StatusAbsent {
+		t.Errorf("expected Bob late to the lecture and absent from the lab, got %+v", sessions)
+	}
+
+	// Each session Alice has a record for stands for one class marked by
+	// date on its day; a third class that day and one the next day still count.
+	for _, mark := range []struct {
+		at      time.Time
+		present bool
+	}{{day, true}, {day.Add(2 * time.Hour), true}, {day.Add(5 * time.Hour), false}, {day.AddDate(0, 0, 1), false}} {
+		Giveattendence(p.Academic.NewRegistrarS, 101, 1, "T1", mark.present, mark.at)
+	}
+	if a, err := p.Academic.EnrollmentAttendance(1, 101); err != nil || a.Attended != 2 || a.Held != 4 {
+		t.Errorf("expected Alice to have attended 2 of 4 classes, got %+v, %v", a, err)
+	}
+	// Sessions and date marks fall on the same day whatever the time zone.
+	ist := time.FixedZone("IST", 5*3600+1800)
+	dawn := time.Date(2025, 7, 3, 1, 0, 0, 0, ist)
+	extra, err := p.Academic.ScheduleSession(ClassSession{CourseID: 101, TeacherID: "T1", Date: dawn, Period: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC); !extra.Date.Equal(want) {
+		t.Errorf("expected the session on %s, got %s", want, extra.Date)
+	}
+	if err := p.Academic.TakeAttendance(extra.ID, map[int]AttendanceStatus{2: StatusPresent}); err != nil {
+		t.Fatal(err)
+	}
+	Giveattendence(p.Academic.NewRegistrarS, 101, 2, "T1", true, dawn)
+	if a, err := p.Academic.EnrollmentAttendance(2, 101); err != nil || a.Attended != 2 || a.Held != 3 {
+		t.Errorf("expected Bob to have attended 2 of 3 classes, got %+v, %v", a, err)
+	}
+
+	snap, err := p.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestorePortal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := restored.Academic.SessionRoll(lab.ID); err != nil || !reflect.DeepEqual(got, roll) {
+		t.Errorf("expected the roll to survive a snapshot, got %+v, %v", got, err)
+	}
+}
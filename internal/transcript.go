@@ -0,0 +1,118 @@
+package internal
+
+import "sort"
+
+// SetResultRecorder tells the registrar where to send course results it
+// records, so placement records stay in step with transcripts.
+func (r *NewRegistrarS) SetResultRecorder(record func(CourseResult)) {
+	r.recordResults = record
+}
+
+// grade grades e, reading letters on the registrar's grade scale.
+func (r *NewRegistrarS) grade(e Enrollment) (string, error) {
+	if g, ok := e.Grader.(AlphabeticGrader); ok {
+		letter, err := g.LetterGrade(e, r.GradeScale())
+		return string(letter), err
+	}
+	return e.Grader.Grade(e)
+}
+
+// creditCourse returns the course as the teacher was assigned it.
+func (r *NewRegistrarS) creditCourse(teacherID string, courseID int) (CreditCourse, bool) {
+	for _, te := range r.Teachermap {
+		if te.TID() == teacherID && te.Course.Id == courseID {
+			return te.CreditCourse, true
+		}
+	}
+	return CreditCourse{}, false
+}
+
+// courseResult turns e's score into a transcript entry, with the credits and
+// semester of the teacher's course. It returns false for graders that do
+// not give letters on the grade scale, or have not graded e yet.
+func (r *NewRegistrarS) courseResult(e EnrollNew) (CourseResult, bool, error) {
+	g, ok := e.Grader.(AlphabeticGrader)
+	if !ok {
+		return CourseResult{}, false, nil
+	}
+	letter, err := g.LetterGrade(e.Enrollment, r.GradeScale())
+	if err != nil || letter == "" {
+		return CourseResult{}, false, err
+	}
+	cc, ok := r.creditCourse(e.Teacher.TID(), e.Course.Id)
+	if !ok || cc.Credits <= 0 || cc.Semester <= 0 {
+		return CourseResult{}, false, notEligiblef("course %d needs credits and a semester assigned to teacher %s before it is graded on the grade scale", e.Course.Id, e.Teacher.TID())
+	}
+	return NewCourseResult(e.Student.ID(), e.Course.Id, e.Course.Name, letter, cc.Semester, float64(cc.Credits)), true, nil
+}
+
+// recordResult puts cr on the student's transcript, replacing an earlier
+// result for the same course.
+func (r *NewRegistrarS) recordResult(cr CourseResult) {
+	replaced := false
+	for i, old := range r.results {
+		if old.StudentId == cr.StudentId && old.CourseId == cr.CourseId {
+			r.results[i], replaced = cr, true
+			break
+		}
+	}
+	if !replaced {
+		r.results = append(r.results, cr)
+	}
+	if r.recordResults != nil {
+		r.recordResults(cr)
+	}
+}
+
+// CourseResults returns the results recorded for a student from uploaded
+// marks, by semester and course.
+func (r *NewRegistrarS) CourseResults(studentID int) []CourseResult {
+	var out []CourseResult
+	for _, cr := range r.results {
+		if cr.StudentId == studentID {
+			out = append(out, cr)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Semester != out[j].Semester {
+			return out[i].Semester < out[j].Semester
+		}
+		return out[i].CourseId < out[j].CourseId
+	})
+	return out
+}
+
+// Transcript builds a student's academic record from the results recorded
+// for them, on the registrar's grade scale.
+func (r *NewRegistrarS) Transcript(studentID int) *AcademicRecord {
+	record := NewAcademicRecord(studentID)
+	record.SetGradeScale(r.gradeScale)
+	for _, cr := range r.CourseResults(studentID) {
+		record.AddResult(cr, cr.Semester)
+	}
+	record.Status = NewGPACalculator().DetermineStatus(record.GradeScale().OnTenPointScale(record.CGPA))
+	return record
+}
+
+// RecordResult updates a registered applicant's academic record with a
+// course result, recalculating their CGPA and status. Results of students
+// who have not registered for placements are ignored.
+func (pr *PlacementRegistrar) RecordResult(cr CourseResult) {
+	a, err := pr.ApplicantByID(cr.StudentId)
+	if err != nil {
+		return
+	}
+	for sem, sr := range a.Semesters {
+		if _, ok := sr.Courses[cr.CourseId]; !ok || sem == cr.Semester {
+			continue
+		}
+		delete(sr.Courses, cr.CourseId)
+		if len(sr.Courses) == 0 {
+			delete(a.Semesters, sem)
+		} else {
+			sr.calculateSGPA()
+		}
+	}
+	a.AddResult(cr, cr.Semester)
+	a.Status = NewGPACalculator().DetermineStatus(a.GradeScale().OnTenPointScale(a.CGPA))
+}
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestScaleGrader(t *testing.T) {
+	g := ScaleGrader{MaxScore: 50}
+	for score, want := range map[float64]AlphabeticGrade{50: O, 44: Aplus, 35: A, 25: B, 19: F} {
+		got, err := g.LetterGrade(Enrollment{score: score}, DefaultGradeScale())
+		if err != nil || got != want {
+			t.Errorf("%g out of 50: expected %s, got %s, %v", score, want, got, err)
+		}
+	}
+	if _, err := g.LetterGrade(Enrollment{score: 51}, DefaultGradeScale()); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected a score above the maximum to be refused, got %v", err)
+	}
+	if grade, _ := (ScaleGrader{}).Grade(Enrollment{score: 91}); grade != "O" {
+		t.Errorf("expected marks out of 100 by default, got %s", grade)
+	}
+
+	four, err := ParseGradeScale([]byte(fourPointScale))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := g.LetterGrade(Enrollment{score: 41}, four); got != "B" {
+		t.Errorf("expected 82%% to be a B on the 4.0 scale, got %s", got)
+	}
+}
+
+func TestUploadStudentMark_RecordsTranscript(t *testing.T) {
+	p := NewPortal()
+	teacher := NewTeacher("T1", "Prof. Smith")
+	alice := NewStudent(1, "Alice")
+	math, physics := NewCourse(101, "Math"), NewCourse(102, "Physics")
+	p.Academic.AddStudent(alice)
+	p.Academic.AddTeacher(teacher)
+	mathCourse := NewCreditCourse(math, 4)
+	mathCourse.Semester = 1
+	p.Academic.AddTeacherenrollment(NewTeacherEnrollment(teacher, mathCourse))
+	p.Academic.AddTeacherenrollment(NewTeacherEnrollment(teacher, NewCreditCourse(physics, 3)))
+	p.Academic.AddEnrollnew(NewEnrollNew(alice, math, ScaleGrader{}, 0, Attendance{}, teacher))
+	p.Academic.AddEnrollnew(NewEnrollNew(alice, physics, ScaleGrader{}, 0, Attendance{}, teacher))
+	if err := p.Placement.AddApplicant(NewApplicant(alice, *NewAcademicRecord(1))); err != nil {
+		t.Fatal(err)
+	}
+	ts := &TeacherService{Registrar: p.Academic, Teacher: teacher}
+
+	if err := ts.UploadStudentMark(101, 1, 120); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected a mark above 100 to be refused, got %v", err)
+	}
+	if err := ts.UploadStudentMark(102, 1, 80); !errors.Is(err, ErrNotEligible) {
+		t.Fatalf("expected a course without a semester to be refused, got %v", err)
+	}
+	if err := ts.UploadStudentMark(101, 1, 75); err != nil {
+		t.Fatal(err)
+	}
+	results := p.Academic.CourseResults(1)
+	if len(results) != 1 || results[0].Grade != A || results[0].Semester != 1 || results[0].Credits != 4 {
+		t.Fatalf("expected an A in semester 1 worth 4 credits, got %+v", results)
+	}
+
+	// A corrected mark replaces the earlier result.
+	if err := ts.UploadStudentMark(101, 1, 92); err != nil {
+		t.Fatal(err)
+	}
+	transcript := p.Academic.Transcript(1)
+	if got := transcript.Semesters[1].Courses[101].Grade; got != O || len(p.Academic.CourseResults(1)) != 1 {
+		t.Errorf("expected the O to replace the A, got %s", got)
+	}
+	if transcript.Semesters[1].SGPA != 10 {
+		t.Errorf("expected an SGPA of 10, got %v", transcript.Semesters[1].SGPA)
+	}
+	applicant, _ := p.Placement.ApplicantByID(1)
+	if got := applicant.Semesters[1].Courses[101].Grade; got != O || applicant.CGPA != transcript.CGPA {
+		t.Errorf("expected the applicant's record to follow the transcript, got %s and CGPA %v", got, applicant.CGPA)
+	}
+	if msgs := p.Notifications.Inbox().Messages(1); len(msgs) != 2 || msgs[1].Body != "You scored 92.00 in Math (course #101), grade O." {
+		t.Errorf("expected the marks notification to carry the letter, got %+v", msgs)
+	}
+
+	snap, err := p.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestorePortal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := restored.Academic.CourseResults(1); len(got) != 1 || got[0].Grade != O {
+		t.Errorf("expected the transcript to survive a snapshot, got %+v", got)
+	}
+	if err := restored.SetGradeScale(&GradeScale{Name: "pass/fail", Bands: []GradeBand{{Letter: "P", Points: 1, Pass: true, MinScore: 40}, {Letter: F, MinScore: 0}}}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected a scale without O to be refused while the transcript has one, got %v", err)
+	}
+}